@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/fatih/color"
 	"github.com/kris-nova/logger"
+	lol "github.com/kris-nova/lolgopher"
 	"github.com/spf13/cobra"
 
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
@@ -14,11 +16,15 @@ import (
 	"github.com/weaveworks/eksctl/pkg/ctl/drain"
 	"github.com/weaveworks/eksctl/pkg/ctl/generate"
 	"github.com/weaveworks/eksctl/pkg/ctl/get"
+	"github.com/weaveworks/eksctl/pkg/ctl/gettoken"
 	"github.com/weaveworks/eksctl/pkg/ctl/gitops"
 	"github.com/weaveworks/eksctl/pkg/ctl/install"
+	"github.com/weaveworks/eksctl/pkg/ctl/replace"
+	"github.com/weaveworks/eksctl/pkg/ctl/resume"
 	"github.com/weaveworks/eksctl/pkg/ctl/scale"
 	"github.com/weaveworks/eksctl/pkg/ctl/update"
 	"github.com/weaveworks/eksctl/pkg/ctl/utils"
+	"github.com/weaveworks/eksctl/pkg/events"
 )
 
 func addCommands(rootCmd *cobra.Command, flagGrouping *cmdutils.FlagGrouping) {
@@ -28,12 +34,15 @@ func addCommands(rootCmd *cobra.Command, flagGrouping *cmdutils.FlagGrouping) {
 	rootCmd.AddCommand(delete.Command(flagGrouping))
 	rootCmd.AddCommand(scale.Command(flagGrouping))
 	rootCmd.AddCommand(drain.Command(flagGrouping))
+	rootCmd.AddCommand(replace.Command(flagGrouping))
+	rootCmd.AddCommand(resume.Command(flagGrouping))
 	if os.Getenv("EKSCTL_EXPERIMENTAL") == "true" {
 		rootCmd.AddCommand(install.Command(flagGrouping))
 		rootCmd.AddCommand(generate.Command(flagGrouping))
 		rootCmd.AddCommand(gitops.Command(flagGrouping))
 	}
 	rootCmd.AddCommand(utils.Command(flagGrouping))
+	rootCmd.AddCommand(gettoken.Command(flagGrouping))
 	rootCmd.AddCommand(completion.Command(rootCmd))
 	rootCmd.AddCommand(versionCmd(flagGrouping))
 }
@@ -59,6 +68,7 @@ func main() {
 	rootCmd.PersistentFlags().IntVarP(&logger.Level, "verbose", "v", 3, "set log level, use 0 to silence, 4 for debugging and 5 for debugging with AWS debug logging")
 
 	colorValue := rootCmd.PersistentFlags().StringP("color", "C", "true", "toggle colorized logs (valid options: true, false, fabulous)")
+	outputEvents := rootCmd.PersistentFlags().String("output-events", "", fmt.Sprintf("output progress and result as structured events, log messages are written to stderr instead of stdout (valid options: %s)", events.OutputJSONL))
 
 	cobra.OnInitialize(func() {
 		// Control colored output
@@ -68,10 +78,40 @@ func main() {
 		logger.Timestamps = logger.Level >= 4
 	})
 
+	rootCmd.PersistentPreRunE = func(_ *cobra.Command, _ []string) error {
+		switch *outputEvents {
+		case "":
+		case events.OutputJSONL:
+			// keep stdout for events and command output
+			events.Enable(os.Stdout)
+			redirectLogsToStderr()
+		default:
+			return fmt.Errorf("--output-events=%s is not supported (valid options: %s)", *outputEvents, events.OutputJSONL)
+		}
+		return nil
+	}
+
 	rootCmd.SetUsageFunc(flagGrouping.Usage)
 
-	if err := rootCmd.Execute(); err != nil {
+	if cmd, err := rootCmd.ExecuteC(); err != nil {
+		events.EmitResult(cmd.CommandPath(), err)
 		fmt.Println(err)
 		os.Exit(-1)
 	}
 }
+
+// redirectLogsToStderr points the writers of logger at stderr; logger writes plain logs to
+// os.Stdout, which has to be kept for command output, so colors are enabled without any
+// color attributes for plain logs, making logger write them to color.Output too
+func redirectLogsToStderr() {
+	if !logger.Color && !logger.Fabulous {
+		logger.Color = true
+		color.NoColor = true
+	}
+	color.Output = os.Stderr
+	for _, w := range []interface{}{logger.FabulousWriter, logger.FabulousTrueWriter} {
+		if w, ok := w.(*lol.Writer); ok {
+			w.Output = os.Stderr
+		}
+	}
+}
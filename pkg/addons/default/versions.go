@@ -0,0 +1,92 @@
+package defaultaddons
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ImageTags returns the image tags of add-ons that the update commands would
+// install for the given control plane version
+func ImageTags(controlPlaneVersion string) (map[string]string, error) {
+	tags := map[string]string{
+		KubeProxy: "v" + controlPlaneVersion,
+	}
+
+	awsNode, err := LoadAsset(AWSNode, "yaml")
+	if err != nil {
+		return nil, err
+	}
+	if tags[AWSNode], err = firstImageTag(awsNode, AWSNode); err != nil {
+		return nil, err
+	}
+
+	coreDNS, err := loadAssetCoreDNS(controlPlaneVersion)
+	if err != nil {
+		return nil, err
+	}
+	if tags[CoreDNS], err = firstImageTag(coreDNS, CoreDNS); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// CurrentImageTags returns the image tags of add-ons that are installed,
+// add-ons that cannot be found are omitted
+func CurrentImageTags(clientSet kubernetes.Interface) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, name := range []string{AWSNode, KubeProxy} {
+		d, err := clientSet.AppsV1().DaemonSets(metav1.NamespaceSystem).Get(name, metav1.GetOptions{})
+		if err != nil {
+			if apierrs.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "getting %q", name)
+		}
+		if tags[name], err = containerImageTag(d.Spec.Template.Spec.Containers, name); err != nil {
+			return nil, err
+		}
+	}
+
+	d, err := clientSet.AppsV1().Deployments(metav1.NamespaceSystem).Get(CoreDNS, metav1.GetOptions{})
+	if err != nil {
+		if apierrs.IsNotFound(err) {
+			return tags, nil
+		}
+		return nil, errors.Wrapf(err, "getting %q", CoreDNS)
+	}
+	if tags[CoreDNS], err = containerImageTag(d.Spec.Template.Spec.Containers, CoreDNS); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func firstImageTag(list *metav1.List, name string) (string, error) {
+	for _, item := range list.Items {
+		switch obj := item.Object.(type) {
+		case *appsv1.DaemonSet:
+			return containerImageTag(obj.Spec.Template.Spec.Containers, name)
+		case *appsv1.Deployment:
+			return containerImageTag(obj.Spec.Template.Spec.Containers, name)
+		}
+	}
+	return "", fmt.Errorf("no workload found in manifest for %q", name)
+}
+
+func containerImageTag(containers []corev1.Container, name string) (string, error) {
+	if len(containers) == 0 {
+		return "", fmt.Errorf("%s has no containers", name)
+	}
+	imageParts := strings.Split(containers[0].Image, ":")
+	if len(imageParts) != 2 {
+		return "", fmt.Errorf("unexpected image format %q for %q", containers[0].Image, name)
+	}
+	return imageParts[1], nil
+}
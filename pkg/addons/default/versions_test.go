@@ -0,0 +1,32 @@
+package defaultaddons_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/weaveworks/eksctl/pkg/addons/default"
+	"github.com/weaveworks/eksctl/pkg/testutils"
+)
+
+var _ = Describe("default addons - versions", func() {
+	It("can get image tags that would be installed", func() {
+		tags, err := ImageTags("1.13.7")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tags).To(Equal(map[string]string{
+			AWSNode:   "v1.5.0",
+			CoreDNS:   "v1.2.6",
+			KubeProxy: "v1.13.7",
+		}))
+	})
+
+	It("can get image tags that are installed", func() {
+		clientSet, _ := testutils.NewFakeClientSetWithSamples("testdata/sample-1.12.json")
+		tags, err := CurrentImageTags(clientSet)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tags).To(Equal(map[string]string{
+			AWSNode:   "v1.4.1",
+			CoreDNS:   "v1.2.2",
+			KubeProxy: "v1.12.6",
+		}))
+	})
+})
@@ -27,6 +27,9 @@ const (
 	// ResolverAuto is used to indicate that the latest EKS AMIs should be used for the nodes. This implies
 	// that automatic resolution of AMI will occur.
 	ResolverAuto = api.NodeImageResolverAuto
+	// ResolverSSM is used to indicate that the AMIs recommended by EKS, which are published as
+	// SSM parameters, should be used
+	ResolverSSM = api.NodeImageResolverSSM
 )
 
 // Variations of iamge classes
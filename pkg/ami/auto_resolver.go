@@ -42,6 +42,42 @@ var ImageSearchPatterns = map[string]map[string]map[int]string{
 	},
 }
 
+// customOwnerAccountIDs holds the owner account IDs of custom image families
+var customOwnerAccountIDs = map[string]string{}
+
+// RegisterImageFamilies makes custom image families known to the auto resolver,
+// by adding their name patterns to ImageSearchPatterns
+func RegisterImageFamilies(families []api.ImageFamily) {
+	for _, family := range families {
+		customOwnerAccountIDs[family.Name] = family.OwnerAccountID
+		for class, patterns := range map[int]map[string]string{
+			ImageClassGeneral: family.NamePatterns,
+			ImageClassGPU:     family.GPUNamePatterns,
+		} {
+			for version, pattern := range patterns {
+				if _, ok := ImageSearchPatterns[version]; !ok {
+					ImageSearchPatterns[version] = map[string]map[int]string{}
+				}
+				if _, ok := ImageSearchPatterns[version][family.Name]; !ok {
+					ImageSearchPatterns[version][family.Name] = map[int]string{}
+				}
+				ImageSearchPatterns[version][family.Name][class] = pattern
+			}
+		}
+	}
+}
+
+// IsKnownImageFamily returns true for the built-in image families, and for
+// the custom image families that were registered with RegisterImageFamilies
+func IsKnownImageFamily(imageFamily string) bool {
+	switch imageFamily {
+	case ImageFamilyAmazonLinux2, ImageFamilyUbuntu1804:
+		return true
+	}
+	_, ok := customOwnerAccountIDs[imageFamily]
+	return ok
+}
+
 // OwnerAccountID returns the AWS account ID that owns worker AMI.
 func OwnerAccountID(imageFamily string, region string) (string, error) {
 	switch imageFamily {
@@ -50,6 +86,9 @@ func OwnerAccountID(imageFamily string, region string) (string, error) {
 	case ImageFamilyAmazonLinux2:
 		return api.EKSResourceAccountID(region), nil
 	default:
+		if ownerAccountID, ok := customOwnerAccountIDs[imageFamily]; ok {
+			return ownerAccountID, nil
+		}
 		return "", fmt.Errorf("unable to determine the account owner for image family %s", imageFamily)
 	}
 }
@@ -75,6 +114,11 @@ func (r *AutoResolver) Resolve(region, version, instanceType, imageFamily string
 		}
 	}
 
+	if namePattern == "" {
+		logger.Critical("image family %s has no image name pattern for version %s", imageFamily, version)
+		return "", NewErrFailedResolution(region, version, instanceType, imageFamily)
+	}
+
 	ownerAccount, err := OwnerAccountID(imageFamily, region)
 	if err != nil {
 		logger.Critical("%v", err)
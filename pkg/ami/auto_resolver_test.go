@@ -7,6 +7,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/mock"
 	. "github.com/weaveworks/eksctl/pkg/ami"
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/eks"
 	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
 )
@@ -212,6 +213,65 @@ var _ = Describe("AMI Auto Resolution", func() {
 				})
 			})
 		})
+
+		Context("with a custom image family", func() {
+			BeforeEach(func() {
+				RegisterImageFamilies([]api.ImageFamily{
+					{
+						Name:           "Golden",
+						OwnerAccountID: "123456789012",
+						NamePatterns: map[string]string{
+							"1.12": "golden-eks-node-1.12-*",
+						},
+						Bootstrap: ImageFamilyAmazonLinux2,
+					},
+				})
+				region = "eu-west-1"
+				version = "1.12"
+				instanceType = "t2.medium"
+				imageFamily = "Golden"
+				expectedAmi = "ami-golden"
+			})
+
+			It("should return the owner account ID of the family", func() {
+				ownerAccount, err := OwnerAccountID(imageFamily, region)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ownerAccount).To(Equal("123456789012"))
+			})
+
+			It("should know the family once it's registered", func() {
+				Expect(IsKnownImageFamily(imageFamily)).To(BeTrue())
+				Expect(IsKnownImageFamily("Silver")).To(BeFalse())
+				Expect(IsKnownImageFamily(ImageFamilyUbuntu1804)).To(BeTrue())
+			})
+
+			It("should find images using the name pattern of the family", func() {
+				_, p = createProviders()
+				addMockDescribeImages(p, "golden-eks-node-1.12-*", expectedAmi, "available", "2018-08-20T23:25:53.000Z", imageFamily)
+
+				resolvedAmi, err = NewAutoResolver(p.MockEC2()).Resolve(region, version, instanceType, imageFamily)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resolvedAmi).To(Equal(expectedAmi))
+			})
+
+			It("should fail for versions without a name pattern", func() {
+				_, p = createProviders()
+
+				_, err = NewAutoResolver(p.MockEC2()).Resolve(region, "1.13", instanceType, imageFamily)
+
+				Expect(err).To(HaveOccurred())
+				Expect(p.MockEC2().AssertNotCalled(GinkgoT(), "DescribeImages", mock.Anything)).To(BeTrue())
+			})
+
+			It("should fail for GPU instance types without a GPU name pattern", func() {
+				_, p = createProviders()
+
+				_, err = NewAutoResolver(p.MockEC2()).Resolve(region, version, "p2.xlarge", imageFamily)
+
+				Expect(err).To(HaveOccurred())
+			})
+		})
 	})
 })
 
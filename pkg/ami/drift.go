@@ -0,0 +1,118 @@
+package ami
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/pkg/errors"
+)
+
+// Drift describes how far an image is behind the latest image
+type Drift struct {
+	CurrentImageID string `json:"currentImageID"`
+	CurrentName    string `json:"currentName,omitempty"`
+	LatestImageID  string `json:"latestImageID"`
+	LatestName     string `json:"latestName,omitempty"`
+	// Behind is the time between the creation of the current and the latest
+	// image, it's zero when either of the images can't be found
+	Behind time.Duration `json:"behind"`
+}
+
+// UpToDate tells whether the current image is the latest one
+func (d *Drift) UpToDate() bool {
+	return d.CurrentImageID == d.LatestImageID
+}
+
+// String describes the drift for humans
+func (d *Drift) String() string {
+	if d.UpToDate() {
+		return "up to date"
+	}
+	if d.Behind <= 0 {
+		return fmt.Sprintf("differs from %s", d.LatestImageID)
+	}
+	return fmt.Sprintf("%d day(s) behind %s", int(d.Behind.Hours()/24), d.LatestImageID)
+}
+
+// GetDrift compares the current image with the latest one, using their creation dates
+func GetDrift(ec2api ec2iface.EC2API, currentImageID, latestImageID string) (*Drift, error) {
+	drift := &Drift{
+		CurrentImageID: currentImageID,
+		LatestImageID:  latestImageID,
+	}
+	if drift.UpToDate() {
+		return drift, nil
+	}
+
+	output, err := ec2api.DescribeImages(&ec2.DescribeImagesInput{
+		ImageIds: aws.StringSlice([]string{currentImageID, latestImageID}),
+	})
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "InvalidAMIID.NotFound" {
+		// the current image may have been deregistered
+		return drift, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "describing images %q and %q", currentImageID, latestImageID)
+	}
+
+	var currentCreated, latestCreated time.Time
+	for _, image := range output.Images {
+		//nolint:gosec
+		created, _ := time.Parse(time.RFC3339, aws.StringValue(image.CreationDate))
+		switch aws.StringValue(image.ImageId) {
+		case currentImageID:
+			drift.CurrentName = aws.StringValue(image.Name)
+			currentCreated = created
+		case latestImageID:
+			drift.LatestName = aws.StringValue(image.Name)
+			latestCreated = created
+		}
+	}
+
+	if !currentCreated.IsZero() && !latestCreated.IsZero() && latestCreated.After(currentCreated) {
+		drift.Behind = latestCreated.Sub(currentCreated)
+	}
+	return drift, nil
+}
+
+// GetImageKubernetesVersion returns the Kubernetes version of an image of the given family, by matching
+// its name with ImageSearchPatterns; it returns an empty string when the image can't be found, as it may
+// have been deregistered, or when its name doesn't match any of the patterns
+func GetImageKubernetesVersion(ec2api ec2iface.EC2API, imageFamily, imageID string) (string, error) {
+	output, err := ec2api.DescribeImages(&ec2.DescribeImagesInput{
+		ImageIds: aws.StringSlice([]string{imageID}),
+	})
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "InvalidAMIID.NotFound" {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "describing image %q", imageID)
+	}
+	if len(output.Images) == 0 {
+		return "", nil
+	}
+
+	name := aws.StringValue(output.Images[0].Name)
+	for version, families := range ImageSearchPatterns {
+		for _, pattern := range families[imageFamily] {
+			if matchNamePattern(pattern, name) {
+				return version, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// matchNamePattern matches a name with a pattern that uses the wildcards of EC2 filters
+func matchNamePattern(pattern, name string) bool {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.Replace(expr, `\*`, ".*", -1)
+	expr = strings.Replace(expr, `\?`, ".", -1)
+	return regexp.MustCompile("^" + expr + "$").MatchString(name)
+}
@@ -0,0 +1,101 @@
+package ami_test
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	. "github.com/weaveworks/eksctl/pkg/ami"
+	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
+)
+
+var _ = Describe("AMI drift", func() {
+
+	var p *mockprovider.MockProvider
+
+	BeforeEach(func() {
+		p = mockprovider.NewMockProvider()
+	})
+
+	It("should be up to date without describing images when the images are the same", func() {
+		drift, err := GetDrift(p.MockEC2(), "ami-1", "ami-1")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drift.UpToDate()).To(BeTrue())
+		Expect(drift.String()).To(Equal("up to date"))
+		Expect(p.MockEC2().AssertNotCalled(GinkgoT(), "DescribeImages", mock.Anything)).To(BeTrue())
+	})
+
+	It("should report how far behind the current image is", func() {
+		p.MockEC2().On("DescribeImages", mock.Anything).Return(&ec2.DescribeImagesOutput{
+			Images: []*ec2.Image{
+				{
+					ImageId:      aws.String("ami-2"),
+					Name:         aws.String("amazon-eks-node-1.13-v20191213"),
+					CreationDate: aws.String("2019-12-13T00:00:00.000Z"),
+				},
+				{
+					ImageId:      aws.String("ami-1"),
+					Name:         aws.String("amazon-eks-node-1.13-v20191119"),
+					CreationDate: aws.String("2019-11-19T00:00:00.000Z"),
+				},
+			},
+		}, nil)
+
+		drift, err := GetDrift(p.MockEC2(), "ami-1", "ami-2")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drift.UpToDate()).To(BeFalse())
+		Expect(drift.CurrentName).To(Equal("amazon-eks-node-1.13-v20191119"))
+		Expect(drift.LatestName).To(Equal("amazon-eks-node-1.13-v20191213"))
+		Expect(drift.Behind).To(Equal(24 * 24 * time.Hour))
+		Expect(drift.String()).To(Equal("24 day(s) behind ami-2"))
+	})
+
+	It("should tolerate a current image that was deregistered", func() {
+		p.MockEC2().On("DescribeImages", mock.Anything).Return(nil, awserr.New("InvalidAMIID.NotFound", "not found", nil))
+
+		drift, err := GetDrift(p.MockEC2(), "ami-1", "ami-2")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drift.UpToDate()).To(BeFalse())
+		Expect(drift.Behind).To(BeZero())
+		Expect(drift.String()).To(Equal("differs from ami-2"))
+	})
+
+	It("should get the Kubernetes version of an image from its name", func() {
+		p.MockEC2().On("DescribeImages", mock.Anything).Return(&ec2.DescribeImagesOutput{
+			Images: []*ec2.Image{
+				{
+					ImageId: aws.String("ami-1"),
+					Name:    aws.String("ubuntu-eks/k8s_1.12/images/hvm-ssd/ubuntu-bionic-18.04-amd64-server-20190212"),
+				},
+			},
+		}, nil)
+
+		version, err := GetImageKubernetesVersion(p.MockEC2(), ImageFamilyUbuntu1804, "ami-1")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(Equal("1.12"))
+	})
+
+	It("should not get the Kubernetes version of an image whose name doesn't match the patterns", func() {
+		p.MockEC2().On("DescribeImages", mock.Anything).Return(&ec2.DescribeImagesOutput{
+			Images: []*ec2.Image{
+				{
+					ImageId: aws.String("ami-1"),
+					Name:    aws.String("my-own-image"),
+				},
+			},
+		}, nil)
+
+		version, err := GetImageKubernetesVersion(p.MockEC2(), ImageFamilyAmazonLinux2, "ami-1")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(BeEmpty())
+	})
+})
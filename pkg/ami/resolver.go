@@ -1,15 +1,24 @@
 package ami
 
-var (
-	// DefaultResolvers contains a list of resolvers to try in order
-	DefaultResolvers = []Resolver{&StaticGPUResolver{}, &StaticDefaultResolver{}}
-)
+import "github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+
+// NewDefaultResolvers returns the default resolvers to try in order, the AMI
+// recommended by EKS comes first and the static AMIs are the fallback for
+// the image families and versions that aren't published in SSM
+func NewDefaultResolvers(api ssmiface.SSMAPI) []Resolver {
+	return append([]Resolver{NewSSMResolver(api)}, NewStaticResolvers()...)
+}
+
+// NewStaticResolvers returns the resolvers of the AMIs compiled into eksctl
+func NewStaticResolvers() []Resolver {
+	return []Resolver{&StaticGPUResolver{}, &StaticDefaultResolver{}}
+}
 
 // Resolve will resolve an AMI from the supplied region
-// and instance type. It will invoke a specific resolver
-// to do the actual determining of AMI.
-func Resolve(region, version, instanceType, imageFamily string) (string, error) {
-	for _, resolver := range DefaultResolvers {
+// and instance type. It will invoke the given resolvers in
+// order to do the actual determining of AMI.
+func Resolve(resolvers []Resolver, region, version, instanceType, imageFamily string) (string, error) {
+	for _, resolver := range resolvers {
 		ami, err := resolver.Resolve(region, version, instanceType, imageFamily)
 		if err != nil {
 			return "", err
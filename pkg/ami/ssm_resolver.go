@@ -0,0 +1,69 @@
+package ami
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	"github.com/weaveworks/eksctl/pkg/utils"
+)
+
+// MakeSSMParameterName returns the name of the SSM parameter that holds the
+// AMI recommended by EKS for the given version, instance type and image family,
+// it returns an empty string when EKS doesn't publish such a parameter
+func MakeSSMParameterName(version, instanceType, imageFamily string) string {
+	var family string
+	switch imageFamily {
+	case ImageFamilyAmazonLinux2:
+		family = "amazon-linux-2"
+		if utils.IsGPUInstanceType(instanceType) {
+			family = "amazon-linux-2-gpu"
+		}
+	default:
+		return ""
+	}
+	return fmt.Sprintf("/aws/service/eks/optimized-ami/%s/%s/recommended/image_id", version, family)
+}
+
+// SSMResolver resolves the AMI to the one recommended by EKS, by
+// reading the SSM parameters that EKS publishes in every region
+type SSMResolver struct {
+	api ssmiface.SSMAPI
+}
+
+// Resolve will return the AMI recommended by EKS, or an empty string
+// if there is no parameter for the version and image family
+func (r *SSMResolver) Resolve(region, version, instanceType, imageFamily string) (string, error) {
+	logger.Debug("resolving AMI using SSMResolver for region %s, version %s, instanceType %s and imageFamily %s", region, version, instanceType, imageFamily)
+
+	parameterName := MakeSSMParameterName(version, instanceType, imageFamily)
+	if parameterName == "" {
+		logger.Debug("can't resolve AMI using SSMResolver as image family %s is not published in SSM", imageFamily)
+		return "", nil
+	}
+
+	output, err := r.api.GetParameter(&ssm.GetParameterInput{
+		Name: aws.String(parameterName),
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ssm.ErrCodeParameterNotFound {
+			logger.Debug("SSM parameter %q not found", parameterName)
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "error getting AMI from SSM parameter %q", parameterName)
+	}
+
+	if output.Parameter == nil || aws.StringValue(output.Parameter.Value) == "" {
+		return "", nil
+	}
+	return aws.StringValue(output.Parameter.Value), nil
+}
+
+// NewSSMResolver creates a new SSMResolver
+func NewSSMResolver(api ssmiface.SSMAPI) *SSMResolver {
+	return &SSMResolver{api: api}
+}
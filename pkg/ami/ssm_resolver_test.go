@@ -0,0 +1,132 @@
+package ami_test
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	. "github.com/weaveworks/eksctl/pkg/ami"
+	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
+)
+
+var _ = Describe("AMI SSM Resolution", func() {
+
+	var (
+		p        *mockprovider.MockProvider
+		resolver *SSMResolver
+	)
+
+	BeforeEach(func() {
+		p = mockprovider.NewMockProvider()
+		resolver = NewSSMResolver(p.MockSSM())
+	})
+
+	Context("making parameter names", func() {
+		It("should use the amazon-linux-2 parameter for general instance types", func() {
+			Expect(MakeSSMParameterName("1.13", "m5.large", ImageFamilyAmazonLinux2)).
+				To(Equal("/aws/service/eks/optimized-ami/1.13/amazon-linux-2/recommended/image_id"))
+		})
+
+		It("should use the amazon-linux-2-gpu parameter for GPU instance types", func() {
+			Expect(MakeSSMParameterName("1.13", "p3.2xlarge", ImageFamilyAmazonLinux2)).
+				To(Equal("/aws/service/eks/optimized-ami/1.13/amazon-linux-2-gpu/recommended/image_id"))
+		})
+
+		It("should not have a parameter for Ubuntu", func() {
+			Expect(MakeSSMParameterName("1.13", "m5.large", ImageFamilyUbuntu1804)).To(BeEmpty())
+		})
+	})
+
+	Context("resolving an AMI", func() {
+		It("should return the value of the parameter", func() {
+			addMockGetParameter(p, "/aws/service/eks/optimized-ami/1.13/amazon-linux-2/recommended/image_id", "ami-12345", nil)
+
+			id, err := resolver.Resolve("us-west-2", "1.13", "m5.large", ImageFamilyAmazonLinux2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(Equal("ami-12345"))
+		})
+
+		It("should return an empty AMI when the parameter doesn't exist", func() {
+			addMockGetParameter(p, "/aws/service/eks/optimized-ami/1.99/amazon-linux-2/recommended/image_id", "",
+				awserr.New(ssm.ErrCodeParameterNotFound, "not found", nil))
+
+			id, err := resolver.Resolve("us-west-2", "1.99", "m5.large", ImageFamilyAmazonLinux2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(BeEmpty())
+		})
+
+		It("should return an empty AMI without calling SSM for image families that aren't published", func() {
+			id, err := resolver.Resolve("us-west-2", "1.13", "m5.large", ImageFamilyUbuntu1804)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(BeEmpty())
+			Expect(p.MockSSM().AssertNotCalled(GinkgoT(), "GetParameter", mock.Anything)).To(BeTrue())
+		})
+
+		It("should return other errors", func() {
+			addMockGetParameter(p, "/aws/service/eks/optimized-ami/1.13/amazon-linux-2/recommended/image_id", "",
+				errors.New("access denied"))
+
+			_, err := resolver.Resolve("us-west-2", "1.13", "m5.large", ImageFamilyAmazonLinux2)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("access denied"))
+		})
+	})
+
+	Context("resolving an AMI with the default resolvers", func() {
+		var resolvers []Resolver
+
+		BeforeEach(func() {
+			resolvers = NewDefaultResolvers(p.MockSSM())
+		})
+
+		It("should try the SSM resolver first", func() {
+			Expect(resolvers).To(HaveLen(3))
+			Expect(resolvers[0]).To(BeAssignableToTypeOf(&SSMResolver{}))
+			Expect(resolvers[1:]).To(Equal(NewStaticResolvers()))
+		})
+
+		It("should prefer the AMI recommended by EKS", func() {
+			addMockGetParameter(p, "/aws/service/eks/optimized-ami/1.12/amazon-linux-2/recommended/image_id", "ami-12345", nil)
+
+			id, err := Resolve(resolvers, "us-west-2", "1.12", "m5.large", ImageFamilyAmazonLinux2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(Equal("ami-12345"))
+		})
+
+		It("should fall back to the static AMIs when the parameter doesn't exist", func() {
+			addMockGetParameter(p, "/aws/service/eks/optimized-ami/1.12/amazon-linux-2/recommended/image_id", "",
+				awserr.New(ssm.ErrCodeParameterNotFound, "not found", nil))
+
+			id, err := Resolve(resolvers, "us-west-2", "1.12", "m5.large", ImageFamilyAmazonLinux2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(Equal("ami-0355c210cb3f58aa2"))
+		})
+	})
+})
+
+func addMockGetParameter(p *mockprovider.MockProvider, name, value string, err error) {
+	var output *ssm.GetParameterOutput
+	if err == nil {
+		output = &ssm.GetParameterOutput{
+			Parameter: &ssm.Parameter{
+				Name:  aws.String(name),
+				Value: aws.String(value),
+			},
+		}
+	}
+	p.MockSSM().On("GetParameter",
+		mock.MatchedBy(func(input *ssm.GetParameterInput) bool {
+			return *input.Name == name
+		}),
+	).Return(output, err)
+}
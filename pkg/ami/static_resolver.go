@@ -33,7 +33,13 @@ func (r *StaticGPUResolver) Resolve(region, version, instanceType, imageFamily s
 		return "", nil
 	}
 
-	regionalAMIs, ok := StaticImages[version][imageFamily][ImageClassGPU]
+	imageClasses, ok := StaticImages[version][imageFamily]
+	if !ok {
+		logger.Debug("can't resolve AMI using StaticGPUResolver as there are no static AMIs for version %s and image family %s", version, imageFamily)
+		return "", nil
+	}
+
+	regionalAMIs, ok := imageClasses[ImageClassGPU]
 	if !ok {
 		logger.Critical("image family %s doesn't support GPU image class", imageFamily)
 		return "", NewErrFailedResolution(region, version, instanceType, imageFamily)
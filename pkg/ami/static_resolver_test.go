@@ -21,7 +21,7 @@ var _ = Describe("AMI Static Resolution", func() {
 
 	DescribeTable("When resolving an AMI using the default resolvers",
 		func(c ResolveCase) {
-			actualAmi, err := ami.Resolve(c.Region, c.Version, c.InstanceType, c.ImageFamily)
+			actualAmi, err := ami.Resolve(ami.NewStaticResolvers(), c.Region, c.Version, c.InstanceType, c.ImageFamily)
 			Expect(actualAmi).Should(Equal(c.ExpectedAMI))
 			if c.ExpectError {
 				Expect(err).Should(HaveOccurred())
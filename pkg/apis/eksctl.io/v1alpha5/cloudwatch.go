@@ -10,6 +10,12 @@ type ClusterCloudWatch struct {
 type ClusterCloudWatchLogging struct {
 	//+optional
 	EnableTypes []string `json:"enableTypes,omitempty"`
+	// RetentionInDays sets the retention of the cluster log group, logs are kept indefinitely when not set
+	//+optional
+	RetentionInDays int `json:"retentionInDays,omitempty"`
+	// KMSKeyID is the ARN of a KMS key used to encrypt the cluster log group
+	//+optional
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
 }
 
 // SupportedCloudWatchClusterLogTypes retuls all supported logging facilities
@@ -17,6 +23,11 @@ func SupportedCloudWatchClusterLogTypes() []string {
 	return []string{"api", "audit", "authenticator", "controllerManager", "scheduler"}
 }
 
+// SupportedCloudWatchLogRetentionDays returns all retention periods supported by CloudWatch Logs
+func SupportedCloudWatchLogRetentionDays() []int {
+	return []int{1, 3, 5, 7, 14, 30, 60, 90, 120, 150, 180, 365, 400, 545, 731, 1827, 3653}
+}
+
 // HasClusterCloudWatchLogging determines if cluster logging was enabled or not
 func (c *ClusterConfig) HasClusterCloudWatchLogging() bool {
 	return c.CloudWatch != nil && c.CloudWatch.ClusterLogging != nil && len(c.CloudWatch.ClusterLogging.EnableTypes) > 0
@@ -26,3 +37,9 @@ func (c *ClusterConfig) HasClusterCloudWatchLogging() bool {
 func (c *ClusterConfig) AppendClusterCloudWatchLogTypes(types ...string) {
 	c.CloudWatch.ClusterLogging.EnableTypes = append(c.CloudWatch.ClusterLogging.EnableTypes, types...)
 }
+
+// HasClusterLogGroupSettings determines if retention or encryption of the cluster log group was set
+func (c *ClusterConfig) HasClusterLogGroupSettings() bool {
+	return c.CloudWatch != nil && c.CloudWatch.ClusterLogging != nil &&
+		(c.CloudWatch.ClusterLogging.RetentionInDays != 0 || c.CloudWatch.ClusterLogging.KMSKeyID != "")
+}
@@ -8,6 +8,15 @@ func SetClusterConfigDefaults(cfg *ClusterConfig) {
 			cfg.CloudWatch.ClusterLogging.EnableTypes = SupportedCloudWatchClusterLogTypes()
 		}
 	}
+
+	if cfg.SecurityGroups != nil {
+		setSecurityGroupRulesDefaults(cfg.SecurityGroups.IngressRules)
+		setSecurityGroupRulesDefaults(cfg.SecurityGroups.EgressRules)
+	}
+
+	if cfg.Git != nil {
+		setGitDefaults(cfg.Git)
+	}
 }
 
 // SetNodeGroupDefaults will set defaults for a given nodegroup
@@ -37,6 +46,8 @@ func SetNodeGroupDefaults(_ int, ng *NodeGroup) {
 	if ng.SecurityGroups.WithShared == nil {
 		ng.SecurityGroups.WithShared = Enabled()
 	}
+	setSecurityGroupRulesDefaults(ng.SecurityGroups.IngressRules)
+	setSecurityGroupRulesDefaults(ng.SecurityGroups.EgressRules)
 
 	if ng.SSH == nil {
 		ng.SSH = &NodeGroupSSH{
@@ -59,6 +70,10 @@ func SetNodeGroupDefaults(_ int, ng *NodeGroup) {
 		}
 	}
 
+	if ng.SSH.EnableSSM == nil {
+		ng.SSH.EnableSSM = Disabled()
+	}
+
 	if !IsSetAndNonEmptyString(ng.VolumeType) {
 		ng.VolumeType = &DefaultNodeVolumeType
 	}
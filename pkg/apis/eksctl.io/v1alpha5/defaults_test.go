@@ -85,6 +85,46 @@ var _ = Describe("ClusterConfig validation", func() {
 		})
 	})
 
+	Context("Git settings", func() {
+
+		It("Git defaults are set for the repo, the operator and the profiles", func() {
+			cfg := NewClusterConfig()
+			cfg.Git = &Git{
+				Repo: &Repo{
+					URL:   "git@github.com:org/repo.git",
+					Email: "user@example.com",
+				},
+				Profiles: []Profile{{Source: "app-dev"}},
+			}
+
+			SetClusterConfigDefaults(cfg)
+
+			Expect(cfg.Git.Repo.Branch).To(Equal("master"))
+			Expect(cfg.Git.Repo.FluxPath).To(Equal("flux/"))
+			Expect(cfg.Git.Repo.User).To(Equal("Flux"))
+			Expect(cfg.Git.Operator.Namespace).To(Equal("flux"))
+			Expect(cfg.Git.Operator.Label).To(Equal("flux"))
+			Expect(*cfg.Git.Operator.WithHelm).To(BeTrue())
+			Expect(cfg.Git.Operator.FluxVersion).To(Equal(FluxVersionV1))
+			Expect(cfg.Git.Profiles[0].Revision).To(BeEmpty())
+			Expect(cfg.Git.Profiles[0].OutputPath).To(Equal("base"))
+		})
+
+		It("Git defaults don't override values which are set", func() {
+			cfg := NewClusterConfig()
+			cfg.Git = &Git{
+				Repo:     &Repo{Branch: "main"},
+				Operator: &Operator{WithHelm: Disabled(), FluxVersion: FluxVersionV2},
+			}
+
+			SetClusterConfigDefaults(cfg)
+
+			Expect(cfg.Git.Repo.Branch).To(Equal("main"))
+			Expect(*cfg.Git.Operator.WithHelm).To(BeFalse())
+			Expect(cfg.Git.Operator.FluxVersion).To(Equal(FluxVersionV2))
+		})
+	})
+
 	Context("Cluster NAT settings", func() {
 
 		It("Cluster NAT defaults to single NAT gateway mode", func() {
@@ -0,0 +1,232 @@
+package v1alpha5
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Values for the Flux and Helm versions that can be set in git.operator
+const (
+	FluxVersionV1 = "v1"
+	FluxVersionV2 = "v2"
+	HelmVersionV2 = "v2"
+	HelmVersionV3 = "v3"
+)
+
+// Values for the Git hosting services that can be set in git.repo.provider
+const (
+	GitProviderGitHub = "github"
+	GitProviderGitLab = "gitlab"
+)
+
+type (
+	// Git groups the configuration of GitOps for the cluster: the repository
+	// Flux syncs from, how Flux is installed, and the profiles to apply
+	Git struct {
+		Repo *Repo `json:"repo"`
+		// +optional
+		Operator *Operator `json:"operator,omitempty"`
+		// Profiles are added to the repository once Flux is installed
+		// +optional
+		Profiles []Profile `json:"profiles,omitempty"`
+		// Catalog is the path or URL of the catalog in which the names of profiles
+		// are looked up (default: the catalog of the Quick Start profiles)
+		// +optional
+		Catalog string `json:"catalog,omitempty"`
+	}
+
+	// Repo is the Git repository Flux syncs from
+	Repo struct {
+		// URL of the repository, e.g. git@github.com:org/repo.git
+		URL string `json:"url"`
+		// +optional
+		Branch string `json:"branch,omitempty"`
+		// Paths within the repository where Flux looks for manifests
+		// +optional
+		Paths []string `json:"paths,omitempty"`
+		// FluxPath is the directory where the Flux manifests are committed
+		// +optional
+		FluxPath string `json:"fluxPath,omitempty"`
+		// User is the name of the Git committer
+		// +optional
+		User string `json:"user,omitempty"`
+		// Email of the Git committer
+		Email string `json:"email"`
+		// PrivateSSHKeyPath is the key used by eksctl to push to the repository,
+		// it is never committed or stored in the cluster
+		// +optional
+		PrivateSSHKeyPath string `json:"privateSSHKeyPath,omitempty"`
+		// Provider is the Git hosting service of the repository, either "github" or
+		// "gitlab"; when set, Flux's key is added to the repository as a deploy key
+		// with the token in $GITHUB_TOKEN or $GITLAB_TOKEN, and eksctl waits for
+		// Flux's first sync
+		// +optional
+		Provider string `json:"provider,omitempty"`
+		// ProviderURL is the base URL of the provider's API, for self-hosted instances
+		// (default: https://api.github.com or https://gitlab.com/api/v4)
+		// +optional
+		ProviderURL string `json:"providerURL,omitempty"`
+		// CreateIfMissing creates the repository, as a private one, with the
+		// provider when it doesn't exist
+		// +optional
+		CreateIfMissing bool `json:"createIfMissing,omitempty"`
+	}
+
+	// Operator holds the options of the Flux installation
+	Operator struct {
+		// +optional
+		Namespace string `json:"namespace,omitempty"`
+		// Label is used by Flux to keep track of its sync progress
+		// +optional
+		Label string `json:"label,omitempty"`
+		// WithHelm installs the Helm Operator (or the helm controller)
+		// +optional
+		WithHelm *bool `json:"withHelm,omitempty"`
+		// HelmVersions enabled in the Helm Operator, Tiller is only
+		// installed when "v2" is enabled
+		// +optional
+		HelmVersions []string `json:"helmVersions,omitempty"`
+		// FluxVersion is either "v1" or "v2" (the GitOps Toolkit)
+		// +optional
+		FluxVersion string `json:"fluxVersion,omitempty"`
+	}
+
+	// Profile is a set of templated manifests added to the repository
+	Profile struct {
+		// Source is the name of a profile of the catalog (e.g. app-dev) or the URL of its
+		// repository, optionally followed by @version (e.g. app-dev@v0.1.0)
+		Source string `json:"source"`
+		// Revision is the branch or tag of the profile's repository (default: master),
+		// it cannot differ from the version of the source
+		// +optional
+		Revision string `json:"revision,omitempty"`
+		// OutputPath is where the profile's manifests are written in the repository
+		// +optional
+		OutputPath string `json:"outputPath,omitempty"`
+		// ValuesFile is a YAML file whose values are available to the
+		// profile's templates as .Values
+		// +optional
+		ValuesFile string `json:"valuesFile,omitempty"`
+	}
+)
+
+// NewGit returns a Git configuration with all fields allocated,
+// so that they can be set from flags
+func NewGit() *Git {
+	return &Git{
+		Repo:     &Repo{},
+		Operator: &Operator{},
+	}
+}
+
+// HasGitOps determines if GitOps was configured for the cluster
+func (c *ClusterConfig) HasGitOps() bool {
+	return c.Git != nil && c.Git.Repo != nil && c.Git.Repo.URL != ""
+}
+
+func setGitDefaults(git *Git) {
+	if git.Repo == nil {
+		git.Repo = &Repo{}
+	}
+	if git.Repo.Branch == "" {
+		git.Repo.Branch = "master"
+	}
+	if git.Repo.FluxPath == "" {
+		git.Repo.FluxPath = "flux/"
+	}
+	if git.Repo.User == "" {
+		git.Repo.User = "Flux"
+	}
+
+	if git.Operator == nil {
+		git.Operator = &Operator{}
+	}
+	if git.Operator.Namespace == "" {
+		git.Operator.Namespace = "flux"
+	}
+	if git.Operator.Label == "" {
+		git.Operator.Label = "flux"
+	}
+	if git.Operator.WithHelm == nil {
+		git.Operator.WithHelm = Enabled()
+	}
+	if git.Operator.FluxVersion == "" {
+		git.Operator.FluxVersion = FluxVersionV1
+	}
+
+	for i := range git.Profiles {
+		profile := &git.Profiles[i]
+		if profile.OutputPath == "" {
+			profile.OutputPath = "base"
+		}
+	}
+}
+
+func validateGit(git *Git) error {
+	if git.Repo == nil || git.Repo.URL == "" {
+		return fmt.Errorf("git.repo.url must be set")
+	}
+	if !isSSHGitURL(git.Repo.URL) {
+		return fmt.Errorf("git.repo.url %q must be an SSH URL, e.g. git@github.com:org/repo.git", git.Repo.URL)
+	}
+	if git.Repo.Email == "" {
+		return fmt.Errorf("git.repo.email must be set")
+	}
+	switch git.Repo.Provider {
+	case "", GitProviderGitHub, GitProviderGitLab:
+	default:
+		return fmt.Errorf("git.repo.provider %q is not supported, must be either %q or %q",
+			git.Repo.Provider, GitProviderGitHub, GitProviderGitLab)
+	}
+	if git.Repo.Provider == "" {
+		if git.Repo.ProviderURL != "" {
+			return fmt.Errorf("git.repo.providerURL cannot be set without git.repo.provider")
+		}
+		if git.Repo.CreateIfMissing {
+			return fmt.Errorf("git.repo.createIfMissing cannot be set without git.repo.provider")
+		}
+	}
+
+	if git.Operator != nil {
+		switch git.Operator.FluxVersion {
+		case "", FluxVersionV1, FluxVersionV2:
+		default:
+			return fmt.Errorf("git.operator.fluxVersion %q is not supported, must be either %q or %q",
+				git.Operator.FluxVersion, FluxVersionV1, FluxVersionV2)
+		}
+		for i, version := range git.Operator.HelmVersions {
+			if version != HelmVersionV2 && version != HelmVersionV3 {
+				return fmt.Errorf("git.operator.helmVersions[%d] %q is not supported, must be either %q or %q",
+					i, version, HelmVersionV2, HelmVersionV3)
+			}
+		}
+	}
+
+	outputPaths := nameSet{}
+	for i, profile := range git.Profiles {
+		path := fmt.Sprintf("git.profiles[%d]", i)
+		if profile.Source == "" {
+			return fmt.Errorf("%s.source must be set", path)
+		}
+		if profile.OutputPath != "" {
+			if ok, err := outputPaths.checkNonUnique(path+".outputPath", profile.OutputPath); !ok {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isSSHGitURL checks for either the scp-like syntax (user@host:path)
+// or an ssh:// URL, as these are the only ones Flux can push to
+func isSSHGitURL(url string) bool {
+	if strings.HasPrefix(url, "ssh://") {
+		return true
+	}
+	if strings.Contains(url, "://") {
+		return false
+	}
+	at := strings.Index(url, "@")
+	colon := strings.Index(url, ":")
+	return at > 0 && colon > at+1 && colon < len(url)-1
+}
@@ -0,0 +1,130 @@
+package v1alpha5
+
+import (
+	"fmt"
+	"net"
+)
+
+const (
+	// SecurityGroupRuleProtocolTCP is the default protocol of a security group rule
+	SecurityGroupRuleProtocolTCP = "tcp"
+	// SecurityGroupRuleProtocolUDP represents UDP protocol
+	SecurityGroupRuleProtocolUDP = "udp"
+	// SecurityGroupRuleProtocolICMP represents ICMP protocol
+	SecurityGroupRuleProtocolICMP = "icmp"
+	// SecurityGroupRuleProtocolAll represents all protocols (and all ports)
+	SecurityGroupRuleProtocolAll = "-1"
+)
+
+type (
+	// ClusterSecurityGroups holds extra rules for the control plane security group
+	ClusterSecurityGroups struct {
+		// +optional
+		IngressRules []SecurityGroupRule `json:"ingressRules,omitempty"`
+		// +optional
+		EgressRules []SecurityGroupRule `json:"egressRules,omitempty"`
+	}
+
+	// SecurityGroupRule holds an ingress or egress rule, the peer is
+	// either a CIDR or another security group
+	SecurityGroupRule struct {
+		// +optional
+		CIDR string `json:"cidr,omitempty"`
+		// +optional
+		SecurityGroupID string `json:"securityGroupID,omitempty"`
+		// +optional
+		Protocol string `json:"protocol,omitempty"`
+		// +optional
+		FromPort *int `json:"fromPort,omitempty"`
+		// +optional
+		ToPort *int `json:"toPort,omitempty"`
+		// +optional
+		Description string `json:"description,omitempty"`
+	}
+)
+
+// SupportedSecurityGroupRuleProtocols are the protocols that can be used in security group rules
+func SupportedSecurityGroupRuleProtocols() []string {
+	return []string{
+		SecurityGroupRuleProtocolTCP,
+		SecurityGroupRuleProtocolUDP,
+		SecurityGroupRuleProtocolICMP,
+		SecurityGroupRuleProtocolAll,
+	}
+}
+
+// IsIPv6CIDR returns true if the given CIDR is an IPv6 one, it assumes the CIDR
+// has already been validated
+func IsIPv6CIDR(cidr string) bool {
+	ip, _, err := net.ParseCIDR(cidr)
+	return err == nil && ip.To4() == nil
+}
+
+// HasSecurityGroupRules determines if any extra control plane rules were given
+func (c *ClusterConfig) HasSecurityGroupRules() bool {
+	return c.SecurityGroups != nil && (len(c.SecurityGroups.IngressRules) > 0 || len(c.SecurityGroups.EgressRules) > 0)
+}
+
+func setSecurityGroupRulesDefaults(rules []SecurityGroupRule) {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Protocol == "" {
+			rule.Protocol = SecurityGroupRuleProtocolTCP
+		}
+		if rule.ToPort == nil && rule.FromPort != nil {
+			toPort := *rule.FromPort
+			rule.ToPort = &toPort
+		}
+	}
+}
+
+func validateSecurityGroupRules(path string, rules []SecurityGroupRule) error {
+	for i, rule := range rules {
+		rulePath := fmt.Sprintf("%s[%d]", path, i)
+
+		if (rule.CIDR == "") == (rule.SecurityGroupID == "") {
+			return fmt.Errorf("exactly one of %s.cidr and %s.securityGroupID must be set", rulePath, rulePath)
+		}
+		if rule.CIDR != "" {
+			if _, _, err := net.ParseCIDR(rule.CIDR); err != nil {
+				return fmt.Errorf("%s.cidr %q is not a valid CIDR", rulePath, rule.CIDR)
+			}
+		}
+
+		if rule.Protocol != "" {
+			isUnknown := true
+			for _, protocol := range SupportedSecurityGroupRuleProtocols() {
+				if rule.Protocol == protocol {
+					isUnknown = false
+				}
+			}
+			if isUnknown {
+				return fmt.Errorf("%s.protocol %q is unknown", rulePath, rule.Protocol)
+			}
+		}
+
+		if rule.Protocol == SecurityGroupRuleProtocolAll {
+			continue
+		}
+		if rule.FromPort == nil {
+			return fmt.Errorf("%s.fromPort must be set", rulePath)
+		}
+		// ICMP uses -1 as a wildcard for type and code
+		minPort := 0
+		if rule.Protocol == SecurityGroupRuleProtocolICMP {
+			minPort = -1
+		}
+		if *rule.FromPort < minPort || *rule.FromPort > 65535 {
+			return fmt.Errorf("%s.fromPort must be between %d and 65535", rulePath, minPort)
+		}
+		if rule.ToPort != nil {
+			if *rule.ToPort < minPort || *rule.ToPort > 65535 {
+				return fmt.Errorf("%s.toPort must be between %d and 65535", rulePath, minPort)
+			}
+			if rule.Protocol != SecurityGroupRuleProtocolICMP && *rule.ToPort < *rule.FromPort {
+				return fmt.Errorf("%s.toPort cannot be lower than %s.fromPort", rulePath, rulePath)
+			}
+		}
+	}
+	return nil
+}
@@ -4,13 +4,16 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
 	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
 	"github.com/aws/aws-sdk-go/service/cloudtrail/cloudtrailiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/eks/eksiface"
 	"github.com/aws/aws-sdk-go/service/elb/elbiface"
 	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -111,6 +114,16 @@ const (
 	NodeImageResolverStatic = "static"
 	// NodeImageResolverAuto represents auto AMI resolver (see ami package)
 	NodeImageResolverAuto = "auto"
+	// NodeImageResolverSSM represents SSM AMI resolver (see ami package)
+	NodeImageResolverSSM = "ssm"
+
+	// AMIUpdatePolicyPinned means that the AMI of a nodegroup is never updated
+	AMIUpdatePolicyPinned = "pinned"
+	// AMIUpdatePolicyLatestPatch means that the AMI of a nodegroup is updated to the latest
+	// AMI for the Kubernetes version of the nodegroup whenever the nodegroup is updated
+	AMIUpdatePolicyLatestPatch = "latest-patch"
+	// AMIUpdatePolicyManual means that the AMI of a nodegroup is only updated on request (default)
+	AMIUpdatePolicyManual = "manual"
 
 	// ClusterNameTag defines the tag of the cluster name
 	ClusterNameTag = "alpha.eksctl.io/cluster-name"
@@ -286,9 +299,13 @@ type ClusterProvider interface {
 	STS() stsiface.STSAPI
 	IAM() iamiface.IAMAPI
 	CloudTrail() cloudtrailiface.CloudTrailAPI
+	CloudWatchLogs() cloudwatchlogsiface.CloudWatchLogsAPI
+	ASG() autoscalingiface.AutoScalingAPI
+	SSM() ssmiface.SSMAPI
 	Region() string
 	Profile() string
 	WaitTimeout() time.Duration
+	StackEventsOutput() string
 }
 
 // ProviderConfig holds global parameters for all interactions with AWS APIs
@@ -298,6 +315,33 @@ type ProviderConfig struct {
 	Region      string
 	Profile     string
 	WaitTimeout time.Duration
+
+	StackEventsOutput string
+}
+
+// Values for ProviderConfig.StackEventsOutput
+const (
+	// StackEventsOutputLog streams stack events as log messages
+	StackEventsOutputLog = "log"
+	// StackEventsOutputJSON streams stack events as JSON, one object per line
+	StackEventsOutputJSON = "json"
+	// StackEventsOutputNone disables streaming of stack events
+	StackEventsOutputNone = "none"
+)
+
+// SupportedStackEventsOutputs returns all supported values of ProviderConfig.StackEventsOutput
+func SupportedStackEventsOutputs() []string {
+	return []string{StackEventsOutputLog, StackEventsOutputJSON, StackEventsOutputNone}
+}
+
+// IsSupportedStackEventsOutput checks if the given value of ProviderConfig.StackEventsOutput is supported
+func IsSupportedStackEventsOutput(output string) bool {
+	for _, supported := range SupportedStackEventsOutputs() {
+		if output == supported {
+			return true
+		}
+	}
+	return false
 }
 
 // +genclient
@@ -324,9 +368,37 @@ type ClusterConfig struct {
 	// +optional
 	CloudWatch *ClusterCloudWatch `json:"cloudWatch,omitempty"`
 
+	// +optional
+	SecurityGroups *ClusterSecurityGroups `json:"securityGroups,omitempty"`
+
+	// ImageFamilies declares custom image families, which nodegroups
+	// can use as their amiFamily
+	// +optional
+	ImageFamilies []ImageFamily `json:"imageFamilies,omitempty"`
+
+	// Git configures GitOps, which is bootstrapped once the cluster is created
+	// +optional
+	Git *Git `json:"git,omitempty"`
+
 	Status *ClusterStatus `json:"status,omitempty"`
 }
 
+// ImageFamily is a custom image family, e.g. the AMIs built by a golden-image pipeline,
+// its AMIs are found by searching EC2 (i.e. with the auto resolver)
+type ImageFamily struct {
+	Name string `json:"name"`
+	// OwnerAccountID is the ID of the AWS account that owns the AMIs
+	OwnerAccountID string `json:"ownerAccountID"`
+	// NamePatterns are AMI name patterns by Kubernetes version, e.g. "my-eks-node-1.14-*"
+	NamePatterns map[string]string `json:"namePatterns"`
+	// GPUNamePatterns are AMI name patterns by Kubernetes version for GPU instance types
+	// +optional
+	GPUNamePatterns map[string]string `json:"gpuNamePatterns,omitempty"`
+	// Bootstrap is the family whose bootstrapping the AMIs support,
+	// valid values are "AmazonLinux2" and "Ubuntu1804"
+	Bootstrap string `json:"bootstrap"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // ClusterConfigList is a list of ClusterConfigs
@@ -417,6 +489,7 @@ func NewNodeGroup() *NodeGroup {
 		SSH: &NodeGroupSSH{
 			Allow:         Disabled(),
 			PublicKeyPath: &DefaultNodeSSHPublicKeyPath,
+			EnableSSM:     Disabled(),
 		},
 	}
 }
@@ -445,6 +518,11 @@ type NodeGroup struct {
 	AMI string `json:"ami,omitempty"`
 	// +optional
 	AMIFamily string `json:"amiFamily,omitempty"`
+	// AMIUpdatePolicy controls whether `update nodegroup` changes the AMI
+	// of the nodegroup, valid values are "pinned", "latest-patch" and
+	// "manual" (default)
+	// +optional
+	AMIUpdatePolicy string `json:"amiUpdatePolicy,omitempty"`
 	// +optional
 	InstanceType string `json:"instanceType,omitempty"`
 	//+optional
@@ -530,6 +608,10 @@ type (
 		WithShared *bool `json:"withShared"`
 		// +optional
 		WithLocal *bool `json:"withLocal"`
+		// +optional
+		IngressRules []SecurityGroupRule `json:"ingressRules,omitempty"`
+		// +optional
+		EgressRules []SecurityGroupRule `json:"egressRules,omitempty"`
 	}
 	// NodeGroupIAM holds all IAM attributes of a NodeGroup
 	NodeGroupIAM struct {
@@ -580,6 +662,12 @@ type (
 		PublicKey *string `json:"publicKey,omitempty"`
 		// +optional
 		PublicKeyName *string `json:"publicKeyName,omitempty"`
+		// +optional
+		SourceCIDRs []string `json:"sourceCIDRs,omitempty"`
+		// EnableSSM attaches SSM managed instance policy and installs SSM agent,
+		// so that nodes can be accessed via SSM Session Manager without opening port 22
+		// +optional
+		EnableSSM *bool `json:"enableSSM,omitempty"`
 	}
 
 	// NodeGroupInstancesDistribution holds the configuration for spot instances
@@ -610,6 +698,21 @@ func (in *InlineDocument) DeepCopy() *InlineDocument {
 	return out
 }
 
+// IsBuiltInImageFamily tells whether the image family is one that eksctl supports out of the box
+func IsBuiltInImageFamily(name string) bool {
+	return name == NodeImageFamilyAmazonLinux2 || name == NodeImageFamilyUbuntu1804
+}
+
+// FindImageFamily returns the custom image family with the given name, or nil
+func (c *ClusterConfig) FindImageFamily(name string) *ImageFamily {
+	for i := range c.ImageFamilies {
+		if c.ImageFamilies[i].Name == name {
+			return &c.ImageFamilies[i]
+		}
+	}
+	return nil
+}
+
 // HasMixedInstances checks if a nodegroup has mixed instances option declared
 func HasMixedInstances(ng *NodeGroup) bool {
 	return ng.InstancesDistribution != nil && ng.InstancesDistribution.InstanceTypes != nil && len(ng.InstancesDistribution.InstanceTypes) != 0
@@ -2,6 +2,7 @@ package v1alpha5
 
 import (
 	"fmt"
+	"net"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -21,6 +22,10 @@ func (s nameSet) checkNonUnique(path, name string) (bool, error) {
 
 // ValidateClusterConfig checks compatible fields of a given ClusterConfig
 func ValidateClusterConfig(cfg *ClusterConfig) error {
+	if err := validateImageFamilies(cfg.ImageFamilies); err != nil {
+		return err
+	}
+
 	ngNames := nameSet{}
 	for i, ng := range cfg.NodeGroups {
 		path := fmt.Sprintf("nodeGroups[%d]", i)
@@ -30,6 +35,10 @@ func ValidateClusterConfig(cfg *ClusterConfig) error {
 		if ok, err := ngNames.checkNonUnique(path, ng.NameString()); !ok {
 			return err
 		}
+		if ng.AMIFamily != "" && !IsBuiltInImageFamily(ng.AMIFamily) && cfg.FindImageFamily(ng.AMIFamily) == nil {
+			return fmt.Errorf("%s.amiFamily %q is neither %q, %q nor declared in imageFamilies", path,
+				ng.AMIFamily, NodeImageFamilyAmazonLinux2, NodeImageFamilyUbuntu1804)
+		}
 	}
 
 	if cfg.HasClusterCloudWatchLogging() {
@@ -46,6 +55,53 @@ func ValidateClusterConfig(cfg *ClusterConfig) error {
 		}
 	}
 
+	if cfg.HasClusterLogGroupSettings() {
+		if err := validateClusterLogGroupSettings(cfg.CloudWatch.ClusterLogging); err != nil {
+			return err
+		}
+	}
+
+	if cfg.SecurityGroups != nil {
+		if err := validateSecurityGroupRules("securityGroups.ingressRules", cfg.SecurityGroups.IngressRules); err != nil {
+			return err
+		}
+		if err := validateSecurityGroupRules("securityGroups.egressRules", cfg.SecurityGroups.EgressRules); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Git != nil {
+		if err := validateGit(cfg.Git); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateImageFamilies(families []ImageFamily) error {
+	names := nameSet{}
+	for i, family := range families {
+		path := fmt.Sprintf("imageFamilies[%d]", i)
+		if family.Name == "" {
+			return fmt.Errorf("%s.name must be set", path)
+		}
+		if ok, err := names.checkNonUnique(path, family.Name); !ok {
+			return err
+		}
+		if IsBuiltInImageFamily(family.Name) {
+			return fmt.Errorf("%s.name %q is reserved for a built-in image family", path, family.Name)
+		}
+		if family.OwnerAccountID == "" {
+			return fmt.Errorf("%s.ownerAccountID must be set", path)
+		}
+		if len(family.NamePatterns) == 0 {
+			return fmt.Errorf("%s.namePatterns must be set", path)
+		}
+		if !IsBuiltInImageFamily(family.Bootstrap) {
+			return fmt.Errorf("%s.bootstrap must be either %q or %q", path, NodeImageFamilyAmazonLinux2, NodeImageFamilyUbuntu1804)
+		}
+	}
 	return nil
 }
 
@@ -102,6 +158,10 @@ func ValidateNodeGroup(i int, ng *NodeGroup) error {
 		}
 	}
 
+	if err := validateNodeGroupSecurityGroups(path, ng); err != nil {
+		return err
+	}
+
 	if err := validateNodeGroupKubeletExtraConfig(ng.KubeletExtraConfig); err != nil {
 		return err
 	}
@@ -110,6 +170,13 @@ func ValidateNodeGroup(i int, ng *NodeGroup) error {
 		return err
 	}
 
+	switch ng.AMIUpdatePolicy {
+	case "", AMIUpdatePolicyPinned, AMIUpdatePolicyLatestPatch, AMIUpdatePolicyManual:
+	default:
+		return fmt.Errorf("%s.amiUpdatePolicy must be one of %q, %q or %q", path,
+			AMIUpdatePolicyPinned, AMIUpdatePolicyLatestPatch, AMIUpdatePolicyManual)
+	}
+
 	return nil
 }
 
@@ -281,6 +348,33 @@ func validateNodeGroupSSH(SSH *NodeGroupSSH) error {
 	return nil
 }
 
+func validateNodeGroupSecurityGroups(path string, ng *NodeGroup) error {
+	if ng.SSH != nil {
+		for i, cidr := range ng.SSH.SourceCIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("%s.ssh.sourceCIDRs[%d] %q is not a valid CIDR", path, i, cidr)
+			}
+		}
+	}
+
+	sgs := ng.SecurityGroups
+	if sgs == nil {
+		return nil
+	}
+	if IsDisabled(sgs.WithLocal) {
+		if len(sgs.IngressRules) > 0 || len(sgs.EgressRules) > 0 {
+			return fmt.Errorf("%s.securityGroups.ingressRules and %s.securityGroups.egressRules cannot be set without %s.securityGroups.withLocal enabled", path, path, path)
+		}
+		if ng.SSH != nil && len(ng.SSH.SourceCIDRs) > 0 {
+			return fmt.Errorf("%s.ssh.sourceCIDRs cannot be set without %s.securityGroups.withLocal enabled", path, path)
+		}
+	}
+	if err := validateSecurityGroupRules(path+".securityGroups.ingressRules", sgs.IngressRules); err != nil {
+		return err
+	}
+	return validateSecurityGroupRules(path+".securityGroups.egressRules", sgs.EgressRules)
+}
+
 func countEnabledFields(fields ...*string) int {
 	count := 0
 	for _, flag := range fields {
@@ -313,3 +407,21 @@ func validateNodeGroupKubeletExtraConfig(kubeletConfig *InlineDocument) error {
 	}
 	return nil
 }
+
+func validateClusterLogGroupSettings(logging *ClusterCloudWatchLogging) error {
+	if days := logging.RetentionInDays; days != 0 {
+		isSupported := false
+		for _, supportedDays := range SupportedCloudWatchLogRetentionDays() {
+			if days == supportedDays {
+				isSupported = true
+			}
+		}
+		if !isSupported {
+			return fmt.Errorf("retention of %d days (cloudWatch.clusterLogging.retentionInDays) is not supported, supported values are: %v", days, SupportedCloudWatchLogRetentionDays())
+		}
+	}
+	if logging.KMSKeyID != "" && !strings.HasPrefix(logging.KMSKeyID, "arn:") {
+		return fmt.Errorf("cloudWatch.clusterLogging.kmsKeyID must be a key ARN")
+	}
+	return nil
+}
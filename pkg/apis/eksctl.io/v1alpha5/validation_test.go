@@ -69,6 +69,115 @@ var _ = Describe("ClusterConfig validation", func() {
 			err = ValidateClusterConfig(cfg)
 			Expect(err).To(HaveOccurred())
 		})
+
+		It("should accept supported retention and KMS key ARN", func() {
+			cfg.CloudWatch.ClusterLogging.RetentionInDays = 30
+			cfg.CloudWatch.ClusterLogging.KMSKeyID = "arn:aws:kms:us-west-2:123456789012:key/abcd"
+
+			err = ValidateClusterConfig(cfg)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should reject unsupported retention", func() {
+			cfg.CloudWatch.ClusterLogging.RetentionInDays = 10
+
+			err = ValidateClusterConfig(cfg)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject KMS key ID that is not an ARN", func() {
+			cfg.CloudWatch.ClusterLogging.KMSKeyID = "abcd"
+
+			err = ValidateClusterConfig(cfg)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("git", func() {
+		var (
+			cfg *ClusterConfig
+			err error
+		)
+
+		BeforeEach(func() {
+			cfg = NewClusterConfig()
+			cfg.Git = &Git{
+				Repo: &Repo{
+					URL:   "git@github.com:org/repo.git",
+					Email: "user@example.com",
+				},
+				Profiles: []Profile{{Source: "app-dev"}},
+			}
+		})
+
+		It("should accept a valid config", func() {
+			err = ValidateClusterConfig(cfg)
+			Expect(err).ToNot(HaveOccurred())
+
+			cfg.Git.Repo.URL = "ssh://git@github.com/org/repo.git"
+			err = ValidateClusterConfig(cfg)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should reject a missing repo URL or email", func() {
+			cfg.Git.Repo.URL = ""
+			err = ValidateClusterConfig(cfg)
+			Expect(err).To(MatchError("git.repo.url must be set"))
+
+			cfg.Git.Repo.URL = "git@github.com:org/repo.git"
+			cfg.Git.Repo.Email = ""
+			err = ValidateClusterConfig(cfg)
+			Expect(err).To(MatchError("git.repo.email must be set"))
+		})
+
+		It("should reject a repo URL which isn't SSH", func() {
+			cfg.Git.Repo.URL = "https://github.com/org/repo.git"
+			err = ValidateClusterConfig(cfg)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject unsupported Flux and Helm versions", func() {
+			cfg.Git.Operator = &Operator{FluxVersion: "v3"}
+			err = ValidateClusterConfig(cfg)
+			Expect(err).To(HaveOccurred())
+
+			cfg.Git.Operator = &Operator{HelmVersions: []string{"v3", "v4"}}
+			err = ValidateClusterConfig(cfg)
+			Expect(err).To(MatchError(`git.operator.helmVersions[1] "v4" is not supported, must be either "v2" or "v3"`))
+		})
+
+		It("should reject an unsupported provider, or provider settings without a provider", func() {
+			cfg.Git.Repo.Provider = GitProviderGitLab
+			cfg.Git.Repo.ProviderURL = "https://gitlab.example.com/api/v4"
+			cfg.Git.Repo.CreateIfMissing = true
+			err = ValidateClusterConfig(cfg)
+			Expect(err).ToNot(HaveOccurred())
+
+			cfg.Git.Repo.Provider = "bitbucket"
+			err = ValidateClusterConfig(cfg)
+			Expect(err).To(MatchError(`git.repo.provider "bitbucket" is not supported, must be either "github" or "gitlab"`))
+
+			cfg.Git.Repo.Provider = ""
+			err = ValidateClusterConfig(cfg)
+			Expect(err).To(MatchError("git.repo.providerURL cannot be set without git.repo.provider"))
+
+			cfg.Git.Repo.ProviderURL = ""
+			err = ValidateClusterConfig(cfg)
+			Expect(err).To(MatchError("git.repo.createIfMissing cannot be set without git.repo.provider"))
+		})
+
+		It("should reject profiles without a source or with the same output path", func() {
+			cfg.Git.Profiles = []Profile{{Source: ""}}
+			err = ValidateClusterConfig(cfg)
+			Expect(err).To(MatchError("git.profiles[0].source must be set"))
+
+			cfg.Git.Profiles = []Profile{
+				{Source: "app-dev", OutputPath: "base"},
+				{Source: "git@github.com:org/profile.git", OutputPath: "base"},
+			}
+			err = ValidateClusterConfig(cfg)
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	Describe("ssh flags", func() {
@@ -240,6 +349,147 @@ var _ = Describe("ClusterConfig validation", func() {
 		})
 	})
 
+	Describe("image families", func() {
+		var cfg *ClusterConfig
+
+		BeforeEach(func() {
+			cfg = NewClusterConfig()
+			cfg.ImageFamilies = []ImageFamily{
+				{
+					Name:           "Golden",
+					OwnerAccountID: "123456789012",
+					NamePatterns:   map[string]string{"1.14": "golden-eks-node-1.14-*"},
+					Bootstrap:      NodeImageFamilyAmazonLinux2,
+				},
+			}
+			ng := cfg.NewNodeGroup()
+			ng.Name = "ng1"
+			ng.AMIFamily = "Golden"
+		})
+
+		It("allows nodegroups to use declared image families", func() {
+			Expect(ValidateClusterConfig(cfg)).To(Succeed())
+		})
+
+		It("forbids nodegroups to use undeclared image families", func() {
+			cfg.NodeGroups[0].AMIFamily = "Silver"
+			Expect(ValidateClusterConfig(cfg)).ToNot(Succeed())
+		})
+
+		It("forbids redeclaring built-in image families", func() {
+			cfg.ImageFamilies[0].Name = NodeImageFamilyAmazonLinux2
+			Expect(ValidateClusterConfig(cfg)).ToNot(Succeed())
+		})
+
+		It("requires an owner account, name patterns and a known bootstrap family", func() {
+			cfg.ImageFamilies[0].OwnerAccountID = ""
+			Expect(ValidateClusterConfig(cfg)).ToNot(Succeed())
+
+			cfg.ImageFamilies[0].OwnerAccountID = "123456789012"
+			cfg.ImageFamilies[0].NamePatterns = nil
+			Expect(ValidateClusterConfig(cfg)).ToNot(Succeed())
+
+			cfg.ImageFamilies[0].NamePatterns = map[string]string{"1.14": "golden-eks-node-1.14-*"}
+			cfg.ImageFamilies[0].Bootstrap = "Windows"
+			err := ValidateClusterConfig(cfg)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`imageFamilies[0].bootstrap must be either "AmazonLinux2" or "Ubuntu1804"`))
+		})
+	})
+
+	Describe("AMI update policy", func() {
+		var ng *NodeGroup
+
+		BeforeEach(func() {
+			ng = NewNodeGroup()
+			ng.Name = "ng1"
+		})
+
+		It("allows an empty or a known policy", func() {
+			for _, policy := range []string{"", AMIUpdatePolicyPinned, AMIUpdatePolicyLatestPatch, AMIUpdatePolicyManual} {
+				ng.AMIUpdatePolicy = policy
+				Expect(ValidateNodeGroup(0, ng)).To(Succeed())
+			}
+		})
+
+		It("forbids unknown policies", func() {
+			ng.AMIUpdatePolicy = "latest"
+			err := ValidateNodeGroup(0, ng)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("nodeGroups[0].amiUpdatePolicy must be one of"))
+		})
+	})
+
+	Describe("security group rules", func() {
+		var ng *NodeGroup
+
+		BeforeEach(func() {
+			ng = NewNodeGroup()
+			ng.Name = "ng1"
+		})
+
+		It("allows rules with either a CIDR or a security group", func() {
+			ng.SecurityGroups.IngressRules = []SecurityGroupRule{
+				{CIDR: "10.0.0.0/8", FromPort: newInt(80)},
+				{SecurityGroupID: "sg-1", Protocol: "-1"},
+			}
+			ng.SecurityGroups.EgressRules = []SecurityGroupRule{
+				{CIDR: "::/0", Protocol: "udp", FromPort: newInt(53)},
+			}
+			Expect(ValidateNodeGroup(0, ng)).To(Succeed())
+		})
+
+		It("forbids rules with both or neither of CIDR and security group", func() {
+			ng.SecurityGroups.IngressRules = []SecurityGroupRule{
+				{CIDR: "10.0.0.0/8", SecurityGroupID: "sg-1", FromPort: newInt(80)},
+			}
+			Expect(ValidateNodeGroup(0, ng)).ToNot(Succeed())
+
+			ng.SecurityGroups.IngressRules = []SecurityGroupRule{
+				{FromPort: newInt(80)},
+			}
+			Expect(ValidateNodeGroup(0, ng)).ToNot(Succeed())
+		})
+
+		It("forbids invalid CIDRs, protocols and ports", func() {
+			ng.SecurityGroups.IngressRules = []SecurityGroupRule{{CIDR: "10.0.0.0", FromPort: newInt(80)}}
+			Expect(ValidateNodeGroup(0, ng)).ToNot(Succeed())
+
+			ng.SecurityGroups.IngressRules = []SecurityGroupRule{{CIDR: "10.0.0.0/8", Protocol: "sctp", FromPort: newInt(80)}}
+			Expect(ValidateNodeGroup(0, ng)).ToNot(Succeed())
+
+			ng.SecurityGroups.IngressRules = []SecurityGroupRule{{CIDR: "10.0.0.0/8"}}
+			Expect(ValidateNodeGroup(0, ng)).ToNot(Succeed())
+
+			ng.SecurityGroups.IngressRules = []SecurityGroupRule{{CIDR: "10.0.0.0/8", FromPort: newInt(100), ToPort: newInt(99)}}
+			Expect(ValidateNodeGroup(0, ng)).ToNot(Succeed())
+
+			ng.SecurityGroups.EgressRules = []SecurityGroupRule{{CIDR: "10.0.0.0/8", FromPort: newInt(65536)}}
+			Expect(ValidateNodeGroup(0, ng)).ToNot(Succeed())
+		})
+
+		It("forbids rules and SSH source CIDRs without a local security group", func() {
+			ng.SecurityGroups.WithLocal = Disabled()
+			ng.SecurityGroups.IngressRules = []SecurityGroupRule{{CIDR: "10.0.0.0/8", FromPort: newInt(80)}}
+			Expect(ValidateNodeGroup(0, ng)).ToNot(Succeed())
+
+			ng.SecurityGroups.IngressRules = nil
+			ng.SSH.SourceCIDRs = []string{"10.0.0.0/8"}
+			Expect(ValidateNodeGroup(0, ng)).ToNot(Succeed())
+		})
+
+		It("validates control plane rules", func() {
+			cfg := NewClusterConfig()
+			cfg.SecurityGroups = &ClusterSecurityGroups{
+				IngressRules: []SecurityGroupRule{{CIDR: "10.0.0.0/8", FromPort: newInt(443)}},
+			}
+			Expect(ValidateClusterConfig(cfg)).To(Succeed())
+
+			cfg.SecurityGroups.EgressRules = []SecurityGroupRule{{CIDR: "10.0.0.0/8"}}
+			Expect(ValidateClusterConfig(cfg)).ToNot(Succeed())
+		})
+	})
+
 	Describe("ebs encryption", func() {
 		var (
 			nodegroup = "ng1"
@@ -0,0 +1,218 @@
+package capacity
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+	"github.com/weaveworks/eksctl/pkg/nodebootstrap"
+)
+
+//go:generate go run ./prices_generate.go
+
+const (
+	instanceTypeLabel       = "node.kubernetes.io/instance-type"
+	legacyInstanceTypeLabel = "beta.kubernetes.io/instance-type"
+)
+
+// NodeGroupCapacity describes how much of the capacity of a nodegroup is in use and what it costs
+type NodeGroupCapacity struct {
+	Cluster       string
+	Name          string
+	InstanceTypes []string
+	Nodes         int
+	OnDemandNodes int
+	SpotNodes     int
+
+	AllocatableCPU    resource.Quantity
+	RequestedCPU      resource.Quantity
+	AllocatableMemory resource.Quantity
+	RequestedMemory   resource.Quantity
+
+	Pods    int
+	MaxPods int
+
+	// HourlyCost is the estimated cost in USD, it's nil if the price of any of the
+	// instance types isn't known
+	HourlyCost *float64
+}
+
+// PodHeadroom returns how many more pods can be scheduled on the nodes of the nodegroup
+func (c *NodeGroupCapacity) PodHeadroom() int {
+	return c.MaxPods - c.Pods
+}
+
+// Collector gathers capacity reports of nodegroups
+type Collector struct {
+	ClientSet kubernetes.Interface
+	Pricer    Pricer
+}
+
+// NodeGroups returns capacity reports for the given nodegroups, distributions holds
+// the instances distribution of each nodegroup that uses mixed instances
+func (c *Collector) NodeGroups(summaries []*manager.NodeGroupSummary, distributions map[string]*api.NodeGroupInstancesDistribution) ([]*NodeGroupCapacity, error) {
+	podsByNode, err := c.listPodsByNode()
+	if err != nil {
+		return nil, err
+	}
+
+	reports := []*NodeGroupCapacity{}
+	for _, summary := range summaries {
+		report, err := c.nodeGroup(summary, distributions[summary.Name], podsByNode)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func (c *Collector) nodeGroup(summary *manager.NodeGroupSummary, distribution *api.NodeGroupInstancesDistribution, podsByNode map[string][]corev1.Pod) (*NodeGroupCapacity, error) {
+	report := &NodeGroupCapacity{
+		Cluster:       summary.Cluster,
+		Name:          summary.Name,
+		InstanceTypes: []string{summary.InstanceType},
+	}
+	if distribution != nil && len(distribution.InstanceTypes) > 0 {
+		report.InstanceTypes = distribution.InstanceTypes
+	}
+
+	listOptions := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", api.NodeGroupNameLabel, summary.Name),
+	}
+	nodes, err := c.ClientSet.CoreV1().Nodes().List(listOptions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing nodes of nodegroup %q", summary.Name)
+	}
+
+	report.Nodes = len(nodes.Items)
+	report.OnDemandNodes = OnDemandNodes(report.Nodes, distribution)
+	report.SpotNodes = report.Nodes - report.OnDemandNodes
+
+	onDemandShare, spotShare := 1.0, 0.0
+	if report.Nodes > 0 {
+		onDemandShare = float64(report.OnDemandNodes) / float64(report.Nodes)
+		spotShare = float64(report.SpotNodes) / float64(report.Nodes)
+	}
+
+	hourlyCost, costKnown := 0.0, true
+	for _, node := range nodes.Items {
+		instanceType := nodeInstanceType(&node, summary.InstanceType)
+
+		report.AllocatableCPU.Add(*node.Status.Allocatable.Cpu())
+		report.AllocatableMemory.Add(*node.Status.Allocatable.Memory())
+
+		for _, pod := range podsByNode[node.Name] {
+			cpu, memory := podRequests(&pod)
+			report.RequestedCPU.Add(cpu)
+			report.RequestedMemory.Add(memory)
+			report.Pods++
+		}
+
+		if maxPods, ok := nodebootstrap.MaxPodsPerNode(instanceType); ok {
+			report.MaxPods += maxPods
+		} else {
+			report.MaxPods += int(node.Status.Allocatable.Pods().Value())
+		}
+
+		if !costKnown {
+			continue
+		}
+		// it's not known which nodes are spot instances, so the price
+		// of every node is weighted by the share of spot instances
+		if onDemandShare > 0 {
+			price, ok := c.Pricer.OnDemandPrice(instanceType)
+			if !ok {
+				costKnown = false
+				continue
+			}
+			hourlyCost += onDemandShare * price
+		}
+		if spotShare > 0 {
+			price, ok := c.Pricer.SpotPrice(instanceType)
+			if !ok {
+				costKnown = false
+				continue
+			}
+			hourlyCost += spotShare * price
+		}
+	}
+	if costKnown {
+		report.HourlyCost = &hourlyCost
+	}
+	return report, nil
+}
+
+func (c *Collector) listPodsByNode() (map[string][]corev1.Pod, error) {
+	listOptions := metav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	}
+	pods, err := c.ClientSet.CoreV1().Pods(metav1.NamespaceAll).List(listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing pods")
+	}
+	podsByNode := make(map[string][]corev1.Pod)
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+	}
+	return podsByNode, nil
+}
+
+// OnDemandNodes returns how many of the nodes are on-demand instances, following the
+// rules of auto scaling groups with mixed instances
+func OnDemandNodes(nodes int, distribution *api.NodeGroupInstancesDistribution) int {
+	if distribution == nil {
+		return nodes
+	}
+	baseCapacity, percentage := 0, 100
+	if distribution.OnDemandBaseCapacity != nil {
+		baseCapacity = *distribution.OnDemandBaseCapacity
+	}
+	if distribution.OnDemandPercentageAboveBaseCapacity != nil {
+		percentage = *distribution.OnDemandPercentageAboveBaseCapacity
+	}
+	if nodes <= baseCapacity {
+		return nodes
+	}
+	// the on-demand share above base capacity is rounded up
+	aboveBaseCapacity := nodes - baseCapacity
+	return baseCapacity + (aboveBaseCapacity*percentage+99)/100
+}
+
+func nodeInstanceType(node *corev1.Node, defaultInstanceType string) string {
+	if instanceType, ok := node.Labels[instanceTypeLabel]; ok {
+		return instanceType
+	}
+	if instanceType, ok := node.Labels[legacyInstanceTypeLabel]; ok {
+		return instanceType
+	}
+	return defaultInstanceType
+}
+
+// podRequests returns the effective CPU and memory requests of a pod, i.e. the
+// sum of requests of all containers, or the highest init container request
+func podRequests(pod *corev1.Pod) (resource.Quantity, resource.Quantity) {
+	cpu, memory := resource.Quantity{}, resource.Quantity{}
+	for _, container := range pod.Spec.Containers {
+		cpu.Add(*container.Resources.Requests.Cpu())
+		memory.Add(*container.Resources.Requests.Memory())
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if initCPU := container.Resources.Requests.Cpu(); initCPU.Cmp(cpu) > 0 {
+			cpu = *initCPU
+		}
+		if initMemory := container.Resources.Requests.Memory(); initMemory.Cmp(memory) > 0 {
+			memory = *initMemory
+		}
+	}
+	return cpu, memory
+}
@@ -0,0 +1,205 @@
+package capacity_test
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	. "github.com/weaveworks/eksctl/pkg/capacity"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+	"github.com/weaveworks/eksctl/pkg/eks/mocks"
+)
+
+type fixedPricer struct {
+	onDemand, spot map[string]float64
+}
+
+func (p *fixedPricer) OnDemandPrice(instanceType string) (float64, bool) {
+	price, ok := p.onDemand[instanceType]
+	return price, ok
+}
+
+func (p *fixedPricer) SpotPrice(instanceType string) (float64, bool) {
+	price, ok := p.spot[instanceType]
+	return price, ok
+}
+
+func newNode(name, nodeGroup, instanceType, cpu, memory string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				api.NodeGroupNameLabel:             nodeGroup,
+				"beta.kubernetes.io/instance-type": instanceType,
+			},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+		},
+	}
+}
+
+func newPod(name, nodeName, cpu, memory string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse(cpu),
+						corev1.ResourceMemory: resource.MustParse(memory),
+					},
+				},
+			}},
+		},
+	}
+}
+
+var _ = Describe("Capacity", func() {
+	Context("OnDemandNodes", func() {
+		It("should count all nodes as on-demand without instances distribution", func() {
+			Expect(OnDemandNodes(5, nil)).To(Equal(5))
+		})
+
+		It("should follow base capacity and percentage above it", func() {
+			distribution := &api.NodeGroupInstancesDistribution{
+				InstanceTypes:                       []string{"m5.large", "m5a.large"},
+				OnDemandBaseCapacity:                aws.Int(2),
+				OnDemandPercentageAboveBaseCapacity: aws.Int(25),
+			}
+			Expect(OnDemandNodes(1, distribution)).To(Equal(1))
+			Expect(OnDemandNodes(2, distribution)).To(Equal(2))
+			Expect(OnDemandNodes(6, distribution)).To(Equal(3))
+			Expect(OnDemandNodes(7, distribution)).To(Equal(4))
+		})
+	})
+
+	Context("Collector", func() {
+		var (
+			collector *Collector
+			summaries []*manager.NodeGroupSummary
+		)
+
+		BeforeEach(func() {
+			clientSet := fake.NewSimpleClientset(
+				newNode("node-1", "ng-1", "m5.large", "1930m", "7Gi"),
+				newNode("node-2", "ng-1", "m5.large", "1930m", "7Gi"),
+				newNode("node-3", "ng-2", "m5.xlarge", "3920m", "14Gi"),
+				newPod("pod-1", "node-1", "500m", "1Gi"),
+				newPod("pod-2", "node-2", "250m", "512Mi"),
+				newPod("pod-3", "", "1", "1Gi"),
+			)
+			collector = &Collector{
+				ClientSet: clientSet,
+				Pricer: &fixedPricer{
+					onDemand: map[string]float64{"m5.large": 0.1, "m5.xlarge": 0.2},
+					spot:     map[string]float64{"m5.large": 0.04},
+				},
+			}
+			summaries = []*manager.NodeGroupSummary{
+				{Cluster: "test", Name: "ng-1", InstanceType: "m5.large"},
+			}
+		})
+
+		It("should report allocatable and requested resources of nodegroup nodes", func() {
+			reports, err := collector.NodeGroups(summaries, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reports).To(HaveLen(1))
+
+			report := reports[0]
+			Expect(report.Name).To(Equal("ng-1"))
+			Expect(report.InstanceTypes).To(Equal([]string{"m5.large"}))
+			Expect(report.Nodes).To(Equal(2))
+			Expect(report.OnDemandNodes).To(Equal(2))
+			Expect(report.AllocatableCPU.MilliValue()).To(Equal(int64(3860)))
+			Expect(report.RequestedCPU.MilliValue()).To(Equal(int64(750)))
+			Expect(report.AllocatableMemory.Value()).To(Equal(int64(14 << 30)))
+			Expect(report.RequestedMemory.Value()).To(Equal(int64(1536 << 20)))
+			Expect(report.Pods).To(Equal(2))
+			// m5.large nodes can run 29 pods each
+			Expect(report.MaxPods).To(Equal(58))
+			Expect(report.PodHeadroom()).To(Equal(56))
+			Expect(report.HourlyCost).ToNot(BeNil())
+			Expect(*report.HourlyCost).To(BeNumerically("~", 0.2))
+		})
+
+		It("should estimate cost of spot instances", func() {
+			distributions := map[string]*api.NodeGroupInstancesDistribution{
+				"ng-1": {
+					InstanceTypes:                       []string{"m5.large"},
+					OnDemandPercentageAboveBaseCapacity: aws.Int(50),
+				},
+			}
+			reports, err := collector.NodeGroups(summaries, distributions)
+			Expect(err).ToNot(HaveOccurred())
+
+			report := reports[0]
+			Expect(report.OnDemandNodes).To(Equal(1))
+			Expect(report.SpotNodes).To(Equal(1))
+			Expect(*report.HourlyCost).To(BeNumerically("~", 0.14))
+		})
+
+		It("should not estimate cost when a price is unknown", func() {
+			summaries[0].Name = "ng-2"
+			distributions := map[string]*api.NodeGroupInstancesDistribution{
+				"ng-2": {
+					InstanceTypes:                       []string{"m5.xlarge"},
+					OnDemandPercentageAboveBaseCapacity: aws.Int(0),
+				},
+			}
+			reports, err := collector.NodeGroups(summaries, distributions)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reports[0].SpotNodes).To(Equal(1))
+			Expect(reports[0].HourlyCost).To(BeNil())
+		})
+	})
+
+	Context("RegionPricer", func() {
+		It("should average current spot prices across availability zones", func() {
+			ec2API := &mocks.EC2API{}
+			ec2API.On("DescribeSpotPriceHistoryPages", mock.MatchedBy(func(input *ec2.DescribeSpotPriceHistoryInput) bool {
+				return aws.StringValue(input.InstanceTypes[0]) == "m5.large"
+			}), mock.Anything).Return(func(_ *ec2.DescribeSpotPriceHistoryInput, fn func(*ec2.DescribeSpotPriceHistoryOutput, bool) bool) error {
+				fn(&ec2.DescribeSpotPriceHistoryOutput{
+					SpotPriceHistory: []*ec2.SpotPrice{
+						{AvailabilityZone: aws.String("us-west-2a"), SpotPrice: aws.String("0.030000")},
+						{AvailabilityZone: aws.String("us-west-2b"), SpotPrice: aws.String("0.040000")},
+					},
+				}, true)
+				return nil
+			}).Once()
+
+			pricer := NewRegionPricer(ec2API, "us-west-2")
+
+			price, ok := pricer.SpotPrice("m5.large")
+			Expect(ok).To(BeTrue())
+			Expect(price).To(BeNumerically("~", 0.035))
+
+			// the price is cached
+			price, ok = pricer.SpotPrice("m5.large")
+			Expect(ok).To(BeTrue())
+			Expect(price).To(BeNumerically("~", 0.035))
+			ec2API.AssertExpectations(GinkgoT())
+
+			price, ok = pricer.OnDemandPrice("m5.large")
+			Expect(ok).To(BeTrue())
+			Expect(price).To(Equal(0.096))
+		})
+	})
+})
@@ -0,0 +1,114 @@
+package capacity
+
+// onDemandPrices holds hourly on-demand prices of Linux instances in USD for each region.
+//
+// This table is maintained by hand and only covers common instance types in a few regions,
+// prices are copied from https://aws.amazon.com/ec2/pricing/on-demand/. To refresh it from
+// the price list API instead, run `make generate-prices` with AWS credentials that allow
+// pricing:GetProducts, it overwrites this file with every supported region and instance type
+var onDemandPrices = map[string]map[string]float64{
+	"eu-west-1": {
+		"c5.2xlarge":  0.384,
+		"c5.4xlarge":  0.768,
+		"c5.large":    0.096,
+		"c5.xlarge":   0.192,
+		"g4dn.xlarge": 0.587,
+		"m4.large":    0.111,
+		"m4.xlarge":   0.222,
+		"m5.2xlarge":  0.428,
+		"m5.4xlarge":  0.856,
+		"m5.large":    0.107,
+		"m5.xlarge":   0.214,
+		"p2.xlarge":   0.972,
+		"p3.2xlarge":  3.305,
+		"r5.2xlarge":  0.564,
+		"r5.large":    0.141,
+		"r5.xlarge":   0.282,
+		"t2.large":    0.101,
+		"t2.medium":   0.05,
+		"t3.2xlarge":  0.3648,
+		"t3.large":    0.0912,
+		"t3.medium":   0.0456,
+		"t3.micro":    0.0114,
+		"t3.small":    0.0228,
+		"t3.xlarge":   0.1824,
+	},
+	"us-east-1": {
+		"c5.2xlarge":  0.34,
+		"c5.4xlarge":  0.68,
+		"c5.large":    0.085,
+		"c5.xlarge":   0.17,
+		"g4dn.xlarge": 0.526,
+		"m4.large":    0.1,
+		"m4.xlarge":   0.2,
+		"m5.2xlarge":  0.384,
+		"m5.4xlarge":  0.768,
+		"m5.large":    0.096,
+		"m5.xlarge":   0.192,
+		"p2.xlarge":   0.9,
+		"p3.2xlarge":  3.06,
+		"r5.2xlarge":  0.504,
+		"r5.large":    0.126,
+		"r5.xlarge":   0.252,
+		"t2.large":    0.0928,
+		"t2.medium":   0.0464,
+		"t3.2xlarge":  0.3328,
+		"t3.large":    0.0832,
+		"t3.medium":   0.0416,
+		"t3.micro":    0.0104,
+		"t3.small":    0.0208,
+		"t3.xlarge":   0.1664,
+	},
+	"us-east-2": {
+		"c5.2xlarge":  0.34,
+		"c5.4xlarge":  0.68,
+		"c5.large":    0.085,
+		"c5.xlarge":   0.17,
+		"g4dn.xlarge": 0.526,
+		"m4.large":    0.1,
+		"m4.xlarge":   0.2,
+		"m5.2xlarge":  0.384,
+		"m5.4xlarge":  0.768,
+		"m5.large":    0.096,
+		"m5.xlarge":   0.192,
+		"p2.xlarge":   0.9,
+		"p3.2xlarge":  3.06,
+		"r5.2xlarge":  0.504,
+		"r5.large":    0.126,
+		"r5.xlarge":   0.252,
+		"t2.large":    0.0928,
+		"t2.medium":   0.0464,
+		"t3.2xlarge":  0.3328,
+		"t3.large":    0.0832,
+		"t3.medium":   0.0416,
+		"t3.micro":    0.0104,
+		"t3.small":    0.0208,
+		"t3.xlarge":   0.1664,
+	},
+	"us-west-2": {
+		"c5.2xlarge":  0.34,
+		"c5.4xlarge":  0.68,
+		"c5.large":    0.085,
+		"c5.xlarge":   0.17,
+		"g4dn.xlarge": 0.526,
+		"m4.large":    0.1,
+		"m4.xlarge":   0.2,
+		"m5.2xlarge":  0.384,
+		"m5.4xlarge":  0.768,
+		"m5.large":    0.096,
+		"m5.xlarge":   0.192,
+		"p2.xlarge":   0.9,
+		"p3.2xlarge":  3.06,
+		"r5.2xlarge":  0.504,
+		"r5.large":    0.126,
+		"r5.xlarge":   0.252,
+		"t2.large":    0.0928,
+		"t2.medium":   0.0464,
+		"t3.2xlarge":  0.3328,
+		"t3.large":    0.0832,
+		"t3.medium":   0.0416,
+		"t3.micro":    0.0104,
+		"t3.small":    0.0208,
+		"t3.xlarge":   0.1664,
+	},
+}
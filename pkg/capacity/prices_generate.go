@@ -0,0 +1,144 @@
+// +build ignore
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/pricing"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+
+	. "github.com/dave/jennifer/jen"
+)
+
+// the price list API is only available in a few regions, but it returns prices for all regions
+const pricingRegion = "us-east-1"
+
+// priceListItem holds the fields of a price list item that are needed, see
+// https://docs.aws.amazon.com/awsaccountbilling/latest/aboutv2/price-changes.html
+type priceListItem struct {
+	Product struct {
+		Attributes struct {
+			InstanceType string `json:"instanceType"`
+		} `json:"attributes"`
+	} `json:"product"`
+	Terms struct {
+		OnDemand map[string]struct {
+			PriceDimensions map[string]struct {
+				Unit         string            `json:"unit"`
+				PricePerUnit map[string]string `json:"pricePerUnit"`
+			} `json:"priceDimensions"`
+		} `json:"OnDemand"`
+	} `json:"terms"`
+}
+
+func main() {
+	fmt.Println("generating table of on-demand instance prices")
+
+	client := pricing.New(newSession(pricingRegion))
+
+	d := Dict{}
+	for _, region := range api.SupportedRegions() {
+		location, ok := endpoints.AwsPartition().Regions()[region]
+		if !ok {
+			log.Fatalf("unknown region %q", region)
+		}
+		log.Printf("looking up prices in %q", location.Description())
+
+		regionPrices := Dict{}
+		for instanceType, price := range getPrices(client, location.Description()) {
+			regionPrices[Lit(instanceType)] = Lit(price)
+		}
+		d[Lit(region)] = Values(regionPrices)
+	}
+
+	f := NewFile("capacity")
+
+	f.Comment("This file was generated by prices_generate.go; DO NOT EDIT.")
+	f.Line()
+
+	f.Comment("onDemandPrices holds hourly on-demand prices of Linux instances in USD for each region")
+	f.Var().Id("onDemandPrices").Op("=").
+		Map(String()).Map(String()).Float64().Values(d)
+
+	if err := f.Save("prices.go"); err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+func getPrices(client *pricing.Pricing, location string) map[string]float64 {
+	filter := func(field, value string) *pricing.Filter {
+		return &pricing.Filter{
+			Type:  aws.String(pricing.FilterTypeTermMatch),
+			Field: aws.String(field),
+			Value: aws.String(value),
+		}
+	}
+	input := &pricing.GetProductsInput{
+		ServiceCode: aws.String("AmazonEC2"),
+		Filters: []*pricing.Filter{
+			filter("location", location),
+			filter("operatingSystem", "Linux"),
+			filter("tenancy", "Shared"),
+			filter("preInstalledSw", "NA"),
+			filter("capacitystatus", "Used"),
+			filter("licenseModel", "No License required"),
+		},
+	}
+
+	prices := make(map[string]float64)
+	err := client.GetProductsPages(input, func(output *pricing.GetProductsOutput, _ bool) bool {
+		for _, value := range output.PriceList {
+			data, err := json.Marshal(value)
+			if err != nil {
+				log.Fatal(err)
+			}
+			item := priceListItem{}
+			if err := json.Unmarshal(data, &item); err != nil {
+				log.Fatal(err)
+			}
+			for _, term := range item.Terms.OnDemand {
+				for _, dimension := range term.PriceDimensions {
+					if dimension.Unit != "Hrs" {
+						continue
+					}
+					price, err := strconv.ParseFloat(dimension.PricePerUnit["USD"], 64)
+					if err != nil {
+						log.Fatal(err)
+					}
+					if price > 0 {
+						prices[item.Product.Attributes.InstanceType] = price
+					}
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return prices
+}
+
+func newSession(region string) *session.Session {
+	config := aws.NewConfig()
+	config = config.WithRegion(region)
+	config = config.WithCredentialsChainVerboseErrors(true)
+
+	// Create the options for the session
+	opts := session.Options{
+		Config:                  *config,
+		SharedConfigState:       session.SharedConfigEnable,
+		AssumeRoleTokenProvider: stscreds.StdinTokenProvider,
+	}
+
+	return session.Must(session.NewSessionWithOptions(opts))
+}
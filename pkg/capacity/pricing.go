@@ -0,0 +1,87 @@
+package capacity
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/kris-nova/logger"
+)
+
+// Pricer returns hourly prices of instance types in USD
+type Pricer interface {
+	OnDemandPrice(instanceType string) (float64, bool)
+	SpotPrice(instanceType string) (float64, bool)
+}
+
+// OnDemandPrice returns the on-demand price of an instance type according to the
+// embedded price table
+func OnDemandPrice(region, instanceType string) (float64, bool) {
+	price, ok := onDemandPrices[region][instanceType]
+	return price, ok
+}
+
+// RegionPricer uses the embedded price table for on-demand prices and current spot
+// prices from the EC2 API
+type RegionPricer struct {
+	ec2API ec2iface.EC2API
+	region string
+
+	spotPrices map[string]float64
+}
+
+// NewRegionPricer creates a pricer for the given region
+func NewRegionPricer(ec2API ec2iface.EC2API, region string) *RegionPricer {
+	return &RegionPricer{
+		ec2API:     ec2API,
+		region:     region,
+		spotPrices: make(map[string]float64),
+	}
+}
+
+// OnDemandPrice returns the on-demand price of an instance type
+func (p *RegionPricer) OnDemandPrice(instanceType string) (float64, bool) {
+	return OnDemandPrice(p.region, instanceType)
+}
+
+// SpotPrice returns the current spot price of an instance type, averaged across availability zones
+func (p *RegionPricer) SpotPrice(instanceType string) (float64, bool) {
+	if price, ok := p.spotPrices[instanceType]; ok {
+		return price, true
+	}
+
+	input := &ec2.DescribeSpotPriceHistoryInput{
+		InstanceTypes:       aws.StringSlice([]string{instanceType}),
+		ProductDescriptions: aws.StringSlice([]string{"Linux/UNIX"}),
+		StartTime:           aws.Time(time.Now()),
+	}
+	// only the latest price of each availability zone is returned when start time is now
+	pricesByZone := make(map[string]float64)
+	err := p.ec2API.DescribeSpotPriceHistoryPages(input, func(output *ec2.DescribeSpotPriceHistoryOutput, _ bool) bool {
+		for _, price := range output.SpotPriceHistory {
+			value, err := strconv.ParseFloat(aws.StringValue(price.SpotPrice), 64)
+			if err != nil {
+				logger.Debug("ignoring spot price %q of %s: %s", aws.StringValue(price.SpotPrice), instanceType, err.Error())
+				continue
+			}
+			pricesByZone[aws.StringValue(price.AvailabilityZone)] = value
+		}
+		return true
+	})
+	if err != nil {
+		logger.Debug("unable to get spot price of %s: %s", instanceType, err.Error())
+		return 0, false
+	}
+	if len(pricesByZone) == 0 {
+		return 0, false
+	}
+
+	sum := 0.0
+	for _, price := range pricesByZone {
+		sum += price
+	}
+	p.spotPrices[instanceType] = sum / float64(len(pricesByZone))
+	return p.spotPrices[instanceType], true
+}
@@ -72,6 +72,9 @@ type Properties struct {
 
 	CidrIp, CidrIpv6, IpProtocol string
 	FromPort, ToPort             int
+	Description                  string
+
+	GroupId, SourceSecurityGroupId, DestinationSecurityGroupId interface{}
 
 	VpcId, SubnetId                            interface{}
 	RouteTableId, AllocationId                 interface{}
@@ -1115,6 +1118,30 @@ var _ = Describe("CloudFormation template builder API", func() {
 		})
 	})
 
+	Context("NodeGroupSSM", func() {
+		cfg, ng := newClusterConfigAndNodegroup(true)
+
+		ng.SSH.EnableSSM = api.Enabled()
+
+		build(cfg, "eksctl-test-ssm-cluster", ng)
+
+		roundtrip()
+
+		It("should have correct managed profile", func() {
+			Expect(ngTemplate.Resources).To(HaveKey("NodeInstanceRole"))
+
+			role := ngTemplate.Resources["NodeInstanceRole"].Properties
+
+			Expect(role.ManagedPolicyArns).To(HaveLen(4))
+			Expect(role.ManagedPolicyArns[3]).To(Equal("arn:aws:iam::aws:policy/AmazonSSMManagedInstanceCore"))
+		})
+
+		It("should not open SSH port", func() {
+			Expect(ngTemplate.Resources).ToNot(HaveKey("SSHIPv4"))
+			Expect(ngTemplate.Resources).ToNot(HaveKey("SSHIPv6"))
+		})
+	})
+
 	Context("NodeGroupEBS", func() {
 		cfg, ng := newClusterConfigAndNodegroup(true)
 
@@ -1466,6 +1493,123 @@ var _ = Describe("CloudFormation template builder API", func() {
 		})
 	})
 
+	Context("NodeGroup{PrivateNetworking=false SSH.Allow=true SSH.SourceCIDRs=[2]}", func() {
+		cfg, ng := newClusterConfigAndNodegroup(true)
+
+		ng.SSH.Allow = api.Enabled()
+		keyName := ""
+		ng.SSH.PublicKeyName = &keyName
+		ng.SSH.SourceCIDRs = []string{"10.1.0.0/16", "2001:db8::/32"}
+		ng.PrivateNetworking = false
+
+		build(cfg, "eksctl-test-ssh-cidrs-ng", ng)
+
+		roundtrip()
+
+		It("should only allow SSH from the given CIDRs", func() {
+			Expect(ngTemplate.Resources).ToNot(HaveKey("SSHIPv4"))
+			Expect(ngTemplate.Resources).ToNot(HaveKey("SSHIPv6"))
+
+			Expect(ngTemplate.Resources).To(HaveKey("SSHSourceCIDR0"))
+			Expect(ngTemplate.Resources["SSHSourceCIDR0"].Properties.CidrIp).To(Equal("10.1.0.0/16"))
+			Expect(ngTemplate.Resources["SSHSourceCIDR0"].Properties.FromPort).To(Equal(22))
+			Expect(ngTemplate.Resources["SSHSourceCIDR0"].Properties.ToPort).To(Equal(22))
+
+			Expect(ngTemplate.Resources).To(HaveKey("SSHSourceCIDR1"))
+			Expect(ngTemplate.Resources["SSHSourceCIDR1"].Properties.CidrIp).To(BeEmpty())
+			Expect(ngTemplate.Resources["SSHSourceCIDR1"].Properties.CidrIpv6).To(Equal("2001:db8::/32"))
+		})
+	})
+
+	Context("NodeGroup{SecurityGroups.IngressRules=[2] SecurityGroups.EgressRules=[1]}", func() {
+		cfg, ng := newClusterConfigAndNodegroup(true)
+
+		httpPort, nodePortMin, nodePortMax := 80, 30000, 32767
+		ng.SecurityGroups.IngressRules = []api.SecurityGroupRule{
+			{
+				CIDR:        "10.0.0.0/8",
+				Protocol:    "tcp",
+				FromPort:    &nodePortMin,
+				ToPort:      &nodePortMax,
+				Description: "Allow NodePort services from the office",
+			},
+			{
+				SecurityGroupID: "sg-lb",
+				Protocol:        "tcp",
+				FromPort:        &httpPort,
+				ToPort:          &httpPort,
+			},
+		}
+		ng.SecurityGroups.EgressRules = []api.SecurityGroupRule{
+			{
+				SecurityGroupID: "sg-db",
+				Protocol:        "-1",
+			},
+		}
+
+		build(cfg, "eksctl-test-sg-rules-ng", ng)
+
+		roundtrip()
+
+		It("should have ingress rules", func() {
+			rule := ngTemplate.Resources["IngressRule0"].Properties
+			Expect(rule.CidrIp).To(Equal("10.0.0.0/8"))
+			Expect(rule.IpProtocol).To(Equal("tcp"))
+			Expect(rule.FromPort).To(Equal(30000))
+			Expect(rule.ToPort).To(Equal(32767))
+			Expect(rule.Description).To(Equal("Allow NodePort services from the office"))
+			Expect(rule.GroupId).To(Equal(map[string]interface{}{"Ref": "SG"}))
+
+			rule = ngTemplate.Resources["IngressRule1"].Properties
+			Expect(rule.SourceSecurityGroupId).To(Equal("sg-lb"))
+			Expect(rule.FromPort).To(Equal(80))
+			Expect(rule.Description).To(ContainSubstring("worker nodes in group"))
+		})
+
+		It("should have egress rules", func() {
+			rule := ngTemplate.Resources["EgressRule0"].Properties
+			Expect(rule.DestinationSecurityGroupId).To(Equal("sg-db"))
+			Expect(rule.IpProtocol).To(Equal("-1"))
+		})
+
+		It("should only render rules when updating", func() {
+			rs := NewNodeGroupResourceSet(p, cfg, "eksctl-test-sg-rules-ng", ng)
+			rs.AddSecurityGroupRuleResources()
+
+			for name := range rs.Template().Resources {
+				Expect(IsNodeGroupSecurityGroupRule(name)).To(BeTrue())
+			}
+			Expect(rs.Template().Resources).To(HaveLen(3))
+		})
+	})
+
+	Context("Cluster{SecurityGroups.IngressRules=[1]}", func() {
+		cfg, ng := newClusterConfigAndNodegroup(true)
+
+		httpsPort := 443
+		cfg.SecurityGroups = &api.ClusterSecurityGroups{
+			IngressRules: []api.SecurityGroupRule{{
+				CIDR:     "172.16.0.0/12",
+				Protocol: "tcp",
+				FromPort: &httpsPort,
+				ToPort:   &httpsPort,
+			}},
+		}
+
+		build(cfg, "eksctl-test-cp-sg-rules", ng)
+
+		roundtrip()
+
+		It("should add the rule to the control plane security group", func() {
+			Expect(clusterTemplate.Resources).To(HaveKey("ControlPlaneIngressRule0"))
+			rule := clusterTemplate.Resources["ControlPlaneIngressRule0"].Properties
+			Expect(rule.CidrIp).To(Equal("172.16.0.0/12"))
+			Expect(rule.FromPort).To(Equal(443))
+			Expect(rule.GroupId).To(Equal("sg-0b44c48bcba5b7362"))
+			Expect(IsClusterSecurityGroupRule("ControlPlaneIngressRule0")).To(BeTrue())
+		})
+	})
+
 	Context("NodeGroup{PrivateNetworking=false SSH.Allow=false}", func() {
 		cfg, ng := newClusterConfigAndNodegroup(true)
 		disable := api.ClusterDisableNAT
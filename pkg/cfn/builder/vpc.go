@@ -180,22 +180,38 @@ var (
 	sgMaxNodePort = gfn.NewInteger(65535)
 
 	sgPortHTTPS = gfn.NewInteger(443)
-	sgPortSSH   = gfn.NewInteger(22)
+	sgPortSSH   = gfn.NewInteger(sshPort)
+
+	sshPort = 22
+)
+
+const (
+	clusterIngressRulePrefix     = "ControlPlaneIngressRule"
+	clusterEgressRulePrefix      = "ControlPlaneEgressRule"
+	nodeGroupIngressRulePrefix   = "IngressRule"
+	nodeGroupEgressRulePrefix    = "EgressRule"
+	nodeGroupSSHSourceCIDRPrefix = "SSHSourceCIDR"
 )
 
 func (c *ClusterResourceSet) addResourcesForSecurityGroups() {
 	var refControlPlaneSG, refClusterSharedNodeSG *gfn.Value
 
 	if c.spec.VPC.SecurityGroup == "" {
-		refControlPlaneSG = c.newResource("ControlPlaneSecurityGroup", &gfn.AWSEC2SecurityGroup{
+		controlPlaneSG := &gfn.AWSEC2SecurityGroup{
 			GroupDescription: gfn.NewString("Communication between the control plane and worker nodegroups"),
 			VpcId:            c.vpc,
-		})
+		}
+		if c.spec.SecurityGroups != nil && len(c.spec.SecurityGroups.EgressRules) > 0 {
+			controlPlaneSG.SecurityGroupEgress = RestrictedEgress()
+		}
+		refControlPlaneSG = c.newResource("ControlPlaneSecurityGroup", controlPlaneSG)
 	} else {
 		refControlPlaneSG = gfn.NewString(c.spec.VPC.SecurityGroup)
 	}
 	c.securityGroups = []*gfn.Value{refControlPlaneSG} // only this one SG is passed to EKS API, nodes are isolated
 
+	c.addResourcesForSecurityGroupRules(refControlPlaneSG)
+
 	if c.spec.VPC.SharedNodeSecurityGroup == "" {
 		refClusterSharedNodeSG = c.newResource("ClusterSharedNodeSecurityGroup", &gfn.AWSEC2SecurityGroup{
 			GroupDescription: gfn.NewString("Communication between all nodes in the cluster"),
@@ -242,18 +258,20 @@ func (n *NodeGroupResourceSet) addResourcesForSecurityGroups() {
 
 	desc := "worker nodes in group " + n.nodeGroupName
 
-	allInternalIPv4 := gfn.NewString(n.clusterSpec.VPC.CIDR.String())
-
 	refControlPlaneSG := makeImportValue(n.clusterStackName, outputs.ClusterSecurityGroup)
 
-	refNodeGroupLocalSG := n.newResource("SG", &gfn.AWSEC2SecurityGroup{
+	nodeGroupLocalSG := &gfn.AWSEC2SecurityGroup{
 		VpcId:            makeImportValue(n.clusterStackName, outputs.ClusterVPC),
 		GroupDescription: gfn.NewString("Communication between the control plane and " + desc),
 		Tags: []gfn.Tag{{
 			Key:   gfn.NewString("kubernetes.io/cluster/" + n.clusterSpec.Metadata.Name),
 			Value: gfn.NewString("owned"),
 		}},
-	})
+	}
+	if len(n.spec.SecurityGroups.EgressRules) > 0 {
+		nodeGroupLocalSG.SecurityGroupEgress = RestrictedEgress()
+	}
+	refNodeGroupLocalSG := n.newResource("SG", nodeGroupLocalSG)
 
 	n.securityGroups = append(n.securityGroups, refNodeGroupLocalSG)
 
@@ -297,8 +315,59 @@ func (n *NodeGroupResourceSet) addResourcesForSecurityGroups() {
 		FromPort:              sgPortHTTPS,
 		ToPort:                sgPortHTTPS,
 	})
-	if *n.spec.SSH.Allow {
-		if n.spec.PrivateNetworking {
+	n.addResourcesForSecurityGroupRules(refNodeGroupLocalSG)
+}
+
+// AddSecurityGroupRuleResources adds only the user-defined rules of the control plane
+// security group, it's used for updating an existing cluster stack; when the stack
+// owns the security group, rules must reference it instead of using the ID
+func (c *ClusterResourceSet) AddSecurityGroupRuleResources(ownSecurityGroup bool) {
+	refControlPlaneSG := gfn.MakeRef("ControlPlaneSecurityGroup")
+	if !ownSecurityGroup {
+		refControlPlaneSG = gfn.NewString(c.spec.VPC.SecurityGroup)
+	}
+	c.addResourcesForSecurityGroupRules(refControlPlaneSG)
+}
+
+func (c *ClusterResourceSet) addResourcesForSecurityGroupRules(refControlPlaneSG *gfn.Value) {
+	if !c.spec.HasSecurityGroupRules() {
+		return
+	}
+	for i, rule := range c.spec.SecurityGroups.IngressRules {
+		c.newResource(fmt.Sprintf("%s%d", clusterIngressRulePrefix, i), newSecurityGroupIngress(refControlPlaneSG, rule,
+			"Allow extra ingress to the control plane"))
+	}
+	for i, rule := range c.spec.SecurityGroups.EgressRules {
+		c.newResource(fmt.Sprintf("%s%d", clusterEgressRulePrefix, i), newSecurityGroupEgress(refControlPlaneSG, rule,
+			"Allow extra egress from the control plane"))
+	}
+}
+
+// AddSecurityGroupRuleResources adds only the SSH and user-defined rules of the nodegroup
+// security group, it's used for updating an existing nodegroup stack
+func (n *NodeGroupResourceSet) AddSecurityGroupRuleResources() {
+	if api.IsDisabled(n.spec.SecurityGroups.WithLocal) {
+		return
+	}
+	n.addResourcesForSecurityGroupRules(gfn.MakeRef("SG"))
+}
+
+func (n *NodeGroupResourceSet) addResourcesForSecurityGroupRules(refNodeGroupLocalSG *gfn.Value) {
+	desc := "worker nodes in group " + n.nodeGroupName
+
+	if api.IsEnabled(n.spec.SSH.Allow) {
+		switch {
+		case len(n.spec.SSH.SourceCIDRs) > 0:
+			for i, cidr := range n.spec.SSH.SourceCIDRs {
+				n.newResource(fmt.Sprintf("%s%d", nodeGroupSSHSourceCIDRPrefix, i), newSecurityGroupIngress(refNodeGroupLocalSG, api.SecurityGroupRule{
+					CIDR:     cidr,
+					Protocol: api.SecurityGroupRuleProtocolTCP,
+					FromPort: &sshPort,
+					ToPort:   &sshPort,
+				}, "Allow SSH access to "+desc+" (from "+cidr+")"))
+			}
+		case n.spec.PrivateNetworking:
+			allInternalIPv4 := gfn.NewString(n.clusterSpec.VPC.CIDR.String())
 			n.newResource("SSHIPv4", &gfn.AWSEC2SecurityGroupIngress{
 				GroupId:     refNodeGroupLocalSG,
 				CidrIp:      allInternalIPv4,
@@ -307,7 +376,7 @@ func (n *NodeGroupResourceSet) addResourcesForSecurityGroups() {
 				FromPort:    sgPortSSH,
 				ToPort:      sgPortSSH,
 			})
-		} else {
+		default:
 			n.newResource("SSHIPv4", &gfn.AWSEC2SecurityGroupIngress{
 				GroupId:     refNodeGroupLocalSG,
 				CidrIp:      sgSourceAnywhereIPv4,
@@ -326,6 +395,102 @@ func (n *NodeGroupResourceSet) addResourcesForSecurityGroups() {
 			})
 		}
 	}
+
+	for i, rule := range n.spec.SecurityGroups.IngressRules {
+		n.newResource(fmt.Sprintf("%s%d", nodeGroupIngressRulePrefix, i), newSecurityGroupIngress(refNodeGroupLocalSG, rule,
+			"Allow extra ingress to "+desc))
+	}
+	for i, rule := range n.spec.SecurityGroups.EgressRules {
+		n.newResource(fmt.Sprintf("%s%d", nodeGroupEgressRulePrefix, i), newSecurityGroupEgress(refNodeGroupLocalSG, rule,
+			"Allow extra egress from "+desc))
+	}
+}
+
+// IsClusterSecurityGroupRule returns true for logical names of resources
+// that are added by ClusterResourceSet.AddSecurityGroupRuleResources
+func IsClusterSecurityGroupRule(name string) bool {
+	return strings.HasPrefix(name, clusterIngressRulePrefix) || strings.HasPrefix(name, clusterEgressRulePrefix)
+}
+
+// IsNodeGroupSecurityGroupRule returns true for logical names of resources
+// that are added by NodeGroupResourceSet.AddSecurityGroupRuleResources
+func IsNodeGroupSecurityGroupRule(name string) bool {
+	return IsNodeGroupSSHRule(name) ||
+		strings.HasPrefix(name, nodeGroupIngressRulePrefix) || strings.HasPrefix(name, nodeGroupEgressRulePrefix)
+}
+
+// IsNodeGroupSSHRule returns true for logical names of the SSH rules of a nodegroup
+func IsNodeGroupSSHRule(name string) bool {
+	for _, prefix := range []string{"SSHIPv4", "SSHIPv6", nodeGroupSSHSourceCIDRPrefix} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RestrictedEgress returns the egress rules of a security group that has user-defined
+// egress rules, CloudFormation only revokes the default rule allowing all egress when
+// the security group itself has egress rules, so this one allows no traffic
+func RestrictedEgress() []gfn.AWSEC2SecurityGroup_Egress {
+	return []gfn.AWSEC2SecurityGroup_Egress{{
+		CidrIp:      gfn.NewString("127.0.0.1/32"),
+		IpProtocol:  gfn.NewString("-1"),
+		Description: gfn.NewString("Revoke the default rule allowing all egress, only the egress rules that are set are allowed"),
+	}}
+}
+
+func setSecurityGroupRulePeerAndPorts(rule api.SecurityGroupRule, setCIDR func(*gfn.Value), setCIDRv6 func(*gfn.Value), setSG func(*gfn.Value)) (fromPort, toPort *gfn.Value) {
+	switch {
+	case rule.SecurityGroupID != "":
+		setSG(gfn.NewString(rule.SecurityGroupID))
+	case api.IsIPv6CIDR(rule.CIDR):
+		setCIDRv6(gfn.NewString(rule.CIDR))
+	default:
+		setCIDR(gfn.NewString(rule.CIDR))
+	}
+	if rule.FromPort != nil {
+		fromPort = gfn.NewInteger(*rule.FromPort)
+	}
+	if rule.ToPort != nil {
+		toPort = gfn.NewInteger(*rule.ToPort)
+	}
+	return fromPort, toPort
+}
+
+func newSecurityGroupIngress(refSG *gfn.Value, rule api.SecurityGroupRule, defaultDescription string) *gfn.AWSEC2SecurityGroupIngress {
+	ingress := &gfn.AWSEC2SecurityGroupIngress{
+		GroupId:     refSG,
+		Description: gfn.NewString(securityGroupRuleDescription(rule, defaultDescription)),
+		IpProtocol:  gfn.NewString(rule.Protocol),
+	}
+	ingress.FromPort, ingress.ToPort = setSecurityGroupRulePeerAndPorts(rule,
+		func(v *gfn.Value) { ingress.CidrIp = v },
+		func(v *gfn.Value) { ingress.CidrIpv6 = v },
+		func(v *gfn.Value) { ingress.SourceSecurityGroupId = v },
+	)
+	return ingress
+}
+
+func newSecurityGroupEgress(refSG *gfn.Value, rule api.SecurityGroupRule, defaultDescription string) *gfn.AWSEC2SecurityGroupEgress {
+	egress := &gfn.AWSEC2SecurityGroupEgress{
+		GroupId:     refSG,
+		Description: gfn.NewString(securityGroupRuleDescription(rule, defaultDescription)),
+		IpProtocol:  gfn.NewString(rule.Protocol),
+	}
+	egress.FromPort, egress.ToPort = setSecurityGroupRulePeerAndPorts(rule,
+		func(v *gfn.Value) { egress.CidrIp = v },
+		func(v *gfn.Value) { egress.CidrIpv6 = v },
+		func(v *gfn.Value) { egress.DestinationSecurityGroupId = v },
+	)
+	return egress
+}
+
+func securityGroupRuleDescription(rule api.SecurityGroupRule, defaultDescription string) string {
+	if rule.Description != "" {
+		return rule.Description
+	}
+	return defaultDescription
 }
 
 func (c *ClusterResourceSet) haNAT() {
@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 
@@ -110,8 +111,9 @@ func (c *StackCollection) DoCreateStackRequest(i *Stack, templateBody []byte, ta
 // CreateStack with given name, stack builder instance and parameters;
 // any errors will be written to errs channel, when nil is written,
 // assume completion, do not expect more then one error value on the
-// channel, it's closed immediately after it is written to
-func (c *StackCollection) CreateStack(name string, stack builder.ResourceSet, tags, parameters map[string]string, errs chan error) error {
+// channel, it's closed immediately after it is written to; waiting
+// stops when ctx is cancelled
+func (c *StackCollection) CreateStack(ctx context.Context, name string, stack builder.ResourceSet, tags, parameters map[string]string, errs chan error) error {
 	i := &Stack{StackName: &name}
 	templateBody, err := stack.RenderJSON()
 	if err != nil {
@@ -124,7 +126,7 @@ func (c *StackCollection) CreateStack(name string, stack builder.ResourceSet, ta
 
 	logger.Info("deploying stack %q", name)
 
-	go c.waitUntilStackIsCreated(i, stack, errs)
+	go c.waitUntilStackIsCreated(ctx, i, stack, errs)
 
 	return nil
 }
@@ -136,7 +138,9 @@ func (c *StackCollection) UpdateStack(stackName string, changeSetName string, de
 	if err := c.doCreateChangeSetRequest(i, changeSetName, description, template, parameters, true); err != nil {
 		return err
 	}
-	if err := c.doWaitUntilChangeSetIsCreated(i, changeSetName); err != nil {
+	// TODO: updates are not run as tasks yet, so there is no context to use
+	ctx := context.TODO()
+	if err := c.doWaitUntilChangeSetIsCreated(ctx, i, changeSetName); err != nil {
 		return err
 	}
 	changeSet, err := c.DescribeStackChangeSet(i, changeSetName)
@@ -148,7 +152,7 @@ func (c *StackCollection) UpdateStack(stackName string, changeSetName string, de
 		logger.Warning("error executing Cloudformation changeSet %s in stack %s. Check the Cloudformation console for further details", changeSetName, stackName)
 		return err
 	}
-	return c.doWaitUntilStackIsUpdated(i)
+	return c.doWaitUntilStackIsUpdated(ctx, i)
 }
 
 // DescribeStack describes a cloudformation stack.
@@ -301,7 +305,7 @@ func (c *StackCollection) DeleteStackByName(name string) (*Stack, error) {
 // DeleteStackByNameSync sends a request to delete the stack, and waits until status is DELETE_COMPLETE;
 // any errors will be written to errs channel, assume completion when nil is written, do not expect
 // more then one error value on the channel, it's closed immediately after it is written to
-func (c *StackCollection) DeleteStackByNameSync(name string, errs chan error) error {
+func (c *StackCollection) DeleteStackByNameSync(ctx context.Context, name string, errs chan error) error {
 	i, err := c.DeleteStackByName(name)
 	if err != nil {
 		return err
@@ -309,7 +313,7 @@ func (c *StackCollection) DeleteStackByNameSync(name string, errs chan error) er
 
 	logger.Info("waiting for stack %q to get deleted", *i.StackName)
 
-	go c.waitUntilStackIsDeleted(i, errs)
+	go c.waitUntilStackIsDeleted(ctx, i, errs)
 
 	return nil
 }
@@ -353,7 +357,7 @@ func matchesClusterName(key, value, name string) bool {
 // DeleteStackBySpecSync sends a request to delete the stack, and waits until status is DELETE_COMPLETE;
 // any errors will be written to errs channel, assume completion when nil is written, do not expect
 // more then one error value on the channel, it's closed immediately after it is written to
-func (c *StackCollection) DeleteStackBySpecSync(s *Stack, errs chan error) error {
+func (c *StackCollection) DeleteStackBySpecSync(ctx context.Context, s *Stack, errs chan error) error {
 	i, err := c.DeleteStackBySpec(s)
 	if err != nil {
 		return err
@@ -361,7 +365,7 @@ func (c *StackCollection) DeleteStackBySpecSync(s *Stack, errs chan error) error
 
 	logger.Info("waiting for stack %q to get deleted", *i.StackName)
 
-	go c.waitUntilStackIsDeleted(i, errs)
+	go c.waitUntilStackIsDeleted(ctx, i, errs)
 
 	return nil
 }
@@ -387,6 +391,12 @@ func (c *StackCollection) DescribeStacks() ([]*Stack, error) {
 	return stacks, nil
 }
 
+// ListStacksForCluster lists the stacks of the cluster that haven't been deleted, unlike
+// DescribeStacks it's not an error if there are none
+func (c *StackCollection) ListStacksForCluster() ([]*Stack, error) {
+	return c.ListStacks(fmtStacksRegexForCluster(c.spec.Metadata.Name))
+}
+
 // DescribeStackEvents describes the events that have occurred on the stack
 func (c *StackCollection) DescribeStackEvents(i *Stack) ([]*cloudformation.StackEvent, error) {
 	input := &cloudformation.DescribeStackEventsInput{
@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -26,7 +27,7 @@ func (c *StackCollection) makeClusterStackName() string {
 }
 
 // createClusterTask creates the cluster
-func (c *StackCollection) createClusterTask(errs chan error) error {
+func (c *StackCollection) createClusterTask(ctx context.Context, errs chan error) error {
 	name := c.makeClusterStackName()
 	logger.Info("building cluster stack %q", name)
 	stack := builder.NewClusterResourceSet(c.provider, c.spec)
@@ -35,7 +36,7 @@ func (c *StackCollection) createClusterTask(errs chan error) error {
 	}
 
 	// Unlike with `createNodeGroupTask`, all tags are already set for the cluster stack
-	return c.CreateStack(name, stack, nil, nil, errs)
+	return c.CreateStack(ctx, name, stack, nil, nil, errs)
 }
 
 // DescribeClusterStack calls DescribeStacks and filters out cluster stack
@@ -56,6 +57,20 @@ func (c *StackCollection) DescribeClusterStack() (*Stack, error) {
 	return nil, c.errStackNotFound()
 }
 
+// HasClusterStack returns true if the cluster stack exists
+func (c *StackCollection) HasClusterStack() (bool, error) {
+	stacks, err := c.ListStacksForCluster()
+	if err != nil {
+		return false, err
+	}
+	for _, s := range stacks {
+		if getClusterName(s) != "" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // AppendNewClusterStackResource will update cluster
 // stack with new resources in append-only way
 func (c *StackCollection) AppendNewClusterStackResource(plan bool) (bool, error) {
@@ -104,6 +119,10 @@ func (c *StackCollection) AppendNewClusterStackResource(plan bool) (bool, error)
 		if currentSet.Get(k).Exists() {
 			return true
 		}
+		// security group rules are managed by UpdateClusterSecurityGroupRules
+		if root == resourcesRootPath && builder.IsClusterSecurityGroupRule(k) {
+			return true
+		}
 		*list = append(*list, k)
 		path := root + "." + k
 		currentTemplate, iterErr = sjson.Set(currentTemplate, path, value.Value())
@@ -143,6 +162,41 @@ func (c *StackCollection) AppendNewClusterStackResource(plan bool) (bool, error)
 	return true, c.UpdateStack(name, c.MakeChangeSetName("update-cluster"), describeUpdate, []byte(currentTemplate), nil)
 }
 
+// UpdateClusterSecurityGroupRules will update user-defined rules of the control plane
+// security group in the cluster stack, the default rule allowing all egress is revoked
+// when there are egress rules, unless the security group isn't owned by the stack
+func (c *StackCollection) UpdateClusterSecurityGroupRules(plan bool) (bool, error) {
+	name := c.makeClusterStackName()
+
+	currentTemplate, err := c.GetStackTemplate(name)
+	if err != nil {
+		return false, errors.Wrapf(err, "error getting stack template %s", name)
+	}
+
+	ownSecurityGroup := gjson.Get(currentTemplate, resourcesRootPath+".ControlPlaneSecurityGroup").Exists()
+
+	hasEgressRules := c.spec.SecurityGroups != nil && len(c.spec.SecurityGroups.EgressRules) > 0
+
+	var changed []string
+	if ownSecurityGroup {
+		var egressChanged bool
+		currentTemplate, egressChanged, err = restrictTemplateEgress(currentTemplate, "ControlPlaneSecurityGroup", hasEgressRules)
+		if err != nil {
+			return false, err
+		}
+		if egressChanged {
+			changed = append(changed, "ControlPlaneSecurityGroup")
+		}
+	} else if hasEgressRules {
+		logger.Warning("the default rule allowing all egress from security group %q is kept, as it's not managed by eksctl", c.spec.VPC.SecurityGroup)
+	}
+
+	newStack := builder.NewClusterResourceSet(c.provider, c.spec)
+	newStack.AddSecurityGroupRuleResources(ownSecurityGroup)
+
+	return c.updateStackResources(name, "update-cluster-security-groups", currentTemplate, newStack, builder.IsClusterSecurityGroupRule, changed, plan)
+}
+
 func getClusterName(s *Stack) string {
 	if strings.HasSuffix(*s.StackName, "-cluster") {
 		if v := getClusterNameTag(s); v != "" {
@@ -13,8 +13,9 @@ func (c *StackCollection) NewTasksToCreateClusterWithNodeGroups(onlyNodeGroupSub
 
 	tasks.Append(
 		&taskWithoutParams{
-			info: fmt.Sprintf("create cluster control plane %q", c.spec.Metadata.Name),
-			call: c.createClusterTask,
+			info:      fmt.Sprintf("create cluster control plane %q", c.spec.Metadata.Name),
+			stackName: c.makeClusterStackName(),
+			call:      c.createClusterTask,
 		},
 	)
 
@@ -40,6 +41,7 @@ func (c *StackCollection) NewTasksToCreateNodeGroups(onlySubset sets.String) *Ta
 		}
 		tasks.Append(&taskWithNodeGroupSpec{
 			info:      fmt.Sprintf("create nodegroup %q", ng.Name),
+			stackName: c.makeNodeGroupStackName(ng.Name),
 			nodeGroup: ng,
 			call:      c.createNodeGroupTask,
 		})
@@ -61,25 +61,30 @@ func (c *StackCollection) NewTasksToDeleteNodeGroups(onlySubset sets.String, wai
 		if onlySubset != nil && !onlySubset.Has(name) {
 			continue
 		}
+		dependencies := []Task{}
 		if *s.StackStatus == cloudformation.StackStatusDeleteFailed && cleanup != nil {
-			tasks.Append(&taskWithNameParam{
+			cleanupTask := &taskWithNameParam{
 				info: fmt.Sprintf("cleanup for nodegroup %q", name),
+				name: name,
 				call: cleanup,
-			})
+			}
+			tasks.Append(cleanupTask)
+			// stack deletion would fail again if it started before cleanup is done
+			dependencies = append(dependencies, cleanupTask)
 		}
 		info := fmt.Sprintf("delete nodegroup %q", name)
 		if wait {
-			tasks.Append(&taskWithStackSpec{
+			tasks.AppendWithDependencies(&taskWithStackSpec{
 				info:  info,
 				stack: s,
 				call:  c.DeleteStackBySpecSync,
-			})
+			}, dependencies...)
 		} else {
-			tasks.Append(&asyncTaskWithStackSpec{
+			tasks.AppendWithDependencies(&asyncTaskWithStackSpec{
 				info:  info,
 				stack: s,
 				call:  c.DeleteStackBySpec,
-			})
+			}, dependencies...)
 		}
 	}
 
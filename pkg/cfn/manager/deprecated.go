@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -35,7 +36,7 @@ func (c *StackCollection) DeleteTasksForDeprecatedStacks() (*TaskTree, error) {
 
 	deleteControlPlaneTask := &taskWithoutParams{
 		info: fmt.Sprintf("delete control plane %q", c.spec.Metadata.Name),
-		call: func(errs chan error) error {
+		call: func(ctx context.Context, errs chan error) error {
 			_, err := c.provider.EKS().DescribeCluster(&eks.DescribeClusterInput{
 				Name: &c.spec.Metadata.Name,
 			})
@@ -68,7 +69,7 @@ func (c *StackCollection) DeleteTasksForDeprecatedStacks() (*TaskTree, error) {
 				},
 			)
 
-			return waiters.Wait(c.spec.Metadata.Name, msg, acceptors, newRequest, c.provider.WaitTimeout(), nil)
+			return waiters.Wait(ctx, c.spec.Metadata.Name, msg, acceptors, newRequest, c.provider.WaitTimeout(), nil)
 		},
 	}
 
@@ -0,0 +1,191 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cfn "github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/kris-nova/logger"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/events"
+)
+
+var (
+	// stackEventsPollInterval is how often stack events are fetched while waiting
+	stackEventsPollInterval = 5 * time.Second
+	// stackEventsClockSkew allows for events that happen just before waiting starts,
+	// e.g. right after a stack creation request
+	stackEventsClockSkew = time.Minute
+
+	// stackEventsWriter is where JSON events are written (stdout if nil), it's
+	// shared by all stacks that are being waited on at the same time
+	stackEventsWriter      io.Writer
+	stackEventsWriterMutex sync.Mutex
+)
+
+// StackEvent describes a stack event as output with --stack-events=json
+type StackEvent struct {
+	Stack              string    `json:"stack"`
+	EventID            string    `json:"eventId"`
+	Timestamp          time.Time `json:"timestamp"`
+	ResourceType       string    `json:"resourceType"`
+	LogicalResourceID  string    `json:"logicalResourceId"`
+	PhysicalResourceID string    `json:"physicalResourceId,omitempty"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	// ElapsedSeconds is how long the resource took to reach the status,
+	// it's only set once the resource is no longer in progress
+	ElapsedSeconds float64 `json:"elapsedSeconds,omitempty"`
+}
+
+// stackEventStreamer outputs new events of a stack, each event is output only once
+type stackEventStreamer struct {
+	stackCollection *StackCollection
+	stack           *Stack
+	output          string
+	since           time.Time
+
+	seen       sets.String
+	inProgress map[string]time.Time
+}
+
+func (c *StackCollection) newStackEventStreamer(i *Stack, output string, since time.Time) *stackEventStreamer {
+	return &stackEventStreamer{
+		stackCollection: c,
+		stack:           i,
+		output:          output,
+		since:           since,
+		seen:            sets.NewString(),
+		inProgress:      make(map[string]time.Time),
+	}
+}
+
+// streamStackEvents outputs events of the stack until ctx is done, the returned
+// channel gets closed once the last events have been output
+func (c *StackCollection) streamStackEvents(ctx context.Context, i *Stack) <-chan struct{} {
+	done := make(chan struct{})
+
+	output := c.provider.StackEventsOutput()
+	if output == "" {
+		output = api.StackEventsOutputLog
+	}
+	if output == api.StackEventsOutputNone && !events.Enabled() {
+		close(done)
+		return done
+	}
+
+	s := c.newStackEventStreamer(i, output, time.Now().Add(-stackEventsClockSkew))
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(stackEventsPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.poll()
+			case <-ctx.Done():
+				// stack has reached the final status, so output the remaining events
+				s.poll()
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// poll fetches and outputs events that haven't been seen yet
+func (s *stackEventStreamer) poll() {
+	input := &cfn.DescribeStackEventsInput{
+		StackName: s.stack.StackName,
+	}
+	if api.IsSetAndNonEmptyString(s.stack.StackId) {
+		input.StackName = s.stack.StackId
+	}
+
+	// events are returned most recent first, so there is no need
+	// to fetch further pages once a known event is found
+	newEvents := []*cfn.StackEvent{}
+	pager := func(p *cfn.DescribeStackEventsOutput, _ bool) bool {
+		for _, e := range p.StackEvents {
+			if s.seen.Has(aws.StringValue(e.EventId)) || aws.TimeValue(e.Timestamp).Before(s.since) {
+				return false
+			}
+			newEvents = append(newEvents, e)
+		}
+		return true
+	}
+	if err := s.stackCollection.provider.CloudFormation().DescribeStackEventsPages(input, pager); err != nil {
+		logger.Debug("ignoring error while fetching events of stack %q: %s", *s.stack.StackName, err.Error())
+		return
+	}
+
+	for i := len(newEvents) - 1; i >= 0; i-- {
+		s.seen.Insert(aws.StringValue(newEvents[i].EventId))
+		s.emit(s.newStackEvent(newEvents[i]))
+	}
+}
+
+func (s *stackEventStreamer) newStackEvent(e *cfn.StackEvent) *StackEvent {
+	event := &StackEvent{
+		Stack:              *s.stack.StackName,
+		EventID:            aws.StringValue(e.EventId),
+		Timestamp:          aws.TimeValue(e.Timestamp),
+		ResourceType:       aws.StringValue(e.ResourceType),
+		LogicalResourceID:  aws.StringValue(e.LogicalResourceId),
+		PhysicalResourceID: aws.StringValue(e.PhysicalResourceId),
+		Status:             aws.StringValue(e.ResourceStatus),
+		Reason:             aws.StringValue(e.ResourceStatusReason),
+	}
+
+	if strings.HasSuffix(event.Status, "_IN_PROGRESS") {
+		if _, ok := s.inProgress[event.LogicalResourceID]; !ok {
+			s.inProgress[event.LogicalResourceID] = event.Timestamp
+		}
+	} else if startTime, ok := s.inProgress[event.LogicalResourceID]; ok {
+		event.ElapsedSeconds = event.Timestamp.Sub(startTime).Seconds()
+		delete(s.inProgress, event.LogicalResourceID)
+	}
+	return event
+}
+
+func (s *stackEventStreamer) emit(event *StackEvent) {
+	events.Emit(events.StackEvent, false, event, "%s: %s/%s: %s", event.Stack, event.ResourceType, event.LogicalResourceID, event.Status)
+
+	switch s.output {
+	case api.StackEventsOutputNone:
+		return
+	case api.StackEventsOutputJSON:
+		stackEventsWriterMutex.Lock()
+		defer stackEventsWriterMutex.Unlock()
+		w := stackEventsWriter
+		if w == nil {
+			w = os.Stdout
+		}
+		if err := json.NewEncoder(w).Encode(event); err != nil {
+			logger.Debug("ignoring error while writing stack event: %s", err.Error())
+		}
+		return
+	}
+
+	msg := fmt.Sprintf("[%s] %s/%s: %s", event.Stack, event.ResourceType, event.LogicalResourceID, event.Status)
+	if event.ElapsedSeconds != 0 {
+		msg = fmt.Sprintf("%s (after %s)", msg, formatDuration(time.Duration(event.ElapsedSeconds*float64(time.Second))))
+	}
+	if event.Reason != "" {
+		msg = fmt.Sprintf("%s – %q", msg, event.Reason)
+	}
+	if strings.HasSuffix(event.Status, "_FAILED") {
+		logger.Warning(msg)
+	} else {
+		logger.Info(msg)
+	}
+}
@@ -0,0 +1,119 @@
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cfn "github.com/aws/aws-sdk-go/service/cloudformation"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
+)
+
+var _ = Describe("StackCollection stack events", func() {
+	var (
+		p        *mockprovider.MockProvider
+		streamer *stackEventStreamer
+		out      *bytes.Buffer
+		pages    [][]*cfn.StackEvent
+
+		startTime = time.Date(2019, 10, 18, 12, 0, 0, 0, time.UTC)
+	)
+
+	newEvent := func(id, logicalID, status string, offset time.Duration) *cfn.StackEvent {
+		return &cfn.StackEvent{
+			EventId:           aws.String(id),
+			Timestamp:         aws.Time(startTime.Add(offset)),
+			ResourceType:      aws.String("AWS::EC2::VPC"),
+			LogicalResourceId: aws.String(logicalID),
+			ResourceStatus:    aws.String(status),
+		}
+	}
+
+	decodeEvents := func() []StackEvent {
+		events := []StackEvent{}
+		decoder := json.NewDecoder(out)
+		for {
+			event := StackEvent{}
+			err := decoder.Decode(&event)
+			if err == io.EOF {
+				return events
+			}
+			Expect(err).NotTo(HaveOccurred())
+			events = append(events, event)
+		}
+	}
+
+	BeforeEach(func() {
+		p = mockprovider.NewMockProvider()
+		cfg := api.NewClusterConfig()
+		cfg.Metadata.Name = "test-cluster"
+		stackManager := NewStackCollection(p, cfg)
+
+		streamer = stackManager.newStackEventStreamer(&Stack{StackName: aws.String("eksctl-test-cluster-cluster")}, api.StackEventsOutputJSON, startTime)
+
+		out = &bytes.Buffer{}
+		stackEventsWriter = out
+
+		pages = nil
+		p.MockCloudFormation().On("DescribeStackEventsPages", mock.Anything, mock.Anything).Return(
+			func(_ *cfn.DescribeStackEventsInput, fn func(*cfn.DescribeStackEventsOutput, bool) bool) error {
+				fn(&cfn.DescribeStackEventsOutput{StackEvents: pages[0]}, true)
+				pages = pages[1:]
+				return nil
+			},
+		)
+	})
+
+	AfterEach(func() {
+		stackEventsWriter = nil
+	})
+
+	It("should output each new event once, along with elapsed time", func() {
+		oldEvent := newEvent("e0", "VPC", cfn.ResourceStatusDeleteComplete, -time.Hour)
+		inProgress := newEvent("e1", "VPC", cfn.ResourceStatusCreateInProgress, time.Second)
+		complete := newEvent("e2", "VPC", cfn.ResourceStatusCreateComplete, 13*time.Second)
+
+		pages = [][]*cfn.StackEvent{
+			{inProgress, oldEvent},
+			{complete, inProgress, oldEvent},
+			{complete, inProgress, oldEvent},
+		}
+
+		streamer.poll()
+		streamer.poll()
+		streamer.poll()
+
+		events := decodeEvents()
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].EventID).To(Equal("e1"))
+		Expect(events[0].Stack).To(Equal("eksctl-test-cluster-cluster"))
+		Expect(events[0].ElapsedSeconds).To(BeZero())
+		Expect(events[1].EventID).To(Equal("e2"))
+		Expect(events[1].Status).To(Equal(cfn.ResourceStatusCreateComplete))
+		Expect(events[1].ElapsedSeconds).To(Equal(12.0))
+	})
+
+	It("should output events in chronological order", func() {
+		pages = [][]*cfn.StackEvent{{
+			newEvent("e3", "Subnet", cfn.ResourceStatusCreateFailed, 3*time.Second),
+			newEvent("e2", "Subnet", cfn.ResourceStatusCreateInProgress, 2*time.Second),
+			newEvent("e1", "VPC", cfn.ResourceStatusCreateInProgress, time.Second),
+		}}
+
+		streamer.poll()
+
+		ids := []string{}
+		for _, event := range decodeEvents() {
+			ids = append(ids, event.EventID)
+		}
+		Expect(strings.Join(ids, ",")).To(Equal("e1,e2,e3"))
+	})
+})
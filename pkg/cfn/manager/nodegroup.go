@@ -1,8 +1,9 @@
 package manager
 
 import (
-	"bytes"
+	"context"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -23,8 +24,13 @@ const (
 	minSizePath         = resourcesRootPath + ".NodeGroup.Properties.MinSize"
 	instanceTypePath    = resourcesRootPath + ".NodeGroupLaunchTemplate.Properties.LaunchTemplateData.InstanceType"
 	imageIDPath         = resourcesRootPath + ".NodeGroupLaunchTemplate.Properties.LaunchTemplateData.ImageId"
+
+	mixedInstancesPolicyPath = resourcesRootPath + ".NodeGroup.Properties.MixedInstancesPolicy"
 )
 
+// amiFamilyDescriptionRegexp matches the AMI family in the description of nodegroup templates
+var amiFamilyDescriptionRegexp = regexp.MustCompile(`\(AMI family: ([^,]+),`)
+
 // NodeGroupSummary represents a summary of a nodegroup stack
 type NodeGroupSummary struct {
 	StackName       string
@@ -35,6 +41,7 @@ type NodeGroupSummary struct {
 	DesiredCapacity int
 	InstanceType    string
 	ImageID         string
+	AMIFamily       string
 	CreationTime    *time.Time
 }
 
@@ -44,7 +51,7 @@ func (c *StackCollection) makeNodeGroupStackName(name string) string {
 }
 
 // createNodeGroupTask creates the nodegroup
-func (c *StackCollection) createNodeGroupTask(errs chan error, ng *api.NodeGroup) error {
+func (c *StackCollection) createNodeGroupTask(ctx context.Context, errs chan error, ng *api.NodeGroup) error {
 	name := c.makeNodeGroupStackName(ng.Name)
 	logger.Info("building nodegroup stack %q", name)
 	stack := builder.NewNodeGroupResourceSet(c.provider, c.spec, c.makeClusterStackName(), ng)
@@ -58,7 +65,7 @@ func (c *StackCollection) createNodeGroupTask(errs chan error, ng *api.NodeGroup
 	ng.Tags[api.NodeGroupNameTag] = ng.Name
 	ng.Tags[api.OldNodeGroupNameTag] = ng.Name
 
-	return c.CreateStack(name, stack, ng.Tags, nil, errs)
+	return c.CreateStack(ctx, name, stack, ng.Tags, nil, errs)
 }
 
 // DescribeNodeGroupStacks calls DescribeStacks and filters out nodegroups
@@ -127,7 +134,75 @@ func (c *StackCollection) DescribeNodeGroupStacksAndResources() (map[string]Stac
 	return allResources, nil
 }
 
-// ScaleNodeGroup will scale an existing nodegroup
+// NodeGroupScaling is the size of the auto scaling group of a nodegroup
+type NodeGroupScaling struct {
+	MinSize         int
+	MaxSize         int
+	DesiredCapacity int
+}
+
+// NewNodeGroupScaling applies the sizes that are set in ng to the current scaling
+// and validates the result. When only the desired capacity is set, min and max size
+// are extended to include it, otherwise the desired capacity is kept within them.
+func NewNodeGroupScaling(current NodeGroupScaling, ng *api.NodeGroup) (NodeGroupScaling, error) {
+	scaling := current
+	if ng.MinSize != nil {
+		scaling.MinSize = *ng.MinSize
+	}
+	if ng.MaxSize != nil {
+		scaling.MaxSize = *ng.MaxSize
+	}
+
+	if ng.DesiredCapacity != nil {
+		scaling.DesiredCapacity = *ng.DesiredCapacity
+		if ng.MinSize == nil && scaling.DesiredCapacity < scaling.MinSize {
+			scaling.MinSize = scaling.DesiredCapacity
+		}
+		if ng.MaxSize == nil && scaling.DesiredCapacity > scaling.MaxSize {
+			scaling.MaxSize = scaling.DesiredCapacity
+		}
+	} else {
+		if scaling.DesiredCapacity < scaling.MinSize {
+			scaling.DesiredCapacity = scaling.MinSize
+		}
+		if scaling.DesiredCapacity > scaling.MaxSize {
+			scaling.DesiredCapacity = scaling.MaxSize
+		}
+	}
+
+	if scaling.MinSize < 0 || scaling.MaxSize < 0 || scaling.DesiredCapacity < 0 {
+		return current, fmt.Errorf("number of nodes must be 0 or greater")
+	}
+	if scaling.MinSize > scaling.MaxSize {
+		return current, fmt.Errorf("min size (%d) cannot be greater than max size (%d)", scaling.MinSize, scaling.MaxSize)
+	}
+	if scaling.DesiredCapacity < scaling.MinSize || scaling.DesiredCapacity > scaling.MaxSize {
+		return current, fmt.Errorf("desired capacity (%d) must be between min size (%d) and max size (%d)", scaling.DesiredCapacity, scaling.MinSize, scaling.MaxSize)
+	}
+	return scaling, nil
+}
+
+func getNodeGroupScaling(template string) NodeGroupScaling {
+	return NodeGroupScaling{
+		MinSize:         int(gjson.Get(template, minSizePath).Int()),
+		MaxSize:         int(gjson.Get(template, maxSizePath).Int()),
+		DesiredCapacity: int(gjson.Get(template, desiredCapacityPath).Int()),
+	}
+}
+
+// GetNodeGroupScaling returns the current scaling of a nodegroup, according to its stack template
+func (c *StackCollection) GetNodeGroupScaling(ng *api.NodeGroup) (NodeGroupScaling, error) {
+	name := c.makeNodeGroupStackName(ng.Name)
+	template, err := c.GetStackTemplate(name)
+	if err != nil {
+		return NodeGroupScaling{}, errors.Wrapf(err, "error getting stack template %s", name)
+	}
+	return getNodeGroupScaling(template), nil
+}
+
+// ScaleNodeGroup will scale an existing nodegroup, by changing the min size, max size
+// and desired capacity in the stack template, so that the template and the auto scaling
+// group stay consistent
 func (c *StackCollection) ScaleNodeGroup(ng *api.NodeGroup) error {
 	clusterName := c.makeClusterStackName()
 	c.spec.Status = &api.ClusterStatus{StackName: clusterName}
@@ -141,51 +216,122 @@ func (c *StackCollection) ScaleNodeGroup(ng *api.NodeGroup) error {
 	}
 	logger.Debug("stack template (pre-scale change): %s", template)
 
+	current := getNodeGroupScaling(template)
+	scaling, err := NewNodeGroupScaling(current, ng)
+	if err != nil {
+		return errors.Wrapf(err, "scaling nodegroup %q", ng.Name)
+	}
+
+	if scaling == current {
+		logger.Info("nodegroup %q in cluster %q already has min size %d, max size %d and desired capacity %d",
+			ng.Name, clusterName, current.MinSize, current.MaxSize, current.DesiredCapacity)
+		return nil
+	}
+
 	//TODO: In the future we might want to use Goformation for strongly typed
 	//manipulation of the template.
 
-	var descriptionBuffer bytes.Buffer
-	descriptionBuffer.WriteString("scaling nodegroup, ")
+	changes := []string{}
+	for _, value := range []struct {
+		path, name string
+		from, to   int
+	}{
+		{minSizePath, "min size", current.MinSize, scaling.MinSize},
+		{maxSizePath, "max size", current.MaxSize, scaling.MaxSize},
+		{desiredCapacityPath, "desired capacity", current.DesiredCapacity, scaling.DesiredCapacity},
+	} {
+		if value.from == value.to {
+			continue
+		}
+		template, err = sjson.Set(template, value.path, fmt.Sprintf("%d", value.to))
+		if err != nil {
+			return errors.Wrapf(err, "setting %s", value.name)
+		}
+		changes = append(changes, fmt.Sprintf("%s from %d to %d", value.name, value.from, value.to))
+	}
+	logger.Debug("stack template (post-scale change): %s", template)
 
-	// Get the current values
-	currentCapacity := gjson.Get(template, desiredCapacityPath)
-	currentMaxSize := gjson.Get(template, maxSizePath)
-	currentMinSize := gjson.Get(template, minSizePath)
+	description := "scaling nodegroup, " + strings.Join(changes, ", ")
+	return c.UpdateStack(name, c.MakeChangeSetName("scale-nodegroup"), description, []byte(template), nil)
+}
 
-	if ng.DesiredCapacity != nil && int64(*ng.DesiredCapacity) == currentCapacity.Int() {
-		logger.Info("desired capacity of nodegroup %q in cluster %q is already %d", ng.Name, clusterName, *ng.DesiredCapacity)
-		return nil
+// getAMIFamily returns the AMI family of a nodegroup, according to the description of
+// its template, or an empty string if the template doesn't record it
+func getAMIFamily(template string) string {
+	match := amiFamilyDescriptionRegexp.FindStringSubmatch(gjson.Get(template, "Description").String())
+	if match == nil {
+		return ""
 	}
+	return match[1]
+}
+
+// UpdateNodeGroupAMI will change the image of the nodegroup launch template, which
+// is only used by new instances, it returns whether the image had to be changed
+func (c *StackCollection) UpdateNodeGroupAMI(ng *api.NodeGroup, imageID string, plan bool) (bool, error) {
+	name := c.makeNodeGroupStackName(ng.Name)
 
-	// Set the new values
-	newCapacity := fmt.Sprintf("%d", *ng.DesiredCapacity)
-	template, err = sjson.Set(template, desiredCapacityPath, newCapacity)
+	template, err := c.GetStackTemplate(name)
 	if err != nil {
-		return errors.Wrap(err, "setting desired capacity")
+		return false, errors.Wrapf(err, "error getting stack template %s", name)
 	}
-	descriptionBuffer.WriteString(fmt.Sprintf("desired capacity from %s to %d", currentCapacity.Str, *ng.DesiredCapacity))
 
-	// If the desired number of nodes is less than the min then update the min
-	if int64(*ng.DesiredCapacity) < currentMinSize.Int() {
-		newMinSize := fmt.Sprintf("%d", *ng.DesiredCapacity)
-		template, err = sjson.Set(template, minSizePath, newMinSize)
-		if err != nil {
-			return errors.Wrap(err, "setting min size")
-		}
-		descriptionBuffer.WriteString(fmt.Sprintf(", min size from %s to %d", currentMinSize.Str, *ng.DesiredCapacity))
+	currentImageID := gjson.Get(template, imageIDPath).String()
+	if currentImageID == imageID {
+		logger.Info("nodegroup %q already uses AMI %q", ng.Name, imageID)
+		return false, nil
+	}
+
+	if plan {
+		logger.Info("(plan) would update AMI of nodegroup %q from %q to %q", ng.Name, currentImageID, imageID)
+		return true, nil
+	}
+
+	template, err = sjson.Set(template, imageIDPath, imageID)
+	if err != nil {
+		return false, errors.Wrap(err, "setting image ID")
+	}
+
+	description := fmt.Sprintf("updating nodegroup AMI from %s to %s", currentImageID, imageID)
+	if err := c.UpdateStack(name, c.MakeChangeSetName("update-nodegroup-ami"), description, []byte(template), nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UpdateNodeGroupSecurityGroupRules will update user-defined rules of the local security group
+// in an existing nodegroup stack, and its SSH rules when updateSSH is true; the default rule
+// allowing all egress is revoked when there are egress rules
+func (c *StackCollection) UpdateNodeGroupSecurityGroupRules(ng *api.NodeGroup, updateSSH, plan bool) (bool, error) {
+	name := c.makeNodeGroupStackName(ng.Name)
+
+	currentTemplate, err := c.GetStackTemplate(name)
+	if err != nil {
+		return false, errors.Wrapf(err, "error getting stack template %s", name)
 	}
-	// If the desired number of nodes is greater than the max then update the max
-	if int64(*ng.DesiredCapacity) > currentMaxSize.Int() {
-		newMaxSize := fmt.Sprintf("%d", *ng.DesiredCapacity)
-		template, err = sjson.Set(template, maxSizePath, newMaxSize)
+
+	var changed []string
+	if gjson.Get(currentTemplate, resourcesRootPath+".SG").Exists() {
+		var egressChanged bool
+		currentTemplate, egressChanged, err = restrictTemplateEgress(currentTemplate, "SG", len(ng.SecurityGroups.EgressRules) > 0)
 		if err != nil {
-			return errors.Wrap(err, "setting max size")
+			return false, err
+		}
+		if egressChanged {
+			changed = append(changed, "SG")
 		}
-		descriptionBuffer.WriteString(fmt.Sprintf(", max size from %s to %d", currentMaxSize.Str, *ng.DesiredCapacity))
 	}
-	logger.Debug("stack template (post-scale change): %s", template)
 
-	return c.UpdateStack(name, c.MakeChangeSetName("scale-nodegroup"), descriptionBuffer.String(), []byte(template), nil)
+	newStack := builder.NewNodeGroupResourceSet(c.provider, c.spec, c.makeClusterStackName(), ng)
+	newStack.AddSecurityGroupRuleResources()
+
+	match := builder.IsNodeGroupSecurityGroupRule
+	if !updateSSH {
+		// the existing SSH rules are kept
+		match = func(name string) bool {
+			return builder.IsNodeGroupSecurityGroupRule(name) && !builder.IsNodeGroupSSHRule(name)
+		}
+	}
+	return c.updateStackResources(name, "update-nodegroup-security-groups", currentTemplate, newStack, match, changed, plan)
 }
 
 // GetNodeGroupSummaries returns a list of summaries for the nodegroups of a cluster
@@ -235,12 +381,50 @@ func (c *StackCollection) mapStackToNodeGroupSummary(stack *Stack) (*NodeGroupSu
 		DesiredCapacity: int(desired.Int()),
 		InstanceType:    instanceType.String(),
 		ImageID:         imageID.String(),
+		AMIFamily:       getAMIFamily(template),
 		CreationTime:    stack.CreationTime,
 	}
 
 	return summary, nil
 }
 
+// GetNodeGroupInstancesDistribution returns the instances distribution of a nodegroup stack,
+// or nil if the nodegroup doesn't use mixed instances
+func (c *StackCollection) GetNodeGroupInstancesDistribution(stackName string) (*api.NodeGroupInstancesDistribution, error) {
+	template, err := c.GetStackTemplate(stackName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting Cloudformation template for stack %s", stackName)
+	}
+
+	policy := gjson.Get(template, mixedInstancesPolicyPath)
+	if !policy.Exists() {
+		return nil, nil
+	}
+
+	distribution := &api.NodeGroupInstancesDistribution{}
+	for _, override := range policy.Get("LaunchTemplate.Overrides").Array() {
+		distribution.InstanceTypes = append(distribution.InstanceTypes, override.Get("InstanceType").String())
+	}
+	// all values are rendered as strings by the template builder
+	if v := policy.Get("InstancesDistribution.SpotMaxPrice"); v.Exists() {
+		maxPrice := v.Float()
+		distribution.MaxPrice = &maxPrice
+	}
+	if v := policy.Get("InstancesDistribution.OnDemandBaseCapacity"); v.Exists() {
+		baseCapacity := int(v.Int())
+		distribution.OnDemandBaseCapacity = &baseCapacity
+	}
+	if v := policy.Get("InstancesDistribution.OnDemandPercentageAboveBaseCapacity"); v.Exists() {
+		percentage := int(v.Int())
+		distribution.OnDemandPercentageAboveBaseCapacity = &percentage
+	}
+	if v := policy.Get("InstancesDistribution.SpotInstancePools"); v.Exists() {
+		pools := int(v.Int())
+		distribution.SpotInstancePools = &pools
+	}
+	return distribution, nil
+}
+
 // GetNodeGroupName will return nodegroup name based on tags
 func (*StackCollection) GetNodeGroupName(s *Stack) string {
 	for _, tag := range s.Tags {
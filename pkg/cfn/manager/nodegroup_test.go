@@ -85,6 +85,94 @@ var _ = Describe("StackCollection NodeGroup", func() {
 		})
 	})
 
+	Describe("UpdateNodeGroupAMI", func() {
+		var ng *api.NodeGroup
+
+		BeforeEach(func() {
+			p = mockprovider.NewMockProvider()
+			cc = newClusterConfig("test-cluster")
+			ng = newNodeGroup(cc)
+			ng.Name = "12345"
+			sc = NewStackCollection(p, cc)
+
+			p.MockCloudFormation().On("GetTemplate", mock.MatchedBy(func(input *cfn.GetTemplateInput) bool {
+				return input.StackName != nil && *input.StackName == "eksctl-test-cluster-nodegroup-12345"
+			})).Return(&cfn.GetTemplateOutput{
+				TemplateBody: aws.String(`{
+					"Resources": {
+						"NodeGroupLaunchTemplate": {
+							"Properties": {
+								"LaunchTemplateData": {
+									"ImageId": "ami-1"
+								}
+							}
+						}
+					}
+				}`),
+			}, nil)
+		})
+
+		It("should be a no-op if the nodegroup already uses the AMI", func() {
+			updated, err := sc.UpdateNodeGroupAMI(ng, "ami-1", false)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).To(BeFalse())
+			Expect(p.MockCloudFormation().AssertNotCalled(GinkgoT(), "CreateChangeSet", mock.Anything)).To(BeTrue())
+		})
+
+		It("should only report the change in plan mode", func() {
+			updated, err := sc.UpdateNodeGroupAMI(ng, "ami-2", true)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).To(BeTrue())
+			Expect(p.MockCloudFormation().AssertNotCalled(GinkgoT(), "CreateChangeSet", mock.Anything)).To(BeTrue())
+		})
+	})
+
+	Describe("getAMIFamily", func() {
+		It("reads the AMI family from the template description", func() {
+			template := `{"Description": "EKS nodes (AMI family: Ubuntu1804, SSH access: false, private networking: false) [created and managed by eksctl]"}`
+			Expect(getAMIFamily(template)).To(Equal("Ubuntu1804"))
+		})
+
+		It("returns an empty string for templates without a description", func() {
+			Expect(getAMIFamily(`{}`)).To(BeEmpty())
+		})
+	})
+
+	Describe("NewNodeGroupScaling", func() {
+		current := NodeGroupScaling{MinSize: 1, MaxSize: 3, DesiredCapacity: 2}
+
+		scale := func(minSize, maxSize, desiredCapacity *int) (NodeGroupScaling, error) {
+			ng := api.NewNodeGroup()
+			ng.MinSize = minSize
+			ng.MaxSize = maxSize
+			ng.DesiredCapacity = desiredCapacity
+			return NewNodeGroupScaling(current, ng)
+		}
+
+		It("extends min and max size when only the desired capacity is set", func() {
+			Expect(scale(nil, nil, aws.Int(0))).To(Equal(NodeGroupScaling{MinSize: 0, MaxSize: 3, DesiredCapacity: 0}))
+			Expect(scale(nil, nil, aws.Int(5))).To(Equal(NodeGroupScaling{MinSize: 1, MaxSize: 5, DesiredCapacity: 5}))
+		})
+
+		It("keeps the desired capacity within new min and max size", func() {
+			Expect(scale(aws.Int(3), aws.Int(6), nil)).To(Equal(NodeGroupScaling{MinSize: 3, MaxSize: 6, DesiredCapacity: 3}))
+			Expect(scale(aws.Int(0), aws.Int(0), nil)).To(Equal(NodeGroupScaling{MinSize: 0, MaxSize: 0, DesiredCapacity: 0}))
+		})
+
+		It("rejects inconsistent sizes", func() {
+			_, err := scale(aws.Int(4), aws.Int(2), nil)
+			Expect(err).To(MatchError("min size (4) cannot be greater than max size (2)"))
+
+			_, err = scale(aws.Int(1), aws.Int(2), aws.Int(3))
+			Expect(err).To(MatchError("desired capacity (3) must be between min size (1) and max size (2)"))
+
+			_, err = scale(nil, nil, aws.Int(-1))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Describe("GetNodeGroupSummaries", func() {
 		Context("With a cluster name", func() {
 			var (
@@ -1,27 +1,112 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/kris-nova/logger"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/events"
 )
 
 // Task is a common interface for the stack manager tasks
 type Task interface {
 	Describe() string
-	Do(chan error) error
+	Do(context.Context, chan error) error
 }
 
-// TaskTree wraps a set of tasks
+// TaskState describes the state of a task
+type TaskState string
+
+// Possible states of a task
+const (
+	TaskPending   TaskState = "pending"
+	TaskRunning   TaskState = "running"
+	TaskDone      TaskState = "done"
+	TaskFailed    TaskState = "failed"
+	TaskSkipped   TaskState = "skipped"
+	TaskCancelled TaskState = "cancelled"
+)
+
+// TaskResult holds the outcome of a single task
+type TaskResult struct {
+	Description string
+	State       TaskState
+	StackName   string
+	Err         error
+	StartTime   time.Time
+	EndTime     time.Time
+}
+
+// Duration returns how long the task took to run
+func (r *TaskResult) Duration() time.Duration {
+	if r.StartTime.IsZero() || r.EndTime.IsZero() {
+		return 0
+	}
+	return r.EndTime.Sub(r.StartTime)
+}
+
+// String describes the outcome of the task
+func (r *TaskResult) String() string {
+	if r.StartTime.IsZero() {
+		return string(r.State)
+	}
+	return fmt.Sprintf("%s in %s", r.State, formatDuration(r.Duration()))
+}
+
+// TaskResults holds results of all tasks in the order of completion
+type TaskResults []*TaskResult
+
+// Errors returns errors of all tasks that failed or were cancelled while running
+func (r TaskResults) Errors() []error {
+	errs := []error{}
+	for _, result := range r {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return errs
+}
+
+// TaskRecorder gets notified every time state of a task changes, it's called for each of
+// the tasks with TaskPending state before any of the tasks run; the result must not be
+// retained, as it's a copy that will not get updated
+type TaskRecorder interface {
+	RecordTask(*TaskResult)
+}
+
+// stackTask is implemented by tasks that operate on a single stack
+type stackTask interface {
+	StackName() string
+}
+
+// TaskTree wraps a set of tasks; tasks in a sequential tree run one after another,
+// tasks in a parallel tree run at the same time, unless they have dependencies
 type TaskTree struct {
-	tasks     []Task
+	tasks        []Task
+	dependencies map[Task][]Task
+
+	results      map[Task]*TaskResult
+	resultsMutex sync.Mutex
+
 	Parallel  bool
 	PlanMode  bool
 	IsSubTask bool
+
+	// MaxParallel limits how many tasks of this tree (including all sub-tasks)
+	// can run at the same time, zero means there is no limit
+	MaxParallel int
+
+	// Recorder, if set, is notified about progress of all tasks of this tree (including all sub-tasks)
+	Recorder TaskRecorder
+	// CompletedTasks holds descriptions of tasks that were completed by a previous run,
+	// these tasks are marked as done without running
+	CompletedTasks sets.String
 }
 
 // Append new tasks to the set
@@ -29,6 +114,31 @@ func (t *TaskTree) Append(newTasks ...Task) {
 	t.tasks = append(t.tasks, newTasks...)
 }
 
+// AppendWithDependencies appends a task that will only run once all of the given
+// tasks have completed successfully; dependencies must have already been appended
+// to the same set, which also ensures there are no cycles
+func (t *TaskTree) AppendWithDependencies(task Task, dependencies ...Task) {
+	for _, dependency := range dependencies {
+		if !t.has(dependency) {
+			panic(fmt.Sprintf("dependency %q of task %q is not in the set", dependency.Describe(), task.Describe()))
+		}
+	}
+	if t.dependencies == nil {
+		t.dependencies = make(map[Task][]Task)
+	}
+	t.dependencies[task] = append(t.dependencies[task], dependencies...)
+	t.Append(task)
+}
+
+func (t *TaskTree) has(task Task) bool {
+	for _, existingTask := range t.tasks {
+		if existingTask == task {
+			return true
+		}
+	}
+	return false
+}
+
 // Len returns number of tasks in the set
 func (t *TaskTree) Len() int {
 	if t == nil {
@@ -37,11 +147,17 @@ func (t *TaskTree) Len() int {
 	return len(t.tasks)
 }
 
-// Describe the set
+// Describe the set, once the tasks have run, outcome and timing of each task is included
 func (t *TaskTree) Describe() string {
 	descriptions := []string{}
 	for _, task := range t.tasks {
-		descriptions = append(descriptions, task.Describe())
+		description := task.Describe()
+		if _, isTree := task.(*TaskTree); !isTree {
+			if result := t.getResult(task); result != nil {
+				description = fmt.Sprintf("%s [%s]", description, result)
+			}
+		}
+		descriptions = append(descriptions, description)
 	}
 	mode := "sequential"
 	if t.Parallel {
@@ -74,24 +190,16 @@ func (t *TaskTree) Describe() string {
 // Do will run through the set in the backround, it may return an error immediately,
 // or eventually write to the errs channel; it will close the channel once all tasks
 // are completed
-func (t *TaskTree) Do(allErrs chan error) error {
+func (t *TaskTree) Do(ctx context.Context, allErrs chan error) error {
 	if t.Len() == 0 || t.PlanMode {
 		logger.Debug("no actual tasks")
 		close(allErrs)
 		return nil
 	}
 
-	errs := make(chan error)
-
-	if t.Parallel {
-		go doParallelTasks(errs, t.tasks)
-	} else {
-		go doSequentialTasks(errs, t.tasks)
-	}
-
 	go func() {
 		defer close(allErrs)
-		for err := range errs {
+		for _, err := range t.DoAllSync(ctx).Errors() {
 			allErrs <- err
 		}
 	}()
@@ -99,36 +207,281 @@ func (t *TaskTree) Do(allErrs chan error) error {
 	return nil
 }
 
-// DoAllSync will run through the set in the foregounds and return all the errors
-// in a slice
-func (t *TaskTree) DoAllSync() []error {
+// DoAllSync will run through the set in the foreground and return results of all the tasks;
+// once ctx is cancelled, tasks that haven't started yet will not run and running tasks are
+// expected to stop
+func (t *TaskTree) DoAllSync(ctx context.Context) TaskResults {
 	if t.Len() == 0 || t.PlanMode {
 		logger.Debug("no actual tasks")
 		return nil
 	}
 
-	errs := make(chan error)
+	collector := &taskResultCollector{
+		recorder:  t.Recorder,
+		completed: t.CompletedTasks,
+	}
+	t.recordPending(collector)
+	t.run(ctx, nil, collector)
+	return collector.results
+}
+
+// recordPending notifies the recorder about all tasks before any of them run
+func (t *TaskTree) recordPending(collector *taskResultCollector) {
+	for _, task := range t.tasks {
+		if subTree, ok := task.(*TaskTree); ok {
+			subTree.recordPending(collector)
+			continue
+		}
+		collector.record(newTaskResult(task, TaskPending))
+	}
+}
+
+type taskResultCollector struct {
+	results TaskResults
+	mutex   sync.Mutex
+
+	recorder  TaskRecorder
+	completed sets.String
+}
+
+func (c *taskResultCollector) add(result *TaskResult) {
+	c.mutex.Lock()
+	c.results = append(c.results, result)
+	c.mutex.Unlock()
+	c.record(result)
+}
+
+func (c *taskResultCollector) record(result *TaskResult) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	snapshot := *result
+	if c.recorder != nil {
+		c.recorder.RecordTask(&snapshot)
+	}
+	if snapshot.State != TaskPending {
+		events.Emit(events.Task, false, newTaskEvent(&snapshot), "%s: %s", snapshot.Description, snapshot.State)
+	}
+}
+
+// taskEvent describes a task that has started or finished as a structured event
+type taskEvent struct {
+	Description     string    `json:"description"`
+	State           TaskState `json:"state"`
+	StackName       string    `json:"stackName,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	DurationSeconds float64   `json:"durationSeconds,omitempty"`
+}
+
+func newTaskEvent(result *TaskResult) *taskEvent {
+	event := &taskEvent{
+		Description:     result.Description,
+		State:           result.State,
+		StackName:       result.StackName,
+		DurationSeconds: result.Duration().Seconds(),
+	}
+	if result.Err != nil {
+		event.Error = result.Err.Error()
+	}
+	return event
+}
+
+func (c *taskResultCollector) isCompleted(task Task) bool {
+	return c.completed != nil && c.completed.Has(task.Describe())
+}
+
+func newTaskResult(task Task, state TaskState) *TaskResult {
+	result := &TaskResult{
+		Description: task.Describe(),
+		State:       state,
+	}
+	if s, ok := task.(stackTask); ok {
+		result.StackName = s.StackName()
+	}
+	return result
+}
+
+// taskLimiter bounds the number of concurrently running tasks
+type taskLimiter chan struct{}
 
+func (l taskLimiter) acquire(ctx context.Context) bool {
+	select {
+	case l <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (l taskLimiter) release() { <-l }
+
+func (t *TaskTree) getResult(task Task) *TaskResult {
+	t.resultsMutex.Lock()
+	defer t.resultsMutex.Unlock()
+	return t.results[task]
+}
+
+func (t *TaskTree) setResult(task Task, result *TaskResult) {
+	t.resultsMutex.Lock()
+	defer t.resultsMutex.Unlock()
+	if t.results == nil {
+		t.results = make(map[Task]*TaskResult)
+	}
+	t.results[task] = result
+}
+
+// dependenciesOf returns explicit dependencies of a task, as well as the preceding
+// task when the set is sequential
+func (t *TaskTree) dependenciesOf(i int) []Task {
+	dependencies := t.dependencies[t.tasks[i]]
+	if !t.Parallel && i > 0 {
+		dependencies = append([]Task{t.tasks[i-1]}, dependencies...)
+	}
+	return dependencies
+}
+
+// run executes all tasks once their dependencies have completed, it returns
+// true if all of the tasks were successful
+func (t *TaskTree) run(ctx context.Context, limiters []taskLimiter, collector *taskResultCollector) bool {
+	if t.MaxParallel > 0 {
+		// copy, so that limiters of sibling sets are kept separate
+		limiters = append(append([]taskLimiter{}, limiters...), make(taskLimiter, t.MaxParallel))
+	}
+
+	completed := make(map[Task]chan struct{}, len(t.tasks))
+	for _, task := range t.tasks {
+		completed[task] = make(chan struct{})
+	}
+
+	mode := "sequential"
 	if t.Parallel {
-		go doParallelTasks(errs, t.tasks)
+		mode = "parallel"
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(len(t.tasks))
+	for i := range t.tasks {
+		go func(i int) {
+			defer wg.Done()
+			task := t.tasks[i]
+			defer close(completed[task])
+
+			for _, dependency := range t.dependenciesOf(i) {
+				<-completed[dependency]
+				if result := t.getResult(dependency); result == nil || result.State != TaskDone {
+					logger.Debug("skipping task: %s (as %q has not completed successfully)", task.Describe(), dependency.Describe())
+					t.skip(task, TaskSkipped, collector)
+					return
+				}
+			}
+
+			if ctx.Err() != nil {
+				t.skip(task, TaskCancelled, collector)
+				return
+			}
+
+			if ok := t.runTask(ctx, task, limiters, collector); !ok {
+				if t.Parallel {
+					logger.Debug("failed task: %s (will continue until other parallel tasks are completed)", task.Describe())
+				} else {
+					logger.Debug("failed task: %s (will not run other sequential tasks)", task.Describe())
+				}
+			}
+		}(i)
+	}
+	logger.Debug("waiting for %d %s tasks to complete", len(t.tasks), mode)
+	wg.Wait()
+
+	for _, task := range t.tasks {
+		if result := t.getResult(task); result == nil || result.State != TaskDone {
+			return false
+		}
+	}
+	return true
+}
+
+// skip marks a task that didn't run, including all of its sub-tasks
+func (t *TaskTree) skip(task Task, state TaskState, collector *taskResultCollector) {
+	result := newTaskResult(task, state)
+	if subTree, ok := task.(*TaskTree); ok {
+		for _, subTask := range subTree.tasks {
+			subTree.skip(subTask, state, collector)
+		}
 	} else {
-		go doSequentialTasks(errs, t.tasks)
+		collector.add(result)
+	}
+	t.setResult(task, result)
+}
+
+func (t *TaskTree) runTask(ctx context.Context, task Task, limiters []taskLimiter, collector *taskResultCollector) bool {
+	result := newTaskResult(task, TaskRunning)
+
+	if subTree, ok := task.(*TaskTree); ok {
+		result.StartTime = time.Now()
+		if subTree.PlanMode || subTree.run(ctx, limiters, collector) {
+			result.State = TaskDone
+		} else {
+			result.State = TaskFailed
+		}
+		result.EndTime = time.Now()
+		t.setResult(task, result)
+		return result.State == TaskDone
 	}
 
-	allErrs := []error{}
-	for err := range errs {
-		allErrs = append(allErrs, err)
+	if collector.isCompleted(task) {
+		logger.Debug("skipping task: %s (completed by a previous run)", task.Describe())
+		result.State = TaskDone
+		t.setResult(task, result)
+		collector.add(result)
+		return true
+	}
+
+	for i, limiter := range limiters {
+		if !limiter.acquire(ctx) {
+			for _, acquired := range limiters[:i] {
+				acquired.release()
+			}
+			t.skip(task, TaskCancelled, collector)
+			return false
+		}
+	}
+	defer func() {
+		for _, limiter := range limiters {
+			limiter.release()
+		}
+	}()
+
+	result.StartTime = time.Now()
+	collector.record(result)
+	err := doSingleTask(ctx, task)
+	result.EndTime = time.Now()
+
+	switch {
+	case err == nil:
+		result.State = TaskDone
+	case ctx.Err() != nil:
+		result.State = TaskCancelled
+		result.Err = err
+	default:
+		result.State = TaskFailed
+		result.Err = err
 	}
-	return allErrs
+	t.setResult(task, result)
+	collector.add(result)
+
+	return result.State == TaskDone
 }
 
 type taskWithoutParams struct {
-	info string
-	call func(chan error) error
+	info      string
+	stackName string
+	call      func(context.Context, chan error) error
 }
 
-func (t *taskWithoutParams) Describe() string         { return t.info }
-func (t *taskWithoutParams) Do(errs chan error) error { return t.call(errs) }
+func (t *taskWithoutParams) Describe() string  { return t.info }
+func (t *taskWithoutParams) StackName() string { return t.stackName }
+func (t *taskWithoutParams) Do(ctx context.Context, errs chan error) error {
+	return t.call(ctx, errs)
+}
 
 type taskWithNameParam struct {
 	info string
@@ -136,29 +489,34 @@ type taskWithNameParam struct {
 	call func(chan error, string) error
 }
 
-func (t *taskWithNameParam) Describe() string         { return t.info }
-func (t *taskWithNameParam) Do(errs chan error) error { return t.call(errs, t.name) }
+func (t *taskWithNameParam) Describe() string { return t.info }
+func (t *taskWithNameParam) Do(_ context.Context, errs chan error) error {
+	return t.call(errs, t.name)
+}
 
 type taskWithNodeGroupSpec struct {
 	info      string
+	stackName string
 	nodeGroup *api.NodeGroup
-	call      func(chan error, *api.NodeGroup) error
+	call      func(context.Context, chan error, *api.NodeGroup) error
 }
 
-func (t *taskWithNodeGroupSpec) Describe() string { return t.info }
-func (t *taskWithNodeGroupSpec) Do(errs chan error) error {
-	return t.call(errs, t.nodeGroup)
+func (t *taskWithNodeGroupSpec) Describe() string  { return t.info }
+func (t *taskWithNodeGroupSpec) StackName() string { return t.stackName }
+func (t *taskWithNodeGroupSpec) Do(ctx context.Context, errs chan error) error {
+	return t.call(ctx, errs, t.nodeGroup)
 }
 
 type taskWithStackSpec struct {
 	info  string
 	stack *Stack
-	call  func(*Stack, chan error) error
+	call  func(context.Context, *Stack, chan error) error
 }
 
-func (t *taskWithStackSpec) Describe() string { return t.info }
-func (t *taskWithStackSpec) Do(errs chan error) error {
-	return t.call(t.stack, errs)
+func (t *taskWithStackSpec) Describe() string  { return t.info }
+func (t *taskWithStackSpec) StackName() string { return *t.stack.StackName }
+func (t *taskWithStackSpec) Do(ctx context.Context, errs chan error) error {
+	return t.call(ctx, t.stack, errs)
 }
 
 type asyncTaskWithStackSpec struct {
@@ -167,51 +525,31 @@ type asyncTaskWithStackSpec struct {
 	call  func(*Stack) (*Stack, error)
 }
 
-func (t *asyncTaskWithStackSpec) Describe() string { return t.info + " [async]" }
-func (t *asyncTaskWithStackSpec) Do(errs chan error) error {
+func (t *asyncTaskWithStackSpec) Describe() string  { return t.info + " [async]" }
+func (t *asyncTaskWithStackSpec) StackName() string { return *t.stack.StackName }
+func (t *asyncTaskWithStackSpec) Do(_ context.Context, errs chan error) error {
 	_, err := t.call(t.stack)
 	close(errs)
 	return err
 }
 
-func doSingleTask(allErrs chan error, task Task) bool {
+func doSingleTask(ctx context.Context, task Task) error {
 	desc := task.Describe()
 	logger.Debug("started task: %s", desc)
 	errs := make(chan error)
-	if err := task.Do(errs); err != nil {
-		allErrs <- err
-		return false
+	if err := task.Do(ctx, errs); err != nil {
+		return err
 	}
 	if err := <-errs; err != nil {
-		allErrs <- err
-		return false
+		return err
 	}
 	logger.Debug("completed task: %s", desc)
-	return true
-}
-
-func doParallelTasks(allErrs chan error, tasks []Task) {
-	wg := &sync.WaitGroup{}
-	wg.Add(len(tasks))
-	for t := range tasks {
-		go func(t int) {
-			defer wg.Done()
-			if ok := doSingleTask(allErrs, tasks[t]); !ok {
-				logger.Debug("failed task: %s (will continue until other parallel tasks are completed)", tasks[t].Describe())
-			}
-		}(t)
-	}
-	logger.Debug("waiting for %d parallel tasks to complete", len(tasks))
-	wg.Wait()
-	close(allErrs)
+	return nil
 }
 
-func doSequentialTasks(allErrs chan error, tasks []Task) {
-	for t := range tasks {
-		if ok := doSingleTask(allErrs, tasks[t]); !ok {
-			logger.Debug("failed task: %s (will not run other sequential tasks)", tasks[t].Describe())
-			break
-		}
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
 	}
-	close(allErrs)
+	return d.Round(time.Second).String()
 }
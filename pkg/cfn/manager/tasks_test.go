@@ -1,11 +1,13 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
@@ -113,7 +115,7 @@ var _ = Describe("StackCollection Tasks", func() {
 					subTask1 := &TaskTree{Parallel: false, IsSubTask: true}
 					subTask1.Append(&taskWithoutParams{
 						info: "t1.1",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							updateStatus("started t1.1")
 							go func() {
 								time.Sleep(100 * time.Millisecond)
@@ -129,7 +131,7 @@ var _ = Describe("StackCollection Tasks", func() {
 					subTask2 := &TaskTree{Parallel: false, IsSubTask: true}
 					subTask2.Append(&taskWithoutParams{
 						info: "t2.1",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								errs <- fmt.Errorf("never happens")
 								close(errs)
@@ -142,7 +144,7 @@ var _ = Describe("StackCollection Tasks", func() {
 					subTask3 := &TaskTree{Parallel: true, IsSubTask: true}
 					subTask3.Append(&taskWithoutParams{
 						info: "t3.1",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							updateStatus("started t3.1")
 							go func() {
 								time.Sleep(200 * time.Millisecond)
@@ -155,7 +157,7 @@ var _ = Describe("StackCollection Tasks", func() {
 					})
 					subTask3.Append(&taskWithoutParams{
 						info: "t3.2",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							updateStatus("started t3.2")
 							go func() {
 								time.Sleep(350 * time.Millisecond)
@@ -171,7 +173,7 @@ var _ = Describe("StackCollection Tasks", func() {
 					Expect(tasks.Describe()).To(Equal("2 sequential tasks: { 2 sequential sub-tasks: { t1.1, 2 parallel sub-tasks: { t3.1, t3.2 } }, t2.1 }"))
 
 					status.startTime = time.Now()
-					errs := tasks.DoAllSync()
+					errs := tasks.DoAllSync(context.Background()).Errors()
 					Expect(errs).To(HaveLen(1))
 					Expect(errs[0].Error()).To(Equal("t3.2 always fails"))
 
@@ -220,7 +222,7 @@ var _ = Describe("StackCollection Tasks", func() {
 					subTask1 := &TaskTree{Parallel: false, IsSubTask: true}
 					subTask1.Append(&taskWithoutParams{
 						info: "t1.1",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							updateStatus("started t1.1")
 							go func() {
 								time.Sleep(100 * time.Millisecond)
@@ -236,7 +238,7 @@ var _ = Describe("StackCollection Tasks", func() {
 					subTask2 := &TaskTree{Parallel: false, IsSubTask: true}
 					subTask2.Append(&taskWithoutParams{
 						info: "t2.1",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							updateStatus("started t2.1")
 							go func() {
 								time.Sleep(150 * time.Millisecond)
@@ -252,7 +254,7 @@ var _ = Describe("StackCollection Tasks", func() {
 					subTask3 := &TaskTree{Parallel: true, IsSubTask: true}
 					subTask3.Append(&taskWithoutParams{
 						info: "t3.1",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							updateStatus("started t3.1")
 							go func() {
 								time.Sleep(200 * time.Millisecond)
@@ -265,7 +267,7 @@ var _ = Describe("StackCollection Tasks", func() {
 					})
 					subTask3.Append(&taskWithoutParams{
 						info: "t3.2",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							updateStatus("started t3.2")
 							go func() {
 								time.Sleep(350 * time.Millisecond)
@@ -281,7 +283,7 @@ var _ = Describe("StackCollection Tasks", func() {
 					Expect(tasks.Describe()).To(Equal("2 sequential tasks: { 2 sequential sub-tasks: { t1.1, 2 parallel sub-tasks: { t3.1, t3.2 } }, t2.1 }"))
 
 					status.startTime = time.Now()
-					errs := tasks.DoAllSync()
+					errs := tasks.DoAllSync(context.Background()).Errors()
 					Expect(errs).To(HaveLen(1))
 					Expect(errs[0].Error()).To(Equal("t2.1 always fails"))
 
@@ -316,14 +318,14 @@ var _ = Describe("StackCollection Tasks", func() {
 
 				{
 					tasks := &TaskTree{Parallel: false}
-					Expect(tasks.DoAllSync()).To(HaveLen(0))
+					Expect(tasks.DoAllSync(context.Background())).To(HaveLen(0))
 				}
 
 				{
 					tasks := &TaskTree{Parallel: false}
 					tasks.Append(&TaskTree{Parallel: false})
 					tasks.Append(&TaskTree{Parallel: true})
-					Expect(tasks.DoAllSync()).To(HaveLen(0))
+					Expect(tasks.DoAllSync(context.Background())).To(HaveLen(0))
 				}
 
 				{
@@ -333,7 +335,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.0",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							close(errs)
 							atomic.AddInt32(&counter, 1)
 							return fmt.Errorf("t1.0 does not even bother and always returns an immediate error")
@@ -342,7 +344,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.1",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(10 * time.Millisecond)
 								errs <- nil
@@ -355,7 +357,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.2",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(100 * time.Millisecond)
 								errs <- fmt.Errorf("t1.2 always fails")
@@ -368,7 +370,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.3",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(50 * time.Microsecond)
 								errs <- fmt.Errorf("t1.3 always fails")
@@ -381,7 +383,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.4",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							time.Sleep(150 * time.Millisecond)
 							close(errs)
 							atomic.AddInt32(&counter, 1)
@@ -391,7 +393,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.5",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(15 * time.Millisecond)
 								errs <- nil
@@ -404,7 +406,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.6",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(15 * time.Millisecond)
 								errs <- nil
@@ -417,7 +419,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.7",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(215 * time.Millisecond)
 								errs <- nil
@@ -430,10 +432,10 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.PlanMode = true
 
-					Expect(tasks.DoAllSync()).To(HaveLen(0))
+					Expect(tasks.DoAllSync(context.Background())).To(HaveLen(0))
 
 					tasks.PlanMode = false
-					errs := tasks.DoAllSync()
+					errs := tasks.DoAllSync(context.Background()).Errors()
 					Expect(errs).To(HaveLen(4))
 					Expect(errs[0].Error()).To(Equal("t1.0 does not even bother and always returns an immediate error"))
 					Expect(errs[1].Error()).To(Equal("t1.3 always fails"))
@@ -450,7 +452,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							close(errs)
 							atomic.AddInt32(&counter, 1)
 							return fmt.Errorf("t1.0 does not even bother and always returns an immediate error")
@@ -459,7 +461,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t2",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(10 * time.Millisecond)
 								errs <- nil
@@ -471,7 +473,7 @@ var _ = Describe("StackCollection Tasks", func() {
 					})
 
 					tasks.PlanMode = false
-					errs := tasks.DoAllSync()
+					errs := tasks.DoAllSync(context.Background()).Errors()
 					Expect(errs).To(HaveLen(1))
 					Expect(errs[0].Error()).To(Equal("t1.0 does not even bother and always returns an immediate error"))
 
@@ -483,7 +485,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.1",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(100 * time.Millisecond)
 								errs <- fmt.Errorf("t1.1 always fails")
@@ -495,7 +497,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.3",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(150 * time.Millisecond)
 								errs <- nil
@@ -507,7 +509,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.3",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								errs <- fmt.Errorf("t1.3 always fails")
 								close(errs)
@@ -518,10 +520,10 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.PlanMode = true
 
-					Expect(tasks.DoAllSync()).To(HaveLen(0))
+					Expect(tasks.DoAllSync(context.Background())).To(HaveLen(0))
 
 					tasks.PlanMode = false
-					errs := tasks.DoAllSync()
+					errs := tasks.DoAllSync(context.Background()).Errors()
 					Expect(errs).To(HaveLen(2))
 					Expect(errs[0].Error()).To(Equal("t1.3 always fails"))
 					Expect(errs[1].Error()).To(Equal("t1.1 always fails"))
@@ -532,7 +534,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.1",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(100 * time.Millisecond)
 								errs <- fmt.Errorf("t1.1 always fails")
@@ -544,7 +546,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.3",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(150 * time.Millisecond)
 								errs <- nil
@@ -556,7 +558,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.3",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								errs <- nil
 								close(errs)
@@ -567,10 +569,10 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.PlanMode = true
 
-					Expect(tasks.DoAllSync()).To(HaveLen(0))
+					Expect(tasks.DoAllSync(context.Background())).To(HaveLen(0))
 
 					tasks.PlanMode = false
-					errs := tasks.DoAllSync()
+					errs := tasks.DoAllSync(context.Background()).Errors()
 					Expect(errs).To(HaveLen(1))
 					Expect(errs[0].Error()).To(Equal("t1.1 always fails"))
 				}
@@ -580,7 +582,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.1",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(100 * time.Millisecond)
 								errs <- nil
@@ -592,7 +594,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.3",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								time.Sleep(150 * time.Millisecond)
 								errs <- nil
@@ -604,7 +606,7 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.Append(&taskWithoutParams{
 						info: "t1.3",
-						call: func(errs chan error) error {
+						call: func(_ context.Context, errs chan error) error {
 							go func() {
 								errs <- fmt.Errorf("t1.3 always fails")
 								close(errs)
@@ -615,16 +617,162 @@ var _ = Describe("StackCollection Tasks", func() {
 
 					tasks.PlanMode = true
 
-					Expect(tasks.DoAllSync()).To(HaveLen(0))
+					Expect(tasks.DoAllSync(context.Background())).To(HaveLen(0))
 
 					tasks.PlanMode = false
-					errs := tasks.DoAllSync()
+					errs := tasks.DoAllSync(context.Background()).Errors()
 					Expect(errs).To(HaveLen(1))
 					Expect(errs[0].Error()).To(Equal("t1.3 always fails"))
 				}
 			})
 		})
 
+		Context("With dependencies, limits and cancellation", func() {
+
+			newSleepTask := func(info string, d time.Duration, err error, running, maxRunning *int32) *taskWithoutParams {
+				return &taskWithoutParams{
+					info: info,
+					call: func(ctx context.Context, errs chan error) error {
+						if n := atomic.AddInt32(running, 1); n > atomic.LoadInt32(maxRunning) {
+							atomic.StoreInt32(maxRunning, n)
+						}
+						go func() {
+							defer close(errs)
+							result := err
+							select {
+							case <-time.After(d):
+							case <-ctx.Done():
+								result = ctx.Err()
+							}
+							atomic.AddInt32(running, -1)
+							errs <- result
+						}()
+						return nil
+					},
+				}
+			}
+
+			It("should only run tasks once dependencies are done", func() {
+				var running, maxRunning int32
+				tasks := &TaskTree{Parallel: true}
+
+				t1 := newSleepTask("t1", 100*time.Millisecond, nil, &running, &maxRunning)
+				t2 := newSleepTask("t2", 10*time.Millisecond, fmt.Errorf("t2 always fails"), &running, &maxRunning)
+				t3 := newSleepTask("t3", 10*time.Millisecond, nil, &running, &maxRunning)
+				t4 := newSleepTask("t4", 10*time.Millisecond, nil, &running, &maxRunning)
+
+				tasks.Append(t1, t2)
+				tasks.AppendWithDependencies(t3, t1)
+				tasks.AppendWithDependencies(t4, t1, t2)
+
+				results := tasks.DoAllSync(context.Background())
+				Expect(results).To(HaveLen(4))
+				Expect(results.Errors()).To(HaveLen(1))
+
+				states := map[string]TaskState{}
+				for _, result := range results {
+					states[result.Description] = result.State
+				}
+				Expect(states).To(Equal(map[string]TaskState{
+					"t1": TaskDone,
+					"t2": TaskFailed,
+					"t3": TaskDone,
+					"t4": TaskSkipped,
+				}))
+
+				// Results are in order of completion, t3 can only complete after t1
+				order := map[string]int{}
+				for i, result := range results {
+					order[result.Description] = i
+				}
+				Expect(order["t3"]).To(BeNumerically(">", order["t1"]))
+
+				Expect(tasks.Describe()).To(MatchRegexp(`^4 parallel tasks: { t1 \[done in \d+ms\], t2 \[failed in \d+ms\], t3 \[done in \d+ms\], t4 \[skipped\] }$`))
+			})
+
+			It("should limit number of tasks running at the same time", func() {
+				var running, maxRunning int32
+				tasks := &TaskTree{Parallel: false, MaxParallel: 2}
+				subTask1 := &TaskTree{Parallel: true, IsSubTask: true}
+				subTask2 := &TaskTree{Parallel: true, IsSubTask: true}
+				for i := 0; i < 5; i++ {
+					subTask1.Append(newSleepTask(fmt.Sprintf("t1.%d", i), 20*time.Millisecond, nil, &running, &maxRunning))
+					subTask2.Append(newSleepTask(fmt.Sprintf("t2.%d", i), 20*time.Millisecond, nil, &running, &maxRunning))
+				}
+				tasks.Append(subTask1, subTask2)
+
+				results := tasks.DoAllSync(context.Background())
+				Expect(results).To(HaveLen(10))
+				Expect(results.Errors()).To(BeEmpty())
+				Expect(atomic.LoadInt32(&maxRunning)).To(Equal(int32(2)))
+			})
+
+			It("should stop running tasks when cancelled", func() {
+				var running, maxRunning int32
+				tasks := &TaskTree{Parallel: false}
+				tasks.Append(
+					newSleepTask("t1", time.Hour, nil, &running, &maxRunning),
+					newSleepTask("t2", time.Millisecond, nil, &running, &maxRunning),
+				)
+
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+
+				results := tasks.DoAllSync(ctx)
+				Expect(results).To(HaveLen(2))
+				Expect(results[0].Description).To(Equal("t1"))
+				Expect(results[0].State).To(Equal(TaskCancelled))
+				Expect(results[0].Err).To(Equal(context.Canceled))
+				Expect(results[1].Description).To(Equal("t2"))
+				Expect(results[1].State).To(Equal(TaskSkipped))
+				Expect(atomic.LoadInt32(&running)).To(Equal(int32(0)))
+			})
+
+			It("should record progress and skip tasks completed by a previous run", func() {
+				var running, maxRunning int32
+				recorder := &testTaskRecorder{}
+				tasks := &TaskTree{
+					Parallel:       false,
+					Recorder:       recorder,
+					CompletedTasks: sets.NewString("t1"),
+				}
+				tasks.Append(
+					newSleepTask("t1", time.Hour, nil, &running, &maxRunning),
+					&taskWithStackSpec{
+						info:  "t2",
+						stack: &Stack{StackName: aws.String("eksctl-test-cluster-nodegroup-ng")},
+						call: func(_ context.Context, _ *Stack, errs chan error) error {
+							close(errs)
+							return nil
+						},
+					},
+				)
+
+				results := tasks.DoAllSync(context.Background())
+				Expect(results.Errors()).To(BeEmpty())
+				Expect(atomic.LoadInt32(&maxRunning)).To(Equal(int32(0)))
+
+				recorded := []string{}
+				for _, result := range recorder.results {
+					recorded = append(recorded, fmt.Sprintf("%s:%s:%s", result.Description, result.State, result.StackName))
+				}
+				Expect(recorded).To(Equal([]string{
+					"t1:pending:",
+					"t2:pending:eksctl-test-cluster-nodegroup-ng",
+					"t1:done:",
+					"t2:running:eksctl-test-cluster-nodegroup-ng",
+					"t2:done:eksctl-test-cluster-nodegroup-ng",
+				}))
+			})
+
+			It("should not accept dependencies that are not in the set", func() {
+				tasks := &TaskTree{Parallel: true}
+				Expect(func() {
+					tasks.AppendWithDependencies(&taskWithoutParams{info: "t1"}, &taskWithoutParams{info: "t2"})
+				}).To(Panic())
+			})
+		})
+
 		Context("With real tasks", func() {
 
 			BeforeEach(func() {
@@ -670,3 +818,11 @@ var _ = Describe("StackCollection Tasks", func() {
 
 	})
 })
+
+type testTaskRecorder struct {
+	results []*TaskResult
+}
+
+func (r *testTaskRecorder) RecordTask(result *TaskResult) {
+	r.results = append(r.results, result)
+}
@@ -1,8 +1,18 @@
 package manager
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/weaveworks/eksctl/pkg/cfn/builder"
 )
 
 // GetStackTemplate gets the Cloudformation template for a stack
@@ -18,3 +28,137 @@ func (c *StackCollection) GetStackTemplate(stackName string) (string, error) {
 
 	return *output.TemplateBody, nil
 }
+
+// replaceTemplateResources replaces all resources in currentTemplate that match the
+// given function with the matching resources from newTemplate, resources that are
+// no longer present in newTemplate get removed; names of all resources that were
+// added, changed or removed are returned along with the modified template
+func replaceTemplateResources(currentTemplate string, newTemplate []byte, match func(string) bool) (string, []string, error) {
+	currentResources := gjson.Get(currentTemplate, resourcesRootPath)
+	if !currentResources.IsObject() {
+		return "", nil, fmt.Errorf("unexpected template format of the current stack ")
+	}
+	newResources := gjson.Get(string(newTemplate), resourcesRootPath)
+
+	var (
+		changed []string
+		iterErr error
+	)
+
+	currentResources.ForEach(func(k, _ gjson.Result) bool {
+		name := k.String()
+		if !match(name) || newResources.Get(name).Exists() {
+			return true
+		}
+		changed = append(changed, name)
+		currentTemplate, iterErr = sjson.Delete(currentTemplate, resourcesRootPath+"."+name)
+		return iterErr == nil
+	})
+	if iterErr != nil {
+		return "", nil, errors.Wrap(iterErr, "removing resources from current stack template")
+	}
+
+	newResources.ForEach(func(k, v gjson.Result) bool {
+		name := k.String()
+		if !match(name) {
+			return true
+		}
+		if current := currentResources.Get(name); current.Exists() {
+			equal, err := equalJSON(current.Raw, v.Raw)
+			if err != nil {
+				iterErr = err
+				return false
+			}
+			if equal {
+				return true
+			}
+		}
+		changed = append(changed, name)
+		currentTemplate, iterErr = sjson.SetRaw(currentTemplate, resourcesRootPath+"."+name, v.Raw)
+		return iterErr == nil
+	})
+	if iterErr != nil {
+		return "", nil, errors.Wrap(iterErr, "replacing resources in current stack template")
+	}
+
+	return currentTemplate, changed, nil
+}
+
+// restrictTemplateEgress sets the egress rules of the security group resource to builder.RestrictedEgress
+// when restrict is true, or removes them so that the default rule allowing all egress is restored; it
+// returns the modified template and whether it was changed
+func restrictTemplateEgress(currentTemplate, securityGroup string, restrict bool) (string, bool, error) {
+	path := resourcesRootPath + "." + securityGroup + ".Properties.SecurityGroupEgress"
+	current := gjson.Get(currentTemplate, path)
+
+	if !restrict {
+		if !current.Exists() {
+			return currentTemplate, false, nil
+		}
+		updatedTemplate, err := sjson.Delete(currentTemplate, path)
+		if err != nil {
+			return "", false, errors.Wrapf(err, "removing egress rules of %q from current stack template", securityGroup)
+		}
+		return updatedTemplate, true, nil
+	}
+
+	restrictedEgress, err := json.Marshal(builder.RestrictedEgress())
+	if err != nil {
+		return "", false, err
+	}
+	if current.Exists() {
+		equal, err := equalJSON(current.Raw, string(restrictedEgress))
+		if err != nil || equal {
+			return currentTemplate, false, err
+		}
+	}
+	updatedTemplate, err := sjson.SetRaw(currentTemplate, path, string(restrictedEgress))
+	if err != nil {
+		return "", false, errors.Wrapf(err, "setting egress rules of %q in current stack template", securityGroup)
+	}
+	return updatedTemplate, true, nil
+}
+
+func equalJSON(a, b string) (bool, error) {
+	var objA, objB interface{}
+	if err := json.Unmarshal([]byte(a), &objA); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(b), &objB); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(objA, objB), nil
+}
+
+type renderableStack interface {
+	RenderJSON() ([]byte, error)
+}
+
+// updateStackResources uses replaceTemplateResources to update selected resources
+// of the given stack via a ChangeSet, along with the resources that the caller has
+// already changed in currentTemplate, it returns true when an update was needed
+func (c *StackCollection) updateStackResources(name, action, currentTemplate string, stack renderableStack, match func(string) bool, alreadyChanged []string, plan bool) (bool, error) {
+	newTemplate, err := stack.RenderJSON()
+	if err != nil {
+		return false, errors.Wrapf(err, "rendering template for %q stack", name)
+	}
+	logger.Debug("newTemplate = %s", newTemplate)
+
+	updatedTemplate, changed, err := replaceTemplateResources(currentTemplate, newTemplate, match)
+	if err != nil {
+		return false, err
+	}
+	changed = append(alreadyChanged, changed...)
+
+	if len(changed) == 0 {
+		logger.Success("all security group rules in stack %q are up-to-date", name)
+		return false, nil
+	}
+
+	describeUpdate := fmt.Sprintf("updating stack %q to replace security group rules %v", name, changed)
+	if plan {
+		logger.Info("(plan) %s", describeUpdate)
+		return true, nil
+	}
+	return true, c.UpdateStack(name, c.MakeChangeSetName(action), describeUpdate, []byte(updatedTemplate), nil)
+}
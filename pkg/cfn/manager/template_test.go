@@ -2,12 +2,14 @@ package manager
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	cfn "github.com/aws/aws-sdk-go/service/cloudformation"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/mock"
+	"github.com/tidwall/gjson"
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
 )
@@ -96,4 +98,71 @@ var _ = Describe("StackCollection Template", func() {
 			})
 		})
 	})
+
+	Describe("replaceTemplateResources", func() {
+		isRule := func(name string) bool { return strings.HasPrefix(name, "Rule") }
+
+		currentTemplate := `{
+			"Resources": {
+				"SG": {"Type": "AWS::EC2::SecurityGroup"},
+				"Rule0": {"Type": "AWS::EC2::SecurityGroupIngress", "Properties": {"FromPort": 22, "ToPort": 22}},
+				"Rule1": {"Type": "AWS::EC2::SecurityGroupIngress", "Properties": {"FromPort": 80, "ToPort": 80}}
+			}
+		}`
+
+		It("should be a no-op when rules are unchanged", func() {
+			newTemplate := `{"Resources": {
+				"Rule0": {"Type": "AWS::EC2::SecurityGroupIngress", "Properties": {"ToPort": 22, "FromPort": 22}},
+				"Rule1": {"Type": "AWS::EC2::SecurityGroupIngress", "Properties": {"FromPort": 80, "ToPort": 80}}
+			}}`
+			_, changed, err := replaceTemplateResources(currentTemplate, []byte(newTemplate), isRule)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeEmpty())
+		})
+
+		It("should replace changed rules, add new ones and remove old ones", func() {
+			newTemplate := `{"Resources": {
+				"Rule0": {"Type": "AWS::EC2::SecurityGroupIngress", "Properties": {"FromPort": 2222, "ToPort": 2222}},
+				"Rule2": {"Type": "AWS::EC2::SecurityGroupIngress", "Properties": {"FromPort": 443, "ToPort": 443}}
+			}}`
+			updated, changed, err := replaceTemplateResources(currentTemplate, []byte(newTemplate), isRule)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(ConsistOf("Rule0", "Rule1", "Rule2"))
+
+			Expect(gjson.Get(updated, "Resources.SG").Exists()).To(BeTrue())
+			Expect(gjson.Get(updated, "Resources.Rule0.Properties.FromPort").Int()).To(Equal(int64(2222)))
+			Expect(gjson.Get(updated, "Resources.Rule1").Exists()).To(BeFalse())
+			Expect(gjson.Get(updated, "Resources.Rule2.Properties.FromPort").Int()).To(Equal(int64(443)))
+		})
+	})
+
+	Describe("restrictTemplateEgress", func() {
+		currentTemplate := `{"Resources": {"SG": {"Type": "AWS::EC2::SecurityGroup", "Properties": {"VpcId": "vpc-1"}}}}`
+
+		It("should revoke the default egress rule and be a no-op once it's revoked", func() {
+			updated, changed, err := restrictTemplateEgress(currentTemplate, "SG", true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(gjson.Get(updated, "Resources.SG.Properties.VpcId").String()).To(Equal("vpc-1"))
+			Expect(gjson.Get(updated, "Resources.SG.Properties.SecurityGroupEgress.0.CidrIp").String()).To(Equal("127.0.0.1/32"))
+
+			_, changed, err = restrictTemplateEgress(updated, "SG", true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeFalse())
+		})
+
+		It("should restore the default egress rule when there are no egress rules", func() {
+			restricted, _, err := restrictTemplateEgress(currentTemplate, "SG", true)
+			Expect(err).ToNot(HaveOccurred())
+
+			updated, changed, err := restrictTemplateEgress(restricted, "SG", false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(gjson.Get(updated, "Resources.SG.Properties.SecurityGroupEgress").Exists()).To(BeFalse())
+
+			_, changed, err = restrictTemplateEgress(currentTemplate, "SG", false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeFalse())
+		})
+	})
 })
@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws/request"
@@ -22,7 +23,7 @@ const (
 // so this is custom version that is more suitable for our use, as there is no way to add any
 // custom acceptors
 
-func (c *StackCollection) waitWithAcceptors(i *Stack, acceptors []request.WaiterAcceptor) error {
+func (c *StackCollection) waitWithAcceptors(ctx context.Context, i *Stack, acceptors []request.WaiterAcceptor) error {
 	msg := fmt.Sprintf("waiting for CloudFormation stack %q", *i.StackName)
 
 	newRequest := func() *request.Request {
@@ -36,7 +37,18 @@ func (c *StackCollection) waitWithAcceptors(i *Stack, acceptors []request.Waiter
 		return req
 	}
 
+	// stream events while waiting, the remaining events are output
+	// once waiting is done and before troubleshooting starts
+	streamCtx, cancelStreaming := context.WithCancel(ctx)
+	streaming := c.streamStackEvents(streamCtx, i)
+	stopStreaming := func() {
+		cancelStreaming()
+		<-streaming
+	}
+	defer stopStreaming()
+
 	troubleshoot := func(desiredStatus string) {
+		stopStreaming()
 		s, err := c.DescribeStack(i)
 		if err != nil {
 			logger.Debug("describeErr=%v", err)
@@ -46,10 +58,10 @@ func (c *StackCollection) waitWithAcceptors(i *Stack, acceptors []request.Waiter
 		}
 	}
 
-	return waiters.Wait(*i.StackName, msg, acceptors, newRequest, c.provider.WaitTimeout(), troubleshoot)
+	return waiters.Wait(ctx, *i.StackName, msg, acceptors, newRequest, c.provider.WaitTimeout(), troubleshoot)
 }
 
-func (c *StackCollection) waitWithAcceptorsChangeSet(i *Stack, changesetName string, acceptors []request.WaiterAcceptor) error {
+func (c *StackCollection) waitWithAcceptorsChangeSet(ctx context.Context, i *Stack, changesetName string, acceptors []request.WaiterAcceptor) error {
 	msg := fmt.Sprintf("waiting for CloudFormation changeset %q for stack %q", changesetName, *i.StackName)
 
 	newRequest := func() *request.Request {
@@ -70,7 +82,7 @@ func (c *StackCollection) waitWithAcceptorsChangeSet(i *Stack, changesetName str
 		}
 	}
 
-	return waiters.Wait(*i.StackName, msg, acceptors, newRequest, c.provider.WaitTimeout(), troubleshoot)
+	return waiters.Wait(ctx, *i.StackName, msg, acceptors, newRequest, c.provider.WaitTimeout(), troubleshoot)
 }
 
 func (c *StackCollection) troubleshootStackFailureCause(i *Stack, desiredStatus string) {
@@ -111,9 +123,9 @@ func (c *StackCollection) troubleshootStackFailureCause(i *Stack, desiredStatus
 }
 
 // DoWaitUntilStackIsCreated blocks until the given stack's
-// creation has completed.
-func (c *StackCollection) DoWaitUntilStackIsCreated(i *Stack) error {
-	return c.waitWithAcceptors(i,
+// creation has completed, or ctx is cancelled
+func (c *StackCollection) DoWaitUntilStackIsCreated(ctx context.Context, i *Stack) error {
+	return c.waitWithAcceptors(ctx, i,
 		waiters.MakeAcceptors(
 			stackStatus,
 			cfn.StackStatusCreateComplete,
@@ -135,10 +147,10 @@ func (c *StackCollection) DoWaitUntilStackIsCreated(i *Stack) error {
 	)
 }
 
-func (c *StackCollection) waitUntilStackIsCreated(i *Stack, stack builder.ResourceSet, errs chan error) {
+func (c *StackCollection) waitUntilStackIsCreated(ctx context.Context, i *Stack, stack builder.ResourceSet, errs chan error) {
 	defer close(errs)
 
-	if err := c.DoWaitUntilStackIsCreated(i); err != nil {
+	if err := c.DoWaitUntilStackIsCreated(ctx, i); err != nil {
 		errs <- err
 		return
 	}
@@ -154,8 +166,8 @@ func (c *StackCollection) waitUntilStackIsCreated(i *Stack, stack builder.Resour
 	errs <- nil
 }
 
-func (c *StackCollection) doWaitUntilStackIsDeleted(i *Stack) error {
-	return c.waitWithAcceptors(i,
+func (c *StackCollection) doWaitUntilStackIsDeleted(ctx context.Context, i *Stack) error {
+	return c.waitWithAcceptors(ctx, i,
 		waiters.MakeAcceptors(
 			stackStatus,
 			cfn.StackStatusDeleteComplete,
@@ -188,18 +200,18 @@ func (c *StackCollection) doWaitUntilStackIsDeleted(i *Stack) error {
 	)
 }
 
-func (c *StackCollection) waitUntilStackIsDeleted(i *Stack, errs chan error) {
+func (c *StackCollection) waitUntilStackIsDeleted(ctx context.Context, i *Stack, errs chan error) {
 	defer close(errs)
 
-	if err := c.doWaitUntilStackIsDeleted(i); err != nil {
+	if err := c.doWaitUntilStackIsDeleted(ctx, i); err != nil {
 		errs <- err
 		return
 	}
 	errs <- nil
 }
 
-func (c *StackCollection) doWaitUntilStackIsUpdated(i *Stack) error {
-	return c.waitWithAcceptors(i,
+func (c *StackCollection) doWaitUntilStackIsUpdated(ctx context.Context, i *Stack) error {
+	return c.waitWithAcceptors(ctx, i,
 		waiters.MakeAcceptors(
 			stackStatus,
 			cfn.StackStatusUpdateComplete,
@@ -223,8 +235,8 @@ func (c *StackCollection) doWaitUntilStackIsUpdated(i *Stack) error {
 	)
 }
 
-func (c *StackCollection) doWaitUntilChangeSetIsCreated(i *Stack, changesetName string) error {
-	return c.waitWithAcceptorsChangeSet(i, changesetName,
+func (c *StackCollection) doWaitUntilChangeSetIsCreated(ctx context.Context, i *Stack, changesetName string) error {
+	return c.waitWithAcceptorsChangeSet(ctx, i, changesetName,
 		waiters.MakeAcceptors(
 			changesetStatus,
 			cfn.ChangeSetStatusCreateComplete,
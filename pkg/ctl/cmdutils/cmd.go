@@ -1,13 +1,17 @@
 package cmdutils
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/kris-nova/logger"
 	"github.com/spf13/cobra"
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/events"
+	"github.com/weaveworks/eksctl/pkg/journal"
 )
 
 // Cmd holds attributes that are common between commands;
@@ -26,6 +30,13 @@ type Cmd struct {
 	ClusterConfig  *api.ClusterConfig
 
 	Include, Exclude []string
+
+	MaxParallel int
+
+	JournalLocation string
+	journal         *journal.Journal
+
+	SkipPreflight bool
 }
 
 // NewCtl performs common defaulting and validation and constructs a new
@@ -53,6 +64,10 @@ func (c *Cmd) NewCtl() (*eks.ClusterProvider, error) {
 		api.SetNodeGroupDefaults(i, ng)
 	}
 
+	if output := c.ProviderConfig.StackEventsOutput; output != "" && !api.IsSupportedStackEventsOutput(output) {
+		return nil, fmt.Errorf("--stack-events=%s is not supported (valid options: %s)", output, strings.Join(api.SupportedStackEventsOutputs(), ", "))
+	}
+
 	ctl := eks.New(c.ProviderConfig, c.ClusterConfig)
 
 	if !ctl.IsSupportedRegion() {
@@ -64,6 +79,11 @@ func (c *Cmd) NewCtl() (*eks.ClusterProvider, error) {
 
 // AddResourceCmd create a registers a new command under the given verb command
 func AddResourceCmd(flagGrouping *FlagGrouping, parentVerbCmd *cobra.Command, newCmd func(*Cmd)) {
+	parentVerbCmd.AddCommand(NewStandaloneCmd(flagGrouping, newCmd))
+}
+
+// NewStandaloneCmd creates a new command that doesn't belong to any verb command
+func NewStandaloneCmd(flagGrouping *FlagGrouping, newCmd func(*Cmd)) *cobra.Command {
 	c := &Cmd{
 		CobraCommand:   &cobra.Command{},
 		ProviderConfig: &api.ProviderConfig{},
@@ -75,7 +95,7 @@ func AddResourceCmd(flagGrouping *FlagGrouping, parentVerbCmd *cobra.Command, ne
 	c.FlagSetGroup = flagGrouping.New(c.CobraCommand)
 	newCmd(c)
 	c.FlagSetGroup.AddTo(c.CobraCommand)
-	parentVerbCmd.AddCommand(c.CobraCommand)
+	return c.CobraCommand
 }
 
 // SetDescription sets usage along with short and long descriptions as well as aliases
@@ -89,20 +109,28 @@ func (c *Cmd) SetDescription(use, short, long string, aliases ...string) {
 // SetRunFunc registers a command function
 func (c *Cmd) SetRunFunc(cmd func() error) {
 	c.CobraCommand.Run = func(_ *cobra.Command, _ []string) {
-		run(cmd)
+		c.run(cmd)
 	}
 }
 
 // SetRunFuncWithNameArg registers a command function with an optional name argument
 func (c *Cmd) SetRunFuncWithNameArg(cmd func() error) {
 	c.CobraCommand.Run = func(_ *cobra.Command, args []string) {
-		c.NameArg = GetNameArg(args)
-		run(cmd)
+		c.run(func() error {
+			// checked here rather than by GetNameArg, so that the result is emitted
+			if len(args) > 1 {
+				return fmt.Errorf("only one argument is allowed to be used as a name")
+			}
+			c.NameArg = GetNameArg(args)
+			return cmd()
+		})
 	}
 }
 
-func run(cmd func() error) {
-	if err := cmd(); err != nil {
+func (c *Cmd) run(cmd func() error) {
+	err := cmd()
+	events.EmitResult(c.CobraCommand.CommandPath(), err)
+	if err != nil {
 		logger.Critical("%s\n", err.Error())
 		os.Exit(1)
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/events"
 	"github.com/weaveworks/eksctl/pkg/utils/kubeconfig"
 )
 
@@ -49,6 +50,7 @@ func LogIntendedAction(plan bool, msgFmt string, args ...interface{}) {
 		prefix = "(plan) would "
 	}
 	logger.Info(prefix+msgFmt, args...)
+	events.Emit(events.IntendedAction, plan, nil, msgFmt, args...)
 }
 
 // LogCompletedAction calls logger.Success with appropriate prefix
@@ -58,6 +60,7 @@ func LogCompletedAction(plan bool, msgFmt string, args ...interface{}) {
 		prefix = "(plan) would have "
 	}
 	logger.Success(prefix+msgFmt, args...)
+	events.Emit(events.CompletedAction, plan, nil, msgFmt, args...)
 }
 
 // LogPlanModeWarning will log a message to inform user that they are in plan-mode
@@ -101,6 +104,7 @@ func AddCommonFlagsForAWS(group *NamedFlagSetGroup, p *api.ProviderConfig, cfnRo
 		}
 		if cfnRole {
 			fs.StringVar(&p.CloudFormationRoleARN, "cfn-role-arn", "", "IAM role used by CloudFormation to call AWS API on your behalf")
+			fs.StringVar(&p.StackEventsOutput, "stack-events", api.StackEventsOutputLog, fmt.Sprintf("how to output CloudFormation stack events while waiting for stacks (valid options: %s)", strings.Join(api.SupportedStackEventsOutputs(), ", ")))
 		}
 	})
 }
@@ -145,13 +149,22 @@ func AddUpdateAuthConfigMap(fs *pflag.FlagSet, updateAuthConfigMap *bool, descri
 }
 
 // AddCommonFlagsForKubeconfig adds common flags for controlling how output kubeconfig is written
-func AddCommonFlagsForKubeconfig(fs *pflag.FlagSet, outputPath, authenticatorRoleARN *string, setContext, autoPath *bool, exampleName string) {
+func AddCommonFlagsForKubeconfig(fs *pflag.FlagSet, outputPath, authenticator, authenticatorRoleARN *string, setContext, autoPath *bool, exampleName string) {
 	fs.StringVar(outputPath, "kubeconfig", kubeconfig.DefaultPath, "path to write kubeconfig (incompatible with --auto-kubeconfig)")
+	fs.StringVar(authenticator, "authenticator", "", fmt.Sprintf("authenticator command to use in kubeconfig (valid options: %s, %s), detected automatically if unspecified", strings.Join(kubeconfig.AuthenticatorCommands(), ", "), kubeconfig.EksctlAuthenticator))
 	fs.StringVar(authenticatorRoleARN, "authenticator-role-arn", "", "AWS IAM role to assume for authenticator")
 	fs.BoolVar(setContext, "set-kubeconfig-context", true, "if true then current-context will be set in kubeconfig; if a context is already set then it will be overwritten")
 	fs.BoolVar(autoPath, "auto-kubeconfig", false, fmt.Sprintf("save kubeconfig file by cluster name, e.g. %q", kubeconfig.AutoPath(exampleName)))
 }
 
+// ValidateAuthenticatorFlag checks value of --authenticator flag
+func ValidateAuthenticatorFlag(authenticator string) error {
+	if authenticator != "" && !kubeconfig.IsSupportedAuthenticator(authenticator) {
+		return fmt.Errorf("--authenticator=%s is not supported", authenticator)
+	}
+	return nil
+}
+
 // AddCommonFlagsForGetCmd adds common flafs for get commands
 func AddCommonFlagsForGetCmd(fs *pflag.FlagSet, chunkSize *int, outputMode *string) {
 	fs.IntVar(chunkSize, "chunk-size", 100, "return large lists in chunks rather than all at once, pass 0 to disable")
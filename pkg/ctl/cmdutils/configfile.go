@@ -151,6 +151,8 @@ func NewCreateClusterLoader(cmd *Cmd, ngFilter *NodeGroupFilter, ng *api.NodeGro
 		"node-ami-family",
 		"ssh-access",
 		"ssh-public-key",
+		"ssh-source-cidrs",
+		"enable-ssm",
 		"node-private-networking",
 		"node-security-groups",
 		"node-labels",
@@ -231,6 +233,8 @@ func NewCreateNodeGroupLoader(cmd *Cmd, ngFilter *NodeGroupFilter) ClusterConfig
 		"node-ami-family",
 		"ssh-access",
 		"ssh-public-key",
+		"ssh-source-cidrs",
+		"enable-ssm",
 		"node-private-networking",
 		"node-security-groups",
 		"node-labels",
@@ -289,6 +293,22 @@ func normalizeNodeGroup(ng *api.NodeGroup, l *commonClusterConfigLoader) error {
 	return nil
 }
 
+// NewUpdateNodeGroupLoader will load config for 'eksctl update nodegroup',
+// which can only be used with a config file
+func NewUpdateNodeGroupLoader(cmd *Cmd, ngFilter *NodeGroupFilter) ClusterConfigLoader {
+	l := newCommonClusterConfigLoader(cmd)
+
+	l.validateWithConfigFile = func() error {
+		return ngFilter.AppendGlobs(l.Include, l.Exclude, l.ClusterConfig.NodeGroups)
+	}
+
+	l.validateWithoutConfigFile = func() error {
+		return ErrMustBeSet("--config-file")
+	}
+
+	return l
+}
+
 // NewDeleteNodeGroupLoader will load config or use flags for 'eksctl delete nodegroup'
 func NewDeleteNodeGroupLoader(cmd *Cmd, ng *api.NodeGroup, ngFilter *NodeGroupFilter) ClusterConfigLoader {
 	l := newCommonClusterConfigLoader(cmd)
@@ -336,6 +356,55 @@ func NewDeleteNodeGroupLoader(cmd *Cmd, ng *api.NodeGroup, ngFilter *NodeGroupFi
 	return l
 }
 
+// NewScaleNodeGroupLoader will load config or use flags for 'eksctl scale nodegroup'
+func NewScaleNodeGroupLoader(cmd *Cmd, ng *api.NodeGroup, ngFilter *NodeGroupFilter) ClusterConfigLoader {
+	l := newCommonClusterConfigLoader(cmd)
+
+	l.flagsIncompatibleWithConfigFile.Insert(
+		"cluster",
+		"nodes",
+		"nodes-min",
+		"nodes-max",
+	)
+
+	l.validateWithConfigFile = func() error {
+		return ngFilter.AppendGlobs(l.Include, l.Exclude, l.ClusterConfig.NodeGroups)
+	}
+
+	l.flagsIncompatibleWithoutConfigFile.Insert(
+		"include",
+		"exclude",
+	)
+
+	l.validateWithoutConfigFile = func() error {
+		if l.ClusterConfig.Metadata.Name == "" {
+			return ErrMustBeSet("--cluster")
+		}
+
+		if ng.Name != "" && l.NameArg != "" {
+			return ErrNameFlagAndArg(ng.Name, l.NameArg)
+		}
+
+		if l.NameArg != "" {
+			ng.Name = l.NameArg
+		}
+
+		if ng.Name == "" {
+			return ErrMustBeSet("--name")
+		}
+
+		if ng.DesiredCapacity == nil && ng.MinSize == nil && ng.MaxSize == nil {
+			return fmt.Errorf("at least one of --nodes, --nodes-min or --nodes-max must be set")
+		}
+
+		ngFilter.AppendIncludeNames(ng.Name)
+
+		return nil
+	}
+
+	return l
+}
+
 // NewUtilsEnableLoggingLoader will load config or use flags for 'eksctl utils update-cluster-logging'
 func NewUtilsEnableLoggingLoader(cmd *Cmd) ClusterConfigLoader {
 	l := newCommonClusterConfigLoader(cmd)
@@ -0,0 +1,20 @@
+package cmdutils
+
+import (
+	"github.com/spf13/pflag"
+
+	"github.com/weaveworks/eksctl/pkg/drain"
+)
+
+// AddDrainFlags adds common flags that control how nodes are drained
+func AddDrainFlags(fs *pflag.FlagSet, options *drain.Options) {
+	fs.IntVar(&options.MaxUnavailable, "max-unavailable", options.MaxUnavailable, "maximum number of nodes that are drained at the same time")
+	AddNodeDrainFlags(fs, options)
+}
+
+// AddNodeDrainFlags adds the flags that control how a single node is drained
+func AddNodeDrainFlags(fs *pflag.FlagSet, options *drain.Options) {
+	fs.StringSliceVar(&options.SkipPodSelectors, "skip-pods", options.SkipPodSelectors, "label selectors of pods that are left running on the nodes (e.g. \"app=logging\")")
+	fs.DurationVar(&options.PodGracePeriod, "pod-grace-period", options.PodGracePeriod, "grace period for pods to terminate, when it's negative the grace period of each pod is used")
+	fs.BoolVar(&options.DisableEviction, "disable-eviction", options.DisableEviction, "delete pods rather than evicting them, which ignores PodDisruptionBudgets")
+}
@@ -0,0 +1,23 @@
+package cmdutils
+
+import (
+	"fmt"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+)
+
+// SetGitConfigFromFlags uses git, which was set from the given flags, as the
+// git section of the ClusterConfig, unless it was declared in the config file,
+// in which case none of the flags can be used
+func SetGitConfigFromFlags(cmd *Cmd, git *api.Git, flagNames ...string) (fromFlags bool, err error) {
+	if cmd.ClusterConfig.Git == nil {
+		cmd.ClusterConfig.Git = git
+		return true, nil
+	}
+	for _, f := range flagNames {
+		if flag := cmd.CobraCommand.Flag(f); flag != nil && flag.Changed {
+			return false, fmt.Errorf("cannot use --%s when git is set in the config file", f)
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,24 @@
+package cmdutils
+
+import (
+	"github.com/kris-nova/logger"
+	"github.com/spf13/pflag"
+
+	"github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/preflight"
+)
+
+// AddSkipPreflightFlag adds common --skip-preflight flag
+func AddSkipPreflightFlag(fs *pflag.FlagSet, skip *bool) {
+	fs.BoolVar(skip, "skip-preflight", false, "skip checks for problems that would make CloudFormation fail, such as exhausted quotas or missing IAM permissions")
+}
+
+// RunPreflightChecks runs the given checks and returns an error if any of them
+// has failed, unless --skip-preflight is set
+func (c *Cmd) RunPreflightChecks(ctl *eks.ClusterProvider, checks []preflight.Check) error {
+	if c.SkipPreflight {
+		logger.Warning("skipping pre-flight checks")
+		return nil
+	}
+	return preflight.NewRunner(ctl.Provider, c.ClusterConfig, checks...).RunAndReport()
+}
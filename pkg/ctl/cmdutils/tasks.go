@@ -0,0 +1,123 @@
+package cmdutils
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/kris-nova/logger"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+	"github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/journal"
+)
+
+// AddMaxParallelFlag adds common --max-parallel flag
+func AddMaxParallelFlag(fs *pflag.FlagSet, maxParallel *int) {
+	fs.IntVar(maxParallel, "max-parallel", 0, "maximum number of CloudFormation stacks to create or delete at the same time, use it to avoid API throttling (0 means no limit)")
+}
+
+// AddJournalFlag adds common --journal flag
+func AddJournalFlag(fs *pflag.FlagSet, location *string) {
+	fs.StringVar(location, "journal", "", `where operations are recorded, e.g. "s3://<bucket>/<prefix>", "ssm:/<parameter path>" or a local directory (default "`+journal.DefaultDir()+`")`)
+}
+
+// NewInterruptibleContext returns a context that gets cancelled on the first SIGINT or SIGTERM,
+// so that running tasks can stop gracefully; any further signal terminates the process as usual
+func NewInterruptibleContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		defer signal.Stop(signals)
+		select {
+		case <-signals:
+			logger.Warning("interrupted, waiting for running tasks to stop; CloudFormation stacks that are in progress will not be rolled back")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// NewOperation creates an operation for the current cluster config
+func (c *Cmd) NewOperation(kind journal.OperationKind, nodeGroups sets.String) *journal.Operation {
+	return journal.NewOperation(kind, c.ProviderConfig, c.ClusterConfig, nodeGroups)
+}
+
+// StartJournal records progress of tasks run by DoTasks as part of the given operation,
+// so that it can be inspected and resumed later; tasks that the operation already
+// records as done will not run again
+func (c *Cmd) StartJournal(ctl *eks.ClusterProvider, op *journal.Operation) error {
+	store, err := ctl.NewJournalStore(c.JournalLocation)
+	if err != nil {
+		return err
+	}
+	j := journal.New(store, op)
+	if err := j.Start(); err != nil {
+		return err
+	}
+	logger.Debug("recording operation %q", op.ID)
+	c.journal = j
+	return nil
+}
+
+// DoTasks runs all tasks with parallelism limited by --max-parallel, tasks stop once
+// the command is interrupted; progress is recorded when a journal was started
+func (c *Cmd) DoTasks(tasks *manager.TaskTree) manager.TaskResults {
+	if c.journal != nil && !tasks.PlanMode {
+		tasks.Recorder = c.journal
+		tasks.CompletedTasks = c.journal.Operation().CompletedTasks()
+	}
+	return c.doTasks(tasks)
+}
+
+// DoUnrecordedTasks runs tasks like DoTasks, but doesn't record their progress, it's
+// used for tasks that follow an operation but aren't part of it, so resume doesn't run them
+func (c *Cmd) DoUnrecordedTasks(tasks *manager.TaskTree) manager.TaskResults {
+	return c.doTasks(tasks)
+}
+
+func (c *Cmd) doTasks(tasks *manager.TaskTree) manager.TaskResults {
+	tasks.MaxParallel = c.MaxParallel
+
+	ctx, cancel := NewInterruptibleContext()
+	defer cancel()
+
+	results := tasks.DoAllSync(ctx)
+	logger.Debug("completed %s", tasks.Describe())
+
+	if tasks.Recorder != nil {
+		c.finishJournal()
+	}
+	return results
+}
+
+func (c *Cmd) finishJournal() {
+	op := c.journal.Operation()
+	if err := c.journal.Finish(); err != nil {
+		logger.Warning("failed to record result of operation %q: %s", op.ID, err.Error())
+		return
+	}
+	if op.IsComplete() {
+		return
+	}
+	if op.Kind.IsResumable() {
+		logger.Info("to continue from the last incomplete task, run 'eksctl resume %s%s'", op.ID, c.journalFlag())
+	} else {
+		logger.Info("to see progress of this operation, run 'eksctl get operations --cluster=%s%s'", op.Cluster, c.journalFlag())
+	}
+}
+
+func (c *Cmd) journalFlag() string {
+	if c.JournalLocation == "" {
+		return ""
+	}
+	return " --journal=" + c.JournalLocation
+}
@@ -8,10 +8,15 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 
+	"github.com/weaveworks/eksctl/pkg/ami"
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/authconfigmap"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/events"
+	"github.com/weaveworks/eksctl/pkg/journal"
 	"github.com/weaveworks/eksctl/pkg/kops"
+	"github.com/weaveworks/eksctl/pkg/preflight"
 	"github.com/weaveworks/eksctl/pkg/printers"
 	"github.com/weaveworks/eksctl/pkg/utils"
 	"github.com/weaveworks/eksctl/pkg/utils/kubeconfig"
@@ -22,6 +27,7 @@ type createClusterCmdParams struct {
 	writeKubeconfig      bool
 	kubeconfigPath       string
 	autoKubeconfigPath   bool
+	authenticator        string
 	authenticatorRoleARN string
 	setContext           bool
 	availabilityZones    []string
@@ -55,6 +61,9 @@ func createClusterCmd(cmd *cmdutils.Cmd) {
 		cmdutils.AddVersionFlag(fs, cfg.Metadata, "")
 		cmdutils.AddConfigFileFlag(fs, &cmd.ClusterConfigFile)
 		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
+		cmdutils.AddMaxParallelFlag(fs, &cmd.MaxParallel)
+		cmdutils.AddJournalFlag(fs, &cmd.JournalLocation)
+		cmdutils.AddSkipPreflightFlag(fs, &cmd.SkipPreflight)
 	})
 
 	cmd.FlagSetGroup.InFlagSet("Initial nodegroup", func(fs *pflag.FlagSet) {
@@ -80,7 +89,7 @@ func createClusterCmd(cmd *cmdutils.Cmd) {
 	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, true)
 
 	cmd.FlagSetGroup.InFlagSet("Output kubeconfig", func(fs *pflag.FlagSet) {
-		cmdutils.AddCommonFlagsForKubeconfig(fs, &params.kubeconfigPath, &params.authenticatorRoleARN, &params.setContext, &params.autoKubeconfigPath, exampleClusterName)
+		cmdutils.AddCommonFlagsForKubeconfig(fs, &params.kubeconfigPath, &params.authenticator, &params.authenticatorRoleARN, &params.setContext, &params.autoKubeconfigPath, exampleClusterName)
 		fs.BoolVar(&params.writeKubeconfig, "write-kubeconfig", true, "toggle writing of kubeconfig")
 	})
 }
@@ -119,6 +128,10 @@ func doCreateCluster(cmd *cmdutils.Cmd, ng *api.NodeGroup, params *createCluster
 		return err
 	}
 
+	if err := cmdutils.ValidateAuthenticatorFlag(params.authenticator); err != nil {
+		return err
+	}
+
 	if params.autoKubeconfigPath {
 		if params.kubeconfigPath != kubeconfig.DefaultPath {
 			return fmt.Errorf("--kubeconfig and --auto-kubeconfig %s", cmdutils.IncompatibleFlags)
@@ -229,6 +242,8 @@ func doCreateCluster(cmd *cmdutils.Cmd, ng *api.NodeGroup, params *createCluster
 		return err
 	}
 
+	ami.RegisterImageFamilies(cfg.ImageFamilies)
+
 	err = ngFilter.ForEach(cfg.NodeGroups, func(_ int, ng *api.NodeGroup) error {
 		// resolve AMI
 		if err := ctl.EnsureAMI(meta.Version, ng); err != nil {
@@ -264,8 +279,13 @@ func doCreateCluster(cmd *cmdutils.Cmd, ng *api.NodeGroup, params *createCluster
 		return err
 	}
 
+	ngSubset, _ := ngFilter.MatchAll(cfg.NodeGroups)
+
+	if err := cmd.RunPreflightChecks(ctl, preflight.CreateClusterChecks(cfg, ngSubset)); err != nil {
+		return err
+	}
+
 	{ // core action
-		ngSubset, _ := ngFilter.MatchAll(cfg.NodeGroups)
 		stackManager := ctl.NewStackManager(cfg)
 		if ngCount := ngSubset.Len(); ngCount == 1 && cmd.ClusterConfigFile == "" {
 			logger.Info("will create 2 separate CloudFormation stacks for cluster itself and the initial nodegroup")
@@ -277,8 +297,12 @@ func doCreateCluster(cmd *cmdutils.Cmd, ng *api.NodeGroup, params *createCluster
 		tasks := stackManager.NewTasksToCreateClusterWithNodeGroups(ngSubset)
 		ctl.AppendExtraClusterConfigTasks(cfg, tasks)
 
+		if err := cmd.StartJournal(ctl, cmd.NewOperation(journal.CreateCluster, ngSubset)); err != nil {
+			return err
+		}
+
 		logger.Info(tasks.Describe())
-		if errs := tasks.DoAllSync(); len(errs) > 0 {
+		if errs := cmd.DoTasks(tasks).Errors(); len(errs) > 0 {
 			logger.Info("%d error(s) occurred and cluster hasn't been created properly, you may wish to check CloudFormation console", len(errs))
 			logger.Info("to cleanup resources, run 'eksctl delete cluster --region=%s --name=%s'", meta.Region, meta.Name)
 			for _, err := range errs {
@@ -289,6 +313,10 @@ func doCreateCluster(cmd *cmdutils.Cmd, ng *api.NodeGroup, params *createCluster
 	}
 
 	logger.Success("all EKS cluster resource for %q had been created", meta.Name)
+	events.Created(events.ClusterResource, meta.Name, meta.Name)
+	for _, ng := range ngSubset.List() {
+		events.Created(events.NodeGroupResource, meta.Name, ng)
+	}
 
 	// obtain cluster credentials, write kubeconfig
 
@@ -296,7 +324,7 @@ func doCreateCluster(cmd *cmdutils.Cmd, ng *api.NodeGroup, params *createCluster
 		var kubeconfigContextName string
 
 		if params.writeKubeconfig {
-			kubectlConfig := kubeconfig.NewForKubectl(cfg, ctl.GetUsername(), params.authenticatorRoleARN, ctl.Provider.Profile())
+			kubectlConfig := kubeconfig.NewForKubectl(cfg, ctl.GetUsername(), params.authenticator, params.authenticatorRoleARN, ctl.Provider.Profile())
 			kubeconfigContextName = kubectlConfig.CurrentContext
 
 			params.kubeconfigPath, err = kubeconfig.Write(params.kubeconfigPath, *kubectlConfig, params.setContext)
@@ -354,6 +382,20 @@ func doCreateCluster(cmd *cmdutils.Cmd, ng *api.NodeGroup, params *createCluster
 		}
 	}
 
+	if cfg.HasGitOps() {
+		tasks := &manager.TaskTree{Parallel: false}
+		ctl.AppendGitOpsTasks(cfg, tasks)
+		logger.Info(tasks.Describe())
+		// the cluster has been created, so GitOps tasks aren't recorded as part of the operation
+		if errs := cmd.DoUnrecordedTasks(tasks).Errors(); len(errs) > 0 {
+			for _, err := range errs {
+				logger.Critical("%s\n", err.Error())
+			}
+			logger.Info("the cluster was created, to retry run 'eksctl gitops apply --config-file=<file>'")
+			return fmt.Errorf("failed to bootstrap GitOps for cluster %q", meta.Name)
+		}
+	}
+
 	logger.Success("%s is ready", meta.LogString())
 
 	if err := printer.LogObj(logger.Debug, "cfg.json = \\\n%s\n", cfg); err != nil {
@@ -8,6 +8,7 @@ import (
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/authconfigmap"
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/events"
 )
 
 func createIAMIdentityMappingCmd(cmd *cmdutils.Cmd) {
@@ -93,5 +94,9 @@ func doCreateIAMIdentityMapping(cmd *cmdutils.Cmd, id *authconfigmap.MapRole) er
 	if err := acm.AddRole(id.RoleARN, id.Username, id.Groups); err != nil {
 		return err
 	}
-	return acm.Save()
+	if err := acm.Save(); err != nil {
+		return err
+	}
+	events.Created(events.IAMIdentityMappingResource, cfg.Metadata.Name, id.RoleARN)
+	return nil
 }
@@ -7,9 +7,13 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 
+	"github.com/weaveworks/eksctl/pkg/ami"
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/authconfigmap"
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/events"
+	"github.com/weaveworks/eksctl/pkg/journal"
+	"github.com/weaveworks/eksctl/pkg/preflight"
 	"github.com/weaveworks/eksctl/pkg/printers"
 	"github.com/weaveworks/eksctl/pkg/utils"
 )
@@ -39,6 +43,9 @@ func createNodeGroupCmd(cmd *cmdutils.Cmd) {
 		cmdutils.AddNodeGroupFilterFlags(fs, &cmd.Include, &cmd.Exclude)
 		cmdutils.AddUpdateAuthConfigMap(fs, &updateAuthConfigMap, "Remove nodegroup IAM role from aws-auth configmap")
 		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
+		cmdutils.AddMaxParallelFlag(fs, &cmd.MaxParallel)
+		cmdutils.AddJournalFlag(fs, &cmd.JournalLocation)
+		cmdutils.AddSkipPreflightFlag(fs, &cmd.SkipPreflight)
 	})
 
 	cmd.FlagSetGroup.InFlagSet("New nodegroup", func(fs *pflag.FlagSet) {
@@ -93,6 +100,8 @@ func doCreateNodeGroups(cmd *cmdutils.Cmd, updateAuthConfigMap bool) error {
 		return err
 	}
 
+	ami.RegisterImageFamilies(cfg.ImageFamilies)
+
 	err = ngFilter.ForEach(cfg.NodeGroups, func(_ int, ng *api.NodeGroup) error {
 		// resolve AMI
 		if err := ctl.EnsureAMI(meta.Version, ng); err != nil {
@@ -128,6 +137,10 @@ func doCreateNodeGroups(cmd *cmdutils.Cmd, updateAuthConfigMap bool) error {
 	ngSubset, _ := ngFilter.MatchAll(cfg.NodeGroups)
 	ngCount := ngSubset.Len()
 
+	if err := cmd.RunPreflightChecks(ctl, preflight.CreateNodeGroupsChecks(cfg, ngSubset)); err != nil {
+		return err
+	}
+
 	{
 		ngFilter.LogInfo(cfg.NodeGroups)
 		if ngCount > 0 {
@@ -135,8 +148,13 @@ func doCreateNodeGroups(cmd *cmdutils.Cmd, updateAuthConfigMap bool) error {
 		}
 
 		tasks := stackManager.NewTasksToCreateNodeGroups(ngSubset)
+		op := cmd.NewOperation(journal.CreateNodeGroups, ngSubset)
+		op.SkipAuthConfigMap = !updateAuthConfigMap
+		if err := cmd.StartJournal(ctl, op); err != nil {
+			return err
+		}
 		logger.Info(tasks.Describe())
-		errs := tasks.DoAllSync()
+		errs := cmd.DoTasks(tasks).Errors()
 		if len(errs) > 0 {
 			logger.Info("%d error(s) occurred and nodegroups haven't been created properly, you may wish to check CloudFormation console", len(errs))
 			logger.Info("to cleanup resources, run 'eksctl delete nodegroup --region=%s --cluster=%s --name=<name>' for each of the failed nodegroup", cfg.Metadata.Region, cfg.Metadata.Name)
@@ -147,6 +165,9 @@ func doCreateNodeGroups(cmd *cmdutils.Cmd, updateAuthConfigMap bool) error {
 			}
 			return fmt.Errorf("failed to create nodegroups for cluster %q", cfg.Metadata.Name)
 		}
+		for _, name := range ngSubset.List() {
+			events.Created(events.NodeGroupResource, cfg.Metadata.Name, name)
+		}
 	}
 
 	{ // post-creation action
@@ -12,7 +12,11 @@ import (
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/cfn/manager"
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/eks"
 	"github.com/weaveworks/eksctl/pkg/elb"
+	"github.com/weaveworks/eksctl/pkg/events"
+	"github.com/weaveworks/eksctl/pkg/journal"
+	"github.com/weaveworks/eksctl/pkg/preflight"
 	"github.com/weaveworks/eksctl/pkg/printers"
 	"github.com/weaveworks/eksctl/pkg/ssh"
 	"github.com/weaveworks/eksctl/pkg/utils/kubeconfig"
@@ -25,8 +29,10 @@ func deleteClusterCmd(cmd *cmdutils.Cmd) {
 
 	cmd.SetDescription("cluster", "Delete a cluster", "")
 
+	var deleteLogGroup bool
+
 	cmd.SetRunFuncWithNameArg(func() error {
-		return doDeleteCluster(cmd)
+		return doDeleteCluster(cmd, deleteLogGroup)
 	})
 
 	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
@@ -38,6 +44,11 @@ func deleteClusterCmd(cmd *cmdutils.Cmd) {
 
 		cmdutils.AddConfigFileFlag(fs, &cmd.ClusterConfigFile)
 		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
+		cmdutils.AddMaxParallelFlag(fs, &cmd.MaxParallel)
+		cmdutils.AddJournalFlag(fs, &cmd.JournalLocation)
+		cmdutils.AddSkipPreflightFlag(fs, &cmd.SkipPreflight)
+
+		fs.BoolVar(&deleteLogGroup, "delete-log-group", false, "delete CloudWatch log group of the control plane, it is retained by default")
 	})
 
 	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, true)
@@ -51,14 +62,14 @@ func handleErrors(errs []error, subject string) error {
 	return fmt.Errorf("failed to delete %s", subject)
 }
 
-func deleteDeprecatedStacks(stackManager *manager.StackCollection) (bool, error) {
+func deleteDeprecatedStacks(cmd *cmdutils.Cmd, stackManager *manager.StackCollection) (bool, error) {
 	tasks, err := stackManager.DeleteTasksForDeprecatedStacks()
 	if err != nil {
 		return true, err
 	}
 	if count := tasks.Len(); count > 0 {
 		logger.Info(tasks.Describe())
-		if errs := tasks.DoAllSync(); len(errs) > 0 {
+		if errs := cmd.DoTasks(tasks).Errors(); len(errs) > 0 {
 			return true, handleErrors(errs, "deprecated stacks")
 		}
 		logger.Success("deleted all %s deperecated stacks", count)
@@ -67,7 +78,7 @@ func deleteDeprecatedStacks(stackManager *manager.StackCollection) (bool, error)
 	return false, nil
 }
 
-func doDeleteCluster(cmd *cmdutils.Cmd) error {
+func doDeleteCluster(cmd *cmdutils.Cmd, deleteLogGroup bool) error {
 	if err := cmdutils.NewMetadataLoader(cmd).Load(); err != nil {
 		return err
 	}
@@ -87,6 +98,10 @@ func doDeleteCluster(cmd *cmdutils.Cmd) error {
 		return err
 	}
 
+	if err := cmd.RunPreflightChecks(ctl, preflight.DeleteClusterChecks()); err != nil {
+		return err
+	}
+
 	logger.Info("deleting EKS cluster %q", meta.Name)
 	if err := printer.LogObj(logger.Debug, "cfg.json = \\\n%s\n", cfg); err != nil {
 		return err
@@ -98,7 +113,7 @@ func doDeleteCluster(cmd *cmdutils.Cmd) error {
 
 	kubeconfig.MaybeDeleteConfig(meta)
 
-	if hasDeprectatedStacks, err := deleteDeprecatedStacks(stackManager); hasDeprectatedStacks {
+	if hasDeprectatedStacks, err := deleteDeprecatedStacks(cmd, stackManager); hasDeprectatedStacks {
 		if err != nil {
 			return err
 		}
@@ -143,12 +158,26 @@ func doDeleteCluster(cmd *cmdutils.Cmd) error {
 			return nil
 		}
 
+		if err := cmd.StartJournal(ctl, cmd.NewOperation(journal.DeleteCluster, nil)); err != nil {
+			return err
+		}
+
 		logger.Info(tasks.Describe())
-		if errs := tasks.DoAllSync(); len(errs) > 0 {
+		if errs := cmd.DoTasks(tasks).Errors(); len(errs) > 0 {
 			return handleErrors(errs, "cluster with nodegroup(s)")
 		}
 
 		logger.Success("all cluster resources were deleted")
+		events.Deleted(events.ClusterResource, meta.Name, meta.Name)
+	}
+
+	if deleteLogGroup {
+		if err := ctl.DeleteClusterLogGroup(meta); err != nil {
+			return err
+		}
+		events.Deleted(events.LogGroupResource, meta.Name, eks.ClusterLogGroupName(meta.Name))
+	} else {
+		logger.Debug("retaining log group %q, use --delete-log-group to delete it", eks.ClusterLogGroupName(meta.Name))
 	}
 
 	return nil
@@ -7,6 +7,7 @@ import (
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/authconfigmap"
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/events"
 )
 
 func deleteIAMIdentityMappingCmd(cmd *cmdutils.Cmd) {
@@ -78,6 +79,7 @@ func doDeleteIAMIdentityMapping(cmd *cmdutils.Cmd, role string, all bool) error
 	if err := acm.Save(); err != nil {
 		return err
 	}
+	events.Deleted(events.IAMIdentityMappingResource, cfg.Metadata.Name, role)
 
 	// Check whether we have more roles that match
 	roles, err := acm.Roles()
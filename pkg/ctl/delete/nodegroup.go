@@ -9,6 +9,8 @@ import (
 	"github.com/weaveworks/eksctl/pkg/authconfigmap"
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
 	"github.com/weaveworks/eksctl/pkg/drain"
+	"github.com/weaveworks/eksctl/pkg/events"
+	"github.com/weaveworks/eksctl/pkg/journal"
 )
 
 func deleteNodeGroupCmd(cmd *cmdutils.Cmd) {
@@ -17,11 +19,12 @@ func deleteNodeGroupCmd(cmd *cmdutils.Cmd) {
 	cmd.ClusterConfig = cfg
 
 	var updateAuthConfigMap, deleteNodeGroupDrain, onlyMissing bool
+	drainOptions := drain.DefaultOptions()
 
 	cmd.SetDescription("nodegroup", "Delete a nodegroup", "", "ng")
 
 	cmd.SetRunFuncWithNameArg(func() error {
-		return doDeleteNodeGroup(cmd, ng, updateAuthConfigMap, deleteNodeGroupDrain, onlyMissing)
+		return doDeleteNodeGroup(cmd, ng, updateAuthConfigMap, deleteNodeGroupDrain, onlyMissing, drainOptions)
 	})
 
 	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
@@ -34,16 +37,19 @@ func deleteNodeGroupCmd(cmd *cmdutils.Cmd) {
 		fs.BoolVar(&onlyMissing, "only-missing", false, "Only delete nodegroups that are not defined in the given config file")
 		cmdutils.AddUpdateAuthConfigMap(fs, &updateAuthConfigMap, "Remove nodegroup IAM role from aws-auth configmap")
 		fs.BoolVar(&deleteNodeGroupDrain, "drain", true, "Drain and cordon all nodes in the nodegroup before deletion")
+		cmdutils.AddDrainFlags(fs, &drainOptions)
 
 		cmd.Wait = false
 		cmdutils.AddWaitFlag(fs, &cmd.Wait, "deletion of all resources")
 		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
+		cmdutils.AddMaxParallelFlag(fs, &cmd.MaxParallel)
+		cmdutils.AddJournalFlag(fs, &cmd.JournalLocation)
 	})
 
 	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, true)
 }
 
-func doDeleteNodeGroup(cmd *cmdutils.Cmd, ng *api.NodeGroup, updateAuthConfigMap, deleteNodeGroupDrain, onlyMissing bool) error {
+func doDeleteNodeGroup(cmd *cmdutils.Cmd, ng *api.NodeGroup, updateAuthConfigMap, deleteNodeGroupDrain, onlyMissing bool, drainOptions drain.Options) error {
 	ngFilter := cmdutils.NewNodeGroupFilter()
 
 	if err := cmdutils.NewDeleteNodeGroupLoader(cmd, ng, ngFilter).Load(); err != nil {
@@ -113,7 +119,7 @@ func doDeleteNodeGroup(cmd *cmdutils.Cmd, ng *api.NodeGroup, updateAuthConfigMap
 			if cmd.Plan {
 				return nil
 			}
-			if err := drain.NodeGroup(clientSet, ng, ctl.Provider.WaitTimeout(), false); err != nil {
+			if err := drain.NodeGroup(clientSet, ng, ctl.Provider.WaitTimeout(), false, drainOptions); err != nil {
 				return err
 			}
 			return nil
@@ -131,11 +137,21 @@ func doDeleteNodeGroup(cmd *cmdutils.Cmd, ng *api.NodeGroup, updateAuthConfigMap
 			return err
 		}
 		tasks.PlanMode = cmd.Plan
+		if !cmd.Plan {
+			if err := cmd.StartJournal(ctl, cmd.NewOperation(journal.DeleteNodeGroups, ngSubset)); err != nil {
+				return err
+			}
+		}
 		logger.Info(tasks.Describe())
-		if errs := tasks.DoAllSync(); len(errs) > 0 {
+		if errs := cmd.DoTasks(tasks).Errors(); len(errs) > 0 {
 			return handleErrors(errs, "nodegroup(s)")
 		}
 		cmdutils.LogCompletedAction(cmd.Plan, "deleted %d nodegroups from cluster %q", ngCount, cfg.Metadata.Name)
+		if !cmd.Plan {
+			for _, name := range ngSubset.List() {
+				events.Deleted(events.NodeGroupResource, cfg.Metadata.Name, name)
+			}
+		}
 	}
 
 	cmdutils.LogPlanModeWarning(cmd.Plan && ngCount > 0)
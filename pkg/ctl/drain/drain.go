@@ -11,6 +11,7 @@ func Command(flagGrouping *cmdutils.FlagGrouping) *cobra.Command {
 	verbCmd := cmdutils.NewVerbCmd("drain", "Drain resource(s)", "")
 
 	cmdutils.AddResourceCmd(flagGrouping, verbCmd, drainNodeGroupCmd)
+	cmdutils.AddResourceCmd(flagGrouping, verbCmd, drainNodeCmd)
 
 	return verbCmd
 }
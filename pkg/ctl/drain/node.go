@@ -0,0 +1,96 @@
+package drain
+
+import (
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/drain"
+	"github.com/weaveworks/eksctl/pkg/events"
+)
+
+func drainNodeCmd(cmd *cmdutils.Cmd) {
+	cfg := api.NewClusterConfig()
+	cmd.ClusterConfig = cfg
+
+	var (
+		nodeName string
+		undo     bool
+	)
+	drainOptions := drain.DefaultOptions()
+
+	cmd.SetDescription("node", "Cordon and drain a single node", "")
+
+	cmd.SetRunFuncWithNameArg(func() error {
+		return doDrainNode(cmd, nodeName, undo, drainOptions)
+	})
+
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		fs.StringVar(&cfg.Metadata.Name, "cluster", "", "EKS cluster name")
+		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
+		fs.StringVarP(&nodeName, "name", "n", "", "Name of the node to drain")
+		cmdutils.AddApproveFlag(fs, cmd)
+		fs.BoolVar(&undo, "undo", false, "Uncordon the node")
+		cmdutils.AddNodeDrainFlags(fs, &drainOptions)
+		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
+	})
+
+	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
+}
+
+func doDrainNode(cmd *cmdutils.Cmd, nodeName string, undo bool, drainOptions drain.Options) error {
+	cfg := cmd.ClusterConfig
+
+	if cfg.Metadata.Name == "" {
+		return cmdutils.ErrMustBeSet("--cluster")
+	}
+
+	if nodeName != "" && cmd.NameArg != "" {
+		return cmdutils.ErrNameFlagAndArg(nodeName, cmd.NameArg)
+	}
+
+	if cmd.NameArg != "" {
+		nodeName = cmd.NameArg
+	}
+
+	if nodeName == "" {
+		return cmdutils.ErrMustBeSet("--name")
+	}
+
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return err
+	}
+	logger.Info("using region %s", cfg.Metadata.Region)
+
+	if err := ctl.CheckAuth(); err != nil {
+		return err
+	}
+
+	if err := ctl.RefreshClusterConfig(cfg); err != nil {
+		return errors.Wrapf(err, "getting credentials for cluster %q", cfg.Metadata.Name)
+	}
+
+	clientSet, err := ctl.NewStdClientSet(cfg)
+	if err != nil {
+		return err
+	}
+
+	verb := "drain"
+	if undo {
+		verb = "uncordon"
+	}
+	cmdutils.LogIntendedAction(cmd.Plan, "%s node %q in cluster %q", verb, nodeName, cfg.Metadata.Name)
+	cmdutils.LogPlanModeWarning(cmd.Plan)
+	if cmd.Plan {
+		return nil
+	}
+
+	if err := drain.Node(clientSet, nodeName, ctl.Provider.WaitTimeout(), undo, drainOptions); err != nil {
+		return err
+	}
+	events.Updated(events.NodeResource, cfg.Metadata.Name, nodeName)
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
 
 	"github.com/weaveworks/eksctl/pkg/drain"
+	"github.com/weaveworks/eksctl/pkg/events"
 )
 
 func drainNodeGroupCmd(cmd *cmdutils.Cmd) {
@@ -17,11 +18,12 @@ func drainNodeGroupCmd(cmd *cmdutils.Cmd) {
 	cmd.ClusterConfig = cfg
 
 	var undo, onlyMissing bool
+	drainOptions := drain.DefaultOptions()
 
 	cmd.SetDescription("nodegroup", "Cordon and drain a nodegroup", "", "ng")
 
 	cmd.SetRunFuncWithNameArg(func() error {
-		return doDrainNodeGroup(cmd, ng, undo, onlyMissing)
+		return doDrainNodeGroup(cmd, ng, undo, onlyMissing, drainOptions)
 	})
 
 	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
@@ -33,13 +35,14 @@ func drainNodeGroupCmd(cmd *cmdutils.Cmd) {
 		cmdutils.AddNodeGroupFilterFlags(fs, &cmd.Include, &cmd.Exclude)
 		fs.BoolVar(&onlyMissing, "only-missing", false, "Only drain nodegroups that are not defined in the given config file")
 		fs.BoolVar(&undo, "undo", false, "Uncordone the nodegroup")
+		cmdutils.AddDrainFlags(fs, &drainOptions)
 		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
 	})
 
 	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, true)
 }
 
-func doDrainNodeGroup(cmd *cmdutils.Cmd, ng *api.NodeGroup, undo, onlyMissing bool) error {
+func doDrainNodeGroup(cmd *cmdutils.Cmd, ng *api.NodeGroup, undo, onlyMissing bool, drainOptions drain.Options) error {
 	ngFilter := cmdutils.NewNodeGroupFilter()
 
 	if err := cmdutils.NewDeleteNodeGroupLoader(cmd, ng, ngFilter).Load(); err != nil {
@@ -92,9 +95,10 @@ func doDrainNodeGroup(cmd *cmdutils.Cmd, ng *api.NodeGroup, undo, onlyMissing bo
 		if cmd.Plan {
 			return nil
 		}
-		if err := drain.NodeGroup(clientSet, ng, ctl.Provider.WaitTimeout(), undo); err != nil {
+		if err := drain.NodeGroup(clientSet, ng, ctl.Provider.WaitTimeout(), undo, drainOptions); err != nil {
 			return err
 		}
+		events.Updated(events.NodeGroupResource, cfg.Metadata.Name, ng.Name)
 		return nil
 	})
 }
@@ -3,6 +3,7 @@ package generate
 import (
 	"context"
 
+	"github.com/kris-nova/logger"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
@@ -12,6 +13,7 @@ import (
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
 	"github.com/weaveworks/eksctl/pkg/git"
 	"github.com/weaveworks/eksctl/pkg/gitops"
+	"github.com/weaveworks/eksctl/pkg/gitops/catalog"
 	"github.com/weaveworks/eksctl/pkg/gitops/fileprocessor"
 )
 
@@ -19,6 +21,9 @@ type options struct {
 	GitOptions        git.Options
 	ProfilePath       string
 	PrivateSSHKeyPath string
+	ValuesFile        string
+	Catalog           string
+	LoadCluster       bool
 }
 
 func generateProfileCmd(cmd *cmdutils.Cmd) {
@@ -34,9 +39,12 @@ func generateProfileCmd(cmd *cmdutils.Cmd) {
 	})
 
 	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
-		fs.StringVarP(&o.GitOptions.URL, "git-url", "", "", "URL for the quickstart base repository")
-		fs.StringVarP(&o.GitOptions.Branch, "git-branch", "", "master", "Git branch")
+		fs.StringVarP(&o.GitOptions.URL, "git-url", "", "", "URL for the quickstart base repository, or the name of a profile of the catalog, optionally followed by @version")
+		fs.StringVarP(&o.GitOptions.Branch, "git-branch", "", "", "Git branch or tag (default: master, or the version of the profile)")
 		fs.StringVarP(&o.ProfilePath, "profile-path", "", "./", "Path to generate the profile in")
+		fs.StringVar(&o.Catalog, "catalog", "", "Path or URL of the profiles catalog (default: the catalog of the Quick Start profiles)")
+		fs.StringVar(&o.ValuesFile, "values", "", "Path to a YAML file whose values are available to the templates as .Values")
+		fs.BoolVar(&o.LoadCluster, "load-cluster", false, "Fail unless the cluster can be loaded for the templates' .ClusterConfig (default: load it only when it exists)")
 		_ = cobra.MarkFlagRequired(fs, "git-url")
 
 		cmdutils.AddNameFlag(fs, cfg.Metadata)
@@ -52,12 +60,33 @@ func doGenerateProfile(cmd *cmdutils.Cmd, o options) error {
 		return err
 	}
 
+	profilesCatalog, err := catalog.Load(o.Catalog)
+	if err != nil {
+		return err
+	}
+	ref, err := profilesCatalog.Resolve(o.GitOptions.URL, o.GitOptions.Branch)
+	if err != nil {
+		return err
+	}
+	values, err := fileprocessor.ReadValuesFile(o.ValuesFile)
+	if err != nil {
+		return err
+	}
+	if err := ref.CheckParameters(values); err != nil {
+		return err
+	}
+
 	// TODO move the load of the region outside of the creation of the EKS client
 	// currently that is done inside cmd.NewCtl() but we don't need EKS here
 	cmd.ClusterConfig.Metadata.Region = cmd.ProviderConfig.Region
 
+	cfg, err := loadClusterConfigForProfile(cmd, o.LoadCluster)
+	if err != nil {
+		return err
+	}
+
 	processor := &fileprocessor.GoTemplateProcessor{
-		Params: fileprocessor.NewTemplateParameters(cmd.ClusterConfig),
+		Params: fileprocessor.NewTemplateParameters(cfg, values),
 	}
 	profile := &gitops.Profile{
 		Processor: processor,
@@ -67,11 +96,12 @@ func doGenerateProfile(cmd *cmdutils.Cmd, o options) error {
 			Timeout:           git.DefaultGitTimeout,
 			PrivateSSHKeyPath: o.PrivateSSHKeyPath,
 		}),
-		FS: afero.NewOsFs(),
-		IO: afero.Afero{Fs: afero.NewOsFs()},
+		FS:      afero.NewOsFs(),
+		IO:      afero.Afero{Fs: afero.NewOsFs()},
+		Catalog: profilesCatalog,
 	}
 
-	err := profile.Generate(context.Background())
+	err = profile.Generate(context.Background())
 	if err != nil {
 		return errors.Wrap(err, "error generating profile")
 	}
@@ -79,3 +109,34 @@ func doGenerateProfile(cmd *cmdutils.Cmd, o options) error {
 	profile.DeleteClonedDirectory()
 	return nil
 }
+
+// loadClusterConfigForProfile loads the whole ClusterConfig from the cluster when it exists, or fails
+// when it's required; otherwise the templates only get the name and the region of the cluster, which
+// doesn't need AWS credentials
+func loadClusterConfigForProfile(cmd *cmdutils.Cmd, required bool) (*api.ClusterConfig, error) {
+	if cmd.ClusterConfig.Metadata.Name == "" {
+		if required {
+			return nil, cmdutils.ErrMustBeSet("--name")
+		}
+		return cmd.ClusterConfig, nil
+	}
+
+	cfg, err := func() (*api.ClusterConfig, error) {
+		ctl, err := cmd.NewCtl()
+		if err != nil {
+			return nil, err
+		}
+		if err := ctl.CheckAuth(); err != nil {
+			return nil, err
+		}
+		return ctl.LoadClusterConfigForProfiles(cmd.ClusterConfig)
+	}()
+	if err != nil {
+		if required {
+			return nil, errors.Wrapf(err, "loading cluster %q", cmd.ClusterConfig.Metadata.Name)
+		}
+		logger.Info("not loading cluster %q (%s), the templates only have access to its name and region", cmd.ClusterConfig.Metadata.Name, err)
+		return cmd.ClusterConfig, nil
+	}
+	return cfg, nil
+}
@@ -0,0 +1,159 @@
+package get
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/capacity"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/printers"
+)
+
+func getCapacityCmd(cmd *cmdutils.Cmd) {
+	cfg := api.NewClusterConfig()
+	ng := api.NewNodeGroup()
+	cmd.ClusterConfig = cfg
+
+	params := &getCmdParams{}
+
+	cmd.SetDescription("capacity", "Get allocatable and requested capacity of nodegroups and their estimated cost", "")
+
+	cmd.SetRunFuncWithNameArg(func() error {
+		return doGetCapacity(cmd, ng, params)
+	})
+
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		fs.StringVar(&cfg.Metadata.Name, "cluster", "", "EKS cluster name")
+		fs.StringVarP(&ng.Name, "name", "n", "", "only show the given nodegroup")
+		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
+		cmdutils.AddCommonFlagsForGetCmd(fs, &params.chunkSize, &params.output)
+		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
+	})
+
+	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
+}
+
+func doGetCapacity(cmd *cmdutils.Cmd, ng *api.NodeGroup, params *getCmdParams) error {
+	cfg := cmd.ClusterConfig
+
+	if cfg.Metadata.Name == "" {
+		return cmdutils.ErrMustBeSet("--cluster")
+	}
+
+	if ng.Name != "" && cmd.NameArg != "" {
+		return cmdutils.ErrNameFlagAndArg(ng.Name, cmd.NameArg)
+	}
+
+	if cmd.NameArg != "" {
+		ng.Name = cmd.NameArg
+	}
+
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return err
+	}
+
+	if err := ctl.CheckAuth(); err != nil {
+		return err
+	}
+
+	if err := ctl.RefreshClusterConfig(cfg); err != nil {
+		return err
+	}
+
+	stackManager := ctl.NewStackManager(cfg)
+	summaries, err := stackManager.GetNodeGroupSummaries(ng.Name)
+	if err != nil {
+		return errors.Wrap(err, "getting nodegroup stack summaries")
+	}
+
+	distributions := make(map[string]*api.NodeGroupInstancesDistribution)
+	for _, summary := range summaries {
+		distribution, err := stackManager.GetNodeGroupInstancesDistribution(summary.StackName)
+		if err != nil {
+			return err
+		}
+		distributions[summary.Name] = distribution
+	}
+
+	clientSet, err := ctl.NewStdClientSet(cfg)
+	if err != nil {
+		return err
+	}
+
+	collector := &capacity.Collector{
+		ClientSet: clientSet,
+		Pricer:    capacity.NewRegionPricer(ctl.Provider.EC2(), cfg.Metadata.Region),
+	}
+	reports, err := collector.NodeGroups(summaries, distributions)
+	if err != nil {
+		return err
+	}
+
+	printer, err := printers.NewPrinter(params.output)
+	if err != nil {
+		return err
+	}
+
+	if params.output == "table" {
+		addCapacityTableColumns(printer.(*printers.TablePrinter))
+	}
+
+	if err := printer.PrintObjWithKind("nodegroups", reports, os.Stdout); err != nil {
+		return err
+	}
+
+	if params.output == "table" {
+		logger.Info("costs are estimated from on-demand prices that were current when eksctl was released and current spot prices")
+	}
+	return nil
+}
+
+func addCapacityTableColumns(printer *printers.TablePrinter) {
+	printer.AddColumn("CLUSTER", func(c *capacity.NodeGroupCapacity) string {
+		return c.Cluster
+	})
+	printer.AddColumn("NODEGROUP", func(c *capacity.NodeGroupCapacity) string {
+		return c.Name
+	})
+	printer.AddColumn("INSTANCE TYPES", func(c *capacity.NodeGroupCapacity) string {
+		return strings.Join(c.InstanceTypes, ",")
+	})
+	printer.AddColumn("NODES (ON-DEMAND/SPOT)", func(c *capacity.NodeGroupCapacity) string {
+		return fmt.Sprintf("%d (%d/%d)", c.Nodes, c.OnDemandNodes, c.SpotNodes)
+	})
+	printer.AddColumn("CPU (REQUESTED/ALLOCATABLE)", func(c *capacity.NodeGroupCapacity) string {
+		return fmt.Sprintf("%s/%s", formatCPU(c.RequestedCPU), formatCPU(c.AllocatableCPU))
+	})
+	printer.AddColumn("MEMORY (REQUESTED/ALLOCATABLE)", func(c *capacity.NodeGroupCapacity) string {
+		return fmt.Sprintf("%s/%s", formatMemory(c.RequestedMemory), formatMemory(c.AllocatableMemory))
+	})
+	printer.AddColumn("PODS (SCHEDULED/MAX)", func(c *capacity.NodeGroupCapacity) string {
+		return fmt.Sprintf("%d/%d", c.Pods, c.MaxPods)
+	})
+	printer.AddColumn("POD HEADROOM", func(c *capacity.NodeGroupCapacity) string {
+		return strconv.Itoa(c.PodHeadroom())
+	})
+	printer.AddColumn("HOURLY COST (USD)", func(c *capacity.NodeGroupCapacity) string {
+		if c.HourlyCost == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.3f", *c.HourlyCost)
+	})
+}
+
+func formatCPU(q resource.Quantity) string {
+	return fmt.Sprintf("%.2f", float64(q.MilliValue())/1000)
+}
+
+func formatMemory(q resource.Quantity) string {
+	return fmt.Sprintf("%.1fGi", float64(q.Value())/(1<<30))
+}
@@ -18,6 +18,9 @@ func Command(flagGrouping *cmdutils.FlagGrouping) *cobra.Command {
 	cmdutils.AddResourceCmd(flagGrouping, verbCmd, getClusterCmd)
 	cmdutils.AddResourceCmd(flagGrouping, verbCmd, getNodeGroupCmd)
 	cmdutils.AddResourceCmd(flagGrouping, verbCmd, getIAMIdentityMappingCmd)
+	cmdutils.AddResourceCmd(flagGrouping, verbCmd, getOperationsCmd)
+	cmdutils.AddResourceCmd(flagGrouping, verbCmd, getCapacityCmd)
+	cmdutils.AddResourceCmd(flagGrouping, verbCmd, getProfilesCmd)
 
 	return verbCmd
 }
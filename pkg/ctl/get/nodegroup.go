@@ -5,12 +5,14 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/kris-nova/logger"
 	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/cfn/manager"
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/eks"
 	"github.com/weaveworks/eksctl/pkg/printers"
 )
 
@@ -20,11 +22,12 @@ func getNodeGroupCmd(cmd *cmdutils.Cmd) {
 	cmd.ClusterConfig = cfg
 
 	params := &getCmdParams{}
+	var showAMIStatus bool
 
 	cmd.SetDescription("nodegroup", "Get nodegroup(s)", "", "ng", "nodegroups")
 
 	cmd.SetRunFuncWithNameArg(func() error {
-		return doGetNodeGroup(cmd, ng, params)
+		return doGetNodeGroup(cmd, ng, params, showAMIStatus)
 	})
 
 	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
@@ -32,13 +35,14 @@ func getNodeGroupCmd(cmd *cmdutils.Cmd) {
 		fs.StringVarP(&ng.Name, "name", "n", "", "Name of the nodegroup")
 		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
 		cmdutils.AddCommonFlagsForGetCmd(fs, &params.chunkSize, &params.output)
+		fs.BoolVar(&showAMIStatus, "show-ami-status", false, "compare the AMI of each nodegroup with the latest AMI for its image family, Kubernetes version and instance type")
 		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
 	})
 
 	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
 }
 
-func doGetNodeGroup(cmd *cmdutils.Cmd, ng *api.NodeGroup, params *getCmdParams) error {
+func doGetNodeGroup(cmd *cmdutils.Cmd, ng *api.NodeGroup, params *getCmdParams, showAMIStatus bool) error {
 	cfg := cmd.ClusterConfig
 
 	// TODO: move this into a loader when --config-file gets added to this command
@@ -79,6 +83,10 @@ func doGetNodeGroup(cmd *cmdutils.Cmd, ng *api.NodeGroup, params *getCmdParams)
 		return err
 	}
 
+	if showAMIStatus {
+		return printAMIStatus(ctl, cfg, manager, summaries, printer, params.output)
+	}
+
 	if params.output == "table" {
 		addSummaryTableColumns(printer.(*printers.TablePrinter))
 	}
@@ -116,3 +124,61 @@ func addSummaryTableColumns(printer *printers.TablePrinter) {
 		return s.ImageID
 	})
 }
+
+func printAMIStatus(ctl *eks.ClusterProvider, cfg *api.ClusterConfig, stackManager *manager.StackCollection, summaries []*manager.NodeGroupSummary, printer printers.OutputPrinter, output string) error {
+	statuses := []*eks.NodeGroupAMIStatus{}
+	behind, unknown := 0, 0
+	for _, summary := range summaries {
+		status, err := ctl.GetNodeGroupAMIStatus(stackManager, summary)
+		if err != nil {
+			return err
+		}
+		if status.Unknown {
+			unknown++
+		}
+		if !status.UpToDate() {
+			behind++
+		}
+		statuses = append(statuses, status)
+	}
+
+	if output == "table" {
+		addAMIStatusTableColumns(printer.(*printers.TablePrinter))
+	}
+
+	if err := printer.PrintObjWithKind("nodegroups", statuses, os.Stdout); err != nil {
+		return err
+	}
+
+	if unknown > 0 {
+		logger.Info("the latest AMI of %d nodegroup(s) in cluster %q is unknown, as they use custom image families or AMIs of an unknown Kubernetes version", unknown, cfg.Metadata.Name)
+	}
+	if behind > 0 {
+		logger.Warning("%d nodegroup(s) in cluster %q are not using the latest AMI for their Kubernetes version, use 'eksctl update nodegroup' to update them", behind, cfg.Metadata.Name)
+	}
+	return nil
+}
+
+func addAMIStatusTableColumns(printer *printers.TablePrinter) {
+	printer.AddColumn("CLUSTER", func(s *eks.NodeGroupAMIStatus) string {
+		return s.Cluster
+	})
+	printer.AddColumn("NODEGROUP", func(s *eks.NodeGroupAMIStatus) string {
+		return s.NodeGroup
+	})
+	printer.AddColumn("AMI FAMILY", func(s *eks.NodeGroupAMIStatus) string {
+		return s.AMIFamily
+	})
+	printer.AddColumn("VERSION", func(s *eks.NodeGroupAMIStatus) string {
+		return s.KubernetesVersion
+	})
+	printer.AddColumn("IMAGE ID", func(s *eks.NodeGroupAMIStatus) string {
+		return s.CurrentImageID
+	})
+	printer.AddColumn("LATEST IMAGE ID", func(s *eks.NodeGroupAMIStatus) string {
+		return s.LatestImageID
+	})
+	printer.AddColumn("STATUS", func(s *eks.NodeGroupAMIStatus) string {
+		return s.String()
+	})
+}
@@ -0,0 +1,151 @@
+package get
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/journal"
+	"github.com/weaveworks/eksctl/pkg/printers"
+)
+
+func getOperationsCmd(cmd *cmdutils.Cmd) {
+	cfg := api.NewClusterConfig()
+	cmd.ClusterConfig = cfg
+
+	params := &getCmdParams{}
+
+	cmd.SetDescription("operations", "Get operations recorded by create and delete commands", "", "operation", "ops")
+
+	cmd.SetRunFuncWithNameArg(func() error {
+		return doGetOperations(cmd, params)
+	})
+
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		fs.StringVar(&cfg.Metadata.Name, "cluster", "", "only show operations of the given EKS cluster")
+		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
+		cmdutils.AddJournalFlag(fs, &cmd.JournalLocation)
+		cmdutils.AddCommonFlagsForGetCmd(fs, &params.chunkSize, &params.output)
+	})
+
+	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
+}
+
+func doGetOperations(cmd *cmdutils.Cmd, params *getCmdParams) error {
+	cfg := cmd.ClusterConfig
+
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return err
+	}
+
+	store, err := ctl.NewJournalStore(cmd.JournalLocation)
+	if err != nil {
+		return err
+	}
+
+	if cmd.NameArg != "" {
+		op, err := store.Load(cmd.NameArg)
+		if err != nil {
+			return err
+		}
+		return printOperations(params.output, []*journal.Operation{op}, true)
+	}
+
+	ops, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	matching := []*journal.Operation{}
+	for _, op := range ops {
+		if cfg.Metadata.Name == "" || op.Cluster == cfg.Metadata.Name {
+			matching = append(matching, op)
+		}
+	}
+
+	return printOperations(params.output, matching, false)
+}
+
+func printOperations(output string, ops []*journal.Operation, withTasks bool) error {
+	printer, err := printers.NewPrinter(output)
+	if err != nil {
+		return err
+	}
+
+	if output == "table" {
+		addOperationTableColumns(printer.(*printers.TablePrinter))
+	}
+
+	if err := printer.PrintObjWithKind("operations", ops, os.Stdout); err != nil {
+		return err
+	}
+
+	if output == "table" && withTasks {
+		// tasks of a single operation are shown as a separate table
+		fmt.Println()
+		tasksPrinter := printers.NewTablePrinter().(*printers.TablePrinter)
+		addTaskTableColumns(tasksPrinter)
+		return tasksPrinter.PrintObjWithKind("tasks", ops[0].Tasks, os.Stdout)
+	}
+	return nil
+}
+
+func addOperationTableColumns(printer *printers.TablePrinter) {
+	printer.AddColumn("ID", func(op *journal.Operation) string {
+		return op.ID
+	})
+	printer.AddColumn("CLUSTER", func(op *journal.Operation) string {
+		return op.Cluster
+	})
+	printer.AddColumn("REGION", func(op *journal.Operation) string {
+		return op.Region
+	})
+	printer.AddColumn("KIND", func(op *journal.Operation) string {
+		return string(op.Kind)
+	})
+	printer.AddColumn("STATE", func(op *journal.Operation) string {
+		return string(op.State)
+	})
+	printer.AddColumn("STARTED", func(op *journal.Operation) string {
+		return op.StartTime.Format(time.RFC3339)
+	})
+	printer.AddColumn("TASKS DONE", func(op *journal.Operation) string {
+		return fmt.Sprintf("%d/%d", len(op.Tasks)-len(op.IncompleteTasks()), len(op.Tasks))
+	})
+}
+
+func addTaskTableColumns(printer *printers.TablePrinter) {
+	printer.AddColumn("TASK", func(task *journal.TaskRecord) string {
+		return task.Description
+	})
+	printer.AddColumn("STATE", func(task *journal.TaskRecord) string {
+		return string(task.State)
+	})
+	printer.AddColumn("STACK", func(task *journal.TaskRecord) string {
+		return task.StackName
+	})
+	printer.AddColumn("STARTED", func(task *journal.TaskRecord) string {
+		if task.StartTime == nil {
+			return "-"
+		}
+		return task.StartTime.Format(time.RFC3339)
+	})
+	printer.AddColumn("ENDED", func(task *journal.TaskRecord) string {
+		if task.EndTime == nil {
+			return "-"
+		}
+		return task.EndTime.Format(time.RFC3339)
+	})
+	printer.AddColumn("ERROR", func(task *journal.TaskRecord) string {
+		if task.State == manager.TaskDone {
+			return ""
+		}
+		return task.Error
+	})
+}
@@ -0,0 +1,83 @@
+package get
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/gitops/catalog"
+	"github.com/weaveworks/eksctl/pkg/printers"
+)
+
+func getProfilesCmd(cmd *cmdutils.Cmd) {
+	cmd.ClusterConfig = api.NewClusterConfig()
+
+	var (
+		location string
+		output   string
+	)
+
+	cmd.SetDescription("profiles", "Get the GitOps profiles of the catalog", "", "profile")
+
+	cmd.SetRunFuncWithNameArg(func() error {
+		return doGetProfiles(cmd.NameArg, location, output)
+	})
+
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		fs.StringVar(&location, "catalog", "", "Path or URL of the profiles catalog (default: the catalog of the Quick Start profiles)")
+		fs.StringVarP(&output, "output", "o", "table", "specifies the output format (valid option: table, json, yaml)")
+	})
+}
+
+func doGetProfiles(name, location, output string) error {
+	profilesCatalog, err := catalog.Load(location)
+	if err != nil {
+		return err
+	}
+
+	profiles := profilesCatalog.Profiles
+	if name != "" {
+		entry := profilesCatalog.Find(name)
+		if entry == nil {
+			return fmt.Errorf("profile %q not found in the catalog", name)
+		}
+		profiles = []catalog.Entry{*entry}
+	}
+
+	printer, err := printers.NewPrinter(output)
+	if err != nil {
+		return err
+	}
+	if output == "table" {
+		addProfileTableColumns(printer.(*printers.TablePrinter))
+	}
+
+	return printer.PrintObjWithKind("profiles", profiles, os.Stdout)
+}
+
+func addProfileTableColumns(printer *printers.TablePrinter) {
+	printer.AddColumn("NAME", func(p catalog.Entry) string {
+		return p.Name
+	})
+	printer.AddColumn("VERSIONS", func(p catalog.Entry) string {
+		return strings.Join(p.Versions, ",")
+	})
+	printer.AddColumn("PARAMETERS", func(p catalog.Entry) string {
+		var params []string
+		for _, param := range p.Parameters {
+			if param.Required {
+				params = append(params, param.Name+" (required)")
+			} else {
+				params = append(params, param.Name)
+			}
+		}
+		return strings.Join(params, ",")
+	})
+	printer.AddColumn("DESCRIPTION", func(p catalog.Entry) string {
+		return p.Description
+	})
+}
@@ -0,0 +1,91 @@
+package gettoken
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kris-nova/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/utils/kubeconfig"
+)
+
+// Command will create the hidden `get-token` command, which is used
+// as an exec credential plugin in kubeconfig
+func Command(flagGrouping *cmdutils.FlagGrouping) *cobra.Command {
+	return cmdutils.NewStandaloneCmd(flagGrouping, getTokenCmd)
+}
+
+func getTokenCmd(cmd *cmdutils.Cmd) {
+	cfg := api.NewClusterConfig()
+	cmd.ClusterConfig = cfg
+
+	var (
+		roleARN string
+		noCache bool
+	)
+
+	cmd.SetDescription("get-token", "Get a token for authenticating with a cluster", "Output an ExecCredential object with a token for kubectl, this is used in kubeconfig when no other authenticator is available")
+	cmd.CobraCommand.Hidden = true
+
+	cmd.SetRunFunc(func() error {
+		return doGetToken(cmd, roleARN, noCache)
+	})
+
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		fs.StringVar(&cfg.Metadata.Name, "cluster", "", "EKS cluster name")
+		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
+		fs.StringVar(&roleARN, "role-arn", "", "AWS IAM role to assume")
+		fs.BoolVar(&noCache, "no-cache", false, "don't use tokens cached on disk")
+	})
+
+	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
+}
+
+func doGetToken(cmd *cmdutils.Cmd, roleARN string, noCache bool) error {
+	cfg := cmd.ClusterConfig
+
+	if cfg.Metadata.Name == "" {
+		return cmdutils.ErrMustBeSet("--cluster")
+	}
+
+	// anything written to stdout is treated as part of the credential, so this
+	// command mustn't log anything unless it fails
+	cachePath := kubeconfig.TokenCachePath(cfg.Metadata.Name, cmd.ProviderConfig.Region, roleARN, effectiveProfile(cmd.ProviderConfig.Profile))
+	if !noCache {
+		if data, ok := kubeconfig.ReadCachedExecCredential(cachePath, time.Now()); ok {
+			fmt.Println(string(data))
+			return nil
+		}
+	}
+
+	ctl := eks.New(cmd.ProviderConfig, cfg)
+
+	data, err := ctl.GetExecCredential(cfg.Metadata.Name, roleARN)
+	if err != nil {
+		return err
+	}
+
+	if !noCache {
+		if err := kubeconfig.WriteCachedExecCredential(cachePath, data); err != nil {
+			logger.Debug("ignoring error while caching token: %s", err.Error())
+		}
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+// effectiveProfile returns the AWS profile the credentials are loaded from, the kubeconfig
+// generated by eksctl sets it with AWS_PROFILE rather than with --profile
+func effectiveProfile(profile string) string {
+	if profile != "" {
+		return profile
+	}
+	return os.Getenv("AWS_PROFILE")
+}
@@ -2,30 +2,24 @@ package gitops
 
 import (
 	"context"
-	"fmt"
-	"path/filepath"
 
 	"github.com/pkg/errors"
-	"github.com/spf13/afero"
-	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	kubeclient "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
-	"github.com/weaveworks/eksctl/pkg/git"
 	"github.com/weaveworks/eksctl/pkg/gitops"
-	"github.com/weaveworks/eksctl/pkg/gitops/fileprocessor"
-	"github.com/weaveworks/eksctl/pkg/gitops/flux"
+	"github.com/weaveworks/eksctl/pkg/gitops/catalog"
 	"github.com/weaveworks/eksctl/pkg/utils/file"
 )
 
 type options struct {
-	gitOptions           git.Options
-	quickstartNameArg    string
-	outputPath           string
-	gitPrivateSSHKeyPath string
+	quickstartNameArg string
+	valuesFile        string
+	catalog           string
+	outputPath        string
 }
 
 func applyGitops(cmd *cmdutils.Cmd) {
@@ -35,24 +29,24 @@ func applyGitops(cmd *cmdutils.Cmd) {
 	cmd.SetDescription("apply", "Setting up GitOps and apply a Quick Start profile", "")
 
 	var opts options
+	git := api.NewGit()
 
 	cmd.SetRunFuncWithNameArg(func() error {
-		return doApplyGitops(cmd, opts)
+		return doApplyGitops(cmd, git, opts)
 	})
 
 	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
-		fs.StringVarP(&opts.quickstartNameArg, "quickstart-profile", "", "", "name or URL of the Quick Start profile. For example, app-dev.")
-		fs.StringVarP(&opts.gitOptions.URL, "git-url", "", "", "URL for the git repository that will contain the cluster components")
-		fs.StringVarP(&opts.gitOptions.Branch, "git-branch", "", "master", "Git branch")
+		fs.StringVarP(&opts.quickstartNameArg, "quickstart-profile", "", "", "name or URL of the Quick Start profile, optionally followed by @version. For example, app-dev or app-dev@v0.1.0.")
+		fs.StringVarP(&git.Repo.URL, "git-url", "", "", "URL for the git repository that will contain the cluster components")
+		fs.StringVarP(&git.Repo.Branch, "git-branch", "", "master", "Git branch")
+		fs.StringVar(&opts.valuesFile, "values", "", "Path to a YAML file whose values are available to the Quick Start profile's templates as .Values")
+		fs.StringVar(&opts.catalog, "catalog", "", "Path or URL of the profiles catalog, overrides git.catalog of the config file (default: the catalog of the Quick Start profiles)")
 		fs.StringVarP(&opts.outputPath, "output-path", "", "./", "Path to directory where the GitOps repo will be cloned")
-		fs.StringVar(&opts.gitOptions.User, "git-user", "Flux", "Username to use as Git committer")
-		fs.StringVar(&opts.gitOptions.Email, "git-email", "", "Email to use as Git committer")
-		fs.StringVar(&opts.gitPrivateSSHKeyPath, "git-private-ssh-key-path", "",
+		fs.StringVar(&git.Repo.User, "git-user", "Flux", "Username to use as Git committer")
+		fs.StringVar(&git.Repo.Email, "git-email", "", "Email to use as Git committer (required)")
+		fs.StringVar(&git.Repo.PrivateSSHKeyPath, "git-private-ssh-key-path", "",
 			"Optional path to the private SSH key to use with Git, e.g.: ~/.ssh/id_rsa")
 		fs.StringVar(&cfg.Metadata.Name, "cluster", "", "name of the EKS cluster to add the nodegroup to")
-		_ = cobra.MarkFlagRequired(fs, "quickstart-profile")
-		_ = cobra.MarkFlagRequired(fs, "git-url")
-		_ = cobra.MarkFlagRequired(fs, "cluster")
 
 		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
 		cmdutils.AddConfigFileFlag(fs, &cmd.ClusterConfigFile)
@@ -61,27 +55,12 @@ func applyGitops(cmd *cmdutils.Cmd) {
 	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
 }
 
-func doApplyGitops(cmd *cmdutils.Cmd, opts options) error {
-	if opts.quickstartNameArg == "" {
-		return errors.New("please supply a valid gitops Quick Start URL or name in --quickstart-profile")
-	}
-
-	if opts.gitOptions.URL == "" {
-		return errors.New("please supply a valid --git-url argument")
-	}
-	if opts.gitPrivateSSHKeyPath != "" && !file.Exists(opts.gitPrivateSSHKeyPath) {
-		return errors.New("please supply a valid --git-private-ssh-key-path argument")
-	}
-
-	quickstartRepoURL, err := repoURLForQuickstart(opts.quickstartNameArg)
+func doApplyGitops(cmd *cmdutils.Cmd, git *api.Git, opts options) error {
+	profilesCatalog, err := loadGitopsConfig(cmd, git, opts)
 	if err != nil {
-		return errors.Wrapf(err, "please supply a valid Quick Start name or URL")
-	}
-
-	if err := cmdutils.NewGitopsMetadataLoader(cmd).Load(); err != nil {
 		return err
 	}
-	cfg := cmd.ClusterConfig
+
 	ctl, err := cmd.NewCtl()
 	if err != nil {
 		return err
@@ -90,7 +69,8 @@ func doApplyGitops(cmd *cmdutils.Cmd, opts options) error {
 	if err := ctl.CheckAuth(); err != nil {
 		return err
 	}
-	if err := ctl.RefreshClusterConfig(cfg); err != nil {
+	cfg, err := ctl.LoadClusterConfigForProfiles(cmd.ClusterConfig)
+	if err != nil {
 		return err
 	}
 	kubernetesClientConfigs, err := ctl.NewClient(cfg)
@@ -108,75 +88,60 @@ func doApplyGitops(cmd *cmdutils.Cmd, opts options) error {
 		return errors.Errorf("cannot create Kubernetes client set: %s", err)
 	}
 
-	// Create the flux installer. It will clone the user's repository in the outputPath
-	fluxOpts := flux.InstallOpts{
-		GitURL:      opts.gitOptions.URL,
-		GitBranch:   opts.gitOptions.Branch,
-		GitEmail:    opts.gitOptions.Email,
-		GitUser:     opts.gitOptions.User,
-		Namespace:   "flux",
-		GitFluxPath: "flux/",
-		WithHelm:    true,
-		Timeout:     git.DefaultGitTimeout,
-	}
-	fluxInstaller := flux.NewInstaller(context.Background(), k8sRestConfig, k8sClientSet, &fluxOpts)
-
-	processor := &fileprocessor.GoTemplateProcessor{
-		Params: fileprocessor.NewTemplateParameters(cmd.ClusterConfig),
-	}
-
-	// Create the profile generator. It will output the processed templates into a new "/base" directory into the user's repo
-	usersRepoName, err := git.RepoName(opts.gitOptions.URL)
+	// The flux installer and the profiles clone the user's repository in the outputPath
+	gitOps, err := gitops.NewApplier(context.Background(), k8sRestConfig, k8sClientSet, cfg, profilesCatalog, opts.outputPath)
 	if err != nil {
 		return err
 	}
-	usersRepoDir := filepath.Join(opts.outputPath, usersRepoName)
-	profileOutputPath := filepath.Join(usersRepoDir, "base")
-
-	profile := &gitops.Profile{
-		Processor: processor,
-		Path:      profileOutputPath,
-		GitOpts: git.Options{
-			URL:    quickstartRepoURL,
-			Branch: "master",
-		},
-		GitCloner: git.NewGitClient(context.Background(), git.ClientParams{
-			Timeout: git.DefaultGitTimeout,
-		}),
-		FS: afero.NewOsFs(),
-		IO: afero.Afero{Fs: afero.NewOsFs()},
-	}
-
-	// A git client that operates in the user's repo
-	gitClient := git.NewGitClient(context.Background(), git.ClientParams{
-		PrivateSSHKeyPath: opts.gitPrivateSSHKeyPath,
-		Timeout:           git.DefaultGitTimeout,
-		Dir:               usersRepoDir,
-	})
+	return gitOps.Run(context.Background())
+}
 
-	gitOps := gitops.Applier{
-		UserRepoPath:     usersRepoDir,
-		UsersRepoOpts:    opts.gitOptions,
-		GitClient:        gitClient,
-		ProfileGenerator: profile,
-		FluxInstaller:    fluxInstaller,
-		ClusterConfig:    cmd.ClusterConfig,
-		QuickstartName:   opts.quickstartNameArg,
+// loadGitopsConfig loads the config file, or the git config given with flags after
+// validating them, and returns the profiles catalog
+func loadGitopsConfig(cmd *cmdutils.Cmd, git *api.Git, opts options) (*catalog.Catalog, error) {
+	if err := cmdutils.NewGitopsMetadataLoader(cmd).Load(); err != nil {
+		return nil, err
 	}
 
-	if err = gitOps.Run(context.Background()); err != nil {
-		return err
+	profilesCatalog, err := loadCatalog(cmd, opts.catalog)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	fromFlags, err := cmdutils.SetGitConfigFromFlags(cmd, git,
+		"quickstart-profile", "values", "git-url", "git-branch", "git-user", "git-email", "git-private-ssh-key-path")
+	if err != nil {
+		return nil, err
+	}
+	if fromFlags {
+		if cmd.ClusterConfig.Metadata.Name == "" {
+			return nil, errors.New("please supply a valid --cluster argument")
+		}
+		if opts.quickstartNameArg == "" {
+			return nil, errors.New("please supply a valid gitops Quick Start URL or name in --quickstart-profile")
+		}
+		if git.Repo.URL == "" {
+			return nil, errors.New("please supply a valid --git-url argument")
+		}
+		if git.Repo.Email == "" {
+			return nil, errors.New("please supply a valid --git-email argument")
+		}
+		if _, err := profilesCatalog.Resolve(opts.quickstartNameArg, ""); err != nil {
+			return nil, errors.Wrapf(err, "please supply a valid Quick Start name or URL")
+		}
+		git.Profiles = []api.Profile{{Source: opts.quickstartNameArg, ValuesFile: opts.valuesFile}}
+	}
+	if repo := cmd.ClusterConfig.Git.Repo; repo != nil && repo.PrivateSSHKeyPath != "" && !file.Exists(repo.PrivateSSHKeyPath) {
+		return nil, errors.New("please supply a valid --git-private-ssh-key-path argument")
+	}
+	return profilesCatalog, nil
 }
 
-func repoURLForQuickstart(quickstartArgument string) (string, error) {
-	if git.IsGitURL(quickstartArgument) {
-		return quickstartArgument, nil
-	}
-	if quickstartArgument == "app-dev" {
-		// FIXME rename to eks-quickstart-app-dev once the repo is renamed
-		return "git@github.com:weaveworks/eks-gitops-example.git", nil
+// loadCatalog loads the catalog given with --catalog, or the one
+// declared in the config file
+func loadCatalog(cmd *cmdutils.Cmd, location string) (*catalog.Catalog, error) {
+	if location == "" && cmd.ClusterConfig.Git != nil {
+		location = cmd.ClusterConfig.Git.Catalog
 	}
-	return "", fmt.Errorf("invalid URL or unknown Quick Start %s ", quickstartArgument)
+	return catalog.Load(location)
 }
@@ -11,6 +11,7 @@ func Command(flagGrouping *cmdutils.FlagGrouping) *cobra.Command {
 	verbCmd := cmdutils.NewVerbCmd("gitops", "Helps setting up GitOps in a cluster", "")
 
 	cmdutils.AddResourceCmd(flagGrouping, verbCmd, applyGitops)
+	cmdutils.AddResourceCmd(flagGrouping, verbCmd, updateProfile)
 
 	return verbCmd
 }
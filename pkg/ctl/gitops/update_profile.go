@@ -0,0 +1,71 @@
+package gitops
+
+import (
+	"context"
+
+	"github.com/spf13/pflag"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/gitops"
+)
+
+func updateProfile(cmd *cmdutils.Cmd) {
+	cfg := api.NewClusterConfig()
+	cmd.ClusterConfig = cfg
+
+	cmd.SetDescription("update-profile", "Update a Quick Start profile in a GitOps repository, merging the new version with the local changes", "")
+
+	var opts options
+	git := api.NewGit()
+
+	cmd.SetRunFuncWithNameArg(func() error {
+		return doUpdateProfile(cmd, git, opts)
+	})
+
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		fs.StringVarP(&opts.quickstartNameArg, "quickstart-profile", "", "", "name or URL of the Quick Start profile, optionally followed by @version. For example, app-dev or app-dev@v0.2.0.")
+		fs.StringVarP(&git.Repo.URL, "git-url", "", "", "URL for the git repository that contains the cluster components")
+		fs.StringVarP(&git.Repo.Branch, "git-branch", "", "master", "Git branch")
+		fs.StringVar(&opts.valuesFile, "values", "", "Path to a YAML file whose values are available to the Quick Start profile's templates as .Values")
+		fs.StringVar(&opts.catalog, "catalog", "", "Path or URL of the profiles catalog, overrides git.catalog of the config file (default: the catalog of the Quick Start profiles)")
+		fs.StringVarP(&opts.outputPath, "output-path", "", "./", "Path to directory where the GitOps repo will be cloned")
+		fs.StringVar(&git.Repo.User, "git-user", "Flux", "Username to use as Git committer")
+		fs.StringVar(&git.Repo.Email, "git-email", "", "Email to use as Git committer (required)")
+		fs.StringVar(&git.Repo.PrivateSSHKeyPath, "git-private-ssh-key-path", "",
+			"Optional path to the private SSH key to use with Git, e.g.: ~/.ssh/id_rsa")
+		fs.StringVar(&cfg.Metadata.Name, "cluster", "", "name of the EKS cluster the profile is applied to")
+
+		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
+		cmdutils.AddConfigFileFlag(fs, &cmd.ClusterConfigFile)
+	})
+
+	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
+}
+
+func doUpdateProfile(cmd *cmdutils.Cmd, git *api.Git, opts options) error {
+	profilesCatalog, err := loadGitopsConfig(cmd, git, opts)
+	if err != nil {
+		return err
+	}
+
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return err
+	}
+
+	if err := ctl.CheckAuth(); err != nil {
+		return err
+	}
+	cfg, err := ctl.LoadClusterConfigForProfiles(cmd.ClusterConfig)
+	if err != nil {
+		return err
+	}
+
+	// The user's repository is cloned in the outputPath, and left there to resolve the conflicts, if any
+	updater, err := gitops.NewProfileUpdater(context.Background(), cfg, profilesCatalog, opts.outputPath)
+	if err != nil {
+		return err
+	}
+	return updater.Run(context.Background())
+}
@@ -2,6 +2,7 @@ package install
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/pkg/errors"
@@ -10,10 +11,15 @@ import (
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
 	"github.com/weaveworks/eksctl/pkg/gitops/flux"
 	"github.com/weaveworks/eksctl/pkg/utils/file"
-	kubeclient "k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
+// gitFlags are the flags which set the git section of the ClusterConfig
+var gitFlags = []string{
+	"git-url", "git-branch", "git-paths", "git-label", "git-user", "git-email", "git-flux-subdir",
+	"git-private-ssh-key-path", "namespace", "with-helm", "helm-versions", "flux-version",
+	"git-provider", "git-provider-url", "create-git-repo",
+}
+
 func installFluxCmd(cmd *cmdutils.Cmd) {
 	cmd.ClusterConfig = api.NewClusterConfig()
 	cmd.SetDescription(
@@ -21,73 +27,83 @@ func installFluxCmd(cmd *cmdutils.Cmd) {
 		"Bootstrap Flux, installing it in the cluster and initializing its manifests in the specified Git repository",
 		"",
 	)
-	var opts flux.InstallOpts
+	var (
+		opts     flux.InstallOpts
+		withHelm bool
+	)
+	git := api.NewGit()
 	cmd.SetRunFuncWithNameArg(func() error {
-		if opts.GitURL == "" {
-			return errors.New("please supply a valid --git-url argument")
-		}
-		if opts.GitEmail == "" {
-			return errors.New("please supply a valid --git-email argument")
-		}
-		if opts.GitPrivateSSHKeyPath != "" && !file.Exists(opts.GitPrivateSSHKeyPath) {
-			return errors.New("please supply a valid --git-private-ssh-key-path argument")
-		}
-
 		if err := cmdutils.NewMetadataLoader(cmd).Load(); err != nil {
 			return err
 		}
-		cfg := cmd.ClusterConfig
-		ctl, err := cmd.NewCtl()
+		fromFlags, err := cmdutils.SetGitConfigFromFlags(cmd, git, gitFlags...)
 		if err != nil {
 			return err
 		}
-
-		if err := ctl.CheckAuth(); err != nil {
-			return err
-		}
-		if err := ctl.RefreshClusterConfig(cfg); err != nil {
-			return err
+		if fromFlags {
+			if git.Repo.URL == "" {
+				return errors.New("please supply a valid --git-url argument")
+			}
+			if git.Repo.Email == "" {
+				return errors.New("please supply a valid --git-email argument")
+			}
+			if git.Operator.FluxVersion != flux.FluxVersionV1 && git.Operator.FluxVersion != flux.FluxVersionV2 {
+				return fmt.Errorf("unsupported --flux-version %q, must be one of: %s, %s", git.Operator.FluxVersion, flux.FluxVersionV1, flux.FluxVersionV2)
+			}
+			if err := flux.ValidateHelmVersions(git.Operator.HelmVersions); err != nil {
+				return err
+			}
+			if git.Repo.Provider == "" && (git.Repo.ProviderURL != "" || git.Repo.CreateIfMissing) {
+				return errors.New("--git-provider-url and --create-git-repo require --git-provider")
+			}
+			git.Operator.WithHelm = &withHelm
 		}
-		kubernetesClientConfigs, err := ctl.NewClient(cfg)
-		if err != nil {
-			return err
+		if repo := cmd.ClusterConfig.Git.Repo; repo != nil && repo.PrivateSSHKeyPath != "" && !file.Exists(repo.PrivateSSHKeyPath) {
+			return errors.New("please supply a valid --git-private-ssh-key-path argument")
 		}
-		k8sConfig := kubernetesClientConfigs.Config
 
-		k8sRestConfig, err := clientcmd.NewDefaultClientConfig(*k8sConfig, &clientcmd.ConfigOverrides{}).ClientConfig()
-		if err != nil {
-			return errors.Wrap(err, "cannot create Kubernetes client configuration")
-		}
-		k8sClientSet, err := kubeclient.NewForConfig(k8sRestConfig)
+		k8sRestConfig, k8sClientSet, err := newKubernetesClients(cmd)
 		if err != nil {
-			return errors.Errorf("cannot create Kubernetes client set: %s", err)
+			return err
 		}
 
-		installer := flux.NewInstaller(context.Background(), k8sRestConfig, k8sClientSet, &opts)
+		installOpts := flux.NewInstallOpts(cmd.ClusterConfig.Git, opts.Timeout)
+		installOpts.Amend = opts.Amend
+		installer := flux.NewInstaller(context.Background(), k8sRestConfig, k8sClientSet, installOpts)
 		return installer.Run(context.Background())
 	})
 
 	cmd.FlagSetGroup.InFlagSet("Flux installation", func(fs *pflag.FlagSet) {
-		fs.StringVar(&opts.GitURL, "git-url", "",
+		fs.StringVar(&git.Repo.URL, "git-url", "",
 			"URL of the Git repository to be used by Flux, e.g. git@github.com:<github_org>/flux-get-started")
-		fs.StringVar(&opts.GitBranch, "git-branch", "master",
+		fs.StringVar(&git.Repo.Branch, "git-branch", "master",
 			"Git branch to be used by Flux")
-		fs.StringSliceVar(&opts.GitPaths, "git-paths", []string{},
+		fs.StringSliceVar(&git.Repo.Paths, "git-paths", []string{},
 			"Relative paths within the Git repo for Flux to locate Kubernetes manifests")
-		fs.StringVar(&opts.GitLabel, "git-label", "flux",
+		fs.StringVar(&git.Operator.Label, "git-label", "flux",
 			"Git label to keep track of Flux's sync progress; overrides both --git-sync-tag and --git-notes-ref")
-		fs.StringVar(&opts.GitUser, "git-user", "Flux",
+		fs.StringVar(&git.Repo.User, "git-user", "Flux",
 			"Username to use as Git committer")
-		fs.StringVar(&opts.GitEmail, "git-email", "",
+		fs.StringVar(&git.Repo.Email, "git-email", "",
 			"Email to use as Git committer")
-		fs.StringVar(&opts.GitFluxPath, "git-flux-subdir", "flux/",
+		fs.StringVar(&git.Repo.FluxPath, "git-flux-subdir", "flux/",
 			"Directory within the Git repository where to commit the Flux manifests")
-		fs.StringVar(&opts.GitPrivateSSHKeyPath, "git-private-ssh-key-path", "",
+		fs.StringVar(&git.Repo.PrivateSSHKeyPath, "git-private-ssh-key-path", "",
 			"Optional path to the private SSH key to use with Git, e.g.: ~/.ssh/id_rsa")
-		fs.StringVar(&opts.Namespace, "namespace", "flux",
+		fs.StringVar(&git.Repo.Provider, "git-provider", "",
+			"Git hosting service of the repository (github or gitlab), used to add Flux's key as a deploy key with the token in $GITHUB_TOKEN or $GITLAB_TOKEN and wait for the first sync")
+		fs.StringVar(&git.Repo.ProviderURL, "git-provider-url", "",
+			"Base URL of the Git provider's API, for self-hosted instances (default: https://api.github.com or https://gitlab.com/api/v4)")
+		fs.BoolVar(&git.Repo.CreateIfMissing, "create-git-repo", false,
+			"Create the repository, as a private one, with the Git provider when it doesn't exist")
+		fs.StringVar(&git.Operator.Namespace, "namespace", "flux",
 			"Cluster namespace where to install Flux, the Helm Operator and Tiller")
-		fs.BoolVar(&opts.WithHelm, "with-helm", true,
-			"Install the Helm Operator and Tiller")
+		fs.BoolVar(&withHelm, "with-helm", true,
+			"Install the Helm Operator and Tiller (or the helm-controller, with --flux-version=v2)")
+		fs.StringSliceVar(&git.Operator.HelmVersions, "helm-versions", []string{},
+			"Helm versions to enable in the Helm Operator (v2, v3); Tiller is only installed when v2 is enabled (default: the Helm Operator's own default, with Tiller)")
+		fs.StringVar(&git.Operator.FluxVersion, "flux-version", flux.FluxVersionV1,
+			"Flux version to install: v1 (Flux daemon) or v2 (GitOps Toolkit controllers)")
 		fs.BoolVar(&opts.Amend, "amend", false,
 			"Stop to manually tweak the Flux manifests before pushing them to the Git repository")
 	})
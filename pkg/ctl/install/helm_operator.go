@@ -0,0 +1,53 @@
+package install
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/gitops/flux"
+)
+
+func installHelmOperatorCmd(cmd *cmdutils.Cmd) {
+	cmd.ClusterConfig = api.NewClusterConfig()
+	cmd.SetDescription(
+		"helm-operator",
+		"Install the Flux Helm Operator in the cluster, without Tiller unless Helm v2 is enabled",
+		"",
+	)
+	opts := flux.InstallOpts{WithHelm: true}
+	cmd.SetRunFuncWithNameArg(func() error {
+		if err := flux.ValidateHelmVersions(opts.HelmVersions); err != nil {
+			return err
+		}
+		if err := cmdutils.NewMetadataLoader(cmd).Load(); err != nil {
+			return err
+		}
+
+		k8sRestConfig, k8sClientSet, err := newKubernetesClients(cmd)
+		if err != nil {
+			return err
+		}
+
+		installer := flux.NewInstaller(context.Background(), k8sRestConfig, k8sClientSet, &opts)
+		return installer.RunHelmOperator(context.Background())
+	})
+
+	cmd.FlagSetGroup.InFlagSet("Helm Operator installation", func(fs *pflag.FlagSet) {
+		fs.StringSliceVar(&opts.HelmVersions, "helm-versions", []string{flux.HelmVersionV3},
+			"Helm versions to enable in the Helm Operator (v2, v3); Tiller and its TLS certificates are only installed when v2 is enabled")
+		fs.StringVar(&opts.Namespace, "namespace", "flux",
+			"Cluster namespace where to install the Helm Operator")
+	})
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		cmdutils.AddNameFlag(fs, cmd.ClusterConfig.Metadata)
+		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
+		cmdutils.AddConfigFileFlag(fs, &cmd.ClusterConfigFile)
+		cmdutils.AddTimeoutFlagWithValue(fs, &opts.Timeout, 20*time.Second)
+	})
+	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
+	cmd.ProviderConfig.WaitTimeout = opts.Timeout
+}
@@ -11,6 +11,7 @@ func Command(flagGrouping *cmdutils.FlagGrouping) *cobra.Command {
 	verbCmd := cmdutils.NewVerbCmd("install", "Install components in a cluster", "")
 
 	cmdutils.AddResourceCmd(flagGrouping, verbCmd, installFluxCmd)
+	cmdutils.AddResourceCmd(flagGrouping, verbCmd, installHelmOperatorCmd)
 
 	return verbCmd
 }
@@ -0,0 +1,42 @@
+package install
+
+import (
+	"github.com/pkg/errors"
+	kubeclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+)
+
+// newKubernetesClients returns clients for the cluster's Kubernetes API,
+// the cluster's metadata must have been loaded already
+func newKubernetesClients(cmd *cmdutils.Cmd) (*rest.Config, kubeclient.Interface, error) {
+	cfg := cmd.ClusterConfig
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := ctl.CheckAuth(); err != nil {
+		return nil, nil, err
+	}
+	if err := ctl.RefreshClusterConfig(cfg); err != nil {
+		return nil, nil, err
+	}
+	kubernetesClientConfigs, err := ctl.NewClient(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	k8sConfig := kubernetesClientConfigs.Config
+
+	k8sRestConfig, err := clientcmd.NewDefaultClientConfig(*k8sConfig, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot create Kubernetes client configuration")
+	}
+	k8sClientSet, err := kubeclient.NewForConfig(k8sRestConfig)
+	if err != nil {
+		return nil, nil, errors.Errorf("cannot create Kubernetes client set: %s", err)
+	}
+	return k8sRestConfig, k8sClientSet, nil
+}
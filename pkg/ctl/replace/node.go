@@ -0,0 +1,110 @@
+package replace
+
+import (
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/drain"
+	"github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/events"
+)
+
+func replaceNodeCmd(cmd *cmdutils.Cmd) {
+	cfg := api.NewClusterConfig()
+	cmd.ClusterConfig = cfg
+
+	var nodeName string
+	drainOptions := drain.DefaultOptions()
+
+	cmd.SetDescription("node", "Drain a node and replace it with a new instance from its auto scaling group", "")
+
+	cmd.SetRunFuncWithNameArg(func() error {
+		return doReplaceNode(cmd, nodeName, drainOptions)
+	})
+
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		fs.StringVar(&cfg.Metadata.Name, "cluster", "", "EKS cluster name")
+		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
+		fs.StringVarP(&nodeName, "name", "n", "", "Name of the node to replace")
+		cmdutils.AddApproveFlag(fs, cmd)
+		cmdutils.AddNodeDrainFlags(fs, &drainOptions)
+		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
+	})
+
+	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
+}
+
+func doReplaceNode(cmd *cmdutils.Cmd, nodeName string, drainOptions drain.Options) error {
+	cfg := cmd.ClusterConfig
+
+	if cfg.Metadata.Name == "" {
+		return cmdutils.ErrMustBeSet("--cluster")
+	}
+
+	if nodeName != "" && cmd.NameArg != "" {
+		return cmdutils.ErrNameFlagAndArg(nodeName, cmd.NameArg)
+	}
+
+	if cmd.NameArg != "" {
+		nodeName = cmd.NameArg
+	}
+
+	if nodeName == "" {
+		return cmdutils.ErrMustBeSet("--name")
+	}
+
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return err
+	}
+	logger.Info("using region %s", cfg.Metadata.Region)
+
+	if err := ctl.CheckAuth(); err != nil {
+		return err
+	}
+
+	if err := ctl.RefreshClusterConfig(cfg); err != nil {
+		return errors.Wrapf(err, "getting credentials for cluster %q", cfg.Metadata.Name)
+	}
+
+	clientSet, err := ctl.NewStdClientSet(cfg)
+	if err != nil {
+		return err
+	}
+
+	node, err := clientSet.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting node %q", nodeName)
+	}
+	instanceID, err := eks.InstanceID(node)
+	if err != nil {
+		return err
+	}
+	// only nodes in an auto scaling group get replaced, so check it before draining the node
+	groupName, err := ctl.GetNodeAutoScalingGroup(node)
+	if err != nil {
+		return err
+	}
+
+	cmdutils.LogIntendedAction(cmd.Plan, "drain node %q and terminate its instance %q in auto scaling group %q of cluster %q", nodeName, instanceID, groupName, cfg.Metadata.Name)
+	cmdutils.LogPlanModeWarning(cmd.Plan)
+	if cmd.Plan {
+		return nil
+	}
+
+	if err := drain.Node(clientSet, nodeName, ctl.Provider.WaitTimeout(), false, drainOptions); err != nil {
+		return err
+	}
+
+	replacement, err := ctl.ReplaceNode(clientSet, node, groupName)
+	if err != nil {
+		return err
+	}
+	events.Deleted(events.NodeResource, cfg.Metadata.Name, nodeName)
+	events.Created(events.NodeResource, cfg.Metadata.Name, replacement)
+	return nil
+}
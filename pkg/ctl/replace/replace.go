@@ -0,0 +1,16 @@
+package replace
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+)
+
+// Command will create the `replace` commands
+func Command(flagGrouping *cmdutils.FlagGrouping) *cobra.Command {
+	verbCmd := cmdutils.NewVerbCmd("replace", "Replace resource(s)", "")
+
+	cmdutils.AddResourceCmd(flagGrouping, verbCmd, replaceNodeCmd)
+
+	return verbCmd
+}
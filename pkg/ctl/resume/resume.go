@@ -0,0 +1,318 @@
+package resume
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	cfn "github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/authconfigmap"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/events"
+	"github.com/weaveworks/eksctl/pkg/iam"
+	"github.com/weaveworks/eksctl/pkg/journal"
+	"github.com/weaveworks/eksctl/pkg/utils/kubeconfig"
+)
+
+type resumeCmdParams struct {
+	writeKubeconfig      bool
+	kubeconfigPath       string
+	autoKubeconfigPath   bool
+	authenticator        string
+	authenticatorRoleARN string
+	setContext           bool
+}
+
+// Command will create the `resume` command
+func Command(flagGrouping *cmdutils.FlagGrouping) *cobra.Command {
+	return cmdutils.NewStandaloneCmd(flagGrouping, resumeCmd)
+}
+
+func resumeCmd(cmd *cmdutils.Cmd) {
+	cmd.ClusterConfig = api.NewClusterConfig()
+
+	params := &resumeCmdParams{}
+
+	cmd.SetDescription("resume", "Resume an operation that didn't complete", "Continue from the last incomplete task of a 'create cluster' or 'create nodegroup' operation, see 'eksctl get operations' for recorded operations")
+	cmd.CobraCommand.Use = "resume OPERATION_ID"
+
+	cmd.SetRunFuncWithNameArg(func() error {
+		return doResume(cmd, params)
+	})
+
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
+		cmdutils.AddJournalFlag(fs, &cmd.JournalLocation)
+		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
+		cmdutils.AddMaxParallelFlag(fs, &cmd.MaxParallel)
+	})
+
+	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, true)
+
+	cmd.FlagSetGroup.InFlagSet("Output kubeconfig", func(fs *pflag.FlagSet) {
+		cmdutils.AddCommonFlagsForKubeconfig(fs, &params.kubeconfigPath, &params.authenticator, &params.authenticatorRoleARN, &params.setContext, &params.autoKubeconfigPath, cmdutils.ClusterName("", ""))
+		fs.BoolVar(&params.writeKubeconfig, "write-kubeconfig", true, "toggle writing of kubeconfig when resuming 'create cluster'")
+	})
+}
+
+func loadOperation(cmd *cmdutils.Cmd) (*journal.Operation, error) {
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return nil, err
+	}
+	store, err := ctl.NewJournalStore(cmd.JournalLocation)
+	if err != nil {
+		return nil, err
+	}
+	return store.Load(cmd.NameArg)
+}
+
+func doResume(cmd *cmdutils.Cmd, params *resumeCmdParams) error {
+	if cmd.NameArg == "" {
+		return cmdutils.ErrMustBeSet("operation ID")
+	}
+
+	if err := cmdutils.ValidateAuthenticatorFlag(params.authenticator); err != nil {
+		return err
+	}
+
+	op, err := loadOperation(cmd)
+	if err != nil {
+		return err
+	}
+
+	if !op.Kind.IsResumable() {
+		return fmt.Errorf("operation %q cannot be resumed, as it's a %s operation; run the command again instead", op.ID, op.Kind)
+	}
+	if op.IsComplete() {
+		logger.Info("all tasks of operation %q have already completed", op.ID)
+		return nil
+	}
+	if op.ClusterConfig == nil {
+		return fmt.Errorf("operation %q doesn't include cluster config", op.ID)
+	}
+
+	// the operation can only continue in the same region and account
+	cmd.ProviderConfig.Region = op.Region
+	if cmd.ProviderConfig.Profile == "" {
+		cmd.ProviderConfig.Profile = op.Profile
+	}
+	cmd.ClusterConfig = op.ClusterConfig
+	cfg := cmd.ClusterConfig
+
+	if params.autoKubeconfigPath {
+		if params.kubeconfigPath != kubeconfig.DefaultPath {
+			return fmt.Errorf("--kubeconfig and --auto-kubeconfig %s", cmdutils.IncompatibleFlags)
+		}
+		params.kubeconfigPath = kubeconfig.AutoPath(cfg.Metadata.Name)
+	}
+
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return err
+	}
+
+	if err := ctl.CheckAuth(); err != nil {
+		return err
+	}
+
+	stackManager := ctl.NewStackManager(cfg)
+
+	if err := reconcileIncompleteTasks(stackManager, op); err != nil {
+		return err
+	}
+
+	if err := loadClusterStatus(ctl, stackManager, cfg); err != nil {
+		return err
+	}
+
+	ngSubset := sets.NewString(op.NodeGroups...)
+
+	var tasks *manager.TaskTree
+	switch op.Kind {
+	case journal.CreateCluster:
+		tasks = stackManager.NewTasksToCreateClusterWithNodeGroups(ngSubset)
+		ctl.AppendExtraClusterConfigTasks(cfg, tasks)
+	case journal.CreateNodeGroups:
+		tasks = stackManager.NewTasksToCreateNodeGroups(ngSubset)
+	}
+
+	if err := cmd.StartJournal(ctl, op); err != nil {
+		return err
+	}
+
+	logger.Info("resuming operation %q, %d task(s) have already completed", op.ID, op.CompletedTasks().Len())
+	logger.Info(tasks.Describe())
+	if errs := cmd.DoTasks(tasks).Errors(); len(errs) > 0 {
+		logger.Info("%d error(s) occurred while resuming operation %q, you may wish to check CloudFormation console", len(errs), op.ID)
+		for _, err := range errs {
+			logger.Critical("%s\n", err.Error())
+		}
+		return fmt.Errorf("failed to resume operation %q", op.ID)
+	}
+
+	logger.Success("all tasks of operation %q have completed", op.ID)
+	if op.Kind == journal.CreateCluster {
+		events.Created(events.ClusterResource, cfg.Metadata.Name, cfg.Metadata.Name)
+	}
+	for _, name := range op.NodeGroups {
+		events.Created(events.NodeGroupResource, cfg.Metadata.Name, name)
+	}
+
+	return postCreate(ctl, cfg, op, params)
+}
+
+// reconcileIncompleteTasks checks stacks of tasks that were interrupted or have failed,
+// stacks that were still being created are waited for, and tasks of stacks that were
+// created successfully are marked as done
+func reconcileIncompleteTasks(stackManager *manager.StackCollection, op *journal.Operation) error {
+	ctx, cancel := cmdutils.NewInterruptibleContext()
+	defer cancel()
+
+	for _, task := range op.IncompleteTasks() {
+		if task.StackName == "" || task.State == manager.TaskPending || task.State == manager.TaskSkipped {
+			continue
+		}
+		stack, err := stackManager.DescribeStack(&manager.Stack{StackName: aws.String(task.StackName)})
+		if err != nil {
+			if isStackNotFound(err) {
+				logger.Debug("stack %q doesn't exist, task %q will run again", task.StackName, task.Description)
+				continue
+			}
+			return err
+		}
+		switch status := aws.StringValue(stack.StackStatus); status {
+		case cfn.StackStatusCreateComplete:
+		case cfn.StackStatusCreateInProgress:
+			logger.Info("waiting for stack %q that was being created by task %q", task.StackName, task.Description)
+			if err := stackManager.DoWaitUntilStackIsCreated(ctx, stack); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("stack %q is in %s state, it has to be deleted before operation %q can be resumed", task.StackName, status, op.ID)
+		}
+		logger.Info("stack %q was created, task %q is done", task.StackName, task.Description)
+		task.State = manager.TaskDone
+		task.Error = ""
+	}
+	return nil
+}
+
+// loadClusterStatus loads the endpoint, certificate authority and VPC of a cluster whose
+// stack was created by a previous run, as the nodegroup tasks need them, and the task that
+// created the stack, which would have collected them, is skipped
+func loadClusterStatus(ctl *eks.ClusterProvider, stackManager *manager.StackCollection, cfg *api.ClusterConfig) error {
+	hasClusterStack, err := stackManager.HasClusterStack()
+	if err != nil {
+		return err
+	}
+	if !hasClusterStack {
+		return nil
+	}
+	if err := ctl.RefreshClusterConfig(cfg); err != nil {
+		return errors.Wrapf(err, "getting credentials for cluster %q", cfg.Metadata.Name)
+	}
+	if err := ctl.LoadClusterVPC(cfg); err != nil {
+		return errors.Wrapf(err, "getting VPC configuration for cluster %q", cfg.Metadata.Name)
+	}
+	return nil
+}
+
+func isStackNotFound(err error) bool {
+	awsErr, ok := errors.Cause(err).(awserr.Error)
+	return ok && awsErr.Code() == "ValidationError" && strings.Contains(awsErr.Message(), "does not exist")
+}
+
+// postCreate does what 'create cluster' and 'create nodegroup' do once all stacks are created
+func postCreate(ctl *eks.ClusterProvider, cfg *api.ClusterConfig, op *journal.Operation, params *resumeCmdParams) error {
+	if err := ctl.RefreshClusterConfig(cfg); err != nil {
+		return err
+	}
+
+	if op.Kind == journal.CreateCluster && params.writeKubeconfig {
+		kubectlConfig := kubeconfig.NewForKubectl(cfg, ctl.GetUsername(), params.authenticator, params.authenticatorRoleARN, ctl.Provider.Profile())
+		path, err := kubeconfig.Write(params.kubeconfigPath, *kubectlConfig, params.setContext)
+		if err != nil {
+			return errors.Wrap(err, "writing kubeconfig")
+		}
+		logger.Success("saved kubeconfig as %q", path)
+	}
+
+	if op.SkipAuthConfigMap {
+		return nil
+	}
+
+	if err := loadNodeGroupOutputs(ctl, cfg, op); err != nil {
+		return err
+	}
+
+	clientSet, err := ctl.NewStdClientSet(cfg)
+	if err != nil {
+		return err
+	}
+
+	if op.Kind == journal.CreateCluster {
+		if err := ctl.WaitForControlPlane(cfg.Metadata, clientSet); err != nil {
+			return err
+		}
+	}
+
+	ngSubset := sets.NewString(op.NodeGroups...)
+	for _, ng := range cfg.NodeGroups {
+		if !ngSubset.Has(ng.Name) {
+			continue
+		}
+		// authorise nodes to join
+		if err := authconfigmap.AddNodeGroup(clientSet, ng); err != nil {
+			return err
+		}
+		// wait for nodes to join
+		if err := ctl.WaitForNodes(clientSet, ng); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadNodeGroupOutputs loads the IAM configuration of the nodegroups from their stacks, as the
+// stacks created by a previous run, or found by reconcileIncompleteTasks, weren't created by a
+// task of this run, which would have collected their outputs
+func loadNodeGroupOutputs(ctl *eks.ClusterProvider, cfg *api.ClusterConfig, op *journal.Operation) error {
+	stackManager := ctl.NewStackManager(cfg)
+	stacks, err := stackManager.DescribeNodeGroupStacks()
+	if err != nil {
+		return err
+	}
+	ngSubset := sets.NewString(op.NodeGroups...)
+	for _, ng := range cfg.NodeGroups {
+		if !ngSubset.Has(ng.Name) {
+			continue
+		}
+		var stack *manager.Stack
+		for _, s := range stacks {
+			if stackManager.GetNodeGroupName(s) == ng.Name {
+				stack = s
+			}
+		}
+		if stack == nil {
+			return fmt.Errorf("stack of nodegroup %q wasn't found", ng.Name)
+		}
+		if status := aws.StringValue(stack.StackStatus); status != cfn.StackStatusCreateComplete {
+			return fmt.Errorf("stack of nodegroup %q is in %s state", ng.Name, status)
+		}
+		if err := iam.UseFromNodeGroup(ctl.Provider, stack, ng); err != nil {
+			return errors.Wrapf(err, "loading IAM configuration of nodegroup %q", ng.Name)
+		}
+	}
+	return nil
+}
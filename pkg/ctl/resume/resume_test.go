@@ -0,0 +1,124 @@
+package resume
+
+import (
+	"encoding/base64"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cfn "github.com/aws/aws-sdk-go/service/cloudformation"
+	awseks "github.com/aws/aws-sdk-go/service/eks"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+	"github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/journal"
+	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
+)
+
+var _ = Describe("resume", func() {
+	var (
+		p            *mockprovider.MockProvider
+		ctl          *eks.ClusterProvider
+		cfg          *api.ClusterConfig
+		op           *journal.Operation
+		stackManager *manager.StackCollection
+	)
+
+	BeforeEach(func() {
+		p = mockprovider.NewMockProvider()
+		ctl = &eks.ClusterProvider{Provider: p, Status: &eks.ProviderStatus{}}
+
+		cfg = api.NewClusterConfig()
+		cfg.Metadata.Name = "test-cluster"
+		cfg.Metadata.Region = "us-west-2"
+		ng := cfg.NewNodeGroup()
+		ng.Name = "ng-1"
+
+		op = journal.NewOperation(journal.CreateCluster, &api.ProviderConfig{}, cfg, sets.NewString("ng-1"))
+		cfg = op.ClusterConfig
+		stackManager = ctl.NewStackManager(cfg)
+	})
+
+	Context("when the control plane task has already completed", func() {
+		BeforeEach(func() {
+			op.Tasks = []*journal.TaskRecord{
+				{
+					Description: `create cluster control plane "test-cluster"`,
+					State:       manager.TaskDone,
+					StackName:   "eksctl-test-cluster-cluster",
+				},
+				{
+					Description: `create nodegroup "ng-1"`,
+					State:       manager.TaskPending,
+				},
+			}
+
+			p.MockCloudFormation().On("ListStacksPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				consume := args[1].(func(p *cfn.ListStacksOutput, last bool) (shouldContinue bool))
+				consume(&cfn.ListStacksOutput{
+					StackSummaries: []*cfn.StackSummary{
+						{StackName: aws.String("eksctl-test-cluster-cluster")},
+					},
+				}, true)
+			}).Return(nil)
+
+			p.MockCloudFormation().On("DescribeStacks", mock.Anything).Return(&cfn.DescribeStacksOutput{
+				Stacks: []*cfn.Stack{
+					{
+						StackName:   aws.String("eksctl-test-cluster-cluster"),
+						StackId:     aws.String("eksctl-test-cluster-cluster-id"),
+						StackStatus: aws.String(cfn.StackStatusCreateComplete),
+						Tags: []*cfn.Tag{
+							{Key: aws.String(api.ClusterNameTag), Value: aws.String("test-cluster")},
+						},
+						Outputs: []*cfn.Output{
+							{OutputKey: aws.String("VPC"), OutputValue: aws.String("vpc-1234")},
+							{OutputKey: aws.String("SecurityGroup"), OutputValue: aws.String("sg-1234")},
+						},
+					},
+				},
+			}, nil)
+
+			p.MockEKS().On("DescribeCluster", mock.Anything).Return(&awseks.DescribeClusterOutput{
+				Cluster: &awseks.Cluster{
+					Name:     aws.String("test-cluster"),
+					Status:   aws.String(awseks.ClusterStatusActive),
+					Arn:      aws.String("arn-12345678"),
+					Version:  aws.String("1.14"),
+					Endpoint: aws.String("https://test-cluster.eks.amazonaws.com"),
+					CertificateAuthority: &awseks.Certificate{
+						Data: aws.String(base64.StdEncoding.EncodeToString([]byte("ca"))),
+					},
+				},
+			}, nil)
+		})
+
+		It("should load the status of the cluster that nodegroup tasks need", func() {
+			Expect(reconcileIncompleteTasks(stackManager, op)).To(Succeed())
+			Expect(loadClusterStatus(ctl, stackManager, cfg)).To(Succeed())
+
+			Expect(cfg.Status).NotTo(BeNil())
+			Expect(cfg.Status.Endpoint).To(Equal("https://test-cluster.eks.amazonaws.com"))
+			Expect(cfg.Status.CertificateAuthorityData).To(Equal([]byte("ca")))
+			Expect(cfg.VPC.ID).To(Equal("vpc-1234"))
+			Expect(cfg.VPC.SecurityGroup).To(Equal("sg-1234"))
+			Expect(op.CompletedTasks().List()).To(Equal([]string{`create cluster control plane "test-cluster"`}))
+		})
+	})
+
+	Context("when the cluster stack doesn't exist yet", func() {
+		BeforeEach(func() {
+			p.MockCloudFormation().On("ListStacksPages", mock.Anything, mock.Anything).Return(nil)
+		})
+
+		It("should leave loading the status to the control plane task", func() {
+			Expect(loadClusterStatus(ctl, stackManager, cfg)).To(Succeed())
+
+			Expect(cfg.Status).To(BeNil())
+			Expect(p.MockEKS().AssertNotCalled(GinkgoT(), "DescribeCluster", mock.Anything)).To(BeTrue())
+		})
+	})
+})
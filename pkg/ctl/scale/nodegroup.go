@@ -1,13 +1,18 @@
 package scale
 
 import (
-	"fmt"
-
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"k8s.io/client-go/kubernetes"
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/drain"
+	"github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/events"
 )
 
 func scaleNodeGroupCmd(cmd *cmdutils.Cmd) {
@@ -15,68 +20,133 @@ func scaleNodeGroupCmd(cmd *cmdutils.Cmd) {
 	ng := cfg.NewNodeGroup()
 	cmd.ClusterConfig = cfg
 
+	var drainBeforeScaleToZero bool
+	drainOptions := drain.DefaultOptions()
+
 	cmd.SetDescription("nodegroup", "Scale a nodegroup", "", "ng")
 
 	cmd.SetRunFuncWithNameArg(func() error {
-		return doScaleNodeGroup(cmd, ng)
+		return doScaleNodeGroup(cmd, ng, drainBeforeScaleToZero, drainOptions)
 	})
 
 	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
 		fs.StringVar(&cfg.Metadata.Name, "cluster", "", "EKS cluster name")
 		fs.StringVarP(&ng.Name, "name", "n", "", "Name of the nodegroup to scale")
+		cmdutils.AddConfigFileFlag(fs, &cmd.ClusterConfigFile)
+		cmdutils.AddNodeGroupFilterFlags(fs, &cmd.Include, &cmd.Exclude)
 
 		desiredCapacity := fs.IntP("nodes", "N", -1, "total number of nodes (scale to this number)")
+		minSize := fs.IntP("nodes-min", "m", -1, "minimum nodes in ASG")
+		maxSize := fs.IntP("nodes-max", "M", -1, "maximum nodes in ASG")
 		cmdutils.AddPreRun(cmd.CobraCommand, func(cobraCmd *cobra.Command, args []string) {
 			if f := cobraCmd.Flag("nodes"); f.Changed {
 				ng.DesiredCapacity = desiredCapacity
 			}
+			if f := cobraCmd.Flag("nodes-min"); f.Changed {
+				ng.MinSize = minSize
+			}
+			if f := cobraCmd.Flag("nodes-max"); f.Changed {
+				ng.MaxSize = maxSize
+			}
 		})
 
 		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
+		fs.BoolVar(&drainBeforeScaleToZero, "drain", true, "Drain and cordon all nodes in the nodegroup before scaling it to zero")
+		cmdutils.AddDrainFlags(fs, &drainOptions)
 		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
 	})
 
 	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, true)
 }
 
-func doScaleNodeGroup(cmd *cmdutils.Cmd, ng *api.NodeGroup) error {
-	cfg := cmd.ClusterConfig
-
-	// TODO: move this into a loader when --config-file gets added to this command
-	if cfg.Metadata.Name == "" {
-		return cmdutils.ErrMustBeSet("--cluster")
-	}
-
-	if ng.Name != "" && cmd.NameArg != "" {
-		return cmdutils.ErrNameFlagAndArg(ng.Name, cmd.NameArg)
-	}
+func doScaleNodeGroup(cmd *cmdutils.Cmd, ng *api.NodeGroup, drainBeforeScaleToZero bool, drainOptions drain.Options) error {
+	ngFilter := cmdutils.NewNodeGroupFilter()
 
-	if cmd.NameArg != "" {
-		ng.Name = cmd.NameArg
+	if err := cmdutils.NewScaleNodeGroupLoader(cmd, ng, ngFilter).Load(); err != nil {
+		return err
 	}
 
-	if ng.Name == "" {
-		return cmdutils.ErrMustBeSet("--name")
-	}
+	cfg := cmd.ClusterConfig
 
 	ctl, err := cmd.NewCtl()
 	if err != nil {
 		return err
 	}
+	logger.Info("using region %s", cfg.Metadata.Region)
 
 	if err := ctl.CheckAuth(); err != nil {
 		return err
 	}
 
-	if ng.DesiredCapacity == nil || *ng.DesiredCapacity < 0 {
-		return fmt.Errorf("number of nodes must be 0 or greater. Use the --nodes/-N flag")
+	stackManager := ctl.NewStackManager(cfg)
+
+	if cmd.ClusterConfigFile != "" {
+		if err := ngFilter.SetIncludeOrExcludeMissingFilter(stackManager, false, &cfg.NodeGroups); err != nil {
+			return err
+		}
 	}
+	ngFilter.LogInfo(cfg.NodeGroups)
+
+	// the client is only needed for draining nodegroups that are scaled to zero
+	var clientSet kubernetes.Interface
+
+	return ngFilter.ForEach(cfg.NodeGroups, func(_ int, ng *api.NodeGroup) error {
+		if ng.DesiredCapacity == nil && ng.MinSize == nil && ng.MaxSize == nil {
+			logger.Warning("skipping nodegroup %q, as none of desiredCapacity, minSize or maxSize is set", ng.Name)
+			return nil
+		}
+
+		drained := false
+		if drainBeforeScaleToZero {
+			scaleToZero, err := isScaleToZero(stackManager, ng)
+			if err != nil {
+				return err
+			}
+			if scaleToZero {
+				if clientSet == nil {
+					if clientSet, err = newClientSet(ctl, cfg); err != nil {
+						return err
+					}
+				}
+				logger.Info("draining nodegroup %q before scaling it to zero", ng.Name)
+				if err := drain.NodeGroup(clientSet, ng, ctl.Provider.WaitTimeout(), false, drainOptions); err != nil {
+					return err
+				}
+				drained = true
+			}
+		}
+
+		if err := stackManager.ScaleNodeGroup(ng); err != nil {
+			if drained {
+				// the nodes are kept, so they have to be able to run pods again
+				logger.Info("uncordoning the nodes of nodegroup %q, as it couldn't be scaled", ng.Name)
+				if err := drain.NodeGroup(clientSet, ng, ctl.Provider.WaitTimeout(), true, drainOptions); err != nil {
+					logger.Warning("failed to uncordon the nodes of nodegroup %q: %s", ng.Name, err.Error())
+				}
+			}
+			return errors.Wrapf(err, "failed to scale nodegroup %q in cluster %q", ng.Name, cfg.Metadata.Name)
+		}
+		events.Updated(events.NodeGroupResource, cfg.Metadata.Name, ng.Name)
+		return nil
+	})
+}
 
-	stackManager := ctl.NewStackManager(cfg)
-	err = stackManager.ScaleNodeGroup(ng)
+// isScaleToZero tells whether scaling the nodegroup will remove all of its nodes
+func isScaleToZero(stackManager *manager.StackCollection, ng *api.NodeGroup) (bool, error) {
+	current, err := stackManager.GetNodeGroupScaling(ng)
+	if err != nil {
+		return false, err
+	}
+	scaling, err := manager.NewNodeGroupScaling(current, ng)
 	if err != nil {
-		return fmt.Errorf("failed to scale nodegroup for cluster %q, error %v", cfg.Metadata.Name, err)
+		return false, errors.Wrapf(err, "scaling nodegroup %q", ng.Name)
 	}
+	return current.DesiredCapacity > 0 && scaling.DesiredCapacity == 0, nil
+}
 
-	return nil
+func newClientSet(ctl *eks.ClusterProvider, cfg *api.ClusterConfig) (kubernetes.Interface, error) {
+	if err := ctl.RefreshClusterConfig(cfg); err != nil {
+		return nil, errors.Wrapf(err, "getting credentials for cluster %q", cfg.Metadata.Name)
+	}
+	return ctl.NewStdClientSet(cfg)
 }
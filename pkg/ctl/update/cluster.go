@@ -9,6 +9,7 @@ import (
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/events"
 	"github.com/weaveworks/eksctl/pkg/printers"
 )
 
@@ -103,6 +104,16 @@ func doUpdateClusterCmd(cmd *cmdutils.Cmd) error {
 		return err
 	}
 
+	if cmd.ClusterConfigFile != "" {
+		// security group rules are only taken from the config file, as otherwise
+		// all of existing rules would get removed
+		rulesUpdateRequired, err := stackManager.UpdateClusterSecurityGroupRules(cmd.Plan)
+		if err != nil {
+			return err
+		}
+		stackUpdateRequired = stackUpdateRequired || rulesUpdateRequired
+	}
+
 	if err := ctl.ValidateExistingNodeGroupsForCompatibility(cfg, stackManager); err != nil {
 		logger.Critical("failed checking nodegroups", err.Error())
 	}
@@ -116,6 +127,7 @@ func doUpdateClusterCmd(cmd *cmdutils.Cmd) error {
 					return err
 				}
 				logger.Success("cluster %q control plane has been upgraded to version %q", cfg.Metadata.Name, cfg.Metadata.Version)
+				events.Updated(events.ClusterResource, cfg.Metadata.Name, cfg.Metadata.Name)
 				logger.Info(msgNodeGroupsAndAddons)
 			} else {
 				if _, err := ctl.UpdateClusterVersion(cfg); err != nil {
@@ -0,0 +1,201 @@
+package update
+
+import (
+	"fmt"
+
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	"github.com/weaveworks/eksctl/pkg/ami"
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/events"
+)
+
+func updateNodeGroupCmd(cmd *cmdutils.Cmd) {
+	cfg := api.NewClusterConfig()
+	cmd.ClusterConfig = cfg
+
+	var updateAMI bool
+
+	cmd.SetDescription("nodegroup", "Update nodegroup(s)", "Update nodegroup(s) from a config file, currently only security group rules and AMIs can be updated", "ng", "nodegroups")
+
+	cmd.SetRunFunc(func() error {
+		return doUpdateNodeGroups(cmd, updateAMI)
+	})
+
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		cmdutils.AddConfigFileFlag(fs, &cmd.ClusterConfigFile)
+		cmdutils.AddNodeGroupFilterFlags(fs, &cmd.Include, &cmd.Exclude)
+		cmdutils.AddApproveFlag(fs, cmd)
+		fs.BoolVar(&updateAMI, "update-ami", false, "update the AMI of nodegroups with amiUpdatePolicy 'manual' (default) to the latest AMI")
+		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
+	})
+
+	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, true)
+}
+
+func doUpdateNodeGroups(cmd *cmdutils.Cmd, updateAMI bool) error {
+	ngFilter := cmdutils.NewNodeGroupFilter()
+
+	if err := cmdutils.NewUpdateNodeGroupLoader(cmd, ngFilter).Load(); err != nil {
+		return err
+	}
+
+	cfg := cmd.ClusterConfig
+	meta := cmd.ClusterConfig.Metadata
+
+	// SSH may have been enabled with flags when the nodegroups were created, so their SSH
+	// rules are only updated when it's set in the config file, before defaults are applied
+	updateSSH := map[string]bool{}
+	for _, ng := range cfg.NodeGroups {
+		updateSSH[ng.Name] = isSSHSet(ng)
+	}
+
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return err
+	}
+	logger.Info("using region %s", meta.Region)
+
+	if err := ctl.CheckAuth(); err != nil {
+		return err
+	}
+
+	if err := ctl.RefreshClusterConfig(cfg); err != nil {
+		return errors.Wrapf(err, "getting credentials for cluster %q", meta.Name)
+	}
+
+	if err := ctl.LoadClusterVPC(cfg); err != nil {
+		return errors.Wrapf(err, "getting VPC configuration for cluster %q", meta.Name)
+	}
+
+	stackManager := ctl.NewStackManager(cfg)
+
+	if err := ngFilter.SetIncludeOrExcludeMissingFilter(stackManager, false, &cfg.NodeGroups); err != nil {
+		return err
+	}
+	ngFilter.LogInfo(cfg.NodeGroups)
+
+	ngSubset, _ := ngFilter.MatchAll(cfg.NodeGroups)
+	ngCount := ngSubset.Len()
+
+	updateRequired := false
+
+	cmdutils.LogIntendedAction(cmd.Plan, "update security group rules of %d nodegroup(s) in cluster %q", ngCount, meta.Name)
+	err = ngFilter.ForEach(cfg.NodeGroups, func(_ int, ng *api.NodeGroup) error {
+		ngUpdateRequired, err := stackManager.UpdateNodeGroupSecurityGroupRules(ng, updateSSH[ng.Name], cmd.Plan)
+		if err != nil {
+			return errors.Wrapf(err, "updating security group rules of nodegroup %q", ng.Name)
+		}
+		updateRequired = updateRequired || ngUpdateRequired
+		if ngUpdateRequired && !cmd.Plan {
+			events.Updated(events.NodeGroupResource, meta.Name, ng.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	cmdutils.LogCompletedAction(cmd.Plan, "updated security group rules of %d nodegroup(s) in cluster %q", ngCount, meta.Name)
+
+	ami.RegisterImageFamilies(cfg.ImageFamilies)
+	amiUpdateRequired := false
+
+	err = ngFilter.ForEach(cfg.NodeGroups, func(_ int, ng *api.NodeGroup) error {
+		if !shouldUpdateAMI(ng, updateAMI) {
+			return nil
+		}
+		ngUpdateRequired, err := updateNodeGroupAMI(ctl, stackManager, ng, cmd.Plan)
+		if err != nil {
+			return errors.Wrapf(err, "updating AMI of nodegroup %q", ng.Name)
+		}
+		amiUpdateRequired = amiUpdateRequired || ngUpdateRequired
+		if ngUpdateRequired && !cmd.Plan {
+			events.Updated(events.NodeGroupResource, meta.Name, ng.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	updateRequired = updateRequired || amiUpdateRequired
+
+	if amiUpdateRequired && !cmd.Plan {
+		logger.Info("existing nodes keep using their current AMI, use 'eksctl replace node' to replace them with nodes that use the new AMI")
+	}
+
+	cmdutils.LogPlanModeWarning(cmd.Plan && updateRequired)
+
+	return nil
+}
+
+// shouldUpdateAMI tells whether the AMI of the nodegroup should be updated,
+// according to its amiUpdatePolicy and the --update-ami flag; nodegroups
+// with an AMI set in the config file are never updated
+func isSSHSet(ng *api.NodeGroup) bool {
+	if ng.SSH == nil {
+		return false
+	}
+	return ng.SSH.Allow != nil || ng.SSH.PublicKeyName != nil || ng.SSH.PublicKeyPath != nil || ng.SSH.PublicKey != nil || len(ng.SSH.SourceCIDRs) > 0
+}
+
+func shouldUpdateAMI(ng *api.NodeGroup, updateAMI bool) bool {
+	var update bool
+	switch ng.AMIUpdatePolicy {
+	case api.AMIUpdatePolicyPinned:
+		if updateAMI {
+			logger.Warning("not updating AMI of nodegroup %q, as its amiUpdatePolicy is %q", ng.Name, ng.AMIUpdatePolicy)
+		}
+		return false
+	case api.AMIUpdatePolicyLatestPatch:
+		update = true
+	default:
+		update = updateAMI
+	}
+	if update && hasExplicitAMI(ng) {
+		logger.Warning("not updating AMI of nodegroup %q, as it's set to %q in the config file", ng.Name, ng.AMI)
+		return false
+	}
+	return update
+}
+
+// hasExplicitAMI tells whether the AMI of the nodegroup is set to an image ID,
+// rather than to one of the resolvers
+func hasExplicitAMI(ng *api.NodeGroup) bool {
+	switch ng.AMI {
+	case "", ami.ResolverStatic, ami.ResolverAuto, ami.ResolverSSM:
+		return false
+	default:
+		return true
+	}
+}
+
+func updateNodeGroupAMI(ctl *eks.ClusterProvider, stackManager *manager.StackCollection, ng *api.NodeGroup, plan bool) (bool, error) {
+	summaries, err := stackManager.GetNodeGroupSummaries(ng.Name)
+	if err != nil {
+		return false, errors.Wrap(err, "getting nodegroup stack summaries")
+	}
+	if len(summaries) == 0 {
+		return false, fmt.Errorf("no stack found for nodegroup %q", ng.Name)
+	}
+
+	status, err := ctl.GetNodeGroupAMIStatus(stackManager, summaries[0])
+	if err != nil {
+		return false, err
+	}
+	if status.Unknown {
+		logger.Warning("skipping AMI update of nodegroup %q, as its latest AMI is %s", ng.Name, status.String())
+		return false, nil
+	}
+	if status.UpToDate() {
+		logger.Info("nodegroup %q already uses the latest AMI %q", ng.Name, status.LatestImageID)
+		return false, nil
+	}
+
+	logger.Info("AMI %q of nodegroup %q is %s for Kubernetes %s", status.CurrentImageID, ng.Name, status.String(), status.KubernetesVersion)
+	return stackManager.UpdateNodeGroupAMI(ng, status.LatestImageID, plan)
+}
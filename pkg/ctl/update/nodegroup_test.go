@@ -0,0 +1,31 @@
+package update
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+)
+
+var _ = Describe("update nodegroup", func() {
+	type amiUpdateCase struct {
+		ami       string
+		policy    string
+		updateAMI bool
+		expected  bool
+	}
+
+	DescribeTable("deciding whether to update the AMI",
+		func(c amiUpdateCase) {
+			ng := &api.NodeGroup{Name: "ng-1", AMI: c.ami, AMIUpdatePolicy: c.policy}
+			Expect(shouldUpdateAMI(ng, c.updateAMI)).To(Equal(c.expected))
+		},
+		Entry("manual policy without --update-ami", amiUpdateCase{ami: "static", updateAMI: false, expected: false}),
+		Entry("manual policy with --update-ami", amiUpdateCase{ami: "static", updateAMI: true, expected: true}),
+		Entry("latest-patch policy", amiUpdateCase{ami: "ssm", policy: api.AMIUpdatePolicyLatestPatch, expected: true}),
+		Entry("pinned policy with --update-ami", amiUpdateCase{ami: "static", policy: api.AMIUpdatePolicyPinned, updateAMI: true, expected: false}),
+		Entry("AMI set in the config file with latest-patch policy", amiUpdateCase{ami: "ami-123", policy: api.AMIUpdatePolicyLatestPatch, expected: false}),
+		Entry("AMI set in the config file with --update-ami", amiUpdateCase{ami: "ami-123", updateAMI: true, expected: false}),
+	)
+})
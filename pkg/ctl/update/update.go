@@ -11,6 +11,7 @@ func Command(flagGrouping *cmdutils.FlagGrouping) *cobra.Command {
 	verbCmd := cmdutils.NewVerbCmd("update", "Update resource(s)", "")
 
 	cmdutils.AddResourceCmd(flagGrouping, verbCmd, updateClusterCmd)
+	cmdutils.AddResourceCmd(flagGrouping, verbCmd, updateNodeGroupCmd)
 
 	return verbCmd
 }
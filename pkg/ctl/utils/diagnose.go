@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kris-nova/logger"
+	"github.com/spf13/pflag"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/diagnose"
+	"github.com/weaveworks/eksctl/pkg/printers"
+)
+
+func diagnoseCmd(cmd *cmdutils.Cmd) {
+	cfg := api.NewClusterConfig()
+	cmd.ClusterConfig = cfg
+
+	var (
+		output     string
+		bundle     bool
+		bundleFile string
+	)
+
+	cmd.SetDescription("diagnose", "Diagnose common problems of a cluster and create a support bundle", "")
+
+	cmd.SetRunFuncWithNameArg(func() error {
+		return doDiagnose(cmd, output, bundle, bundleFile)
+	})
+
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		fs.StringVar(&cfg.Metadata.Name, "cluster", "", "EKS cluster name")
+		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
+		fs.StringVarP(&output, "output", "o", "table", "specifies the output format (valid option: table, json, yaml)")
+		fs.BoolVar(&bundle, "bundle", true, "write a support bundle with the report and the raw data it's based on")
+		fs.StringVar(&bundleFile, "bundle-file", "", "path of the support bundle (default \"eksctl-diagnose-<cluster>-<timestamp>.tar.gz\")")
+		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
+	})
+
+	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
+}
+
+func doDiagnose(cmd *cmdutils.Cmd, output string, bundle bool, bundleFile string) error {
+	cfg := cmd.ClusterConfig
+
+	if cfg.Metadata.Name != "" && cmd.NameArg != "" {
+		return cmdutils.ErrFlagAndArg("--cluster", cfg.Metadata.Name, cmd.NameArg)
+	}
+
+	if cmd.NameArg != "" {
+		cfg.Metadata.Name = cmd.NameArg
+	}
+
+	if cfg.Metadata.Name == "" {
+		return cmdutils.ErrMustBeSet("--cluster")
+	}
+
+	printer, err := printers.NewPrinter(output)
+	if err != nil {
+		return err
+	}
+
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return err
+	}
+	logger.Info("using region %s", cfg.Metadata.Region)
+
+	if err := ctl.CheckAuth(); err != nil {
+		return err
+	}
+
+	diagnoser := diagnose.New(ctl, cfg)
+	if bundle {
+		diagnoser.Bundle = diagnose.NewBundle("eksctl-diagnose")
+	}
+
+	report := diagnoser.Run()
+
+	if bundle {
+		if bundleFile == "" {
+			bundleFile = fmt.Sprintf("eksctl-diagnose-%s-%s.tar.gz", cfg.Metadata.Name, report.Time.Format("20060102T150405Z"))
+		}
+		if err := diagnoser.Bundle.AddJSON("report.json", report); err != nil {
+			return err
+		}
+		if err := diagnoser.Bundle.WriteFile(bundleFile); err != nil {
+			return err
+		}
+		logger.Info("wrote support bundle %q, attach it to support tickets", bundleFile)
+	}
+
+	if output != "table" {
+		return printer.PrintObjWithKind("report", report, os.Stdout)
+	}
+
+	if len(report.Problems) == 0 {
+		logger.Success("no problems found with cluster %q", cfg.Metadata.Name)
+		return nil
+	}
+
+	addDiagnoseTableColumns(printer.(*printers.TablePrinter))
+	if err := printer.PrintObjWithKind("problems", report.Problems, os.Stdout); err != nil {
+		return err
+	}
+	logger.Warning("found %d problem(s) with cluster %q", len(report.Problems), cfg.Metadata.Name)
+	return nil
+}
+
+func addDiagnoseTableColumns(printer *printers.TablePrinter) {
+	printer.AddColumn("AREA", func(p diagnose.Problem) string {
+		return p.Area
+	})
+	printer.AddColumn("SEVERITY", func(p diagnose.Problem) string {
+		return strings.ToUpper(string(p.Severity))
+	})
+	printer.AddColumn("PROBLEM", func(p diagnose.Problem) string {
+		return p.Description
+	})
+	printer.AddColumn("SUGGESTION", func(p diagnose.Problem) string {
+		return p.Suggestion
+	})
+}
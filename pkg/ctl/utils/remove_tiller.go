@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/gitops/flux"
+)
+
+func removeTillerCmd(cmd *cmdutils.Cmd) {
+	cfg := api.NewClusterConfig()
+	cmd.ClusterConfig = cfg
+
+	cmd.SetDescription("remove-tiller", "Remove Tiller and its TLS secrets, as installed by 'eksctl install flux', once Helm 2 releases have been migrated to Helm 3", "")
+
+	var namespace string
+	cmd.SetRunFuncWithNameArg(func() error {
+		return doRemoveTiller(cmd, namespace)
+	})
+
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		cmdutils.AddNameFlag(fs, cfg.Metadata)
+		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
+		cmdutils.AddConfigFileFlag(fs, &cmd.ClusterConfigFile)
+		fs.StringVar(&namespace, "namespace", "flux", "Cluster namespace where Tiller was installed")
+		cmdutils.AddApproveFlag(fs, cmd)
+		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
+	})
+
+	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
+}
+
+func doRemoveTiller(cmd *cmdutils.Cmd, namespace string) error {
+	if err := cmdutils.NewMetadataLoader(cmd).Load(); err != nil {
+		return err
+	}
+
+	cfg := cmd.ClusterConfig
+	meta := cmd.ClusterConfig.Metadata
+
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return err
+	}
+	logger.Info("using region %s", meta.Region)
+
+	if err := ctl.CheckAuth(); err != nil {
+		return err
+	}
+
+	if err := ctl.RefreshClusterConfig(cfg); err != nil {
+		return errors.Wrapf(err, "getting credentials for cluster %q", meta.Name)
+	}
+
+	clientSet, err := ctl.NewStdClientSet(cfg)
+	if err != nil {
+		return err
+	}
+
+	found, err := flux.RemoveTiller(clientSet, namespace, cmd.Plan)
+	if err != nil {
+		return err
+	}
+	if !found {
+		logger.Info("Tiller is not installed in namespace %q of cluster %q", namespace, meta.Name)
+		return nil
+	}
+
+	cmdutils.LogPlanModeWarning(cmd.Plan)
+	logger.Warning("if the Tiller manifests (tiller-*.yaml) are stored in a Git repository synced by Flux, remove them from it, or Flux will re-create Tiller")
+	return nil
+}
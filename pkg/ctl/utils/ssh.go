@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/ssh"
+)
+
+func sshCmd(cmd *cmdutils.Cmd) {
+	cfg := api.NewClusterConfig()
+	cmd.ClusterConfig = cfg
+
+	var (
+		ngName, instanceID string
+		execute            bool
+	)
+
+	cmd.SetDescription("ssh", "Connect to nodes via SSM Session Manager", "List instances of a nodegroup and print or execute SSM session commands, nodegroup must have been created with ssh.enableSSM")
+
+	cmd.SetRunFunc(func() error {
+		return doSSH(cmd, ngName, instanceID, execute)
+	})
+
+	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
+		fs.StringVar(&cfg.Metadata.Name, "cluster", "", "EKS cluster name")
+		fs.StringVar(&ngName, "nodegroup", "", "nodegroup name")
+		fs.StringVar(&instanceID, "instance-id", "", "instance to connect to (can be omitted if nodegroup has only one instance)")
+		fs.BoolVar(&execute, "exec", false, "start the session instead of printing the command (requires AWS CLI with session manager plugin)")
+		cmdutils.AddRegionFlag(fs, cmd.ProviderConfig)
+	})
+
+	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
+}
+
+func doSSH(cmd *cmdutils.Cmd, ngName, instanceID string, execute bool) error {
+	cfg := cmd.ClusterConfig
+	meta := cmd.ClusterConfig.Metadata
+
+	if meta.Name == "" {
+		return cmdutils.ErrMustBeSet("--cluster")
+	}
+	if ngName == "" {
+		return cmdutils.ErrMustBeSet("--nodegroup")
+	}
+
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return err
+	}
+	logger.Info("using region %s", meta.Region)
+
+	if err := ctl.CheckAuth(); err != nil {
+		return err
+	}
+
+	instances, err := ssh.ListNodeGroupInstances(meta.Name, ngName, ctl.Provider)
+	if err != nil {
+		return err
+	}
+	if len(instances) == 0 {
+		return fmt.Errorf("no running instances found in nodegroup %q of cluster %q", ngName, meta.Name)
+	}
+
+	if !execute {
+		logger.Info("found %d instance(s) in nodegroup %q, use the following command(s) to start a session", len(instances), ngName)
+		for _, instance := range instances {
+			if instanceID != "" && aws.StringValue(instance.InstanceId) != instanceID {
+				continue
+			}
+			fmt.Println(strings.Join(ssh.SessionCommand(cfg.Metadata.Region, aws.StringValue(instance.InstanceId)), " "))
+		}
+		return nil
+	}
+
+	instance, err := ssh.FindInstance(instances, instanceID)
+	if err != nil {
+		return err
+	}
+
+	args := ssh.SessionCommand(cfg.Metadata.Region, aws.StringValue(instance.InstanceId))
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return errors.Wrap(err, "AWS CLI is required to start a session")
+	}
+
+	logger.Info("starting session to instance %q", aws.StringValue(instance.InstanceId))
+	session := exec.Command(path, args[1:]...)
+	session.Stdin = os.Stdin
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+	return session.Run()
+}
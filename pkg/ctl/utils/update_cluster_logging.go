@@ -11,6 +11,8 @@ import (
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/events"
 	"github.com/weaveworks/eksctl/pkg/printers"
 )
 
@@ -88,10 +90,29 @@ func doEnableLogging(cmd *cmdutils.Cmd, logTypesToEnable []string, logTypesToDis
 	willBeDisabled := sets.NewString(api.SupportedCloudWatchClusterLogTypes()...).Difference(willBeEnabled)
 	updateRequired := !currentlyEnabled.Equal(willBeEnabled)
 
+	logGroupUpdateRequired := false
+	if cfg.HasClusterLogGroupSettings() {
+		logGroup, err := ctl.GetClusterLogGroup(meta)
+		if err != nil {
+			return err
+		}
+		logGroupUpdateRequired = !eks.IsClusterLogGroupUpToDate(cfg, logGroup)
+	}
+
 	if err = printer.LogObj(logger.Debug, "cfg.json = \\\n%s\n", cfg); err != nil {
 		return err
 	}
 
+	if logGroupUpdateRequired {
+		cmdutils.LogIntendedAction(cmd.Plan, "update retention and encryption of log group %q", eks.ClusterLogGroupName(meta.Name))
+		if !cmd.Plan && !updateRequired {
+			if err := ctl.EnsureClusterLogGroup(cfg); err != nil {
+				return err
+			}
+			events.Updated(events.LogGroupResource, meta.Name, eks.ClusterLogGroupName(meta.Name))
+		}
+	}
+
 	if updateRequired {
 		describeTypesToEnable := "no types to enable"
 		if len(willBeEnabled.List()) > 0 {
@@ -110,12 +131,13 @@ func doEnableLogging(cmd *cmdutils.Cmd, logTypesToEnable []string, logTypesToDis
 			if err := ctl.UpdateClusterConfigForLogging(cfg); err != nil {
 				return err
 			}
+			events.Updated(events.ClusterResource, meta.Name, meta.Name)
 		}
-	} else {
+	} else if !logGroupUpdateRequired {
 		logger.Success("CloudWatch logging for cluster %q in %q is already up-to-date", meta.Name, meta.Region)
 	}
 
-	cmdutils.LogPlanModeWarning(cmd.Plan && updateRequired)
+	cmdutils.LogPlanModeWarning(cmd.Plan && (updateRequired || logGroupUpdateRequired))
 
 	return nil
 }
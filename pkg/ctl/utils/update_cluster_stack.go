@@ -1,18 +1,21 @@
 package utils
 
 import (
+	"errors"
 	"os"
 
 	"github.com/kris-nova/logger"
 	"github.com/spf13/cobra"
 
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/events"
 )
 
 func updateClusterStackCmd(cmd *cmdutils.Cmd) {
 	cmd.SetDescription("update-cluster-stack", "DEPRECATED: Use 'eksctl update cluster' instead", "")
 
 	cmd.CobraCommand.Run = func(cobraCmd *cobra.Command, _ []string) {
+		events.EmitResult(cobraCmd.CommandPath(), errors.New(cobraCmd.Short))
 		logger.Critical(cobraCmd.Short)
 		os.Exit(1)
 	}
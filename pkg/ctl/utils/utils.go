@@ -17,6 +17,9 @@ func Command(flagGrouping *cmdutils.FlagGrouping) *cobra.Command {
 	cmdutils.AddResourceCmd(flagGrouping, verbCmd, updateAWSNodeCmd)
 	cmdutils.AddResourceCmd(flagGrouping, verbCmd, updateCoreDNSCmd)
 	cmdutils.AddResourceCmd(flagGrouping, verbCmd, enableLoggingCmd)
+	cmdutils.AddResourceCmd(flagGrouping, verbCmd, sshCmd)
+	cmdutils.AddResourceCmd(flagGrouping, verbCmd, diagnoseCmd)
+	cmdutils.AddResourceCmd(flagGrouping, verbCmd, removeTillerCmd)
 
 	return verbCmd
 }
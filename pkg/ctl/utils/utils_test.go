@@ -4,6 +4,9 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
 )
 
 func TestValidateLoggingFlags(t *testing.T) {
@@ -98,3 +101,45 @@ func TestValidateLoggingFlags(t *testing.T) {
 	}
 
 }
+
+func TestMatchesSelector(t *testing.T) {
+	tags := []*cloudformation.Tag{
+		{Key: aws.String("alpha.eksctl.io/cluster-name"), Value: aws.String("cluster-1")},
+		{Key: aws.String("team"), Value: aws.String("platform")},
+		{Key: aws.String("env"), Value: aws.String("dev")},
+	}
+
+	selectorTests := []struct {
+		selector map[string]string
+		matches  bool
+	}{
+		{
+			selector: map[string]string{},
+			matches:  true,
+		},
+		{
+			selector: map[string]string{"team": "platform"},
+			matches:  true,
+		},
+		{
+			selector: map[string]string{"team": "platform", "env": "dev"},
+			matches:  true,
+		},
+		{
+			selector: map[string]string{"team": "platform", "env": "prod"},
+			matches:  false,
+		},
+		{
+			selector: map[string]string{"owner": "platform"},
+			matches:  false,
+		},
+	}
+
+	for i, tt := range selectorTests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if matches := matchesSelector(tags, tt.selector); matches != tt.matches {
+				t.Errorf("expected %v for selector %v; got %v", tt.matches, tt.selector, matches)
+			}
+		})
+	}
+}
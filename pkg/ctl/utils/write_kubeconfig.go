@@ -3,29 +3,43 @@ package utils
 import (
 	"fmt"
 
+	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/kris-nova/logger"
 	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/ctl/cmdutils"
+	"github.com/weaveworks/eksctl/pkg/eks"
 	"github.com/weaveworks/eksctl/pkg/utils/kubeconfig"
 )
 
+type writeKubeconfigCmdParams struct {
+	outputPath           string
+	authenticator        string
+	authenticatorRoleARN string
+	setContext, autoPath bool
+
+	allRegions, prune bool
+	selector          map[string]string
+	chunkSize         int
+}
+
+// isMultiCluster returns true if any of the flags that enumerate clusters are used
+func (p *writeKubeconfigCmdParams) isMultiCluster() bool {
+	return p.allRegions || p.prune || len(p.selector) > 0
+}
+
 func writeKubeconfigCmd(cmd *cmdutils.Cmd) {
 	cfg := api.NewClusterConfig()
 	cmd.ClusterConfig = cfg
 
-	var (
-		outputPath           string
-		authenticatorRoleARN string
-		setContext, autoPath bool
-	)
+	params := &writeKubeconfigCmdParams{}
 
-	cmd.SetDescription("write-kubeconfig", "Write kubeconfig file for a given cluster", "")
+	cmd.SetDescription("write-kubeconfig", "Write kubeconfig file for a given cluster", "Write kubeconfig file for a given cluster, or for all clusters in one or all regions when --all-regions, --selector or --prune is used")
 
 	cmd.SetRunFuncWithNameArg(func() error {
-		return doWriteKubeconfigCmd(cmd, outputPath, authenticatorRoleARN, setContext, autoPath)
+		return doWriteKubeconfigCmd(cmd, params)
 	})
 
 	cmd.FlagSetGroup.InFlagSet("General", func(fs *pflag.FlagSet) {
@@ -34,16 +48,31 @@ func writeKubeconfigCmd(cmd *cmdutils.Cmd) {
 		cmdutils.AddTimeoutFlag(fs, &cmd.ProviderConfig.WaitTimeout)
 	})
 
+	cmd.FlagSetGroup.InFlagSet("Multiple clusters", func(fs *pflag.FlagSet) {
+		fs.BoolVarP(&params.allRegions, "all-regions", "A", false, "write kubeconfig for clusters in all supported regions")
+		fs.StringToStringVar(&params.selector, "selector", nil, `only write kubeconfig for clusters with matching tags (set via metadata.tags), e.g. "team=platform,env=dev"`)
+		fs.BoolVar(&params.prune, "prune", false, "remove clusters created by eksctl that no longer exist from kubeconfig")
+		fs.IntVar(&params.chunkSize, "chunk-size", 100, "number of clusters to list per request")
+	})
+
 	cmd.FlagSetGroup.InFlagSet("Output kubeconfig", func(fs *pflag.FlagSet) {
-		cmdutils.AddCommonFlagsForKubeconfig(fs, &outputPath, &authenticatorRoleARN, &setContext, &autoPath, "<name>")
+		cmdutils.AddCommonFlagsForKubeconfig(fs, &params.outputPath, &params.authenticator, &params.authenticatorRoleARN, &params.setContext, &params.autoPath, "<name>")
 	})
 
 	cmdutils.AddCommonFlagsForAWS(cmd.FlagSetGroup, cmd.ProviderConfig, false)
 }
 
-func doWriteKubeconfigCmd(cmd *cmdutils.Cmd, outputPath, roleARN string, setContext, autoPath bool) error {
+func doWriteKubeconfigCmd(cmd *cmdutils.Cmd, params *writeKubeconfigCmdParams) error {
 	cfg := cmd.ClusterConfig
 
+	if err := cmdutils.ValidateAuthenticatorFlag(params.authenticator); err != nil {
+		return err
+	}
+
+	if params.isMultiCluster() {
+		return doWriteKubeconfigForClusters(cmd, params)
+	}
+
 	// TODO: move this into a loader when --config-file gets added to this command
 	if cfg.Metadata.Name != "" && cmd.NameArg != "" {
 		return cmdutils.ErrNameFlagAndArg(cfg.Metadata.Name, cmd.NameArg)
@@ -57,7 +86,8 @@ func doWriteKubeconfigCmd(cmd *cmdutils.Cmd, outputPath, roleARN string, setCont
 		return cmdutils.ErrMustBeSet("--name")
 	}
 
-	if autoPath {
+	outputPath := params.outputPath
+	if params.autoPath {
 		if outputPath != kubeconfig.DefaultPath {
 			return fmt.Errorf("--kubeconfig and --auto-kubeconfig %s", cmdutils.IncompatibleFlags)
 		}
@@ -78,8 +108,8 @@ func doWriteKubeconfigCmd(cmd *cmdutils.Cmd, outputPath, roleARN string, setCont
 		return err
 	}
 
-	kubectlConfig := kubeconfig.NewForKubectl(cfg, ctl.GetUsername(), roleARN, ctl.Provider.Profile())
-	filename, err := kubeconfig.Write(outputPath, *kubectlConfig, setContext)
+	kubectlConfig := kubeconfig.NewForKubectl(cfg, ctl.GetUsername(), params.authenticator, params.authenticatorRoleARN, ctl.Provider.Profile())
+	filename, err := kubeconfig.Write(outputPath, *kubectlConfig, params.setContext)
 	if err != nil {
 		return errors.Wrap(err, "writing kubeconfig")
 	}
@@ -88,3 +118,115 @@ func doWriteKubeconfigCmd(cmd *cmdutils.Cmd, outputPath, roleARN string, setCont
 
 	return nil
 }
+
+func doWriteKubeconfigForClusters(cmd *cmdutils.Cmd, params *writeKubeconfigCmdParams) error {
+	if cmd.ClusterConfig.Metadata.Name != "" || cmd.NameArg != "" {
+		return fmt.Errorf("--name cannot be used with --all-regions, --selector or --prune")
+	}
+
+	if params.autoPath && params.outputPath != kubeconfig.DefaultPath {
+		return fmt.Errorf("--kubeconfig and --auto-kubeconfig %s", cmdutils.IncompatibleFlags)
+	}
+
+	if params.autoPath && params.prune {
+		return fmt.Errorf("--prune and --auto-kubeconfig %s", cmdutils.IncompatibleFlags)
+	}
+
+	ctl, err := cmd.NewCtl()
+	if err != nil {
+		return err
+	}
+
+	if err := ctl.CheckAuth(); err != nil {
+		return err
+	}
+
+	clusters, listedRegions, err := ctl.ListAllClusters(params.chunkSize, params.allRegions)
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]bool{}
+	written := 0
+	for _, cl := range clusters {
+		existing[cl.String()] = true
+
+		clusterConfig := api.NewClusterConfig()
+		clusterConfig.Metadata.Name = cl.Name
+		clusterConfig.Metadata.Region = cl.Region
+
+		regionalCtl := eks.New(&api.ProviderConfig{
+			Region:      cl.Region,
+			Profile:     cmd.ProviderConfig.Profile,
+			WaitTimeout: cmd.ProviderConfig.WaitTimeout,
+		}, clusterConfig)
+
+		if len(params.selector) > 0 {
+			stack, err := regionalCtl.NewStackManager(clusterConfig).DescribeClusterStack()
+			if err != nil || !matchesSelector(stack.Tags, params.selector) {
+				logger.Debug("skipping %s, as it doesn't match the selector", cl.LogString())
+				continue
+			}
+		}
+
+		if err := regionalCtl.RefreshClusterConfig(clusterConfig); err != nil {
+			logger.Warning("skipping %s: %s", cl.LogString(), err.Error())
+			continue
+		}
+
+		outputPath := params.outputPath
+		if params.autoPath {
+			outputPath = kubeconfig.AutoPath(cl.Name)
+		}
+
+		kubectlConfig := kubeconfig.NewForKubectl(clusterConfig, ctl.GetUsername(), params.authenticator, params.authenticatorRoleARN, ctl.Provider.Profile())
+		filename, err := kubeconfig.Write(outputPath, *kubectlConfig, false)
+		if err != nil {
+			return errors.Wrapf(err, "writing kubeconfig for %s", cl.LogString())
+		}
+		logger.Info("saved kubeconfig for %s as %q", cl.LogString(), filename)
+		written++
+	}
+
+	logger.Success("saved kubeconfig for %d cluster(s)", written)
+
+	if !params.prune {
+		return nil
+	}
+
+	// only prune clusters in regions that were listed successfully,
+	// otherwise clusters would be removed due to transient errors
+	isListed := map[string]bool{}
+	for _, region := range listedRegions {
+		isListed[region] = true
+	}
+	removed, err := kubeconfig.Prune(params.outputPath, func(cl *api.ClusterMeta) bool {
+		return isListed[cl.Region] && !existing[cl.String()]
+	})
+	if err != nil {
+		return err
+	}
+	for _, clusterName := range removed {
+		logger.Info("removed cluster %q that no longer exists from kubeconfig", clusterName)
+	}
+	logger.Success("pruned %d cluster(s) from kubeconfig", len(removed))
+
+	return nil
+}
+
+// matchesSelector checks if all key-value pairs of the selector are present in the stack tags
+func matchesSelector(tags []*cloudformation.Tag, selector map[string]string) bool {
+	for key, value := range selector {
+		found := false
+		for _, tag := range tags {
+			if *tag.Key == key && *tag.Value == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
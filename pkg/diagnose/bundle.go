@@ -0,0 +1,91 @@
+package diagnose
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Bundle is a support bundle, i.e. a set of files that are written as a tarball
+type Bundle struct {
+	dir   string
+	time  time.Time
+	files map[string][]byte
+}
+
+// NewBundle creates an empty bundle, files are put in dir inside the tarball
+func NewBundle(dir string) *Bundle {
+	return &Bundle{
+		dir:   dir,
+		time:  time.Now(),
+		files: make(map[string][]byte),
+	}
+}
+
+// AddJSON adds obj to the bundle as an indented JSON file
+func (b *Bundle) AddJSON(name string, obj interface{}) error {
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "encoding %q", name)
+	}
+	b.files[name] = append(data, '\n')
+	return nil
+}
+
+// Files returns the names of all files in the bundle
+func (b *Bundle) Files() []string {
+	names := []string{}
+	for name := range b.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Write writes the bundle as a gzipped tarball
+func (b *Bundle) Write(w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	for _, name := range b.Files() {
+		data := b.files[name]
+		header := &tar.Header{
+			Name:    path.Join(b.dir, name),
+			Mode:    0600,
+			Size:    int64(len(data)),
+			ModTime: b.time,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return errors.Wrapf(err, "writing header of %q", name)
+		}
+		if _, err := io.Copy(tw, bytes.NewReader(data)); err != nil {
+			return errors.Wrapf(err, "writing %q", name)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// WriteFile writes the bundle to a file
+func (b *Bundle) WriteFile(filename string) error {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "creating bundle %q", filename)
+	}
+	if err := b.Write(f); err != nil {
+		_ = f.Close()
+		return errors.Wrapf(err, "writing bundle %q", filename)
+	}
+	return f.Close()
+}
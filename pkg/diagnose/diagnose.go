@@ -0,0 +1,376 @@
+package diagnose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cfn "github.com/aws/aws-sdk-go/service/cloudformation"
+	awseks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/kris-nova/logger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+
+	defaultaddons "github.com/weaveworks/eksctl/pkg/addons/default"
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/authconfigmap"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+	"github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/iam"
+	"github.com/weaveworks/eksctl/pkg/vpc"
+)
+
+// Severity of a problem
+type Severity string
+
+// Severities of problems
+const (
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Problem is something that is wrong with the cluster
+type Problem struct {
+	Area        string   `json:"area"`
+	Severity    Severity `json:"severity"`
+	Description string   `json:"description"`
+	// Suggestion is usually an eksctl command that fixes the problem
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// ControlPlane is the status of the EKS control plane
+type ControlPlane struct {
+	Status          string `json:"status"`
+	Version         string `json:"version"`
+	PlatformVersion string `json:"platformVersion"`
+	Endpoint        string `json:"endpoint"`
+}
+
+// Stack is the status of a CloudFormation stack of the cluster
+type Stack struct {
+	Name         string `json:"name"`
+	NodeGroup    string `json:"nodeGroup,omitempty"`
+	Status       string `json:"status"`
+	StatusReason string `json:"statusReason,omitempty"`
+}
+
+// Node is the status of a Kubernetes node
+type Node struct {
+	Name           string `json:"name"`
+	NodeGroup      string `json:"nodeGroup,omitempty"`
+	Ready          bool   `json:"ready"`
+	KubeletVersion string `json:"kubeletVersion"`
+}
+
+// AddOn is the image tag of an installed add-on, compared to the tag that
+// eksctl would install
+type AddOn struct {
+	Name        string `json:"name"`
+	ImageTag    string `json:"imageTag"`
+	ExpectedTag string `json:"expectedTag"`
+}
+
+// Report holds everything that was found out about a cluster
+type Report struct {
+	Cluster string    `json:"cluster"`
+	Region  string    `json:"region"`
+	Time    time.Time `json:"time"`
+
+	ControlPlane *ControlPlane `json:"controlPlane,omitempty"`
+	Stacks       []Stack       `json:"stacks"`
+	Nodes        []Node        `json:"nodes"`
+	AddOns       []AddOn       `json:"addOns"`
+
+	DanglingNetworkInterfaces []string `json:"danglingNetworkInterfaces"`
+
+	Problems []Problem `json:"problems"`
+}
+
+func (r *Report) addProblem(area string, severity Severity, suggestion, descriptionFmt string, args ...interface{}) {
+	r.Problems = append(r.Problems, Problem{
+		Area:        area,
+		Severity:    severity,
+		Description: fmt.Sprintf(descriptionFmt, args...),
+		Suggestion:  suggestion,
+	})
+}
+
+func (r *Report) addCheckError(area string, err error) {
+	r.addProblem(area, SeverityWarning, "", "unable to check: %s", err.Error())
+}
+
+// Diagnoser gathers the status of a cluster and looks for common problems
+type Diagnoser struct {
+	ctl  *eks.ClusterProvider
+	spec *api.ClusterConfig
+
+	// Bundle collects raw data for a support bundle, if it's set
+	Bundle *Bundle
+}
+
+// New creates a diagnoser for the cluster
+func New(ctl *eks.ClusterProvider, spec *api.ClusterConfig) *Diagnoser {
+	return &Diagnoser{
+		ctl:  ctl,
+		spec: spec,
+	}
+}
+
+// Run performs all checks, problems with the checks themselves are reported as
+// warnings, so that as much as possible is diagnosed
+func (d *Diagnoser) Run() *Report {
+	meta := d.spec.Metadata
+	report := &Report{
+		Cluster: meta.Name,
+		Region:  meta.Region,
+		Time:    time.Now().UTC(),
+	}
+
+	cluster, err := d.ctl.DescribeControlPlane(meta)
+	if err != nil {
+		report.addCheckError("control plane", err)
+	} else {
+		d.addToBundle("control-plane.json", cluster)
+		report.ControlPlane = checkControlPlane(report, cluster)
+	}
+
+	stackManager := d.ctl.NewStackManager(d.spec)
+	stacks, err := stackManager.ListStacksForCluster()
+	if err != nil {
+		report.addCheckError("stacks", err)
+	} else {
+		d.addToBundle("stacks.json", stacks)
+		report.Stacks = checkStacks(report, stackManager, stacks)
+		if d.Bundle != nil {
+			for _, s := range stacks {
+				if events, err := stackManager.DescribeStackEvents(s); err == nil {
+					d.addToBundle(fmt.Sprintf("stack-events/%s.json", aws.StringValue(s.StackName)), events)
+				}
+			}
+		}
+	}
+
+	if err := d.ctl.LoadClusterVPC(d.spec); err != nil {
+		report.addCheckError("network interfaces", err)
+	} else if enis, err := vpc.FindDanglingENIs(d.ctl.Provider.EC2(), d.spec); err != nil {
+		report.addCheckError("network interfaces", err)
+	} else {
+		report.DanglingNetworkInterfaces = checkNetworkInterfaces(report, enis)
+	}
+
+	if report.ControlPlane == nil || report.ControlPlane.Status != awseks.ClusterStatusActive {
+		logger.Warning("skipping Kubernetes checks, as the control plane isn't active")
+		return report
+	}
+	if err := d.ctl.RefreshClusterConfig(d.spec); err != nil {
+		report.addCheckError("Kubernetes", err)
+		return report
+	}
+	rawClient, err := d.ctl.NewRawClient(d.spec)
+	if err != nil {
+		report.addCheckError("Kubernetes", err)
+		return report
+	}
+	clientSet := rawClient.ClientSet()
+
+	nodes, err := clientSet.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		report.addCheckError("nodes", err)
+	} else {
+		d.addToBundle("nodes.json", nodes)
+		report.Nodes = checkNodes(report, nodes.Items, report.Stacks)
+	}
+
+	if pods, err := clientSet.CoreV1().Pods(metav1.NamespaceSystem).List(metav1.ListOptions{}); err == nil {
+		d.addToBundle("kube-system-pods.json", pods)
+	}
+
+	serverVersion, err := rawClient.ServerVersion()
+	if err != nil {
+		report.addCheckError("add-ons", err)
+	} else if addOns, err := checkAddOns(report, clientSet, serverVersion); err != nil {
+		report.addCheckError("add-ons", err)
+	} else {
+		report.AddOns = addOns
+	}
+
+	roles := []string{}
+	for _, s := range stacks {
+		if name := stackManager.GetNodeGroupName(s); name != "" && hasOutputs(s) {
+			ng := &api.NodeGroup{Name: name}
+			if err := iam.UseFromNodeGroup(d.ctl.Provider, s, ng); err != nil {
+				report.addCheckError("aws-auth", err)
+				continue
+			}
+			roles = append(roles, ng.IAM.InstanceRoleARN)
+		}
+	}
+	if acm, err := authconfigmap.NewFromClientSet(clientSet); err != nil {
+		report.addCheckError("aws-auth", err)
+	} else if mapRoles, err := acm.Roles(); err != nil {
+		report.addCheckError("aws-auth", err)
+	} else {
+		d.addToBundle("aws-auth.json", mapRoles)
+		checkAuthConfigMap(report, mapRoles, roles)
+	}
+
+	return report
+}
+
+func (d *Diagnoser) addToBundle(name string, obj interface{}) {
+	if d.Bundle == nil {
+		return
+	}
+	if err := d.Bundle.AddJSON(name, obj); err != nil {
+		logger.Warning("unable to add %q to bundle: %s", name, err.Error())
+	}
+}
+
+// hasOutputs tells whether the stack was created successfully, so its outputs can be used
+func hasOutputs(s *manager.Stack) bool {
+	switch aws.StringValue(s.StackStatus) {
+	case cfn.StackStatusCreateComplete, cfn.StackStatusUpdateComplete, cfn.StackStatusUpdateRollbackComplete:
+		return true
+	}
+	return false
+}
+
+func checkControlPlane(report *Report, cluster *awseks.Cluster) *ControlPlane {
+	controlPlane := &ControlPlane{
+		Status:          aws.StringValue(cluster.Status),
+		Version:         aws.StringValue(cluster.Version),
+		PlatformVersion: aws.StringValue(cluster.PlatformVersion),
+		Endpoint:        aws.StringValue(cluster.Endpoint),
+	}
+	switch controlPlane.Status {
+	case awseks.ClusterStatusActive:
+	case awseks.ClusterStatusCreating, awseks.ClusterStatusUpdating:
+		report.addProblem("control plane", SeverityWarning, "", "control plane is %s, check again once it's done", strings.ToLower(controlPlane.Status))
+	default:
+		report.addProblem("control plane", SeverityError,
+			fmt.Sprintf("eksctl delete cluster --region=%s --name=%s", report.Region, report.Cluster),
+			"control plane status is %s", controlPlane.Status)
+	}
+	return controlPlane
+}
+
+func checkStacks(report *Report, stackManager *manager.StackCollection, stacks []*manager.Stack) []Stack {
+	result := []Stack{}
+	hasClusterStack := false
+	for _, s := range stacks {
+		stack := Stack{
+			Name:         aws.StringValue(s.StackName),
+			NodeGroup:    stackManager.GetNodeGroupName(s),
+			Status:       aws.StringValue(s.StackStatus),
+			StatusReason: aws.StringValue(s.StackStatusReason),
+		}
+		result = append(result, stack)
+		if stack.NodeGroup == "" {
+			hasClusterStack = true
+		}
+
+		describeStacks := fmt.Sprintf("eksctl utils describe-stacks --region=%s --name=%s --events", report.Region, report.Cluster)
+		switch {
+		case strings.HasSuffix(stack.Status, "_IN_PROGRESS"):
+			report.addProblem("stacks", SeverityWarning, "", "stack %q is %s", stack.Name, stack.Status)
+		case stack.Status == cfn.StackStatusRollbackComplete || strings.HasSuffix(stack.Status, "_FAILED"):
+			suggestion := describeStacks
+			if stack.NodeGroup != "" && stack.Status != cfn.StackStatusUpdateRollbackFailed {
+				suggestion = fmt.Sprintf("eksctl delete nodegroup --region=%s --cluster=%s --name=%s", report.Region, report.Cluster, stack.NodeGroup)
+			}
+			report.addProblem("stacks", SeverityError, suggestion, "stack %q is %s: %s", stack.Name, stack.Status, stack.StatusReason)
+		case stack.Status == cfn.StackStatusUpdateRollbackComplete:
+			report.addProblem("stacks", SeverityWarning, describeStacks, "last update of stack %q was rolled back", stack.Name)
+		}
+	}
+	if !hasClusterStack {
+		report.addProblem("stacks", SeverityWarning, "", "no cluster stack found, the cluster may not have been created by eksctl")
+	}
+	return result
+}
+
+func checkNodes(report *Report, nodes []corev1.Node, stacks []Stack) []Node {
+	result := []Node{}
+	nodeGroupsWithNodes := sets.NewString()
+	for i := range nodes {
+		node := Node{
+			Name:           nodes[i].Name,
+			NodeGroup:      nodes[i].Labels[api.NodeGroupNameLabel],
+			Ready:          eks.IsNodeReady(&nodes[i]),
+			KubeletVersion: nodes[i].Status.NodeInfo.KubeletVersion,
+		}
+		result = append(result, node)
+		nodeGroupsWithNodes.Insert(node.NodeGroup)
+		if !node.Ready {
+			report.addProblem("nodes", SeverityError, fmt.Sprintf("kubectl describe node %s", node.Name), "node %q is not ready", node.Name)
+		}
+	}
+
+	for _, stack := range stacks {
+		if stack.NodeGroup == "" || strings.HasSuffix(stack.Status, "_FAILED") || nodeGroupsWithNodes.Has(stack.NodeGroup) {
+			continue
+		}
+		report.addProblem("nodes", SeverityWarning,
+			fmt.Sprintf("eksctl get nodegroup --region=%s --cluster=%s --name=%s", report.Region, report.Cluster, stack.NodeGroup),
+			"nodegroup %q has no nodes that have joined the cluster, it may be scaled to zero or nodes are unable to join", stack.NodeGroup)
+	}
+	return result
+}
+
+func checkAddOns(report *Report, clientSet kubernetes.Interface, serverVersion string) ([]AddOn, error) {
+	expected, err := defaultaddons.ImageTags(serverVersion)
+	if err != nil {
+		return nil, err
+	}
+	current, err := defaultaddons.CurrentImageTags(clientSet)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := []AddOn{}
+	for _, name := range names {
+		tag, ok := current[name]
+		if !ok {
+			report.addProblem("add-ons", SeverityError, "", "%s is not installed", name)
+			continue
+		}
+		result = append(result, AddOn{Name: name, ImageTag: tag, ExpectedTag: expected[name]})
+		if tag != expected[name] {
+			report.addProblem("add-ons", SeverityWarning,
+				fmt.Sprintf("eksctl utils update-%s --region=%s --name=%s --approve", name, report.Region, report.Cluster),
+				"%s is %s, but %s is expected for Kubernetes %s", name, tag, expected[name], serverVersion)
+		}
+	}
+	return result, nil
+}
+
+func checkAuthConfigMap(report *Report, mapRoles authconfigmap.MapRoles, nodeGroupRoles []string) {
+	for _, role := range nodeGroupRoles {
+		if len(mapRoles.Get(role)) > 0 {
+			continue
+		}
+		report.addProblem("aws-auth", SeverityError,
+			fmt.Sprintf("eksctl create iamidentitymapping --region=%s --cluster=%s --role=%s --username=%s --group=%s",
+				report.Region, report.Cluster, role, authconfigmap.RoleNodeGroupUsername, strings.Join(authconfigmap.RoleNodeGroupGroups, " --group=")),
+			"nodegroup role %q is not mapped in aws-auth ConfigMap, so its nodes cannot join", role)
+	}
+}
+
+func checkNetworkInterfaces(report *Report, enis []string) []string {
+	if len(enis) > 0 {
+		report.addProblem("network interfaces", SeverityWarning,
+			fmt.Sprintf("aws ec2 delete-network-interface --region=%s --network-interface-id=<ID>", report.Region),
+			"found %d dangling network interface(s) (%s), they may prevent deletion of the VPC", len(enis), strings.Join(enis, ", "))
+	}
+	return enis
+}
@@ -0,0 +1,121 @@
+package diagnose
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cfn "github.com/aws/aws-sdk-go/service/cloudformation"
+	awseks "github.com/aws/aws-sdk-go/service/eks"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/authconfigmap"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+)
+
+func newNode(name, nodeGroup string, ready corev1.ConditionStatus) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{api.NodeGroupNameLabel: nodeGroup},
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: ready},
+			},
+		},
+	}
+}
+
+func newStack(name, nodeGroup, status string) *manager.Stack {
+	s := &manager.Stack{
+		StackName:   aws.String(name),
+		StackStatus: aws.String(status),
+	}
+	if nodeGroup != "" {
+		s.Tags = []*cfn.Tag{{Key: aws.String(api.NodeGroupNameTag), Value: aws.String(nodeGroup)}}
+	}
+	return s
+}
+
+var _ = Describe("Diagnose", func() {
+	var report *Report
+
+	BeforeEach(func() {
+		report = &Report{Cluster: "test", Region: "us-west-2"}
+	})
+
+	It("reports a control plane that isn't active", func() {
+		controlPlane := checkControlPlane(report, &awseks.Cluster{Status: aws.String(awseks.ClusterStatusFailed)})
+		Expect(controlPlane.Status).To(Equal(awseks.ClusterStatusFailed))
+		Expect(report.Problems).To(HaveLen(1))
+		Expect(report.Problems[0].Severity).To(Equal(SeverityError))
+	})
+
+	It("reports failed stacks and suggests deleting failed nodegroups", func() {
+		stacks := checkStacks(report, &manager.StackCollection{}, []*manager.Stack{
+			newStack("eksctl-test-cluster", "", cfn.StackStatusCreateComplete),
+			newStack("eksctl-test-nodegroup-ng-1", "ng-1", cfn.StackStatusRollbackComplete),
+		})
+		Expect(stacks).To(HaveLen(2))
+		Expect(stacks[1].NodeGroup).To(Equal("ng-1"))
+		Expect(report.Problems).To(HaveLen(1))
+		Expect(report.Problems[0].Suggestion).To(Equal("eksctl delete nodegroup --region=us-west-2 --cluster=test --name=ng-1"))
+	})
+
+	It("reports nodes that aren't ready and nodegroups without nodes", func() {
+		nodes := checkNodes(report, []corev1.Node{
+			newNode("node-1", "ng-1", corev1.ConditionTrue),
+			newNode("node-2", "ng-1", corev1.ConditionFalse),
+		}, []Stack{
+			{Name: "eksctl-test-nodegroup-ng-1", NodeGroup: "ng-1", Status: cfn.StackStatusCreateComplete},
+			{Name: "eksctl-test-nodegroup-ng-2", NodeGroup: "ng-2", Status: cfn.StackStatusCreateComplete},
+		})
+		Expect(nodes).To(HaveLen(2))
+		Expect(nodes[0].Ready).To(BeTrue())
+		Expect(nodes[1].Ready).To(BeFalse())
+		Expect(report.Problems).To(HaveLen(2))
+		Expect(report.Problems[0].Description).To(ContainSubstring(`node "node-2" is not ready`))
+		Expect(report.Problems[1].Description).To(ContainSubstring(`nodegroup "ng-2" has no nodes`))
+	})
+
+	It("reports nodegroup roles missing from aws-auth", func() {
+		mapRoles := authconfigmap.MapRoles{
+			{RoleARN: "arn:aws:iam::123:role/ng-1"},
+		}
+		checkAuthConfigMap(report, mapRoles, []string{"arn:aws:iam::123:role/ng-1", "arn:aws:iam::123:role/ng-2"})
+		Expect(report.Problems).To(HaveLen(1))
+		Expect(report.Problems[0].Suggestion).To(ContainSubstring("--role=arn:aws:iam::123:role/ng-2"))
+		Expect(report.Problems[0].Suggestion).To(ContainSubstring("--group=system:bootstrappers --group=system:nodes"))
+	})
+
+	It("writes a bundle as a tarball", func() {
+		bundle := NewBundle("diagnose")
+		Expect(bundle.AddJSON("report.json", report)).To(Succeed())
+		Expect(bundle.AddJSON("nodes.json", []Node{})).To(Succeed())
+		Expect(bundle.Files()).To(Equal([]string{"nodes.json", "report.json"}))
+
+		buf := &bytes.Buffer{}
+		Expect(bundle.Write(buf)).To(Succeed())
+
+		gr, err := gzip.NewReader(buf)
+		Expect(err).NotTo(HaveOccurred())
+		tr := tar.NewReader(gr)
+		names := []string{}
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).NotTo(HaveOccurred())
+			names = append(names, header.Name)
+		}
+		Expect(names).To(Equal([]string{"diagnose/nodes.json", "diagnose/report.json"}))
+	})
+})
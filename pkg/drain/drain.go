@@ -51,6 +51,9 @@ type Helper struct {
 	IgnoreDaemonSets    []metav1.ObjectMeta
 	DeleteLocalData     bool
 
+	// SkipPodSelectors select pods that are left running on the node
+	SkipPodSelectors []labels.Selector
+
 	policyAPIGroupVersion string
 	UseEvictions          bool
 }
@@ -24,6 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const (
@@ -140,6 +141,7 @@ func makePodDeleteStatusWithError(message string) podDeleteStatus {
 
 func (d *Helper) makeFilters() []podFilter {
 	return []podFilter{
+		d.skipSelectorFilter,
 		d.annotationFilter,
 		d.daemonSetFilter,
 		d.mirrorPodFilter,
@@ -158,6 +160,15 @@ func hasLocalStorage(pod corev1.Pod) bool {
 	return false
 }
 
+func (d *Helper) skipSelectorFilter(pod corev1.Pod) podDeleteStatus {
+	for _, selector := range d.SkipPodSelectors {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			return makePodDeleteStatusSkip()
+		}
+	}
+	return makePodDeleteStatusOkay()
+}
+
 func (d *Helper) annotationFilter(pod corev1.Pod) podDeleteStatus {
 	if v, ok := pod.Annotations[drainPodAnnotation]; ok {
 		annotation := fmt.Sprintf("due to annotation %s=%s", drainPodAnnotation, v)
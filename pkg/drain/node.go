@@ -0,0 +1,42 @@
+package drain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Node cordons and drains a single node, or uncordons it when undo is set
+func Node(clientSet kubernetes.Interface, nodeName string, waitTimeout time.Duration, undo bool, options Options) error {
+	node, err := clientSet.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting node %q", nodeName)
+	}
+
+	cordon(clientSet, node, undo)
+	if undo {
+		return nil
+	}
+
+	drainer, err := newHelper(clientSet, options)
+	if err != nil {
+		return err
+	}
+	if options.DisableEviction {
+		logger.Warning("pods will be deleted rather than evicted, PodDisruptionBudgets will not be respected")
+	}
+
+	blocked, err := drainNode(drainer, node, options.EvictionBackoff, time.Now().Add(waitTimeout), waitTimeout)
+	if err != nil {
+		return err
+	}
+	if len(blocked) > 0 {
+		return blockedPodsError(blocked, fmt.Sprintf("node %q", nodeName))
+	}
+	logger.Success("drained node %q", nodeName)
+	return nil
+}
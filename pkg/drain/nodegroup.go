@@ -2,14 +2,18 @@ package drain
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/kris-nova/logger"
 	"github.com/pkg/errors"
 
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
@@ -18,27 +22,43 @@ import (
 // this is our custom addition, it's not part of the package
 // we copied from Kubernetes
 
-func evictPods(drainer *Helper, node *corev1.Node) (int, error) {
-	list, errs := drainer.GetPodsForDeletion(node.Name)
-	if len(errs) > 0 {
-		return 0, fmt.Errorf("errs: %v", errs) // TODO: improve formatting
-	}
-	if w := list.Warnings(); w != "" {
-		logger.Warning(w)
-	}
-	pods := list.Pods()
-	pending := len(pods)
-	for _, pod := range pods {
-		// TODO: handle API rate limitter error
-		if err := drainer.EvictOrDeletePod(pod); err != nil {
-			return pending, err
-		}
+// pollInterval is how long to wait for evicted pods to terminate
+// before checking the nodes again
+var pollInterval = 5 * time.Second
+
+// Options control how nodes are drained
+type Options struct {
+	// MaxUnavailable is how many nodes are drained at the same time
+	MaxUnavailable int
+	// SkipPodSelectors are label selectors of pods that are left running
+	SkipPodSelectors []string
+	// PodGracePeriod overrides the termination grace period of pods,
+	// when it's negative the grace period of each pod is used
+	PodGracePeriod time.Duration
+	// DisableEviction deletes pods instead of evicting them, which
+	// bypasses PodDisruptionBudgets
+	DisableEviction bool
+	// EvictionBackoff controls how evictions that are blocked by
+	// PodDisruptionBudgets are retried
+	EvictionBackoff wait.Backoff
+}
+
+// DefaultOptions returns the options that are used unless flags are set
+func DefaultOptions() Options {
+	return Options{
+		MaxUnavailable: 1,
+		PodGracePeriod: -1 * time.Second,
+		EvictionBackoff: wait.Backoff{
+			Duration: 5 * time.Second,
+			Factor:   2,
+			Jitter:   0.1,
+			Steps:    7,
+			Cap:      time.Minute,
+		},
 	}
-	return pending, nil
 }
 
-// NodeGroup drains a nodegroup
-func NodeGroup(clientSet kubernetes.Interface, ng *api.NodeGroup, waitTimeout time.Duration, undo bool) error {
+func newHelper(clientSet kubernetes.Interface, options Options) (*Helper, error) {
 	drainer := &Helper{
 		Client: clientSet,
 
@@ -51,114 +71,286 @@ func NodeGroup(clientSet kubernetes.Interface, ng *api.NodeGroup, waitTimeout ti
 		DeleteLocalData:     true,
 		IgnoreAllDaemonSets: true,
 
-		// TODO: ideally only the list of well-known DaemonSets should
-		// be set by default
-		IgnoreDaemonSets: []metav1.ObjectMeta{
-			{
-				Namespace: "kube-system",
-				Name:      "aws-node",
-			},
-			{
-				Namespace: "kube-system",
-				Name:      "kube-proxy",
-			},
-			{
-				Name: "node-exporter",
-			},
-			{
-				Name: "prom-node-exporter",
-			},
-			{
-				Name: "weave-scope",
-			},
-			{
-				Name: "weave-scope-agent",
-			},
-			{
-				Name: "weave-net",
-			},
-		},
+		GracePeriodSeconds: -1,
+	}
+
+	if options.PodGracePeriod >= 0 {
+		drainer.GracePeriodSeconds = int(options.PodGracePeriod / time.Second)
+	}
+
+	for _, s := range options.SkipPodSelectors {
+		selector, err := labels.Parse(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing pod selector %q", s)
+		}
+		drainer.SkipPodSelectors = append(drainer.SkipPodSelectors, selector)
+	}
+
+	if options.DisableEviction {
+		return drainer, nil
 	}
 
 	if err := drainer.CanUseEvictions(); err != nil {
-		return errors.Wrapf(err, "checking if cluster implements policy API")
+		return nil, errors.Wrapf(err, "checking if cluster implements policy API")
+	}
+	return drainer, nil
+}
+
+// evictionBlockedError is returned when a PodDisruptionBudget still
+// doesn't allow evicting a pod after all retries
+type evictionBlockedError struct {
+	pod string
+	err error
+}
+
+func (e *evictionBlockedError) Error() string {
+	return fmt.Sprintf("%s: %s", e.pod, e.err.Error())
+}
+
+// evictPod evicts or deletes the pod, evictions that are rejected because
+// of a PodDisruptionBudget are retried with backoff until the deadline
+func evictPod(drainer *Helper, pod corev1.Pod, backoff wait.Backoff, deadline time.Time) error {
+	name := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	delay := backoff.Duration
+	for attempt := 1; ; attempt++ {
+		err := drainer.EvictOrDeletePod(pod)
+		switch {
+		case err == nil, apierrors.IsNotFound(err):
+			return nil
+		case !apierrors.IsTooManyRequests(err):
+			return errors.Wrapf(err, "evicting pod %q", name)
+		}
+		// the eviction API returns 429 when it would violate a PodDisruptionBudget
+		remaining := time.Until(deadline)
+		if attempt >= backoff.Steps || remaining <= 0 {
+			return &evictionBlockedError{pod: name, err: err}
+		}
+		logger.Debug("eviction of pod %q is blocked, will retry: %s", name, err.Error())
+
+		sleep := delay
+		if backoff.Jitter > 0 {
+			sleep = wait.Jitter(delay, backoff.Jitter)
+		}
+		if sleep > remaining {
+			sleep = remaining
+		}
+		time.Sleep(sleep)
+
+		delay = time.Duration(float64(delay) * backoff.Factor)
+		if backoff.Cap > 0 && delay > backoff.Cap {
+			delay = backoff.Cap
+		}
+	}
+}
+
+// evictPods evicts all pods of a node concurrently and returns how many
+// pods are still pending as well as the pods that couldn't be evicted
+func evictPods(drainer *Helper, node *corev1.Node, backoff wait.Backoff, deadline time.Time) (int, []*evictionBlockedError, error) {
+	list, errs := drainer.GetPodsForDeletion(node.Name)
+	if len(errs) > 0 {
+		return 0, nil, fmt.Errorf("errs: %v", errs) // TODO: improve formatting
+	}
+	if w := list.Warnings(); w != "" {
+		logger.Warning(w)
+	}
+	pods := list.Pods()
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		blocked  []*evictionBlockedError
+		failures []string
+	)
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue // already terminating
+		}
+		wg.Add(1)
+		go func(pod corev1.Pod) {
+			defer wg.Done()
+			err := evictPod(drainer, pod, backoff, deadline)
+			if err == nil {
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			if blockedErr, ok := err.(*evictionBlockedError); ok {
+				blocked = append(blocked, blockedErr)
+				return
+			}
+			failures = append(failures, err.Error())
+		}(pod)
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return len(pods), blocked, fmt.Errorf("draining node %q: %s", node.Name, strings.Join(failures, "; "))
+	}
+	return len(pods), blocked, nil
+}
+
+// drainNode evicts pods from the node and waits until they have all
+// terminated, it returns the pods that couldn't be evicted
+func drainNode(drainer *Helper, node *corev1.Node, backoff wait.Backoff, deadline time.Time, waitTimeout time.Duration) ([]*evictionBlockedError, error) {
+	for {
+		pending, blocked, err := evictPods(drainer, node, backoff, deadline)
+		if err != nil || len(blocked) > 0 {
+			return blocked, err
+		}
+		if pending == 0 {
+			return nil, nil
+		}
+		logger.Debug("%d pods to be evicted from %s", pending, node.Name)
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out (after %s) waiting for node %q to be drained", waitTimeout, node.Name)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// drainNodes cordons and drains the given nodes, at most maxUnavailable at
+// a time, and returns the nodes that have no more pods left; a node only
+// makes room for the next one once all of its pods have terminated
+func drainNodes(drainer *Helper, nodes []corev1.Node, options Options, deadline time.Time, waitTimeout time.Duration) ([]string, []*evictionBlockedError, error) {
+	maxUnavailable := options.MaxUnavailable
+	if maxUnavailable < 1 {
+		maxUnavailable = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		drained  []string
+		blocked  []*evictionBlockedError
+		firstErr error
+	)
+	failed := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return firstErr != nil || len(blocked) > 0
+	}
+	sem := make(chan struct{}, maxUnavailable)
+	for i := range nodes {
+		sem <- struct{}{}
+		if failed() {
+			// don't take any more nodes out of service
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func(node *corev1.Node) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			cordon(drainer.Client, node, false)
+			blockedPods, err := drainNode(drainer, node, options.EvictionBackoff, deadline, waitTimeout)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			blocked = append(blocked, blockedPods...)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			if len(blockedPods) == 0 && err == nil {
+				drained = append(drained, node.Name)
+			}
+		}(&nodes[i])
+	}
+	wg.Wait()
+	return drained, blocked, firstErr
+}
+
+// cordon cordons or, when undo is set, uncordons the node
+func cordon(clientSet kubernetes.Interface, node *corev1.Node, undo bool) {
+	desired := CordonNode
+	if undo {
+		desired = UncordonNode
+	}
+	c := NewCordonHelper(node, desired)
+	if c.IsUpdateRequired() {
+		err, patchErr := c.PatchOrReplace(clientSet)
+		if patchErr != nil {
+			logger.Warning(patchErr.Error())
+		}
+		if err != nil {
+			logger.Critical(err.Error())
+		}
+		logger.Info("%s node %q", desired, node.Name)
+	} else {
+		logger.Debug("no need to %s node %q", desired, node.Name)
+	}
+}
+
+// blockedPodsError reports every pod that couldn't be evicted
+func blockedPodsError(blocked []*evictionBlockedError, from string) error {
+	pods := []string{}
+	for _, b := range blocked {
+		logger.Warning("pod %s could not be evicted: %s", b.pod, b.err.Error())
+		pods = append(pods, b.pod)
+	}
+	return fmt.Errorf("%d pod(s) could not be evicted from %s because of PodDisruptionBudgets (%s), use --disable-eviction to delete them anyway", len(blocked), from, strings.Join(pods, ", "))
+}
+
+// NodeGroup drains a nodegroup
+func NodeGroup(clientSet kubernetes.Interface, ng *api.NodeGroup, waitTimeout time.Duration, undo bool, options Options) error {
+	drainer, err := newHelper(clientSet, options)
+	if err != nil {
+		return err
+	}
+	if options.DisableEviction && !undo {
+		logger.Warning("pods will be deleted rather than evicted, PodDisruptionBudgets will not be respected")
 	}
 
 	drainedNodes := sets.NewString()
 	// loop until all nodes are drained to handle accidental scale-up
 	// or any other changes in the ASG
-	timer := time.After(waitTimeout)
-	timeout := false
-	for !timeout {
-		select {
-		case <-timer:
-			timeout = true
-		default:
-			nodes, err := clientSet.CoreV1().Nodes().List(ng.ListOptions())
-			if err != nil {
-				return err
-			}
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		nodes, err := clientSet.CoreV1().Nodes().List(ng.ListOptions())
+		if err != nil {
+			return err
+		}
 
-			if len(nodes.Items) == 0 {
-				logger.Warning("no nodes found in nodegroup %q (label selector: %q)", ng.Name, ng.ListOptions().LabelSelector)
-				return nil
-			}
+		if len(nodes.Items) == 0 {
+			logger.Warning("no nodes found in nodegroup %q (label selector: %q)", ng.Name, ng.ListOptions().LabelSelector)
+			return nil
+		}
 
-			newPendingNodes := sets.NewString()
-
-			for _, node := range nodes.Items {
-				if drainedNodes.Has(node.Name) {
-					continue // already drained, get next one
-				}
-				newPendingNodes.Insert(node.Name)
-				desired := CordonNode
-				if undo {
-					desired = UncordonNode
-				}
-				c := NewCordonHelper(&node, desired)
-				if c.IsUpdateRequired() {
-					err, patchErr := c.PatchOrReplace(clientSet)
-					if patchErr != nil {
-						logger.Warning(patchErr.Error())
-					}
-					if err != nil {
-						logger.Critical(err.Error())
-					}
-					logger.Info("%s node %q", desired, node.Name)
-				} else {
-					logger.Debug("no need to %s node %q", desired, node.Name)
-				}
+		if undo {
+			for i := range nodes.Items {
+				cordon(clientSet, &nodes.Items[i], undo)
 			}
+			return nil // no need to kill any pods
+		}
 
-			if undo {
-				return nil // no need to kill any pods
+		newPendingNodes := sets.NewString()
+		pendingNodes := []corev1.Node{}
+		for _, node := range nodes.Items {
+			if drainedNodes.Has(node.Name) {
+				continue // already drained, get next one
 			}
+			newPendingNodes.Insert(node.Name)
+			pendingNodes = append(pendingNodes, node)
+		}
 
-			if drainedNodes.HasAll(newPendingNodes.List()...) {
-				logger.Success("drained nodes: %v", drainedNodes.List())
-				return nil // no new nodes were seen
-			}
+		if len(pendingNodes) == 0 {
+			logger.Success("drained nodes: %v", drainedNodes.List())
+			return nil // no new nodes were seen
+		}
 
-			logger.Debug("already drained: %v", drainedNodes.List())
-			logger.Debug("will drain: %v", newPendingNodes.List())
-
-			for _, node := range nodes.Items {
-				if newPendingNodes.Has(node.Name) {
-					pending, err := evictPods(drainer, &node)
-					if err != nil {
-						return err
-					}
-					logger.Debug("%d pods to be evicted from %s", pending, node.Name)
-					if pending == 0 {
-						drainedNodes.Insert(node.Name)
-					}
-				}
-			}
+		logger.Debug("already drained: %v", drainedNodes.List())
+		logger.Debug("will drain: %v", newPendingNodes.List())
+
+		drained, blocked, err := drainNodes(drainer, pendingNodes, options, deadline, waitTimeout)
+		drainedNodes.Insert(drained...)
+		if err != nil {
+			return err
 		}
+		if len(blocked) > 0 {
+			return blockedPodsError(blocked, fmt.Sprintf("nodegroup %q", ng.Name))
+		}
+		// check for new nodes right away
 	}
-	if timeout {
-		return fmt.Errorf("timed out (after %s) waiting for nodedroup %q to be drain", waitTimeout, ng.Name)
-	}
-
-	return nil
 }
@@ -0,0 +1,169 @@
+package drain
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+)
+
+func newPod(name, nodeName string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+	}
+}
+
+var _ = Describe("Drain", func() {
+	var (
+		clientSet *fake.Clientset
+		backoff   wait.Backoff
+	)
+
+	BeforeEach(func() {
+		clientSet = fake.NewSimpleClientset()
+		backoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 3}
+	})
+
+	// blockEvictions makes the first n evictions fail like they do when
+	// a PodDisruptionBudget doesn't allow them
+	blockEvictions := func(n int) *int {
+		attempts := 0
+		clientSet.PrependReactor("*", "pods", func(action core.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+			attempts++
+			if attempts <= n {
+				return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+			}
+			return true, nil, nil
+		})
+		return &attempts
+	}
+
+	It("retries evictions that are blocked by a PodDisruptionBudget", func() {
+		attempts := blockEvictions(2)
+		drainer := &Helper{Client: clientSet, UseEvictions: true}
+
+		Expect(evictPod(drainer, *newPod("pod-1", "node-1", nil), backoff, time.Now().Add(time.Minute))).To(Succeed())
+		Expect(*attempts).To(Equal(3))
+	})
+
+	It("reports pods that can't be evicted after all retries", func() {
+		attempts := blockEvictions(10)
+		drainer := &Helper{Client: clientSet, UseEvictions: true}
+
+		err := evictPod(drainer, *newPod("pod-1", "node-1", nil), backoff, time.Now().Add(time.Minute))
+		Expect(err).To(BeAssignableToTypeOf(&evictionBlockedError{}))
+		Expect(err.(*evictionBlockedError).pod).To(Equal("default/pod-1"))
+		Expect(*attempts).To(Equal(3))
+	})
+
+	It("stops retrying evictions once the deadline has passed", func() {
+		attempts := blockEvictions(10)
+		drainer := &Helper{Client: clientSet, UseEvictions: true}
+		backoff = wait.Backoff{Duration: time.Hour, Factor: 1, Steps: 3}
+
+		start := time.Now()
+		err := evictPod(drainer, *newPod("pod-1", "node-1", nil), backoff, start.Add(10*time.Millisecond))
+		Expect(err).To(BeAssignableToTypeOf(&evictionBlockedError{}))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(*attempts).To(Equal(2))
+	})
+
+	It("skips pods matching the given selectors and deletes the others", func() {
+		Expect(clientSet.Tracker().Add(newPod("app", "node-1", map[string]string{"app": "web"}))).To(Succeed())
+		Expect(clientSet.Tracker().Add(newPod("logging", "node-1", map[string]string{"app": "logging"}))).To(Succeed())
+
+		options := DefaultOptions()
+		options.DisableEviction = true
+		options.SkipPodSelectors = []string{"app=logging"}
+		drainer, err := newHelper(clientSet, options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drainer.UseEvictions).To(BeFalse())
+
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+		pending, blocked, err := evictPods(drainer, node, backoff, time.Now().Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pending).To(Equal(1))
+		Expect(blocked).To(BeEmpty())
+
+		pods, err := clientSet.CoreV1().Pods("default").List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods.Items).To(HaveLen(1))
+		Expect(pods.Items[0].Name).To(Equal("logging"))
+	})
+
+	It("doesn't cordon more nodes until the pods of the nodes being drained have terminated", func() {
+		defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+		pollInterval = time.Millisecond
+
+		nodes := []corev1.Node{}
+		for _, name := range []string{"node-1", "node-2"} {
+			node := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			Expect(clientSet.Tracker().Add(&node)).To(Succeed())
+			nodes = append(nodes, node)
+		}
+		Expect(clientSet.Tracker().Add(newPod("app", "node-1", nil))).To(Succeed())
+
+		var actions []string
+		clientSet.PrependReactor("*", "*", func(action core.Action) (bool, runtime.Object, error) {
+			if named, ok := action.(interface{ GetName() string }); ok {
+				actions = append(actions, action.GetVerb()+" "+named.GetName())
+			}
+			return false, nil, nil
+		})
+
+		options := DefaultOptions()
+		options.DisableEviction = true
+		drainer, err := newHelper(clientSet, options)
+		Expect(err).NotTo(HaveOccurred())
+
+		drained, blocked, err := drainNodes(drainer, nodes, options, time.Now().Add(time.Minute), time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blocked).To(BeEmpty())
+		Expect(drained).To(Equal([]string{"node-1", "node-2"}))
+		Expect(actions).To(ContainElement("delete app"))
+		Expect(indexOf(actions, "patch node-2")).To(BeNumerically(">", indexOf(actions, "delete app")))
+	})
+
+	It("rejects invalid pod selectors", func() {
+		options := DefaultOptions()
+		options.SkipPodSelectors = []string{"app in (web"}
+		_, err := newHelper(clientSet, options)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("uses the grace period of each pod by default", func() {
+		drainer, err := newHelper(clientSet, Options{DisableEviction: true, PodGracePeriod: -1 * time.Second})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drainer.makeDeleteOptions().GracePeriodSeconds).To(BeNil())
+
+		drainer, err = newHelper(clientSet, Options{DisableEviction: true, PodGracePeriod: 30 * time.Second})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*drainer.makeDeleteOptions().GracePeriodSeconds).To(BeEquivalentTo(30))
+	})
+})
+
+func indexOf(items []string, item string) int {
+	for i := range items {
+		if items[i] == item {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,100 @@
+package eks
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/eksctl/pkg/ami"
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+)
+
+// NodeGroupAMIStatus describes how far the AMI of a nodegroup is behind the
+// latest AMI for its image family, Kubernetes version and instance type
+type NodeGroupAMIStatus struct {
+	Cluster           string `json:"cluster"`
+	NodeGroup         string `json:"nodeGroup"`
+	AMIFamily         string `json:"amiFamily"`
+	InstanceType      string `json:"instanceType"`
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// Unknown is set when the latest AMI can't be resolved, either because the image family
+	// is a custom one that wasn't registered, or because the Kubernetes version of the
+	// current AMI can't be determined
+	Unknown bool   `json:"unknown,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	*ami.Drift
+}
+
+// UpToDate tells whether the nodegroup uses the latest AMI, a nodegroup
+// whose latest AMI is unknown isn't reported as behind
+func (s *NodeGroupAMIStatus) UpToDate() bool {
+	return s.Unknown || s.Drift.UpToDate()
+}
+
+// String describes the status for humans
+func (s *NodeGroupAMIStatus) String() string {
+	if s.Unknown {
+		return "unknown (" + s.Reason + ")"
+	}
+	return s.Drift.String()
+}
+
+// GetNodeGroupAMIStatus compares the AMI of a nodegroup with the latest AMI, as published
+// by EKS in SSM parameters; the latest AMI is the one for the Kubernetes version of the
+// current AMI, as nodegroups may be behind the control plane, and moving them to
+// another minor version isn't an AMI update
+func (c *ClusterProvider) GetNodeGroupAMIStatus(stackManager *manager.StackCollection, summary *manager.NodeGroupSummary) (*NodeGroupAMIStatus, error) {
+	ng := &api.NodeGroup{
+		Name:         summary.Name,
+		AMIFamily:    summary.AMIFamily,
+		InstanceType: summary.InstanceType,
+	}
+	if ng.AMIFamily == "" {
+		// nodegroups created by old versions of eksctl don't record their image family
+		ng.AMIFamily = api.DefaultNodeImageFamily
+	}
+
+	distribution, err := stackManager.GetNodeGroupInstancesDistribution(summary.StackName)
+	if err != nil {
+		return nil, err
+	}
+	ng.InstancesDistribution = distribution
+
+	status := &NodeGroupAMIStatus{
+		Cluster:      summary.Cluster,
+		NodeGroup:    summary.Name,
+		AMIFamily:    ng.AMIFamily,
+		InstanceType: selectInstanceType(ng),
+		Drift:        &ami.Drift{CurrentImageID: summary.ImageID},
+	}
+
+	if !ami.IsKnownImageFamily(ng.AMIFamily) {
+		// custom image families are only declared in config files
+		status.Unknown = true
+		status.Reason = "custom image family"
+		return status, nil
+	}
+
+	version, err := ami.GetImageKubernetesVersion(c.Provider.EC2(), ng.AMIFamily, summary.ImageID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting Kubernetes version of nodegroup %q", ng.Name)
+	}
+	if version == "" {
+		status.Unknown = true
+		status.Reason = "unknown Kubernetes version of current AMI"
+		return status, nil
+	}
+	status.KubernetesVersion = version
+
+	latest, err := c.ResolveAMI(version, ami.ResolverSSM, ng)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving latest AMI of nodegroup %q", ng.Name)
+	}
+
+	drift, err := ami.GetDrift(c.Provider.EC2(), summary.ImageID, latest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "comparing AMI of nodegroup %q with the latest AMI", ng.Name)
+	}
+	status.Drift = drift
+
+	return status, nil
+}
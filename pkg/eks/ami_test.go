@@ -0,0 +1,84 @@
+package eks_test
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	cfn "github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+	. "github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
+)
+
+var _ = Describe("AMI status of nodegroups", func() {
+	var (
+		p            *mockprovider.MockProvider
+		ctl          *ClusterProvider
+		stackManager *manager.StackCollection
+		summary      *manager.NodeGroupSummary
+	)
+
+	BeforeEach(func() {
+		p = mockprovider.NewMockProvider()
+		ctl = &ClusterProvider{Provider: p, Status: &ProviderStatus{}}
+
+		cfg := api.NewClusterConfig()
+		cfg.Metadata.Name = "test-cluster"
+		stackManager = ctl.NewStackManager(cfg)
+
+		summary = &manager.NodeGroupSummary{
+			StackName:    "eksctl-test-cluster-nodegroup-ng-1",
+			Cluster:      "test-cluster",
+			Name:         "ng-1",
+			InstanceType: "m5.large",
+			ImageID:      "ami-1",
+			AMIFamily:    api.NodeImageFamilyAmazonLinux2,
+		}
+
+		p.MockCloudFormation().On("GetTemplate", mock.Anything).Return(&cfn.GetTemplateOutput{
+			TemplateBody: aws.String("{}"),
+		}, nil)
+	})
+
+	mockCurrentImage := func(name string) {
+		p.MockEC2().On("DescribeImages", mock.MatchedBy(func(input *ec2.DescribeImagesInput) bool {
+			return len(input.ImageIds) == 1
+		})).Return(&ec2.DescribeImagesOutput{
+			Images: []*ec2.Image{
+				{ImageId: aws.String("ami-1"), Name: aws.String(name)},
+			},
+		}, nil)
+	}
+
+	It("should compare the AMI with the latest AMI for the Kubernetes version of the nodegroup", func() {
+		mockCurrentImage("amazon-eks-node-1.12-v20191119")
+		mockGetParameter(p, "/aws/service/eks/optimized-ami/1.12/amazon-linux-2/recommended/image_id", "ami-2")
+		p.MockEC2().On("DescribeImages", mock.MatchedBy(func(input *ec2.DescribeImagesInput) bool {
+			return len(input.ImageIds) == 2
+		})).Return(&ec2.DescribeImagesOutput{}, nil)
+
+		status, err := ctl.GetNodeGroupAMIStatus(stackManager, summary)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Unknown).To(BeFalse())
+		Expect(status.KubernetesVersion).To(Equal("1.12"))
+		Expect(status.LatestImageID).To(Equal("ami-2"))
+		Expect(status.UpToDate()).To(BeFalse())
+	})
+
+	It("should report the status as unknown when the Kubernetes version of the AMI is unknown", func() {
+		mockCurrentImage("my-own-image")
+
+		status, err := ctl.GetNodeGroupAMIStatus(stackManager, summary)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Unknown).To(BeTrue())
+		Expect(status.UpToDate()).To(BeTrue())
+		Expect(status.String()).To(Equal("unknown (unknown Kubernetes version of current AMI)"))
+		Expect(p.MockSSM().AssertNotCalled(GinkgoT(), "GetParameter", mock.Anything)).To(BeTrue())
+	})
+})
@@ -12,10 +12,14 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
 	"github.com/aws/aws-sdk-go/service/cloudtrail"
 	"github.com/aws/aws-sdk-go/service/cloudtrail/cloudtrailiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	awseks "github.com/aws/aws-sdk-go/service/eks"
@@ -26,6 +30,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 	"github.com/kris-nova/logger"
@@ -61,7 +67,10 @@ type ProviderServices struct {
 	sts   stsiface.STSAPI
 	iam   iamiface.IAMAPI
 
-	cloudtrail cloudtrailiface.CloudTrailAPI
+	cloudtrail     cloudtrailiface.CloudTrailAPI
+	cloudwatchlogs cloudwatchlogsiface.CloudWatchLogsAPI
+	asg            autoscalingiface.AutoScalingAPI
+	ssm            ssmiface.SSMAPI
 }
 
 // CloudFormation returns a representation of the CloudFormation API
@@ -91,6 +100,17 @@ func (p ProviderServices) IAM() iamiface.IAMAPI { return p.iam }
 // CloudTrail returns a representation of the CloudTrail API
 func (p ProviderServices) CloudTrail() cloudtrailiface.CloudTrailAPI { return p.cloudtrail }
 
+// CloudWatchLogs returns a representation of the CloudWatchLogs API
+func (p ProviderServices) CloudWatchLogs() cloudwatchlogsiface.CloudWatchLogsAPI {
+	return p.cloudwatchlogs
+}
+
+// ASG returns a representation of the AutoScaling API
+func (p ProviderServices) ASG() autoscalingiface.AutoScalingAPI { return p.asg }
+
+// SSM returns a representation of the SSM API
+func (p ProviderServices) SSM() ssmiface.SSMAPI { return p.ssm }
+
 // Region returns provider-level region setting
 func (p ProviderServices) Region() string { return p.spec.Region }
 
@@ -100,10 +120,14 @@ func (p ProviderServices) Profile() string { return p.spec.Profile }
 // WaitTimeout returns provider-level duration after which any wait operation has to timeout
 func (p ProviderServices) WaitTimeout() time.Duration { return p.spec.WaitTimeout }
 
+// StackEventsOutput returns provider-level setting for how stack events are output while waiting
+func (p ProviderServices) StackEventsOutput() string { return p.spec.StackEventsOutput }
+
 // ProviderStatus stores information about the used IAM role and the resulting session
 type ProviderStatus struct {
 	iamRoleARN        string
 	sessionCreds      *credentials.Credentials
+	session           *session.Session
 	cachedClusterInfo *awseks.Cluster
 }
 
@@ -135,9 +159,13 @@ func New(spec *api.ProviderConfig, clusterSpec *api.ClusterConfig) *ClusterProvi
 	)
 	provider.iam = iam.New(s)
 	provider.cloudtrail = cloudtrail.New(s)
+	provider.cloudwatchlogs = cloudwatchlogs.New(s)
+	provider.asg = autoscaling.New(s)
+	provider.ssm = ssm.New(s)
 
 	c.Status = &ProviderStatus{
 		sessionCreds: s.Config.Credentials,
+		session:      s,
 	}
 
 	// override sessions if any custom endpoints specified
@@ -176,6 +204,18 @@ func New(spec *api.ProviderConfig, clusterSpec *api.ClusterConfig) *ClusterProvi
 		logger.Debug("Setting CloudTrail endpoint to %s", endpoint)
 		provider.cloudtrail = cloudtrail.New(s, s.Config.Copy().WithEndpoint(endpoint))
 	}
+	if endpoint, ok := os.LookupEnv("AWS_CLOUDWATCHLOGS_ENDPOINT"); ok {
+		logger.Debug("Setting CloudWatchLogs endpoint to %s", endpoint)
+		provider.cloudwatchlogs = cloudwatchlogs.New(s, s.Config.Copy().WithEndpoint(endpoint))
+	}
+	if endpoint, ok := os.LookupEnv("AWS_AUTOSCALING_ENDPOINT"); ok {
+		logger.Debug("Setting AutoScaling endpoint to %s", endpoint)
+		provider.asg = autoscaling.New(s, s.Config.Copy().WithEndpoint(endpoint))
+	}
+	if endpoint, ok := os.LookupEnv("AWS_SSM_ENDPOINT"); ok {
+		logger.Debug("Setting SSM endpoint to %s", endpoint)
+		provider.ssm = ssm.New(s, s.Config.Copy().WithEndpoint(endpoint))
+	}
 
 	if clusterSpec != nil {
 		clusterSpec.Metadata.Region = c.Provider.Region()
@@ -260,17 +300,10 @@ func (c *ClusterProvider) CheckAuth() error {
 
 // EnsureAMI ensures that the node AMI is set and is available
 func (c *ClusterProvider) EnsureAMI(version string, ng *api.NodeGroup) error {
-	if ng.AMI == ami.ResolverAuto {
-		ami.DefaultResolvers = []ami.Resolver{ami.NewAutoResolver(c.Provider.EC2())}
-	}
-	if ng.AMI == ami.ResolverStatic || ng.AMI == ami.ResolverAuto {
-		instanceType := selectInstanceType(ng)
-		id, err := ami.Resolve(c.Provider.Region(), version, instanceType, ng.AMIFamily)
+	if ng.AMI == ami.ResolverStatic || ng.AMI == ami.ResolverAuto || ng.AMI == ami.ResolverSSM {
+		id, err := c.ResolveAMI(version, ng.AMI, ng)
 		if err != nil {
-			return errors.Wrap(err, "unable to determine AMI to use")
-		}
-		if id == "" {
-			return ami.NewErrFailedResolution(c.Provider.Region(), version, instanceType, ng.AMIFamily)
+			return err
 		}
 		ng.AMI = id
 	}
@@ -280,6 +313,31 @@ func (c *ClusterProvider) EnsureAMI(version string, ng *api.NodeGroup) error {
 
 }
 
+// ResolveAMI returns the AMI that the given resolver (static, auto or ssm) selects
+// for the image family and instance type(s) of the nodegroup
+func (c *ClusterProvider) ResolveAMI(version, resolver string, ng *api.NodeGroup) (string, error) {
+	var resolvers []ami.Resolver
+	switch resolver {
+	case ami.ResolverSSM:
+		// the SSM parameters are only published for Amazon Linux 2, so fall back to the
+		// static AMIs for other image families, and to EC2 for custom image families
+		resolvers = append(ami.NewDefaultResolvers(c.Provider.SSM()), ami.NewAutoResolver(c.Provider.EC2()))
+	case ami.ResolverAuto:
+		resolvers = []ami.Resolver{ami.NewAutoResolver(c.Provider.EC2())}
+	default:
+		resolvers = ami.NewStaticResolvers()
+	}
+	instanceType := selectInstanceType(ng)
+	id, err := ami.Resolve(resolvers, c.Provider.Region(), version, instanceType, ng.AMIFamily)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to determine AMI to use")
+	}
+	if id == "" {
+		return "", ami.NewErrFailedResolution(c.Provider.Region(), version, instanceType, ng.AMIFamily)
+	}
+	return id, nil
+}
+
 // selectInstanceType determines which instanceType is relevant for selecting an AMI
 // If the nodegroup has mixed instances it will prefer a GPU instance type over a general class one
 // This is to make sure that the AMI that is selected later is valid for all the types
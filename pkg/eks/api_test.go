@@ -3,10 +3,10 @@ package eks_test
 import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ssm"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/mock"
-	"github.com/weaveworks/eksctl/pkg/ami"
 	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
@@ -82,8 +82,6 @@ var _ = Describe("eksctl API", func() {
 			p   *mockprovider.MockProvider
 		)
 		BeforeEach(func() {
-			ami.DefaultResolvers = []ami.Resolver{&ami.StaticGPUResolver{}, &ami.StaticDefaultResolver{}}
-
 			cfg = &api.ClusterConfig{}
 			ng = cfg.NewNodeGroup()
 			ng.AMIFamily = api.DefaultNodeImageFamily
@@ -107,6 +105,45 @@ var _ = Describe("eksctl API", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(ng.AMI).To(Equal("abc123"))
 		})
+		It("should retrieve the AMI from SSM when AMI is ssm", func() {
+			ng.AMI = "ssm"
+			ng.InstanceType = "p2.xlarge"
+			mockGetParameter(p, "/aws/service/eks/optimized-ami/1.12/amazon-linux-2-gpu/recommended/image_id", "ami-ssm")
+
+			err := ctl.EnsureAMI("1.12", ng)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ng.AMI).To(Equal("ami-ssm"))
+		})
+		It("should fall back to the static AMIs when AMI is ssm and the image family isn't published in SSM", func() {
+			ng.AMI = "ssm"
+			ng.AMIFamily = api.NodeImageFamilyUbuntu1804
+			ng.InstanceType = "m5.xlarge"
+
+			err := ctl.EnsureAMI("1.12", ng)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ng.AMI).To(Equal("ami-07d8b42a5f4c623f1"))
+			Expect(p.MockSSM().AssertNotCalled(GinkgoT(), "GetParameter", mock.Anything)).To(BeTrue())
+		})
+		It("should keep using the static AMIs for other nodegroups after resolving an AMI from SSM", func() {
+			ng.AMI = "ssm"
+			ng.InstanceType = "m5.xlarge"
+			mockGetParameter(p, "/aws/service/eks/optimized-ami/1.12/amazon-linux-2/recommended/image_id", "ami-ssm")
+			Expect(ctl.EnsureAMI("1.12", ng)).To(Succeed())
+			Expect(ng.AMI).To(Equal("ami-ssm"))
+
+			staticNg := cfg.NewNodeGroup()
+			staticNg.AMIFamily = api.DefaultNodeImageFamily
+			staticNg.AMI = "static"
+			staticNg.InstanceType = "m5.xlarge"
+
+			err := ctl.EnsureAMI("1.12", staticNg)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(staticNg.AMI).To(Equal("ami-0355c210cb3f58aa2"))
+			Expect(p.MockSSM().AssertNumberOfCalls(GinkgoT(), "GetParameter", 1)).To(BeTrue())
+		})
 		It("should pick a valid AMI for GPU  instances when AMI is static", func() {
 			ng.AMI = "static"
 			ng.InstanceType = "p2.xlarge"
@@ -175,3 +212,16 @@ func mockDescribeImages(p *mockprovider.MockProvider, expectedNamePattern string
 			},
 		}, nil)
 }
+
+func mockGetParameter(p *mockprovider.MockProvider, name, value string) {
+	p.MockSSM().On("GetParameter",
+		mock.MatchedBy(func(input *ssm.GetParameterInput) bool {
+			return *input.Name == name
+		})).
+		Return(&ssm.GetParameterOutput{
+			Parameter: &ssm.Parameter{
+				Name:  aws.String(name),
+				Value: aws.String(value),
+			},
+		}, nil)
+}
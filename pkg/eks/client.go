@@ -2,6 +2,7 @@ package eks
 
 import (
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -63,18 +64,45 @@ func (c *Client) new(spec *api.ClusterConfig, stsClient stsiface.STSAPI) (*Clien
 }
 
 func (c *Client) useEmbeddedToken(spec *api.ClusterConfig, stsclient stsiface.STSAPI) error {
+	tok, err := generateToken(spec.Metadata.Name, "", stsclient)
+	if err != nil {
+		return err
+	}
+
+	c.Config.AuthInfos[c.ContextName].Token = tok
+	return nil
+}
+
+// generateToken creates an STS presigned URL token for the cluster,
+// if roleARN is not empty, the role is assumed first
+func generateToken(clusterName, roleARN string, stsclient stsiface.STSAPI) (string, error) {
 	gen, err := token.NewGenerator(true, false)
 	if err != nil {
-		return errors.Wrap(err, "could not get token generator")
+		return "", errors.Wrap(err, "could not get token generator")
+	}
+
+	var tok token.Token
+	if roleARN != "" {
+		tok, err = gen.GetWithRole(clusterName, roleARN)
+	} else {
+		tok, err = gen.GetWithSTS(clusterName, stsclient.(*sts.STS))
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "could not get token")
 	}
 
-	tok, err := gen.GetWithSTS(spec.Metadata.Name, stsclient.(*sts.STS))
+	return tok.Token, nil
+}
+
+// GetExecCredential creates a token for the cluster and returns it
+// serialised as ExecCredential object, which can be used by kubectl
+func (c *ClusterProvider) GetExecCredential(clusterName, roleARN string) ([]byte, error) {
+	tok, err := generateToken(clusterName, roleARN, c.Provider.STS())
 	if err != nil {
-		return errors.Wrap(err, "could not get token")
+		return nil, err
 	}
 
-	c.Config.AuthInfos[c.ContextName].Token = tok.Token
-	return nil
+	return kubeconfig.NewExecCredential(tok, time.Now().Add(kubeconfig.TokenExpiration))
 }
 
 // NewClientSet creates a new API client
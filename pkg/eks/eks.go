@@ -10,6 +10,7 @@ import (
 	"github.com/kris-nova/logger"
 	"github.com/pkg/errors"
 
+	"github.com/aws/aws-sdk-go/aws"
 	awseks "github.com/aws/aws-sdk-go/service/eks"
 
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -121,6 +122,34 @@ func (c *ClusterProvider) ListClusters(clusterName string, chunkSize int, output
 	return printer.PrintObjWithKind("clusters", allClusters, os.Stdout)
 }
 
+// ListAllClusters returns names and regions of all clusters in the current region,
+// or in all supported regions if eachRegion is true; it also returns the regions
+// that were listed successfully, as errors in some regions are not fatal
+func (c *ClusterProvider) ListAllClusters(chunkSize int, eachRegion bool) ([]*api.ClusterMeta, []string, error) {
+	allClusters := []*api.ClusterMeta{}
+	if !eachRegion {
+		if err := c.doListClusters(int64(chunkSize), nil, &allClusters, false); err != nil {
+			return nil, nil, err
+		}
+		return allClusters, []string{c.Provider.Region()}, nil
+	}
+
+	listedRegions := []string{}
+	for _, region := range api.SupportedRegions() {
+		spec := &api.ProviderConfig{
+			Region:      region,
+			Profile:     c.Provider.Profile(),
+			WaitTimeout: c.Provider.WaitTimeout(),
+		}
+		if err := New(spec, nil).doListClusters(int64(chunkSize), nil, &allClusters, false); err != nil {
+			logger.Critical("error listing clusters in %q region: %s", region, err.Error())
+			continue
+		}
+		listedRegions = append(listedRegions, region)
+	}
+	return allClusters, listedRegions, nil
+}
+
 func (c *ClusterProvider) getClustersRequest(chunkSize int64, nextToken string) ([]*string, *string, error) {
 	input := &awseks.ListClustersInput{MaxResults: &chunkSize}
 	if nextToken != "" {
@@ -188,6 +217,10 @@ func (c *ClusterProvider) doGetCluster(clusterName string, printer printers.Outp
 		return err
 	}
 
+	if _, ok := printer.(*printers.TablePrinter); ok {
+		c.logClusterLogGroup(&api.ClusterMeta{Name: clusterName})
+	}
+
 	if *output.Cluster.Status == awseks.ClusterStatusActive {
 		if logger.Level >= 4 {
 			spec := &api.ClusterConfig{Metadata: &api.ClusterMeta{Name: clusterName}}
@@ -203,6 +236,27 @@ func (c *ClusterProvider) doGetCluster(clusterName string, printer printers.Outp
 	return nil
 }
 
+func (c *ClusterProvider) logClusterLogGroup(cl *api.ClusterMeta) {
+	group, err := c.GetClusterLogGroup(cl)
+	if err != nil {
+		logger.Debug("unable to get cluster log group: %s", err.Error())
+		return
+	}
+	if group == nil {
+		logger.Info("log group %q doesn't exist", ClusterLogGroupName(cl.Name))
+		return
+	}
+	retention := "never expire"
+	if group.RetentionInDays != nil {
+		retention = fmt.Sprintf("expire after %d days", *group.RetentionInDays)
+	}
+	encryption := "not encrypted with a KMS key"
+	if group.KmsKeyId != nil {
+		encryption = fmt.Sprintf("encrypted with KMS key %q", *group.KmsKeyId)
+	}
+	logger.Info("logs in log group %q %s, and are %s", *group.LogGroupName, retention, encryption)
+}
+
 // WaitForControlPlane waits till the control plane is ready
 func (c *ClusterProvider) WaitForControlPlane(id *api.ClusterMeta, clientSet *kubernetes.Clientset) error {
 	if _, err := clientSet.ServerVersion(); err == nil {
@@ -263,6 +317,20 @@ func addSummaryTableColumns(printer *printers.TablePrinter) {
 		}
 		return strings.Join(groups.List(), ",")
 	})
+	printer.AddColumn("LOGGING", func(c *awseks.Cluster) string {
+		enabled := sets.NewString()
+		if c.Logging != nil {
+			for _, logSetup := range c.Logging.ClusterLogging {
+				if api.IsEnabled(logSetup.Enabled) {
+					enabled.Insert(aws.StringValueSlice(logSetup.Types)...)
+				}
+			}
+		}
+		if enabled.Len() == 0 {
+			return "-"
+		}
+		return strings.Join(enabled.List(), ",")
+	})
 }
 
 func addListTableColumns(printer *printers.TablePrinter) {
@@ -0,0 +1,104 @@
+package eks
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+	"github.com/weaveworks/eksctl/pkg/gitops"
+	"github.com/weaveworks/eksctl/pkg/gitops/catalog"
+	"github.com/weaveworks/eksctl/pkg/iam"
+)
+
+// AppendGitOpsTasks appends the task bootstrapping GitOps, as declared in the git section of the config
+func (c *ClusterProvider) AppendGitOpsTasks(cfg *api.ClusterConfig, tasks *manager.TaskTree) {
+	if !cfg.HasGitOps() {
+		return
+	}
+	tasks.Append(&clusterConfigTask{
+		info: fmt.Sprintf("bootstrap GitOps with repository %q", cfg.Git.Repo.URL),
+		spec: cfg,
+		call: c.BootstrapGitOps,
+	})
+}
+
+// BootstrapGitOps installs Flux in the cluster and applies the profiles, the
+// user's repository is cloned in a temporary directory which is deleted afterwards
+func (c *ClusterProvider) BootstrapGitOps(spec *api.ClusterConfig) error {
+	cfg, err := c.LoadClusterConfigForProfiles(spec)
+	if err != nil {
+		return err
+	}
+	client, err := c.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	clientSet, err := kubernetes.NewForConfig(client.rawConfig)
+	if err != nil {
+		return errors.Wrap(err, "cannot create Kubernetes client set")
+	}
+
+	outputPath, err := ioutil.TempDir("", "eksctl-gitops-")
+	if err != nil {
+		return errors.Wrap(err, "cannot create temporary directory")
+	}
+	defer func() {
+		if err := os.RemoveAll(outputPath); err != nil {
+			logger.Warning("unable to delete temporary directory %q", outputPath)
+		}
+	}()
+
+	profilesCatalog, err := catalog.Load(cfg.Git.Catalog)
+	if err != nil {
+		return err
+	}
+
+	applier, err := gitops.NewApplier(context.Background(), client.rawConfig, clientSet, cfg, profilesCatalog, outputPath)
+	if err != nil {
+		return err
+	}
+	return applier.Run(context.Background())
+}
+
+// LoadClusterConfigForProfiles returns a copy of spec with the status of the cluster, and the
+// VPC and the nodegroups (with their IAM configuration) loaded from the stacks, as profiles are
+// templated with the whole ClusterConfig; spec itself is left as it is
+func (c *ClusterProvider) LoadClusterConfigForProfiles(spec *api.ClusterConfig) (*api.ClusterConfig, error) {
+	cfg := spec.DeepCopy()
+	if err := c.RefreshClusterConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := c.LoadClusterVPC(cfg); err != nil {
+		return nil, err
+	}
+
+	stackManager := c.NewStackManager(cfg)
+	stacks, err := stackManager.DescribeNodeGroupStacks()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stacks {
+		name := stackManager.GetNodeGroupName(s)
+		var ng *api.NodeGroup
+		for _, existing := range cfg.NodeGroups {
+			if existing.Name == name {
+				ng = existing
+			}
+		}
+		if ng == nil {
+			ng = cfg.NewNodeGroup()
+			ng.Name = name
+		}
+		if err := iam.UseFromNodeGroup(c.Provider, s, ng); err != nil {
+			return nil, errors.Wrapf(err, "loading IAM configuration of nodegroup %q", name)
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,102 @@
+package eks_test
+
+import (
+	"encoding/base64"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cfn "github.com/aws/aws-sdk-go/service/cloudformation"
+	awseks "github.com/aws/aws-sdk-go/service/eks"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	. "github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
+)
+
+var _ = Describe("ClusterConfig for profiles", func() {
+	var (
+		p   *mockprovider.MockProvider
+		ctl *ClusterProvider
+		cfg *api.ClusterConfig
+	)
+
+	BeforeEach(func() {
+		p = mockprovider.NewMockProvider()
+		ctl = &ClusterProvider{Provider: p, Status: &ProviderStatus{}}
+
+		cfg = api.NewClusterConfig()
+		cfg.Metadata.Name = "test-cluster"
+		cfg.Metadata.Region = "us-west-2"
+		ng := cfg.NewNodeGroup()
+		ng.Name = "ng-1"
+
+		stacks := map[string]*cfn.Stack{
+			"eksctl-test-cluster-cluster": {
+				StackName:   aws.String("eksctl-test-cluster-cluster"),
+				StackStatus: aws.String(cfn.StackStatusCreateComplete),
+				Tags: []*cfn.Tag{
+					{Key: aws.String(api.ClusterNameTag), Value: aws.String("test-cluster")},
+				},
+				Outputs: []*cfn.Output{
+					{OutputKey: aws.String("VPC"), OutputValue: aws.String("vpc-1234")},
+					{OutputKey: aws.String("SecurityGroup"), OutputValue: aws.String("sg-1234")},
+				},
+			},
+			"eksctl-test-cluster-nodegroup-ng-2": {
+				StackName:   aws.String("eksctl-test-cluster-nodegroup-ng-2"),
+				StackStatus: aws.String(cfn.StackStatusCreateComplete),
+				Tags: []*cfn.Tag{
+					{Key: aws.String(api.ClusterNameTag), Value: aws.String("test-cluster")},
+					{Key: aws.String(api.NodeGroupNameTag), Value: aws.String("ng-2")},
+				},
+				Outputs: []*cfn.Output{
+					{OutputKey: aws.String("InstanceRoleARN"), OutputValue: aws.String("arn:aws:iam::123456789012:role/ng-2")},
+				},
+			},
+		}
+
+		p.MockCloudFormation().On("ListStacksPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			consume := args[1].(func(p *cfn.ListStacksOutput, last bool) (shouldContinue bool))
+			summaries := []*cfn.StackSummary{}
+			for name := range stacks {
+				summaries = append(summaries, &cfn.StackSummary{StackName: aws.String(name)})
+			}
+			consume(&cfn.ListStacksOutput{StackSummaries: summaries}, true)
+		}).Return(nil)
+
+		p.MockCloudFormation().On("DescribeStacks", mock.Anything).Return(func(input *cfn.DescribeStacksInput) *cfn.DescribeStacksOutput {
+			return &cfn.DescribeStacksOutput{Stacks: []*cfn.Stack{stacks[*input.StackName]}}
+		}, nil)
+
+		p.MockEKS().On("DescribeCluster", mock.Anything).Return(&awseks.DescribeClusterOutput{
+			Cluster: &awseks.Cluster{
+				Name:     aws.String("test-cluster"),
+				Status:   aws.String(awseks.ClusterStatusActive),
+				Arn:      aws.String("arn-12345678"),
+				Version:  aws.String("1.14"),
+				Endpoint: aws.String("https://test-cluster.eks.amazonaws.com"),
+				CertificateAuthority: &awseks.Certificate{
+					Data: aws.String(base64.StdEncoding.EncodeToString([]byte("ca"))),
+				},
+			},
+		}, nil)
+	})
+
+	It("loads the cluster and its nodegroups in a copy of the config", func() {
+		loaded, err := ctl.LoadClusterConfigForProfiles(cfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(loaded.Status.Endpoint).To(Equal("https://test-cluster.eks.amazonaws.com"))
+		Expect(loaded.VPC.ID).To(Equal("vpc-1234"))
+		Expect(loaded.NodeGroups).To(HaveLen(2))
+		Expect(loaded.NodeGroups[1].Name).To(Equal("ng-2"))
+		Expect(loaded.NodeGroups[1].IAM.InstanceRoleARN).To(Equal("arn:aws:iam::123456789012:role/ng-2"))
+
+		Expect(cfg.Status).To(BeNil())
+		Expect(cfg.VPC.ID).To(BeEmpty())
+		Expect(cfg.NodeGroups).To(HaveLen(1))
+	})
+})
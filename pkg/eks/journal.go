@@ -0,0 +1,11 @@
+package eks
+
+import (
+	"github.com/weaveworks/eksctl/pkg/journal"
+)
+
+// NewJournalStore returns a store for recording operations at the given location,
+// S3 and SSM stores use the same session as all other APIs
+func (c *ClusterProvider) NewJournalStore(location string) (journal.Store, error) {
+	return journal.NewStore(location, c.Status.session)
+}
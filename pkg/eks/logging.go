@@ -0,0 +1,131 @@
+package eks
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+)
+
+// ClusterLogGroupName returns the name of the log group EKS sends control plane logs to
+func ClusterLogGroupName(clusterName string) string {
+	return fmt.Sprintf("/aws/eks/%s/cluster", clusterName)
+}
+
+// GetClusterLogGroup returns the cluster log group, or nil if it doesn't exist
+func (c *ClusterProvider) GetClusterLogGroup(cl *api.ClusterMeta) (*cloudwatchlogs.LogGroup, error) {
+	name := ClusterLogGroupName(cl.Name)
+	input := &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: &name,
+	}
+	output, err := c.Provider.CloudWatchLogs().DescribeLogGroups(input)
+	if err != nil {
+		return nil, errors.Wrapf(err, "describing log group %q", name)
+	}
+	// prefix may match log groups of other clusters, e.g. "/aws/eks/foo/cluster-2"
+	for _, group := range output.LogGroups {
+		if aws.StringValue(group.LogGroupName) == name {
+			return group, nil
+		}
+	}
+	return nil, nil
+}
+
+// IsClusterLogGroupUpToDate checks if the given log group has retention and KMS key set as per cfg
+func IsClusterLogGroupUpToDate(cfg *api.ClusterConfig, group *cloudwatchlogs.LogGroup) bool {
+	if !cfg.HasClusterLogGroupSettings() {
+		return true
+	}
+	if group == nil {
+		return false
+	}
+	logging := cfg.CloudWatch.ClusterLogging
+	if logging.RetentionInDays != 0 && aws.Int64Value(group.RetentionInDays) != int64(logging.RetentionInDays) {
+		return false
+	}
+	if logging.KMSKeyID != "" && aws.StringValue(group.KmsKeyId) != logging.KMSKeyID {
+		return false
+	}
+	return true
+}
+
+// EnsureClusterLogGroup creates the cluster log group unless it already exists, and sets
+// its retention and KMS key; it has to be called before logging is enabled, otherwise EKS
+// will create the log group without any of these settings
+func (c *ClusterProvider) EnsureClusterLogGroup(cfg *api.ClusterConfig) error {
+	if !cfg.HasClusterLogGroupSettings() {
+		return nil
+	}
+
+	name := ClusterLogGroupName(cfg.Metadata.Name)
+	logging := cfg.CloudWatch.ClusterLogging
+
+	group, err := c.GetClusterLogGroup(cfg.Metadata)
+	if err != nil {
+		return err
+	}
+	if IsClusterLogGroupUpToDate(cfg, group) {
+		logger.Debug("log group %q is already up-to-date", name)
+		return nil
+	}
+
+	if group == nil {
+		input := &cloudwatchlogs.CreateLogGroupInput{
+			LogGroupName: &name,
+			Tags: map[string]*string{
+				api.ClusterNameTag: &cfg.Metadata.Name,
+			},
+		}
+		if logging.KMSKeyID != "" {
+			input.KmsKeyId = &logging.KMSKeyID
+		}
+		if _, err := c.Provider.CloudWatchLogs().CreateLogGroup(input); err != nil {
+			return errors.Wrapf(err, "creating log group %q", name)
+		}
+		logger.Info("created log group %q", name)
+	} else if logging.KMSKeyID != "" && aws.StringValue(group.KmsKeyId) != logging.KMSKeyID {
+		input := &cloudwatchlogs.AssociateKmsKeyInput{
+			LogGroupName: &name,
+			KmsKeyId:     &logging.KMSKeyID,
+		}
+		if _, err := c.Provider.CloudWatchLogs().AssociateKmsKey(input); err != nil {
+			return errors.Wrapf(err, "associating KMS key with log group %q", name)
+		}
+		logger.Info("associated KMS key %q with log group %q", logging.KMSKeyID, name)
+	}
+
+	if logging.RetentionInDays != 0 && (group == nil || aws.Int64Value(group.RetentionInDays) != int64(logging.RetentionInDays)) {
+		input := &cloudwatchlogs.PutRetentionPolicyInput{
+			LogGroupName:    &name,
+			RetentionInDays: aws.Int64(int64(logging.RetentionInDays)),
+		}
+		if _, err := c.Provider.CloudWatchLogs().PutRetentionPolicy(input); err != nil {
+			return errors.Wrapf(err, "setting retention of log group %q", name)
+		}
+		logger.Info("set retention of log group %q to %d days", name, logging.RetentionInDays)
+	}
+
+	return nil
+}
+
+// DeleteClusterLogGroup deletes the cluster log group, it's not an error if it doesn't exist
+func (c *ClusterProvider) DeleteClusterLogGroup(cl *api.ClusterMeta) error {
+	name := ClusterLogGroupName(cl.Name)
+	input := &cloudwatchlogs.DeleteLogGroupInput{
+		LogGroupName: &name,
+	}
+	if _, err := c.Provider.CloudWatchLogs().DeleteLogGroup(input); err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == cloudwatchlogs.ErrCodeResourceNotFoundException {
+			logger.Debug("log group %q doesn't exist", name)
+			return nil
+		}
+		return errors.Wrapf(err, "deleting log group %q", name)
+	}
+	logger.Info("deleted log group %q", name)
+	return nil
+}
@@ -0,0 +1,120 @@
+package eks_test
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+	. "github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
+)
+
+var _ = Describe("EKS API wrapper", func() {
+	Describe("can manage cluster log group", func() {
+		const (
+			logGroupName = "/aws/eks/testcluster/cluster"
+			kmsKeyID     = "arn:aws:kms:us-west-2:123456789012:key/abcd"
+		)
+
+		var (
+			p   *mockprovider.MockProvider
+			ctl *ClusterProvider
+			cfg *api.ClusterConfig
+		)
+
+		mockLogGroups := func(groups ...*cloudwatchlogs.LogGroup) {
+			p.MockCloudWatchLogs().On("DescribeLogGroups", mock.MatchedBy(func(input *cloudwatchlogs.DescribeLogGroupsInput) bool {
+				return *input.LogGroupNamePrefix == logGroupName
+			})).Return(&cloudwatchlogs.DescribeLogGroupsOutput{LogGroups: groups}, nil)
+		}
+
+		BeforeEach(func() {
+			p = mockprovider.NewMockProvider()
+			ctl = &ClusterProvider{
+				Provider: p,
+			}
+
+			cfg = api.NewClusterConfig()
+			cfg.Metadata.Name = "testcluster"
+		})
+
+		It("should do nothing when retention and KMS key are not set", func() {
+			Expect(ctl.EnsureClusterLogGroup(cfg)).To(Succeed())
+			Expect(p.MockCloudWatchLogs().AssertNotCalled(GinkgoT(), "DescribeLogGroups", mock.Anything)).To(BeTrue())
+		})
+
+		It("should create log group with KMS key and retention", func() {
+			cfg.CloudWatch.ClusterLogging.RetentionInDays = 30
+			cfg.CloudWatch.ClusterLogging.KMSKeyID = kmsKeyID
+
+			mockLogGroups(&cloudwatchlogs.LogGroup{LogGroupName: aws.String(logGroupName + "-2")})
+			p.MockCloudWatchLogs().On("CreateLogGroup", mock.MatchedBy(func(input *cloudwatchlogs.CreateLogGroupInput) bool {
+				return *input.LogGroupName == logGroupName && *input.KmsKeyId == kmsKeyID
+			})).Return(&cloudwatchlogs.CreateLogGroupOutput{}, nil)
+			p.MockCloudWatchLogs().On("PutRetentionPolicy", mock.MatchedBy(func(input *cloudwatchlogs.PutRetentionPolicyInput) bool {
+				return *input.LogGroupName == logGroupName && *input.RetentionInDays == 30
+			})).Return(&cloudwatchlogs.PutRetentionPolicyOutput{}, nil)
+
+			Expect(ctl.EnsureClusterLogGroup(cfg)).To(Succeed())
+			Expect(p.MockCloudWatchLogs().AssertNumberOfCalls(GinkgoT(), "CreateLogGroup", 1)).To(BeTrue())
+			Expect(p.MockCloudWatchLogs().AssertNumberOfCalls(GinkgoT(), "PutRetentionPolicy", 1)).To(BeTrue())
+		})
+
+		It("should update existing log group", func() {
+			cfg.CloudWatch.ClusterLogging.RetentionInDays = 7
+			cfg.CloudWatch.ClusterLogging.KMSKeyID = kmsKeyID
+
+			mockLogGroups(&cloudwatchlogs.LogGroup{LogGroupName: aws.String(logGroupName), RetentionInDays: aws.Int64(90)})
+			p.MockCloudWatchLogs().On("AssociateKmsKey", mock.MatchedBy(func(input *cloudwatchlogs.AssociateKmsKeyInput) bool {
+				return *input.LogGroupName == logGroupName && *input.KmsKeyId == kmsKeyID
+			})).Return(&cloudwatchlogs.AssociateKmsKeyOutput{}, nil)
+			p.MockCloudWatchLogs().On("PutRetentionPolicy", mock.MatchedBy(func(input *cloudwatchlogs.PutRetentionPolicyInput) bool {
+				return *input.RetentionInDays == 7
+			})).Return(&cloudwatchlogs.PutRetentionPolicyOutput{}, nil)
+
+			Expect(ctl.EnsureClusterLogGroup(cfg)).To(Succeed())
+			Expect(p.MockCloudWatchLogs().AssertNotCalled(GinkgoT(), "CreateLogGroup", mock.Anything)).To(BeTrue())
+			Expect(p.MockCloudWatchLogs().AssertNumberOfCalls(GinkgoT(), "AssociateKmsKey", 1)).To(BeTrue())
+			Expect(p.MockCloudWatchLogs().AssertNumberOfCalls(GinkgoT(), "PutRetentionPolicy", 1)).To(BeTrue())
+		})
+
+		It("should not update log group that is up-to-date", func() {
+			cfg.CloudWatch.ClusterLogging.RetentionInDays = 7
+
+			mockLogGroups(&cloudwatchlogs.LogGroup{LogGroupName: aws.String(logGroupName), RetentionInDays: aws.Int64(7)})
+
+			Expect(ctl.EnsureClusterLogGroup(cfg)).To(Succeed())
+			Expect(p.MockCloudWatchLogs().AssertNotCalled(GinkgoT(), "PutRetentionPolicy", mock.Anything)).To(BeTrue())
+		})
+
+		It("should add a task for the log group when only retention is set", func() {
+			cfg.CloudWatch.ClusterLogging.RetentionInDays = 30
+
+			tasks := &manager.TaskTree{}
+			ctl.AppendExtraClusterConfigTasks(cfg, tasks)
+
+			Expect(tasks.Len()).To(Equal(1))
+			Expect(tasks.Describe()).To(ContainSubstring("create CloudWatch log group"))
+		})
+
+		It("should not add any task when logging and log group settings are not set", func() {
+			tasks := &manager.TaskTree{}
+			ctl.AppendExtraClusterConfigTasks(cfg, tasks)
+
+			Expect(tasks.Len()).To(Equal(0))
+		})
+
+		It("should ignore missing log group on deletion", func() {
+			p.MockCloudWatchLogs().On("DeleteLogGroup", mock.Anything).
+				Return(nil, awserr.New(cloudwatchlogs.ErrCodeResourceNotFoundException, "not found", nil))
+
+			Expect(ctl.DeleteClusterLogGroup(cfg.Metadata)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,153 @@
+package eks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+)
+
+// replacementPollInterval is how often the auto scaling group and the
+// nodes are checked while waiting for a replacement node
+const replacementPollInterval = 10 * time.Second
+
+// InstanceID returns the EC2 instance ID of a node, based on its provider ID
+// (e.g. "aws:///us-west-2a/i-0123456789abcdef0")
+func InstanceID(node *corev1.Node) (string, error) {
+	providerID := node.Spec.ProviderID
+	if !strings.HasPrefix(providerID, "aws://") {
+		return "", fmt.Errorf("node %q has no AWS provider ID (%q)", node.Name, providerID)
+	}
+	parts := strings.Split(providerID, "/")
+	instanceID := parts[len(parts)-1]
+	if !strings.HasPrefix(instanceID, "i-") {
+		return "", fmt.Errorf("unable to get instance ID from provider ID %q of node %q", providerID, node.Name)
+	}
+	return instanceID, nil
+}
+
+func (c *ClusterProvider) getAutoScalingGroupInstances(name string) (sets.String, error) {
+	output, err := c.Provider.ASG().DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: aws.StringSlice([]string{name}),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "describing auto scaling group %q", name)
+	}
+	if len(output.AutoScalingGroups) != 1 {
+		return nil, fmt.Errorf("auto scaling group %q not found", name)
+	}
+	instances := sets.NewString()
+	for _, instance := range output.AutoScalingGroups[0].Instances {
+		if aws.StringValue(instance.LifecycleState) == autoscaling.LifecycleStateInService {
+			instances.Insert(aws.StringValue(instance.InstanceId))
+		}
+	}
+	return instances, nil
+}
+
+// GetNodeAutoScalingGroup returns the name of the auto scaling group of a node's instance,
+// it's an error if the instance isn't part of an auto scaling group
+func (c *ClusterProvider) GetNodeAutoScalingGroup(node *corev1.Node) (string, error) {
+	instanceID, err := InstanceID(node)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := c.Provider.ASG().DescribeAutoScalingInstances(&autoscaling.DescribeAutoScalingInstancesInput{
+		InstanceIds: aws.StringSlice([]string{instanceID}),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "describing instance %q", instanceID)
+	}
+	if len(output.AutoScalingInstances) == 0 {
+		return "", fmt.Errorf("instance %q of node %q is not part of an auto scaling group", instanceID, node.Name)
+	}
+	return aws.StringValue(output.AutoScalingInstances[0].AutoScalingGroupName), nil
+}
+
+// ReplaceNode terminates the instance of a node without decrementing the desired
+// capacity of its auto scaling group, so that it gets replaced, and waits for the
+// replacement to join the cluster and become ready, it returns the name of the
+// replacement node; the node should be drained first, once GetNodeAutoScalingGroup
+// has checked that it can be replaced
+func (c *ClusterProvider) ReplaceNode(clientSet kubernetes.Interface, node *corev1.Node, groupName string) (string, error) {
+	instanceID, err := InstanceID(node)
+	if err != nil {
+		return "", err
+	}
+
+	existingInstances, err := c.getAutoScalingGroupInstances(groupName)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Info("terminating instance %q of node %q in auto scaling group %q", instanceID, node.Name, groupName)
+	_, err = c.Provider.ASG().TerminateInstanceInAutoScalingGroup(&autoscaling.TerminateInstanceInAutoScalingGroupInput{
+		InstanceId:                     aws.String(instanceID),
+		ShouldDecrementDesiredCapacity: aws.Bool(false),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "terminating instance %q", instanceID)
+	}
+
+	// the terminated instance may still be listed as in service for a while
+	existingInstances.Insert(instanceID)
+
+	logger.Info("waiting for a replacement of node %q to become ready", node.Name)
+	timer := time.After(c.Provider.WaitTimeout())
+	for {
+		replacement, err := c.findReadyReplacement(clientSet, groupName, existingInstances)
+		if err != nil {
+			return "", err
+		}
+		if replacement != "" {
+			logger.Success("node %q was replaced by %q", node.Name, replacement)
+			return replacement, nil
+		}
+
+		select {
+		case <-timer:
+			return "", fmt.Errorf("timed out (after %s) waiting for a replacement of node %q to become ready", c.Provider.WaitTimeout(), node.Name)
+		case <-time.After(replacementPollInterval):
+		}
+	}
+}
+
+// findReadyReplacement returns the name of a ready node whose instance is in the
+// auto scaling group and isn't one of the given instances
+func (c *ClusterProvider) findReadyReplacement(clientSet kubernetes.Interface, groupName string, existingInstances sets.String) (string, error) {
+	instances, err := c.getAutoScalingGroupInstances(groupName)
+	if err != nil {
+		return "", err
+	}
+	newInstances := instances.Difference(existingInstances)
+	if newInstances.Len() == 0 {
+		logger.Debug("no new instances in auto scaling group %q yet", groupName)
+		return "", nil
+	}
+
+	nodes, err := clientSet.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "listing nodes")
+	}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		instanceID, err := InstanceID(node)
+		if err != nil || !newInstances.Has(instanceID) {
+			continue
+		}
+		if IsNodeReady(node) {
+			return node.Name, nil
+		}
+		logger.Debug("node %q of new instance %q is not ready yet", node.Name, instanceID)
+	}
+	return "", nil
+}
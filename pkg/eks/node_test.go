@@ -0,0 +1,95 @@
+package eks_test
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	. "github.com/weaveworks/eksctl/pkg/eks"
+	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
+)
+
+func newNodeWithInstance(name, instanceID string, ready bool) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{ProviderID: "aws:///us-west-2a/" + instanceID},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func autoScalingGroupWith(instanceIDs ...string) *autoscaling.DescribeAutoScalingGroupsOutput {
+	group := &autoscaling.Group{AutoScalingGroupName: aws.String("ng-1-asg")}
+	for _, id := range instanceIDs {
+		group.Instances = append(group.Instances, &autoscaling.Instance{
+			InstanceId:     aws.String(id),
+			LifecycleState: aws.String(autoscaling.LifecycleStateInService),
+		})
+	}
+	return &autoscaling.DescribeAutoScalingGroupsOutput{AutoScalingGroups: []*autoscaling.Group{group}}
+}
+
+var _ = Describe("Node replacement", func() {
+	It("gets the instance ID from the provider ID", func() {
+		id, err := InstanceID(newNodeWithInstance("node-1", "i-0123456789abcdef0", true))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal("i-0123456789abcdef0"))
+
+		_, err = InstanceID(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("terminates the instance without decrementing desired capacity and waits for a ready replacement", func() {
+		p := mockprovider.NewMockProvider()
+		c := &ClusterProvider{Provider: p}
+
+		oldNode := newNodeWithInstance("old", "i-old", true)
+		clientSet := fake.NewSimpleClientset(
+			oldNode,
+			newNodeWithInstance("other", "i-other", true),
+			newNodeWithInstance("new", "i-new", true),
+		)
+
+		p.MockASG().On("DescribeAutoScalingInstances", mock.MatchedBy(func(input *autoscaling.DescribeAutoScalingInstancesInput) bool {
+			return aws.StringValue(input.InstanceIds[0]) == "i-old"
+		})).Return(&autoscaling.DescribeAutoScalingInstancesOutput{
+			AutoScalingInstances: []*autoscaling.InstanceDetails{
+				{InstanceId: aws.String("i-old"), AutoScalingGroupName: aws.String("ng-1-asg")},
+			},
+		}, nil)
+		p.MockASG().On("DescribeAutoScalingGroups", mock.Anything).Return(autoScalingGroupWith("i-old", "i-other"), nil).Once()
+		p.MockASG().On("DescribeAutoScalingGroups", mock.Anything).Return(autoScalingGroupWith("i-other", "i-new"), nil).Once()
+		p.MockASG().On("TerminateInstanceInAutoScalingGroup", mock.MatchedBy(func(input *autoscaling.TerminateInstanceInAutoScalingGroupInput) bool {
+			return aws.StringValue(input.InstanceId) == "i-old" && !aws.BoolValue(input.ShouldDecrementDesiredCapacity)
+		})).Return(&autoscaling.TerminateInstanceInAutoScalingGroupOutput{}, nil)
+
+		groupName, err := c.GetNodeAutoScalingGroup(oldNode)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groupName).To(Equal("ng-1-asg"))
+
+		replacement, err := c.ReplaceNode(clientSet, oldNode, groupName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replacement).To(Equal("new"))
+		p.MockASG().AssertExpectations(GinkgoT())
+	})
+
+	It("refuses nodes whose instance is not part of an auto scaling group", func() {
+		p := mockprovider.NewMockProvider()
+		c := &ClusterProvider{Provider: p}
+
+		p.MockASG().On("DescribeAutoScalingInstances", mock.Anything).Return(&autoscaling.DescribeAutoScalingInstancesOutput{}, nil)
+
+		_, err := c.GetNodeAutoScalingGroup(newNodeWithInstance("standalone", "i-standalone", true))
+		Expect(err).To(MatchError(`instance "i-standalone" of node "standalone" is not part of an auto scaling group`))
+	})
+})
@@ -17,7 +17,8 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-func isNodeReady(node *corev1.Node) bool {
+// IsNodeReady returns true if the node has reported that it's ready
+func IsNodeReady(node *corev1.Node) bool {
 	for _, c := range node.Status.Conditions {
 		if c.Type == corev1.NodeReady && c.Status == corev1.ConditionTrue {
 			return true
@@ -36,7 +37,7 @@ func getNodes(clientSet kubernetes.Interface, ng *api.NodeGroup) (int, error) {
 	for _, node := range nodes.Items {
 		// logger.Debug("node[%d]=%#v", n, node)
 		ready := "not ready"
-		if isNodeReady(&node) {
+		if IsNodeReady(&node) {
 			ready = "ready"
 			counter++
 		}
@@ -70,7 +71,7 @@ func (c *ClusterProvider) WaitForNodes(clientSet kubernetes.Interface, ng *api.N
 			logger.Debug("event = %#v", event)
 			if event.Object != nil && event.Type != watch.Deleted {
 				if node, ok := event.Object.(*corev1.Node); ok {
-					if isNodeReady(node) {
+					if IsNodeReady(node) {
 						readyNodes.Insert(node.Name)
 						counter = readyNodes.Len()
 						logger.Debug("node %q is ready in %q", node.Name, ng.Name)
@@ -1,6 +1,8 @@
 package eks
 
 import (
+	"context"
+
 	"github.com/kris-nova/logger"
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
@@ -15,7 +17,7 @@ type clusterConfigTask struct {
 
 func (t *clusterConfigTask) Describe() string { return t.info }
 
-func (t *clusterConfigTask) Do(errs chan error) error {
+func (t *clusterConfigTask) Do(_ context.Context, errs chan error) error {
 	err := t.call(t.spec)
 	close(errs)
 	return err
@@ -31,6 +33,15 @@ func (c *ClusterProvider) AppendExtraClusterConfigTasks(cfg *api.ClusterConfig,
 		logger.Info("CloudWatch logging will not be enabled for cluster %q in %q", cfg.Metadata.Name, cfg.Metadata.Region)
 		logger.Info("you can enable it with 'eksctl utils update-cluster-logging --region=%s --name=%s'", cfg.Metadata.Region, cfg.Metadata.Name)
 
+		if cfg.HasClusterLogGroupSettings() {
+			// the log group is created with its retention and KMS key now, so that
+			// they apply to the logs once logging is enabled
+			newTasks.Append(&clusterConfigTask{
+				info: "create CloudWatch log group",
+				spec: cfg,
+				call: c.EnsureClusterLogGroup,
+			})
+		}
 	} else {
 		newTasks.Append(&clusterConfigTask{
 			info: "update CloudWatch logging configuration",
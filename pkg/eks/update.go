@@ -1,6 +1,7 @@
 package eks
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -43,6 +44,10 @@ func (c *ClusterProvider) GetCurrentClusterConfigForLogging(cl *api.ClusterMeta)
 
 // UpdateClusterConfigForLogging calls UpdateClusterConfig to enable logging
 func (c *ClusterProvider) UpdateClusterConfigForLogging(cfg *api.ClusterConfig) error {
+	if err := c.EnsureClusterLogGroup(cfg); err != nil {
+		return err
+	}
+
 	all := sets.NewString(api.SupportedCloudWatchClusterLogTypes()...)
 
 	enabled := sets.NewString()
@@ -138,5 +143,6 @@ func (c *ClusterProvider) waitForUpdateToSucceed(clusterName string, update *aws
 
 	msg := fmt.Sprintf("waiting for requested %q in cluster %q to succeed", *update.Type, clusterName)
 
-	return waiters.Wait(clusterName, msg, acceptors, newRequest, c.Provider.WaitTimeout(), nil)
+	// TODO: cluster updates are not cancellable yet
+	return waiters.Wait(context.TODO(), clusterName, msg, acceptors, newRequest, c.Provider.WaitTimeout(), nil)
 }
@@ -0,0 +1,147 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Type of an event
+type Type string
+
+// Types of events
+const (
+	IntendedAction  Type = "intended-action"
+	CompletedAction Type = "completed-action"
+	Task            Type = "task"
+	StackEvent      Type = "stack-event"
+	Result          Type = "result"
+)
+
+// OutputJSONL is the only supported events output format, one JSON object per line
+const OutputJSONL = "jsonl"
+
+// Event is a structured progress event
+type Event struct {
+	Time    time.Time   `json:"time"`
+	Type    Type        `json:"type"`
+	Message string      `json:"message,omitempty"`
+	Plan    bool        `json:"plan,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Resource identifies a resource that a command has created, updated or deleted
+type Resource struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// Resource kinds
+const (
+	ClusterResource            = "cluster"
+	NodeGroupResource          = "nodegroup"
+	IAMIdentityMappingResource = "iamidentitymapping"
+	LogGroupResource           = "loggroup"
+	NodeResource               = "node"
+)
+
+// CommandResult is the final event of every command
+type CommandResult struct {
+	Command string     `json:"command"`
+	Success bool       `json:"success"`
+	Error   string     `json:"error,omitempty"`
+	Created []Resource `json:"created"`
+	Updated []Resource `json:"updated"`
+	Deleted []Resource `json:"deleted"`
+}
+
+var (
+	output io.Writer
+	mutex  sync.Mutex
+
+	created, updated, deleted []Resource
+)
+
+// Enable starts emitting events to w
+func Enable(w io.Writer) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	output = w
+}
+
+// Enabled returns true if events are being emitted
+func Enabled() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return output != nil
+}
+
+// Emit writes an event, it does nothing unless events are enabled
+func Emit(eventType Type, plan bool, data interface{}, msgFmt string, args ...interface{}) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if output == nil {
+		return
+	}
+	event := &Event{
+		Time:    time.Now().UTC(),
+		Type:    eventType,
+		Message: fmt.Sprintf(msgFmt, args...),
+		Plan:    plan,
+		Data:    data,
+	}
+	// there is no good way to report this error, as events
+	// output is what the user relies upon
+	_ = json.NewEncoder(output).Encode(event)
+}
+
+// Created records a resource that was created, it will be included in the result
+func Created(kind, cluster, name string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	created = append(created, Resource{Kind: kind, Name: name, Cluster: cluster})
+}
+
+// Updated records a resource that was updated, it will be included in the result
+func Updated(kind, cluster, name string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	updated = append(updated, Resource{Kind: kind, Name: name, Cluster: cluster})
+}
+
+// Deleted records a resource that was deleted, it will be included in the result
+func Deleted(kind, cluster, name string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	deleted = append(deleted, Resource{Kind: kind, Name: name, Cluster: cluster})
+}
+
+// NewCommandResult returns the result of a command including all the recorded resources
+func NewCommandResult(command string, err error) *CommandResult {
+	mutex.Lock()
+	defer mutex.Unlock()
+	result := &CommandResult{
+		Command: command,
+		Success: err == nil,
+		Created: append([]Resource{}, created...),
+		Updated: append([]Resource{}, updated...),
+		Deleted: append([]Resource{}, deleted...),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// EmitResult emits the final event of a command
+func EmitResult(command string, err error) {
+	result := NewCommandResult(command, err)
+	msg := "command succeeded"
+	if err != nil {
+		msg = "command failed"
+	}
+	Emit(Result, false, result, msg)
+}
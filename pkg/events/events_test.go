@@ -0,0 +1,68 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("structured events", func() {
+	var out *bytes.Buffer
+
+	decodeEvents := func() []map[string]interface{} {
+		events := []map[string]interface{}{}
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			event := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(line), &event)).To(Succeed())
+			events = append(events, event)
+		}
+		return events
+	}
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		created, updated, deleted = nil, nil, nil
+	})
+
+	AfterEach(func() {
+		Enable(nil)
+	})
+
+	It("should not emit anything unless enabled", func() {
+		Expect(Enabled()).To(BeFalse())
+		Emit(IntendedAction, false, nil, "create %d nodegroups", 2)
+		Expect(out.Len()).To(BeZero())
+	})
+
+	It("should emit one event per line, ending with the result", func() {
+		Enable(out)
+		Expect(Enabled()).To(BeTrue())
+
+		Emit(IntendedAction, true, nil, "delete %d nodegroups", 1)
+		Deleted(NodeGroupResource, "test-cluster", "ng-1")
+		EmitResult("eksctl delete nodegroup", fmt.Errorf("failed to delete nodegroup"))
+
+		events := decodeEvents()
+		Expect(events).To(HaveLen(2))
+		Expect(events[0]["type"]).To(Equal("intended-action"))
+		Expect(events[0]["message"]).To(Equal("delete 1 nodegroups"))
+		Expect(events[0]["plan"]).To(BeTrue())
+		Expect(events[0]).NotTo(HaveKey("data"))
+
+		Expect(events[1]["type"]).To(Equal("result"))
+		Expect(events[1]["data"]).To(Equal(map[string]interface{}{
+			"command": "eksctl delete nodegroup",
+			"success": false,
+			"error":   "failed to delete nodegroup",
+			"created": []interface{}{},
+			"updated": []interface{}{},
+			"deleted": []interface{}{
+				map[string]interface{}{"kind": "nodegroup", "name": "ng-1", "cluster": "test-cluster"},
+			},
+		}))
+	})
+})
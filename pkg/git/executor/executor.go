@@ -10,6 +10,7 @@ import (
 // Executor executes commands shelling out and binding the stdout and stderr to the os ones
 type Executor interface {
 	Exec(command string, dir string, args ...string) error
+	ExecWithOut(command string, dir string, args ...string) ([]byte, error)
 }
 
 // ShellExecutor an executor that shells out to run commands
@@ -41,3 +42,16 @@ func (e ShellExecutor) Exec(command string, dir string, args ...string) error {
 	cmd.Dir = dir
 	return cmd.Run()
 }
+
+// ExecWithOut executes the command inside the directory with the specified args and returns its output
+func (e ShellExecutor) ExecWithOut(command string, dir string, args ...string) ([]byte, error) {
+	ctx, ctxCancel := context.WithTimeout(e.parentCtx, e.timeout)
+	defer ctxCancel()
+	cmd := exec.CommandContext(ctx, command, args...)
+	if len(e.envVars) > 0 {
+		cmd.Env = e.envVars
+	}
+	cmd.Stderr = os.Stderr
+	cmd.Dir = dir
+	return cmd.Output()
+}
@@ -18,3 +18,13 @@ func (e *FakeExecutor) Exec(command string, dir string, args ...string) error {
 	called := e.Called(command, dir, args)
 	return called.Error(0)
 }
+
+// ExecWithOut records the arguments used to call it and returns the recorded output
+func (e *FakeExecutor) ExecWithOut(command string, dir string, args ...string) ([]byte, error) {
+	e.Command = command
+	e.Dir = dir
+	e.Args = args
+	called := e.Called(command, dir, args)
+	out, _ := called.Get(0).([]byte)
+	return out, called.Error(1)
+}
@@ -24,6 +24,10 @@ const (
 // Cloner can clone git repositories
 type Cloner interface {
 	CloneRepo(cloneDirPrefix string, branch string, gitURL string) (string, error)
+	// HeadCommit returns the commit checked out in the cloned repository
+	HeadCommit() (string, error)
+	// Checkout checks out a revision, e.g. a commit, in the cloned repository
+	Checkout(revision string) error
 }
 
 // Client can perform git operations on the given directory
@@ -140,6 +144,21 @@ func (git Client) Commit(message, user, email string) error {
 	return nil
 }
 
+// HeadCommit returns the commit checked out in the repository
+func (git Client) HeadCommit() (string, error) {
+	logger.Debug("running git rev-parse HEAD in %s", git.dir)
+	out, err := git.executor.ExecWithOut("git", git.dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read the commit of the repository")
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Checkout checks out the given revision, e.g. a commit, in the repository
+func (git Client) Checkout(revision string) error {
+	return git.runGitCmd("checkout", "--quiet", revision)
+}
+
 // Push pushes the changes to the origin remote
 func (git Client) Push() error {
 	return git.runGitCmd("push")
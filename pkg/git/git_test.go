@@ -54,6 +54,25 @@ var _ = Describe("GitClient", func() {
 		Expect(os.IsNotExist(err)).To(BeTrue())
 	})
 
+	It("can read the commit checked out", func() {
+		fakeExecutor.On("ExecWithOut", "git", mock.Anything, mock.Anything).Return([]byte("abc123\n"), nil)
+
+		commit, err := gitClient.HeadCommit()
+
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(commit).To(Equal("abc123"))
+		Expect(fakeExecutor.Args).To(Equal([]string{"rev-parse", "HEAD"}))
+	})
+
+	It("can check out a commit", func() {
+		fakeExecutor.On("Exec", "git", mock.Anything, mock.Anything).Return(nil)
+
+		err := gitClient.Checkout("abc123")
+
+		Expect(err).To(Not(HaveOccurred()))
+		Expect(fakeExecutor.Args).To(Equal([]string{"checkout", "--quiet", "abc123"}))
+	})
+
 	It("can add files", func() {
 		fakeExecutor.On("Exec", "git", mock.Anything, mock.Anything).Return(nil)
 
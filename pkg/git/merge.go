@@ -0,0 +1,50 @@
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// MergeLabels name the three versions of a file in conflict markers
+type MergeLabels struct {
+	Current string
+	Base    string
+	Other   string
+}
+
+// MergeFile merges the changes from base to other into current, like git merge-file;
+// when there are conflicts, the merged contents have conflict markers
+func MergeFile(current, base, other []byte, labels MergeLabels) ([]byte, bool, error) {
+	dir, err := ioutil.TempDir("", "eksctl-merge-")
+	if err != nil {
+		return nil, false, errors.Wrap(err, "cannot create temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	paths := make([]string, 3)
+	for i, data := range [][]byte{current, base, other} {
+		paths[i] = filepath.Join(dir, []string{"current", "base", "other"}[i])
+		if err := ioutil.WriteFile(paths[i], data, 0600); err != nil {
+			return nil, false, errors.Wrap(err, "cannot write file to merge")
+		}
+	}
+
+	cmd := exec.Command("git", "merge-file", "--stdout",
+		"-L", labels.Current, "-L", labels.Base, "-L", labels.Other,
+		paths[0], paths[1], paths[2])
+	merged, err := cmd.Output()
+	if err == nil {
+		return merged, false, nil
+	}
+	// The exit status is the number of conflicts, or negative on errors
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if code := exitErr.ExitCode(); code > 0 && code < 128 {
+			return merged, true, nil
+		}
+	}
+	return nil, false, errors.Wrap(err, "unable to merge file")
+}
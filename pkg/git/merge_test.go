@@ -0,0 +1,36 @@
+package git
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MergeFile", func() {
+	labels := MergeLabels{Current: "local", Base: "base", Other: "other"}
+
+	It("merges changes to different lines", func() {
+		merged, conflict, err := MergeFile(
+			[]byte("a: 0\nb: 2\nc: 3\nd: 4\ne: 5\n"),
+			[]byte("a: 1\nb: 2\nc: 3\nd: 4\ne: 5\n"),
+			[]byte("a: 1\nb: 2\nc: 3\nd: 4\ne: 6\n"),
+			labels,
+		)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conflict).To(BeFalse())
+		Expect(string(merged)).To(Equal("a: 0\nb: 2\nc: 3\nd: 4\ne: 6\n"))
+	})
+
+	It("reports conflicts with markers", func() {
+		merged, conflict, err := MergeFile(
+			[]byte("a: 0\n"),
+			[]byte("a: 1\n"),
+			[]byte("a: 2\n"),
+			labels,
+		)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conflict).To(BeTrue())
+		Expect(string(merged)).To(Equal("<<<<<<< local\na: 0\n=======\na: 2\n>>>>>>> other\n"))
+	})
+})
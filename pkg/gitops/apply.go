@@ -3,7 +3,10 @@ package gitops
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 
+	"github.com/kris-nova/logger"
 	"github.com/pkg/errors"
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
@@ -13,16 +16,23 @@ import (
 
 // Applier can set up a repo as a gitops repo with flux
 type Applier struct {
-	UserRepoPath     string
-	ClusterConfig    *api.ClusterConfig
-	UsersRepoOpts    git.Options
-	QuickstartName   string
-	FluxInstaller    *flux.Installer
-	ProfileGenerator *Profile
-	GitClient        *git.Client
+	UserRepoPath  string
+	ClusterConfig *api.ClusterConfig
+	UsersRepoOpts git.Options
+	FluxInstaller *flux.Installer
+	Profiles      []*Profile
+	GitClient     *git.Client
 }
 
-// Run sets up gitops in a repository and a cluster and installs flux, helm, tiller and a quickstart into the cluster
+// ProfileUpdater can update the profiles of a gitops repo
+type ProfileUpdater struct {
+	UserRepoPath  string
+	UsersRepoOpts git.Options
+	Profiles      []*Profile
+	GitClient     *git.Client
+}
+
+// Run sets up gitops in a repository and a cluster and installs flux, helm, tiller and the profiles into the cluster
 func (g *Applier) Run(ctx context.Context) error {
 
 	// Install Flux, Helm and Tiller. Clones the user's repo
@@ -31,16 +41,25 @@ func (g *Applier) Run(ctx context.Context) error {
 		return err
 	}
 
-	// Clone user's repo to apply Quick Start profile
+	if len(g.Profiles) == 0 {
+		return nil
+	}
+
+	// Clone user's repo to apply the profiles
 	err = g.GitClient.CloneRepoInPath(g.UserRepoPath, g.UsersRepoOpts.Branch, g.UsersRepoOpts.URL)
 	if err != nil {
 		return err
 	}
 
-	// Add quickstart components to user's repo. Clones the quickstart base repo
-	err = g.ProfileGenerator.Generate(context.Background())
-	if err != nil {
-		return errors.Wrap(err, "error generating profile")
+	// Add profile components to user's repo. Clones the profiles' repos
+	var profileNames []string
+	for _, profile := range g.Profiles {
+		err = profile.Generate(context.Background())
+		profile.DeleteClonedDirectory()
+		if err != nil {
+			return errors.Wrapf(err, "error generating profile %s", profile.Name)
+		}
+		profileNames = append(profileNames, profile.Name)
 	}
 
 	// Git add, commit and push component files
@@ -48,7 +67,7 @@ func (g *Applier) Run(ctx context.Context) error {
 		return err
 	}
 
-	commitMsg := fmt.Sprintf("Add %s quickstart components", g.QuickstartName)
+	commitMsg := fmt.Sprintf("Add %s quickstart components", strings.Join(profileNames, ", "))
 	if err = g.GitClient.Commit(commitMsg, g.UsersRepoOpts.User, g.UsersRepoOpts.Email); err != nil {
 		return err
 	}
@@ -59,3 +78,43 @@ func (g *Applier) Run(ctx context.Context) error {
 
 	return nil
 }
+
+// Run updates the profiles in the repository, three-way merging the new versions with the local edits;
+// the changes are only committed and pushed when there are no conflicts
+func (u *ProfileUpdater) Run(ctx context.Context) error {
+	err := u.GitClient.CloneRepoInPath(u.UserRepoPath, u.UsersRepoOpts.Branch, u.UsersRepoOpts.URL)
+	if err != nil {
+		return err
+	}
+
+	var (
+		profileNames []string
+		conflicts    []string
+	)
+	for _, profile := range u.Profiles {
+		profileConflicts, err := profile.Update(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "error updating profile %s", profile.Name)
+		}
+		for _, path := range profileConflicts {
+			conflicts = append(conflicts, filepath.Join(profile.Path, path))
+		}
+		profileNames = append(profileNames, profile.Name)
+	}
+
+	if len(conflicts) > 0 {
+		for _, path := range conflicts {
+			logger.Critical("conflict in %q", path)
+		}
+		return fmt.Errorf("%d file(s) could not be merged, resolve the conflicts in %q then commit and push the changes", len(conflicts), u.UserRepoPath)
+	}
+
+	if err = u.GitClient.Add("."); err != nil {
+		return err
+	}
+	commitMsg := fmt.Sprintf("Update %s quickstart components", strings.Join(profileNames, ", "))
+	if err = u.GitClient.Commit(commitMsg, u.UsersRepoOpts.User, u.UsersRepoOpts.Email); err != nil {
+		return err
+	}
+	return u.GitClient.Push()
+}
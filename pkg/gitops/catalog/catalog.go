@@ -0,0 +1,222 @@
+package catalog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/weaveworks/eksctl/pkg/git"
+)
+
+// DefaultRevision is used when a profile reference has no version
+const DefaultRevision = "master"
+
+// fetchTimeout bounds the download of a remote catalog
+var fetchTimeout = 30 * time.Second
+
+type (
+	// Catalog is an index of profiles, usually stored as a YAML file
+	Catalog struct {
+		Profiles []Entry `json:"profiles"`
+	}
+
+	// Entry describes a profile of the catalog
+	Entry struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+		// URL of the profile's Git repository
+		URL string `json:"url"`
+		// Versions are the Git tags of the profile's repository which can be
+		// referenced as name@version, any revision is accepted when empty
+		Versions []string `json:"versions,omitempty"`
+		// Parameters are the values used by the profile's templates
+		Parameters []Parameter `json:"parameters,omitempty"`
+	}
+
+	// Parameter is a value used by the templates of a profile
+	Parameter struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+		Required    bool   `json:"required,omitempty"`
+	}
+
+	// Reference is a profile reference resolved against the catalog
+	Reference struct {
+		// Name is the name of the profile in the catalog, or its URL when it isn't in the catalog
+		Name     string
+		URL      string
+		Revision string
+		// Entry is nil when the profile isn't in the catalog
+		Entry *Entry
+	}
+)
+
+// Default is the catalog of the Quick Start profiles maintained with eksctl
+var Default = Catalog{
+	Profiles: []Entry{
+		{
+			Name:        "app-dev",
+			Description: "Application development components: metrics, monitoring, logging, autoscaling and ingress",
+			// FIXME rename to eks-quickstart-app-dev once the repo is renamed
+			URL: "git@github.com:weaveworks/eks-gitops-example.git",
+		},
+	},
+}
+
+// Load reads a catalog from a local file or an HTTP(S) URL, the default
+// catalog is returned when location is empty
+func Load(location string) (*Catalog, error) {
+	if location == "" {
+		return &Default, nil
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		data, err = fetch(location)
+	} else {
+		data, err = ioutil.ReadFile(location)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read profiles catalog %q", location)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a catalog
+func Parse(data []byte) (*Catalog, error) {
+	catalog := &Catalog{}
+	if err := yaml.UnmarshalStrict(data, catalog); err != nil {
+		return nil, errors.Wrap(err, "cannot parse profiles catalog")
+	}
+
+	names := map[string]bool{}
+	for i, entry := range catalog.Profiles {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("profiles[%d].name must be set", i)
+		}
+		if strings.Contains(entry.Name, "@") {
+			return nil, fmt.Errorf("profiles[%d].name %q cannot contain '@'", i, entry.Name)
+		}
+		if names[entry.Name] {
+			return nil, fmt.Errorf("profiles[%d].name %q is not unique", i, entry.Name)
+		}
+		names[entry.Name] = true
+		if !git.IsGitURL(entry.URL) {
+			return nil, fmt.Errorf("profiles[%d].url %q is not a valid Git URL", i, entry.URL)
+		}
+	}
+	return catalog, nil
+}
+
+func fetch(url string) ([]byte, error) {
+	client := http.Client{Timeout: fetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Find returns the profile with the given name, or nil
+func (c *Catalog) Find(name string) *Entry {
+	for i := range c.Profiles {
+		if c.Profiles[i].Name == name {
+			return &c.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// SplitReference splits a profile reference, i.e. a name or a Git URL
+// optionally followed by @version, into the name (or URL) and the version
+func SplitReference(ref string) (string, string) {
+	i := strings.LastIndex(ref, "@")
+	if i <= 0 {
+		return ref, ""
+	}
+	// The @ of scp-like Git URLs (git@github.com:org/repo) isn't a version separator
+	version := ref[i+1:]
+	if version == "" || strings.ContainsAny(version, ":/") {
+		return ref, ""
+	}
+	return ref[:i], version
+}
+
+// Resolve resolves a profile reference into the URL and the revision to clone,
+// revision is the revision requested separately and conflicts with @version
+func (c *Catalog) Resolve(ref, revision string) (*Reference, error) {
+	name, version := SplitReference(ref)
+
+	resolved := &Reference{Name: name}
+	if git.IsGitURL(name) {
+		resolved.URL = name
+	} else {
+		resolved.Entry = c.Find(name)
+		if resolved.Entry == nil {
+			return nil, fmt.Errorf("invalid URL or unknown profile %q, run 'eksctl get profiles' to list the profiles of the catalog", name)
+		}
+		resolved.URL = resolved.Entry.URL
+	}
+
+	switch {
+	case version != "" && revision != "" && version != revision:
+		return nil, fmt.Errorf("profile %q references version %q and revision %q, only one of them can be set", ref, version, revision)
+	case version != "":
+		if entry := resolved.Entry; entry != nil && len(entry.Versions) > 0 && !contains(entry.Versions, version) {
+			return nil, fmt.Errorf("version %q of profile %q is not in the catalog, available versions: %s",
+				version, name, strings.Join(entry.Versions, ", "))
+		}
+		resolved.Revision = version
+	case revision != "":
+		resolved.Revision = revision
+	default:
+		resolved.Revision = DefaultRevision
+	}
+	return resolved, nil
+}
+
+// MissingParameters returns the names of the required parameters of the profile which
+// aren't set in values, profiles which aren't in the catalog have no required parameters
+func (r *Reference) MissingParameters(values map[string]interface{}) []string {
+	if r.Entry == nil {
+		return nil
+	}
+	var missing []string
+	for _, p := range r.Entry.Parameters {
+		if _, ok := values[p.Name]; p.Required && !ok {
+			missing = append(missing, p.Name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// CheckParameters returns an error listing the required parameters which aren't set in values
+func (r *Reference) CheckParameters(values map[string]interface{}) error {
+	if missing := r.MissingParameters(values); len(missing) > 0 {
+		return fmt.Errorf("profile %q requires the values: %s", r.Name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package catalog
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+const testCatalog = `
+profiles:
+  - name: app-dev
+    description: Application development components
+    url: git@github.com:weaveworks/eks-quickstart-app-dev.git
+    versions:
+      - v0.1.0
+      - v0.2.0
+    parameters:
+      - name: domain
+        description: Domain of the ingress
+        required: true
+      - name: replicas
+  - name: unversioned
+    url: https://github.com/example/profile.git
+`
+
+var _ = Describe("profiles catalog", func() {
+	var catalog *Catalog
+
+	BeforeEach(func() {
+		var err error
+		catalog, err = Parse([]byte(testCatalog))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("parses the profiles", func() {
+		Expect(catalog.Profiles).To(HaveLen(2))
+		entry := catalog.Find("app-dev")
+		Expect(entry).ToNot(BeNil())
+		Expect(entry.Versions).To(Equal([]string{"v0.1.0", "v0.2.0"}))
+		Expect(entry.Parameters).To(Equal([]Parameter{
+			{Name: "domain", Description: "Domain of the ingress", Required: true},
+			{Name: "replicas"},
+		}))
+		Expect(catalog.Find("missing")).To(BeNil())
+	})
+
+	DescribeTable("rejects invalid catalogs",
+		func(data, expectedErr string) {
+			_, err := Parse([]byte(data))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(expectedErr))
+		},
+		Entry("unknown field", "profiles: [{name: a, url: 'git@github.com:org/a.git', tags: []}]", "unknown field"),
+		Entry("no name", "profiles: [{url: 'git@github.com:org/a.git'}]", "profiles[0].name must be set"),
+		Entry("name with @", "profiles: [{name: a@b, url: 'git@github.com:org/a.git'}]", "cannot contain '@'"),
+		Entry("duplicate name", "profiles: [{name: a, url: 'git@github.com:org/a.git'}, {name: a, url: 'git@github.com:org/b.git'}]", `profiles[1].name "a" is not unique`),
+		Entry("invalid URL", "profiles: [{name: a, url: 'not a url'}]", "is not a valid Git URL"),
+	)
+
+	DescribeTable("splits references",
+		func(ref, expectedName, expectedVersion string) {
+			name, version := SplitReference(ref)
+			Expect(name).To(Equal(expectedName))
+			Expect(version).To(Equal(expectedVersion))
+		},
+		Entry("name", "app-dev", "app-dev", ""),
+		Entry("name and version", "app-dev@v0.1.0", "app-dev", "v0.1.0"),
+		Entry("trailing @", "app-dev@", "app-dev@", ""),
+		Entry("scp-like URL", "git@github.com:org/repo.git", "git@github.com:org/repo.git", ""),
+		Entry("scp-like URL and version", "git@github.com:org/repo.git@v1", "git@github.com:org/repo.git", "v1"),
+		Entry("HTTPS URL with user", "https://user@github.com/org/repo.git", "https://user@github.com/org/repo.git", ""),
+	)
+
+	Context("resolving references", func() {
+		It("resolves a name to the branch tip", func() {
+			ref, err := catalog.Resolve("app-dev", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ref.Name).To(Equal("app-dev"))
+			Expect(ref.URL).To(Equal("git@github.com:weaveworks/eks-quickstart-app-dev.git"))
+			Expect(ref.Revision).To(Equal(DefaultRevision))
+			Expect(ref.Entry).To(Equal(catalog.Find("app-dev")))
+		})
+
+		It("resolves a name and a version", func() {
+			ref, err := catalog.Resolve("app-dev@v0.2.0", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ref.Revision).To(Equal("v0.2.0"))
+
+			ref, err = catalog.Resolve("app-dev@v0.2.0", "v0.2.0")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ref.Revision).To(Equal("v0.2.0"))
+		})
+
+		It("resolves a name and a revision", func() {
+			ref, err := catalog.Resolve("app-dev", "develop")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ref.Revision).To(Equal("develop"))
+		})
+
+		It("accepts any version of a profile without versions", func() {
+			ref, err := catalog.Resolve("unversioned@abc123", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ref.URL).To(Equal("https://github.com/example/profile.git"))
+			Expect(ref.Revision).To(Equal("abc123"))
+		})
+
+		It("resolves URLs which aren't in the catalog", func() {
+			ref, err := catalog.Resolve("git@github.com:org/repo.git@v1", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ref.Name).To(Equal("git@github.com:org/repo.git"))
+			Expect(ref.URL).To(Equal("git@github.com:org/repo.git"))
+			Expect(ref.Revision).To(Equal("v1"))
+			Expect(ref.Entry).To(BeNil())
+		})
+
+		It("rejects unknown profiles and versions", func() {
+			_, err := catalog.Resolve("unknown", "")
+			Expect(err).To(MatchError(ContainSubstring(`unknown profile "unknown"`)))
+
+			_, err = catalog.Resolve("app-dev@v9", "")
+			Expect(err).To(MatchError(`version "v9" of profile "app-dev" is not in the catalog, available versions: v0.1.0, v0.2.0`))
+		})
+
+		It("rejects a version and a different revision", func() {
+			_, err := catalog.Resolve("app-dev@v0.1.0", "master")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("checks the required parameters", func() {
+			ref, err := catalog.Resolve("app-dev", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ref.MissingParameters(map[string]interface{}{})).To(Equal([]string{"domain"}))
+			Expect(ref.CheckParameters(map[string]interface{}{"replicas": 2})).To(MatchError(`profile "app-dev" requires the values: domain`))
+			Expect(ref.CheckParameters(map[string]interface{}{"domain": "example.com"})).To(Succeed())
+
+			ref, err = catalog.Resolve("git@github.com:org/repo.git", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ref.CheckParameters(nil)).To(Succeed())
+		})
+	})
+
+	Context("loading", func() {
+		It("returns the default catalog without a location", func() {
+			loaded, err := Load("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loaded.Find("app-dev")).ToNot(BeNil())
+		})
+
+		It("loads a catalog from a file", func() {
+			dir, err := ioutil.TempDir("", "catalog-")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "catalog.yaml")
+			Expect(ioutil.WriteFile(path, []byte(testCatalog), 0600)).To(Succeed())
+
+			loaded, err := Load(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loaded).To(Equal(catalog))
+		})
+
+		It("loads a catalog from a URL", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/catalog.yaml" {
+					http.NotFound(w, r)
+					return
+				}
+				_, _ = w.Write([]byte(testCatalog))
+			}))
+			defer server.Close()
+
+			loaded, err := Load(server.URL + "/catalog.yaml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loaded).To(Equal(catalog))
+
+			_, err = Load(server.URL + "/missing.yaml")
+			Expect(err).To(MatchError(ContainSubstring("404")))
+		})
+	})
+})
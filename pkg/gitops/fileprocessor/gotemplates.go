@@ -2,11 +2,14 @@ package fileprocessor
 
 import (
 	"bytes"
+	"io/ioutil"
 	"strings"
 	"text/template"
 
+	"github.com/Masterminds/sprig"
 	"github.com/kris-nova/logger"
 	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
 
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 )
@@ -20,14 +23,40 @@ const (
 type TemplateParameters struct {
 	ClusterName string
 	Region      string
+	// ClusterConfig is the whole ClusterConfig, e.g. {{ .ClusterConfig.VPC.ID }}
+	ClusterConfig *api.ClusterConfig
+	// Values are supplied by the user, e.g. {{ .Values.domain }}
+	Values map[string]interface{}
 }
 
-// NewTemplateParameters creates a set of variables for templating given a ClusterConfig object
-func NewTemplateParameters(clusterConfig *api.ClusterConfig) TemplateParameters {
+// NewTemplateParameters creates a set of variables for templating given a ClusterConfig object and the user's values
+func NewTemplateParameters(clusterConfig *api.ClusterConfig, values map[string]interface{}) TemplateParameters {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
 	return TemplateParameters{
-		ClusterName: clusterConfig.Metadata.Name,
-		Region:      clusterConfig.Metadata.Region,
+		ClusterName:   clusterConfig.Metadata.Name,
+		Region:        clusterConfig.Metadata.Region,
+		ClusterConfig: clusterConfig,
+		Values:        values,
+	}
+}
+
+// ReadValuesFile reads the values for the templates from a YAML file,
+// an empty path results in no values
+func ReadValuesFile(path string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if path == "" {
+		return values, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read values file %q", path)
+	}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse values file %q", path)
 	}
+	return values, nil
 }
 
 // GoTemplateProcessor is a FileProcessor that executes Go Templates
@@ -42,7 +71,11 @@ func (p *GoTemplateProcessor) ProcessFile(file File) (File, error) {
 		return file, nil
 	}
 
-	parsedTemplate, err := template.New(file.Path).Parse(string(file.Data))
+	// Referencing a value which isn't set is an error rather than an empty string
+	parsedTemplate, err := template.New(file.Path).
+		Funcs(sprig.TxtFuncMap()).
+		Option("missingkey=error").
+		Parse(string(file.Data))
 	if err != nil {
 		return File{}, errors.Wrapf(err, "cannot parse manifest template file %q", file.Path)
 	}
@@ -0,0 +1,133 @@
+package fileprocessor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+)
+
+var _ = Describe("GoTemplateProcessor", func() {
+	var (
+		cfg       *api.ClusterConfig
+		processor *GoTemplateProcessor
+	)
+
+	BeforeEach(func() {
+		cfg = api.NewClusterConfig()
+		cfg.Metadata.Name = "test-cluster"
+		cfg.Metadata.Region = "eu-north-1"
+		cfg.VPC.ID = "vpc-123"
+		ng := cfg.NewNodeGroup()
+		ng.Name = "ng-1"
+		ng.IAM.InstanceRoleARN = "arn:aws:iam::123456789012:role/ng-1"
+
+		processor = &GoTemplateProcessor{
+			Params: NewTemplateParameters(cfg, map[string]interface{}{
+				"domain": "example.com",
+			}),
+		}
+	})
+
+	It("exposes the cluster name, the ClusterConfig and the values", func() {
+		out, err := processor.ProcessFile(File{
+			Path: "a/manifest.yaml.tmpl",
+			Data: []byte(`cluster: {{ .ClusterName }}
+region: {{ .Region }}
+vpc: {{ .ClusterConfig.VPC.ID }}
+role: {{ (index .ClusterConfig.NodeGroups 0).IAM.InstanceRoleARN }}
+host: app.{{ .Values.domain }}`),
+		})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.Path).To(Equal("a/manifest.yaml"))
+		Expect(out.Data).To(MatchYAML(`cluster: test-cluster
+region: eu-north-1
+vpc: vpc-123
+role: arn:aws:iam::123456789012:role/ng-1
+host: app.example.com`))
+	})
+
+	It("provides the sprig functions", func() {
+		out, err := processor.ProcessFile(File{
+			Path: "manifest.yaml.tmpl",
+			Data: []byte(`name: {{ .ClusterName | upper }}
+replicas: {{ index .Values "replicas" | default 2 }}`),
+		})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.Data).To(MatchYAML("name: TEST-CLUSTER\nreplicas: 2"))
+	})
+
+	It("fails on a missing value", func() {
+		_, err := processor.ProcessFile(File{
+			Path: "manifest.yaml.tmpl",
+			Data: []byte("host: {{ .Values.host }}"),
+		})
+
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`map has no entry for key "host"`))
+	})
+
+	It("fails on a missing field", func() {
+		_, err := processor.ProcessFile(File{
+			Path: "manifest.yaml.tmpl",
+			Data: []byte("vpc: {{ .ClusterConfig.VPCID }}"),
+		})
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("leaves non-template files unmodified", func() {
+		file := File{
+			Path: "manifest.yaml",
+			Data: []byte("host: {{ .Values.host }}"),
+		}
+		out, err := processor.ProcessFile(file)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal(file))
+	})
+})
+
+var _ = Describe("ReadValuesFile", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "values-")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	It("returns no values without a file", func() {
+		values, err := ReadValuesFile("")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(values).To(BeEmpty())
+	})
+
+	It("reads the values from a YAML file", func() {
+		path := filepath.Join(dir, "values.yaml")
+		Expect(ioutil.WriteFile(path, []byte("domain: example.com\ningress:\n  enabled: true\n"), 0600)).To(Succeed())
+
+		values, err := ReadValuesFile(path)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(values).To(HaveKeyWithValue("domain", "example.com"))
+		Expect(values).To(HaveKeyWithValue("ingress", map[string]interface{}{"enabled": true}))
+	})
+
+	It("fails when the file doesn't exist", func() {
+		_, err := ReadValuesFile(filepath.Join(dir, "missing.yaml"))
+
+		Expect(err).To(HaveOccurred())
+	})
+})
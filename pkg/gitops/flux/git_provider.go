@@ -0,0 +1,55 @@
+package flux
+
+import (
+	"context"
+
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/eksctl/pkg/gitops/provider"
+)
+
+const deployKeyTitle = "Flux (added by eksctl)"
+
+// gitProvider is the Git hosting service of the repository, used to add the deploy key
+type gitProvider struct {
+	provider provider.Provider
+	repo     provider.Repository
+}
+
+// newGitProvider returns the Git provider of the repository, after creating the repository
+// when requested, or nil when no provider is configured
+func (fi *Installer) newGitProvider(ctx context.Context) (*gitProvider, error) {
+	if fi.opts.GitProvider == "" {
+		return nil, nil
+	}
+	p, err := provider.NewFromEnv(fi.opts.GitProvider, fi.opts.GitProviderURL)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := provider.RepositoryFromURL(fi.opts.GitURL)
+	if err != nil {
+		return nil, err
+	}
+	if fi.opts.CreateRepository {
+		if _, err := p.CreateRepository(ctx, repo, fi.opts.GitBranch); err != nil {
+			return nil, err
+		}
+	}
+	return &gitProvider{provider: p, repo: repo}, nil
+}
+
+// addDeployKey gives the key write access to the repository, when that fails the key is
+// logged so that it can be added manually
+func (g *gitProvider) addDeployKey(ctx context.Context, publicKey string) error {
+	logger.Info("Adding the SSH public key as a deploy key of %s", g.repo)
+	key := provider.DeployKey{
+		Title: deployKeyTitle,
+		Key:   publicKey,
+	}
+	if err := g.provider.AddDeployKey(ctx, g.repo, key); err != nil {
+		logger.Critical("cannot add the deploy key to %s, the SSH public key is\n%s", g.repo, publicKey)
+		return errors.Wrap(err, "cannot add the deploy key, please add the SSH public key to the repository manually")
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package flux
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/gitops/provider"
+)
+
+var _ = Describe("Git provider", func() {
+	var (
+		requests []string
+		server   *httptest.Server
+		opts     *InstallOpts
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		// A repository which already exists, for a stand-in of GitHub's API
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			if r.Header.Get("Authorization") != "token secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"name": "repo"}`))
+		}))
+		git := api.NewGit()
+		git.Repo.URL = "git@github.com:org/repo.git"
+		git.Repo.Branch = "master"
+		git.Repo.Provider = api.GitProviderGitHub
+		git.Repo.ProviderURL = server.URL
+		git.Repo.CreateIfMissing = true
+		opts = NewInstallOpts(git, 0)
+
+		os.Setenv(provider.GitHubTokenEnvVar, "secret")
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.Unsetenv(provider.GitHubTokenEnvVar)
+	})
+
+	It("is not used without a provider", func() {
+		opts.GitProvider = ""
+		p, err := (&Installer{opts: opts}).newGitProvider(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p).To(BeNil())
+	})
+
+	It("ensures the repository exists", func() {
+		p, err := (&Installer{opts: opts}).newGitProvider(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.repo).To(Equal(provider.Repository{Owner: "org", Name: "repo"}))
+		Expect(requests).To(Equal([]string{"GET /repos/org/repo"}))
+	})
+
+	It("requires a token", func() {
+		os.Unsetenv(provider.GitHubTokenEnvVar)
+		_, err := (&Installer{opts: opts}).newGitProvider(context.Background())
+		Expect(err).To(MatchError("$GITHUB_TOKEN must be set to use the github API"))
+	})
+})
@@ -0,0 +1,175 @@
+package flux
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/kubernetes"
+)
+
+const (
+	// HelmVersionV2 enables Helm 2 in the Helm Operator, which requires Tiller
+	HelmVersionV2 = api.HelmVersionV2
+	// HelmVersionV3 enables Helm 3 in the Helm Operator
+	HelmVersionV3 = api.HelmVersionV3
+
+	helmOpDeploymentName = "flux-helm-operator"
+	// helmOpImage is used whenever Helm versions are given explicitly, as
+	// earlier releases don't support --enabled-helm-versions
+	helmOpImage = "docker.io/fluxcd/helm-operator:1.0.0"
+)
+
+// ValidateHelmVersions checks the versions given to --helm-versions
+func ValidateHelmVersions(versions []string) error {
+	for _, version := range versions {
+		if version != HelmVersionV2 && version != HelmVersionV3 {
+			return fmt.Errorf("unsupported Helm version %q, must be one of: %s, %s", version, HelmVersionV2, HelmVersionV3)
+		}
+	}
+	return nil
+}
+
+// RunHelmOperator installs the Helm Operator (and Tiller, if Helm 2 is
+// enabled) in the cluster, without committing anything to a Git repository
+func (fi *Installer) RunHelmOperator(ctx context.Context) error {
+	pki, pkiPaths, err := fi.setupPKI()
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Generating manifests")
+	manifests, secrets, err := getHelmOpManifestsAndSecrets(fi.opts, pki)
+	if err != nil {
+		return err
+	}
+	nsExists, err := kubernetes.CheckNamespaceExists(fi.k8sClientSet, fi.opts.Namespace)
+	if err != nil {
+		return errors.Wrapf(err, "cannot check if namespace %s exists", fi.opts.Namespace)
+	}
+	if !nsExists {
+		manifests[fluxNamespaceFileName] = kubernetes.NewNamespaceYAML(fi.opts.Namespace)
+	}
+	if fi.opts.withTiller() {
+		tillerManifests, tillerSecrets, err := getTillerManifestsAndSecrets(fi.opts.Namespace, fi.k8sClientSet, pkiPaths)
+		if err != nil {
+			return err
+		}
+		manifests = mergeMaps(manifests, tillerManifests)
+		secrets = append(secrets, tillerSecrets...)
+	}
+
+	// The Helm Operator mounts Flux's deploy key, which doesn't exist without Flux
+	_, err = fi.k8sClientSet.CoreV1().Secrets(fi.opts.Namespace).Get(fluxGitDeploySecretName, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "cannot get secret %s/%s", fi.opts.Namespace, fluxGitDeploySecretName)
+		}
+		secrets = append(secrets, newEmptyGitDeploySecret(fi.opts.Namespace))
+	}
+
+	logger.Info("Applying manifests")
+	if err := fi.applyManifests(manifests); err != nil {
+		return err
+	}
+	if len(secrets) > 0 {
+		logger.Info("Applying Secret(s)")
+		if err := fi.applySecrets(secrets); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Waiting for Helm Operator to start")
+	if err := waitForHelmOpToStart(ctx, fi.opts.Namespace, fi.opts.Timeout, fi.k8sRestConfig, fi.k8sClientSet); err != nil {
+		return err
+	}
+	logger.Info("Helm Operator started successfully, with Helm version(s) %s enabled", strings.Join(fi.opts.HelmVersions, ", "))
+	logger.Info("see https://docs.fluxcd.io/projects/helm-operator for details on how to use the Helm Operator")
+	return nil
+}
+
+// configureHelmVersions sets the enabled Helm versions in the Helm Operator
+// Deployment, dropping the Tiller arguments if Helm 2 isn't enabled
+func configureHelmVersions(manifests map[string][]byte, versions []string, withTiller bool) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(manifests))
+	for fileName, manifest := range manifests {
+		objects, err := decodeUnstructured(manifest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot process Helm Operator manifest %s", fileName)
+		}
+		found := false
+		for _, obj := range objects {
+			if obj.GetKind() != "Deployment" || obj.GetName() != helmOpDeploymentName {
+				continue
+			}
+			found = true
+			if err := configureHelmOpContainer(obj, versions, withTiller); err != nil {
+				return nil, err
+			}
+		}
+		if !found {
+			result[fileName] = manifest
+			continue
+		}
+		var objectsBytes [][]byte
+		for _, obj := range objects {
+			objBytes, err := yaml.Marshal(obj.Object)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot serialize Helm Operator manifest %s", fileName)
+			}
+			objectsBytes = append(objectsBytes, objBytes)
+		}
+		result[fileName] = kubernetes.ConcatManifests(objectsBytes...)
+	}
+	return result, nil
+}
+
+func configureHelmOpContainer(deployment *unstructured.Unstructured, versions []string, withTiller bool) error {
+	containers, found, err := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	if err != nil || !found || len(containers) == 0 {
+		return fmt.Errorf("cannot find the containers of the %s Deployment", helmOpDeploymentName)
+	}
+	container, ok := containers[0].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected container definition in the %s Deployment", helmOpDeploymentName)
+	}
+	existingArgs, _, err := unstructured.NestedStringSlice(container, "args")
+	if err != nil {
+		return errors.Wrapf(err, "unexpected container arguments in the %s Deployment", helmOpDeploymentName)
+	}
+	args := []interface{}{}
+	for _, arg := range existingArgs {
+		if strings.HasPrefix(arg, "--enabled-helm-versions") {
+			continue
+		}
+		if !withTiller && strings.HasPrefix(arg, "--tiller-") {
+			continue
+		}
+		args = append(args, arg)
+	}
+	args = append(args, "--enabled-helm-versions="+strings.Join(versions, ","))
+	container["args"] = args
+	container["image"] = helmOpImage
+	containers[0] = container
+	return unstructured.SetNestedSlice(deployment.Object, containers, "spec", "template", "spec", "containers")
+}
+
+func newEmptyGitDeploySecret(namespace string) *corev1.Secret {
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeOpaque,
+	}
+	secret.Kind = "Secret"
+	secret.APIVersion = "v1"
+	secret.Name = fluxGitDeploySecretName
+	secret.Namespace = namespace
+	return secret
+}
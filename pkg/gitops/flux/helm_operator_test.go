@@ -0,0 +1,105 @@
+package flux
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Helm Operator with Helm 3", func() {
+	findHelmOpArgs := func(manifests map[string][]byte) (string, []string) {
+		for _, manifest := range manifests {
+			objects, err := decodeUnstructured(manifest)
+			Expect(err).NotTo(HaveOccurred())
+			for _, obj := range objects {
+				if obj.GetKind() != "Deployment" || obj.GetName() != helmOpDeploymentName {
+					continue
+				}
+				containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+				Expect(err).NotTo(HaveOccurred())
+				container := containers[0].(map[string]interface{})
+				args, _, err := unstructured.NestedStringSlice(container, "args")
+				Expect(err).NotTo(HaveOccurred())
+				return container["image"].(string), args
+			}
+		}
+		Fail("Helm Operator Deployment not found")
+		return "", nil
+	}
+
+	It("should skip Tiller and the PKI when only Helm 3 is enabled", func() {
+		installer := &Installer{
+			opts: &InstallOpts{
+				GitURL:       "git@github.com/foo/bar.git",
+				GitBranch:    "gitbranch",
+				GitFluxPath:  "fluxpath/",
+				Namespace:    "fluxnamespace",
+				WithHelm:     true,
+				HelmVersions: []string{HelmVersionV3},
+			},
+			k8sClientSet: fake.NewSimpleClientset(),
+		}
+
+		pki, pkiPaths, err := installer.setupPKI()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pki).To(BeNil())
+		Expect(pkiPaths).To(BeNil())
+
+		manifests, secrets, err := installer.getManifestsAndSecrets(pki, pkiPaths)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifests).To(HaveLen(10))
+		Expect(secrets).To(BeEmpty())
+		for fileName := range manifests {
+			Expect(fileName).NotTo(HavePrefix(tillerManifestPrefix))
+		}
+
+		image, args := findHelmOpArgs(manifests)
+		Expect(image).To(Equal(helmOpImage))
+		Expect(args).To(ContainElement("--enabled-helm-versions=v3"))
+		for _, arg := range args {
+			Expect(arg).NotTo(HavePrefix("--tiller-"))
+		}
+	})
+
+	It("should keep the Tiller arguments when Helm 2 is enabled", func() {
+		opts := &InstallOpts{
+			Namespace:    "fluxnamespace",
+			WithHelm:     true,
+			HelmVersions: []string{HelmVersionV2, HelmVersionV3},
+		}
+		manifests, _, err := getHelmOpManifestsAndSecrets(opts, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, args := findHelmOpArgs(manifests)
+		Expect(args).To(ContainElement("--enabled-helm-versions=v2,v3"))
+		tillerArgs := 0
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "--tiller-") {
+				tillerArgs++
+			}
+		}
+		Expect(tillerArgs).NotTo(BeZero())
+	})
+
+	DescribeTable("whether Tiller is required",
+		func(withHelm bool, versions []string, expected bool) {
+			opts := &InstallOpts{WithHelm: withHelm, HelmVersions: versions}
+			Expect(opts.withTiller()).To(Equal(expected))
+		},
+		Entry("without Helm", false, []string{HelmVersionV2}, false),
+		Entry("default versions", true, nil, true),
+		Entry("Helm 2", true, []string{HelmVersionV2}, true),
+		Entry("Helm 2 and 3", true, []string{HelmVersionV2, HelmVersionV3}, true),
+		Entry("Helm 3 only", true, []string{HelmVersionV3}, false),
+	)
+
+	It("should validate Helm versions", func() {
+		Expect(ValidateHelmVersions([]string{HelmVersionV3})).To(Succeed())
+		Expect(ValidateHelmVersions([]string{HelmVersionV2, HelmVersionV3})).To(Succeed())
+		Expect(ValidateHelmVersions([]string{"v4"})).To(MatchError(ContainSubstring(`unsupported Helm version "v4"`)))
+	})
+})
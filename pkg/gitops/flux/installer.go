@@ -20,12 +20,14 @@ import (
 	tillerinstall "k8s.io/helm/cmd/helm/installer"
 	"sigs.k8s.io/yaml"
 
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	"github.com/weaveworks/eksctl/pkg/git"
 	"github.com/weaveworks/eksctl/pkg/kubernetes"
 )
 
 const (
 	fluxNamespaceFileName    = "flux-namespace.yaml"
+	fluxGitDeploySecretName  = "flux-git-deploy"        // determined by the generated Flux manifests
 	helmTLSValidFor          = 5 * 365 * 24 * time.Hour // 5 years
 	tillerManifestPrefix     = "tiller-"
 	tillerServiceName        = "tiller-deploy" // do not change at will, hardcoded in Tiller's manifest generation API
@@ -106,6 +108,51 @@ type InstallOpts struct {
 	Timeout              time.Duration
 	Amend                bool
 	WithHelm             bool
+	HelmVersions         []string
+	FluxVersion          string
+	GitProvider          string
+	GitProviderURL       string
+	CreateRepository     bool
+}
+
+// NewInstallOpts returns the installation options declared in the git
+// section of a ClusterConfig, which must have been defaulted
+func NewInstallOpts(git *api.Git, timeout time.Duration) *InstallOpts {
+	return &InstallOpts{
+		GitURL:               git.Repo.URL,
+		GitBranch:            git.Repo.Branch,
+		GitPaths:             git.Repo.Paths,
+		GitLabel:             git.Operator.Label,
+		GitUser:              git.Repo.User,
+		GitEmail:             git.Repo.Email,
+		GitFluxPath:          git.Repo.FluxPath,
+		GitPrivateSSHKeyPath: git.Repo.PrivateSSHKeyPath,
+		Namespace:            git.Operator.Namespace,
+		Timeout:              timeout,
+		WithHelm:             api.IsEnabled(git.Operator.WithHelm),
+		HelmVersions:         git.Operator.HelmVersions,
+		FluxVersion:          git.Operator.FluxVersion,
+		GitProvider:          git.Repo.Provider,
+		GitProviderURL:       git.Repo.ProviderURL,
+		CreateRepository:     git.Repo.CreateIfMissing,
+	}
+}
+
+// withTiller returns true if the Helm Operator is installed with Helm 2
+// enabled, which requires Tiller
+func (opts *InstallOpts) withTiller() bool {
+	if !opts.WithHelm {
+		return false
+	}
+	if len(opts.HelmVersions) == 0 {
+		return true
+	}
+	for _, version := range opts.HelmVersions {
+		if version == HelmVersionV2 {
+			return true
+		}
+	}
+	return false
 }
 
 // Installer installs Flux
@@ -133,6 +180,15 @@ func NewInstaller(ctx context.Context, k8sRestConfig *rest.Config, k8sClientSet
 
 // Run runs the Flux installer
 func (fi *Installer) Run(ctx context.Context) error {
+	gitProvider, err := fi.newGitProvider(ctx)
+	if err != nil {
+		return err
+	}
+
+	if fi.opts.FluxVersion == FluxVersionV2 {
+		return fi.runToolkit(ctx, gitProvider)
+	}
+
 	pki, pkiPaths, err := fi.setupPKI()
 	if err != nil {
 		return err
@@ -213,14 +269,30 @@ func (fi *Installer) Run(ctx context.Context) error {
 	}
 	cleanCloneDir = true
 
-	logger.Info("Flux will only operate properly once it has write-access to the Git repository")
-	logger.Info("please configure %s so that the following Flux SSH public key has write access to it\n%s",
-		fi.opts.GitURL, fluxSSHKey.Key)
+	if gitProvider == nil {
+		logger.Info("Flux will only operate properly once it has write-access to the Git repository")
+		logger.Info("please configure %s so that the following Flux SSH public key has write access to it\n%s",
+			fi.opts.GitURL, fluxSSHKey.Key)
+		return nil
+	}
+
+	headCommit, err := fi.gitClient.HeadCommit()
+	if err != nil {
+		return err
+	}
+	if err := gitProvider.addDeployKey(ctx, fluxSSHKey.Key); err != nil {
+		return err
+	}
+	logger.Info("Waiting for Flux to sync %s", fi.opts.GitURL)
+	if err := waitForFluxToSync(ctx, fi.opts.Namespace, headCommit, fi.opts.Timeout, fi.k8sRestConfig, fi.k8sClientSet); err != nil {
+		return err
+	}
+	logger.Info("Flux synced commit %s", headCommit)
 	return nil
 }
 
 func (fi *Installer) setupPKI() (*publicKeyInfrastructure, *publicKeyInfrastructurePaths, error) {
-	if !fi.opts.WithHelm {
+	if !fi.opts.withTiller() {
 		return nil, nil, nil
 	}
 
@@ -325,7 +397,7 @@ func readFluxManifests(baseDir string) (map[string][]byte, error) {
 	}
 	manifests := map[string][]byte{}
 	for _, manifestFile := range manifestFiles {
-		manifest, err := ioutil.ReadFile(manifestFile.Name())
+		manifest, err := ioutil.ReadFile(filepath.Join(baseDir, manifestFile.Name()))
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to read Flux manifest file %s", manifestFile.Name())
 		}
@@ -364,7 +436,7 @@ func (fi *Installer) getManifestsAndSecrets(pki *publicKeyInfrastructure,
 	if !fi.opts.WithHelm {
 		return manifests, secrets, nil
 	}
-	helmOpManifests, helmOpSecrets, err := getHelmOpManifestsAndSecrets(fi.opts.Namespace, pki)
+	helmOpManifests, helmOpSecrets, err := getHelmOpManifestsAndSecrets(fi.opts, pki)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -372,6 +444,9 @@ func (fi *Installer) getManifestsAndSecrets(pki *publicKeyInfrastructure,
 	secrets = append(secrets, helmOpSecrets...)
 
 	// Tiller
+	if !fi.opts.withTiller() {
+		return manifests, secrets, nil
+	}
 	tillerManifests, tillerSecrets, err := getTillerManifestsAndSecrets(fi.opts.Namespace, fi.k8sClientSet, pkiPaths)
 	if err != nil {
 		return nil, nil, err
@@ -408,12 +483,13 @@ func getFluxManifests(opts *InstallOpts, cs kubeclient.Interface) (map[string][]
 	return mergeMaps(manifests, fluxManifests), nil
 }
 
-func getHelmOpManifestsAndSecrets(namespace string, pki *publicKeyInfrastructure) (map[string][]byte, []*corev1.Secret, error) {
+func getHelmOpManifestsAndSecrets(opts *InstallOpts, pki *publicKeyInfrastructure) (map[string][]byte, []*corev1.Secret, error) {
 	var secrets []*corev1.Secret
+	namespace := opts.Namespace
 	helmOpParameters := helmopinstall.TemplateParameters{
 		Namespace:       namespace,
 		TillerNamespace: namespace,
-		SSHSecretName:   "flux-git-deploy", // determined by the generated Flux manifests
+		SSHSecretName:   fluxGitDeploySecretName,
 	}
 	if pki != nil {
 		helmOpParameters.EnableTillerTLS = true
@@ -437,6 +513,11 @@ func getHelmOpManifestsAndSecrets(namespace string, pki *publicKeyInfrastructure
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to create Helm Operator Manifests")
 	}
+	if len(opts.HelmVersions) > 0 {
+		if manifests, err = configureHelmVersions(manifests, opts.HelmVersions, opts.withTiller()); err != nil {
+			return nil, nil, err
+		}
+	}
 	return manifests, secrets, nil
 }
 
@@ -0,0 +1,155 @@
+package flux
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kubeclient "k8s.io/client-go/kubernetes"
+)
+
+// tillerReleaseSelector matches the ConfigMaps (or Secrets) in which Tiller
+// stores Helm 2 releases
+const tillerReleaseSelector = "OWNER=TILLER"
+
+// tillerObject is a Kubernetes object created for Tiller by the Flux installer
+type tillerObject struct {
+	kind      string
+	namespace string
+	name      string
+	delete    func() error
+}
+
+func (o tillerObject) String() string {
+	if o.namespace == "" {
+		return fmt.Sprintf("%s/%s", o.kind, o.name)
+	}
+	return fmt.Sprintf("%s:%s/%s", o.namespace, o.kind, o.name)
+}
+
+// RemoveTiller deletes Tiller and the objects created for it (including its
+// TLS secrets) from namespace. It refuses to do so while Helm 2 releases are
+// still stored by Tiller, as they would no longer be managed by anything, and
+// while the Helm Operator still uses Tiller or mounts its TLS secrets. It
+// returns false if Tiller isn't installed in namespace.
+func RemoveTiller(cs kubeclient.Interface, namespace string, plan bool) (bool, error) {
+	if _, err := cs.AppsV1().Deployments(namespace).Get(tillerServiceName, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "cannot get Tiller deployment %s/%s", namespace, tillerServiceName)
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: tillerReleaseSelector}
+	releaseConfigMaps, err := cs.CoreV1().ConfigMaps(namespace).List(listOptions)
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot list Helm 2 releases in namespace %s", namespace)
+	}
+	releaseSecrets, err := cs.CoreV1().Secrets(namespace).List(listOptions)
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot list Helm 2 releases in namespace %s", namespace)
+	}
+	if releases := len(releaseConfigMaps.Items) + len(releaseSecrets.Items); releases > 0 {
+		return false, fmt.Errorf("found %d Helm 2 release revision(s) stored by Tiller in namespace %s; "+
+			"migrate them to Helm 3 (e.g. with the helm-2to3 plugin) and clean them up before removing Tiller", releases, namespace)
+	}
+
+	helmOp, err := cs.AppsV1().Deployments(namespace).Get(helmOpDeploymentName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return false, errors.Wrapf(err, "cannot get Helm Operator deployment %s/%s", namespace, helmOpDeploymentName)
+	default:
+		if reasons := helmOpTillerUsage(helmOp); len(reasons) > 0 {
+			return false, fmt.Errorf("the Helm Operator in namespace %s still uses Tiller (%s); "+
+				"enable only Helm v3 first, e.g. with 'eksctl install helm-operator --helm-versions=v3', before removing Tiller",
+				namespace, strings.Join(reasons, ", "))
+		}
+	}
+
+	deleteOptions := &metav1.DeleteOptions{}
+	objects := []tillerObject{
+		{kind: "Deployment", namespace: namespace, name: tillerServiceName, delete: func() error {
+			return cs.AppsV1().Deployments(namespace).Delete(tillerServiceName, deleteOptions)
+		}},
+		{kind: "Service", namespace: namespace, name: tillerServiceName, delete: func() error {
+			return cs.CoreV1().Services(namespace).Delete(tillerServiceName, deleteOptions)
+		}},
+		{kind: "Secret", namespace: namespace, name: "tiller-secret", delete: func() error {
+			return cs.CoreV1().Secrets(namespace).Delete("tiller-secret", deleteOptions)
+		}},
+		{kind: "Secret", namespace: namespace, name: "flux-helm-tls-cert", delete: func() error {
+			return cs.CoreV1().Secrets(namespace).Delete("flux-helm-tls-cert", deleteOptions)
+		}},
+		{kind: "ConfigMap", namespace: namespace, name: "flux-helm-tls-ca-config", delete: func() error {
+			return cs.CoreV1().ConfigMaps(namespace).Delete("flux-helm-tls-ca-config", deleteOptions)
+		}},
+		{kind: "ServiceAccount", namespace: namespace, name: tillerServiceAccountName, delete: func() error {
+			return cs.CoreV1().ServiceAccounts(namespace).Delete(tillerServiceAccountName, deleteOptions)
+		}},
+		{kind: "ServiceAccount", namespace: namespace, name: "helm", delete: func() error {
+			return cs.CoreV1().ServiceAccounts(namespace).Delete("helm", deleteOptions)
+		}},
+		{kind: "ClusterRoleBinding", name: "tiller", delete: func() error {
+			return cs.RbacV1().ClusterRoleBindings().Delete("tiller", deleteOptions)
+		}},
+		{kind: "Role", namespace: namespace, name: "tiller-user", delete: func() error {
+			return cs.RbacV1().Roles(namespace).Delete("tiller-user", deleteOptions)
+		}},
+		{kind: "RoleBinding", namespace: "kube-system", name: "tiller-user-binding", delete: func() error {
+			return cs.RbacV1().RoleBindings("kube-system").Delete("tiller-user-binding", deleteOptions)
+		}},
+	}
+
+	for _, object := range objects {
+		if plan {
+			logger.Info("(plan) would have deleted %q", object)
+			continue
+		}
+		if err := object.delete(); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return true, errors.Wrapf(err, "cannot delete %q", object)
+			}
+			continue
+		}
+		logger.Info("deleted %q", object)
+	}
+	return true, nil
+}
+
+// helmOpTillerUsage returns the reasons why the Helm Operator Deployment would
+// break without Tiller, it's empty when only Helm v3 is enabled
+func helmOpTillerUsage(deployment *appsv1.Deployment) []string {
+	tillerSecrets := sets.NewString("tiller-secret", "flux-helm-tls-cert", "flux-helm-tls-ca-config")
+	reasons := []string{}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		// Helm v2 is enabled unless the enabled versions are given explicitly
+		helmV2 := true
+		for _, arg := range container.Args {
+			if strings.HasPrefix(arg, "--enabled-helm-versions=") {
+				versions := strings.Split(strings.TrimPrefix(arg, "--enabled-helm-versions="), ",")
+				helmV2 = sets.NewString(versions...).Has(HelmVersionV2)
+			}
+			if strings.HasPrefix(arg, "--tiller-") {
+				reasons = append(reasons, fmt.Sprintf("argument %s", arg))
+			}
+		}
+		if helmV2 {
+			reasons = append(reasons, fmt.Sprintf("Helm %s is enabled", HelmVersionV2))
+		}
+	}
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		switch {
+		case volume.Secret != nil && tillerSecrets.Has(volume.Secret.SecretName):
+			reasons = append(reasons, fmt.Sprintf("mounts Secret %s", volume.Secret.SecretName))
+		case volume.ConfigMap != nil && tillerSecrets.Has(volume.ConfigMap.Name):
+			reasons = append(reasons, fmt.Sprintf("mounts ConfigMap %s", volume.ConfigMap.Name))
+		}
+	}
+	return reasons
+}
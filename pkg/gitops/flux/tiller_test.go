@@ -0,0 +1,112 @@
+package flux
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Tiller removal", func() {
+	const namespace = "flux"
+
+	tillerObjects := func() []runtime.Object {
+		objectMeta := func(name string) metav1.ObjectMeta {
+			return metav1.ObjectMeta{Name: name, Namespace: namespace}
+		}
+		return []runtime.Object{
+			&appsv1.Deployment{ObjectMeta: objectMeta(tillerServiceName)},
+			&corev1.Service{ObjectMeta: objectMeta(tillerServiceName)},
+			&corev1.Secret{ObjectMeta: objectMeta("tiller-secret")},
+			&corev1.Secret{ObjectMeta: objectMeta("flux-helm-tls-cert")},
+			&corev1.ServiceAccount{ObjectMeta: objectMeta(tillerServiceAccountName)},
+			&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "tiller"}},
+			&corev1.Secret{ObjectMeta: objectMeta(fluxGitDeploySecretName)},
+		}
+	}
+
+	It("should do nothing when Tiller isn't installed", func() {
+		found, err := RemoveTiller(fake.NewSimpleClientset(), namespace, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	It("should only report what would be deleted in plan mode", func() {
+		cs := fake.NewSimpleClientset(tillerObjects()...)
+		found, err := RemoveTiller(cs, namespace, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+
+		_, err = cs.AppsV1().Deployments(namespace).Get(tillerServiceName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should delete Tiller and its secrets, leaving other objects alone", func() {
+		cs := fake.NewSimpleClientset(tillerObjects()...)
+		found, err := RemoveTiller(cs, namespace, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+
+		deployments, err := cs.AppsV1().Deployments(namespace).List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployments.Items).To(BeEmpty())
+		secrets, err := cs.CoreV1().Secrets(namespace).List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secrets.Items).To(HaveLen(1))
+		Expect(secrets.Items[0].Name).To(Equal(fluxGitDeploySecretName))
+		bindings, err := cs.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bindings.Items).To(BeEmpty())
+	})
+
+	It("should refuse to remove Tiller while the Helm Operator uses it", func() {
+		helmOp := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: helmOpDeploymentName, Namespace: namespace}}
+		helmOp.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name: "flux-helm-operator",
+			Args: []string{"--enabled-helm-versions=v2,v3", "--tiller-tls-enable=true"},
+		}}
+		helmOp.Spec.Template.Spec.Volumes = []corev1.Volume{{
+			Name:         "helm-tls-certs",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "flux-helm-tls-cert"}},
+		}}
+		cs := fake.NewSimpleClientset(append(tillerObjects(), helmOp)...)
+		_, err := RemoveTiller(cs, namespace, false)
+		Expect(err).To(MatchError(ContainSubstring("argument --tiller-tls-enable=true, Helm v2 is enabled, mounts Secret flux-helm-tls-cert")))
+
+		_, err = cs.CoreV1().Secrets(namespace).Get("flux-helm-tls-cert", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should remove Tiller once the Helm Operator only has Helm v3 enabled", func() {
+		helmOp := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: helmOpDeploymentName, Namespace: namespace}}
+		helmOp.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name: "flux-helm-operator",
+			Args: []string{"--enabled-helm-versions=v3"},
+		}}
+		cs := fake.NewSimpleClientset(append(tillerObjects(), helmOp)...)
+		found, err := RemoveTiller(cs, namespace, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+
+		_, err = cs.AppsV1().Deployments(namespace).Get(helmOpDeploymentName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should refuse to remove Tiller while Helm 2 releases remain", func() {
+		release := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:      "podinfo.v1",
+			Namespace: namespace,
+			Labels:    map[string]string{"OWNER": "TILLER", "NAME": "podinfo"},
+		}}
+		cs := fake.NewSimpleClientset(append(tillerObjects(), release)...)
+		_, err := RemoveTiller(cs, namespace, false)
+		Expect(err).To(MatchError(ContainSubstring("found 1 Helm 2 release revision(s)")))
+
+		_, err = cs.AppsV1().Deployments(namespace).Get(tillerServiceName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,570 @@
+package flux
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+	giturls "github.com/whilp/git-urls"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	kubeclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/kubernetes"
+)
+
+const (
+	// FluxVersionV1 installs Flux (and optionally the Helm Operator and Tiller)
+	FluxVersionV1 = api.FluxVersionV1
+	// FluxVersionV2 installs the GitOps Toolkit controllers
+	FluxVersionV2 = api.FluxVersionV2
+
+	toolkitComponentsFileName = "toolkit-components.yaml"
+	toolkitRBACFileName       = "toolkit-rbac.yaml"
+	toolkitSyncFileName       = "toolkit-sync.yaml"
+	toolkitSyncName           = "flux-system"
+	toolkitHealthPort         = 9440
+	toolkitRBACTemplate       = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: %[1]s-cluster-reconciler
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: cluster-admin
+subjects:
+  - kind: ServiceAccount
+    name: default
+    namespace: %[1]s
+`
+	toolkitGitRepositoryTemplate = `apiVersion: source.toolkit.fluxcd.io/v1beta1
+kind: GitRepository
+metadata:
+  name: %[1]s
+  namespace: %[2]s
+spec:
+  interval: 1m0s
+  ref:
+    branch: %[3]s
+  secretRef:
+    name: %[1]s
+  url: %[4]s
+`
+	toolkitKustomizationTemplate = `apiVersion: kustomize.toolkit.fluxcd.io/v1beta1
+kind: Kustomization
+metadata:
+  name: %[1]s
+  namespace: %[2]s
+spec:
+  interval: 10m0s
+  path: %[3]s
+  prune: true
+  sourceRef:
+    kind: GitRepository
+    name: %[4]s
+`
+)
+
+// toolkitComponent is a GitOps Toolkit controller, pinned to a release
+type toolkitComponent struct {
+	name    string
+	version string
+}
+
+var (
+	toolkitComponents = []toolkitComponent{
+		{name: "source-controller", version: "v0.1.0"},
+		{name: "kustomize-controller", version: "v0.1.0"},
+	}
+	toolkitHelmComponent = toolkitComponent{name: "helm-controller", version: "v0.1.0"}
+
+	// toolkitManifestURLTemplate is filled in with the component's name, its
+	// version and the kind of manifest ("crds" or "deployment")
+	toolkitManifestURLTemplate = "https://github.com/fluxcd/%[1]s/releases/download/%[2]s/%[1]s.%[3]s.yaml"
+
+	// scanKnownHosts is a variable so that tests don't need to reach the Git host
+	scanKnownHosts = sshKeyScan
+)
+
+// kustomizationResource is the resource of the Kustomization applying the Flux directory
+var kustomizationResource = schema.GroupVersionResource{
+	Group:    "kustomize.toolkit.fluxcd.io",
+	Version:  "v1beta1",
+	Resource: "kustomizations",
+}
+
+// clusterScopedKinds are the kinds found in the toolkit manifests which must
+// not be given a namespace
+var clusterScopedKinds = map[string]bool{
+	"CustomResourceDefinition": true,
+	"ClusterRole":              true,
+	"ClusterRoleBinding":       true,
+	"Namespace":                true,
+}
+
+// deployKey is the SSH key pair the source controller uses to access the repository
+type deployKey struct {
+	privateKey []byte
+	publicKey  []byte
+	knownHosts []byte
+}
+
+func (fi *Installer) runToolkit(ctx context.Context, gitProvider *gitProvider) error {
+	logger.Info("Generating GitOps Toolkit manifests")
+	manifests, err := getToolkitManifests(fi.opts, fi.k8sClientSet)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Generating SSH deploy key")
+	key, err := newDeployKey(fi.opts.GitURL, fi.opts.Timeout)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Cloning %s", fi.opts.GitURL)
+	cloneDir, err := fi.gitClient.CloneRepo("eksctl-install-flux-clone-", fi.opts.GitBranch, fi.opts.GitURL)
+	if err != nil {
+		return errors.Wrapf(err, "cannot clone repository %s", fi.opts.GitURL)
+	}
+	cleanCloneDir := false
+	defer func() {
+		if cleanCloneDir {
+			_ = fi.gitClient.DeleteLocalRepo()
+		} else {
+			logger.Critical("You may find the local clone of %s used by eksctl at %s",
+				fi.opts.GitURL,
+				cloneDir)
+		}
+	}()
+	logger.Info("Writing GitOps Toolkit manifests")
+	fluxManifestDir := filepath.Join(cloneDir, fi.opts.GitFluxPath)
+	if err := writeFluxManifests(fluxManifestDir, manifests); err != nil {
+		return err
+	}
+
+	if fi.opts.Amend {
+		logger.Info("Stopping to amend the the GitOps Toolkit manifests, please exit the shell when done.")
+		if err := runShell(fluxManifestDir); err != nil {
+			return err
+		}
+		// Re-read the manifests, as they may have changed:
+		manifests, err = readFluxManifests(fluxManifestDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The sync objects can only be applied once the controllers (and their
+	// CRDs) are in place, and once the manifests have been pushed
+	syncManifest := manifests[toolkitSyncFileName]
+	delete(manifests, toolkitSyncFileName)
+
+	logger.Info("Applying manifests")
+	if err := fi.applyToolkitManifests(manifests); err != nil {
+		return err
+	}
+
+	logger.Info("Applying SSH deploy key Secret")
+	secret, err := yaml.Marshal(newDeployKeySecret(fi.opts.Namespace, key))
+	if err != nil {
+		return errors.Wrap(err, "cannot serialize deploy key secret")
+	}
+	if err := fi.applyToolkitManifests(map[string][]byte{"secret": secret}); err != nil {
+		return err
+	}
+	logger.Warning("Note: the deploy key secret isn't added to the Git repository for security reasons")
+
+	components := toolkitComponents
+	if fi.opts.WithHelm {
+		components = append(components, toolkitHelmComponent)
+	}
+	for _, component := range components {
+		logger.Info("Waiting for %s to start", component.name)
+		if err := waitForToolkitComponentToStart(ctx, fi.opts.Namespace, component.name, fi.opts.Timeout, fi.k8sRestConfig, fi.k8sClientSet); err != nil {
+			return err
+		}
+	}
+	logger.Info("GitOps Toolkit started successfully")
+
+	logger.Info("Committing and pushing manifests to %s", fi.opts.GitURL)
+	if err := fi.addFilesToRepo(ctx, cloneDir); err != nil {
+		return err
+	}
+	cleanCloneDir = true
+
+	logger.Info("Applying sync manifests")
+	if err := fi.applyToolkitManifests(map[string][]byte{toolkitSyncFileName: syncManifest}); err != nil {
+		return err
+	}
+	logger.Info("see https://toolkit.fluxcd.io for details on how to use the GitOps Toolkit")
+
+	if gitProvider == nil {
+		logger.Info("the GitOps Toolkit will only operate properly once it has read-access to the Git repository")
+		logger.Info("please configure %s so that the following SSH public key has read access to it\n%s",
+			fi.opts.GitURL, key.publicKey)
+		return nil
+	}
+
+	if err := gitProvider.addDeployKey(ctx, string(key.publicKey)); err != nil {
+		return err
+	}
+	logger.Info("Waiting for the GitOps Toolkit to sync %s", fi.opts.GitURL)
+	return fi.waitForToolkitSync(ctx)
+}
+
+// waitForToolkitSync waits until the Flux directory has been applied by the kustomize controller,
+// for up to the configured timeout or until ctx is done
+func (fi *Installer) waitForToolkitSync(ctx context.Context) error {
+	dynamicClient, err := dynamic.NewForConfig(fi.k8sRestConfig)
+	if err != nil {
+		return errors.Wrap(err, "cannot create dynamic Kubernetes client")
+	}
+	kustomizations := dynamicClient.Resource(kustomizationResource).Namespace(fi.opts.Namespace)
+	deadline := time.Now().Add(fi.opts.Timeout)
+	for ; time.Now().Before(deadline); time.Sleep(5 * time.Second) {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrapf(err, "waiting for the GitOps Toolkit to sync %s", fi.opts.GitURL)
+		}
+		kustomization, err := kustomizations.Get(toolkitSyncName, metav1.GetOptions{})
+		if err != nil {
+			logger.Warning("cannot get kustomization %q (%s), retrying ...", toolkitSyncName, err)
+			continue
+		}
+		revision, _, _ := unstructured.NestedString(kustomization.Object, "status", "lastAppliedRevision")
+		if revision != "" {
+			logger.Info("the GitOps Toolkit synced revision %s", revision)
+			return nil
+		}
+	}
+	return fmt.Errorf("timed out waiting for the GitOps Toolkit to sync %s", fi.opts.GitURL)
+}
+
+// applyToolkitManifests applies the manifests using the dynamic client, as
+// the toolkit's custom resources (and apiextensions.k8s.io/v1 CRDs) cannot be
+// decoded by the typed client
+func (fi *Installer) applyToolkitManifests(manifestsMap map[string][]byte) error {
+	dynamicClient, err := dynamic.NewForConfig(fi.k8sRestConfig)
+	if err != nil {
+		return errors.Wrap(err, "cannot create dynamic Kubernetes client")
+	}
+	// Discover the API resources on every call, as CRDs may have been added since
+	apiGroupResources, err := restmapper.GetAPIGroupResources(fi.k8sClientSet.Discovery())
+	if err != nil {
+		return errors.Wrap(err, "getting list of API resources")
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(apiGroupResources)
+
+	var manifestValues [][]byte
+	if namespace, ok := manifestsMap[fluxNamespaceFileName]; ok {
+		manifestValues = append(manifestValues, namespace)
+	}
+	for fileName, manifest := range manifestsMap {
+		if fileName != fluxNamespaceFileName {
+			manifestValues = append(manifestValues, manifest)
+		}
+	}
+	objects, err := decodeUnstructured(kubernetes.ConcatManifests(manifestValues...))
+	if err != nil {
+		return err
+	}
+
+	// CRDs go first, the sync manifests rely on them
+	sortedObjects := []*unstructured.Unstructured{}
+	for _, obj := range objects {
+		if obj.GetKind() == "Namespace" || obj.GetKind() == "CustomResourceDefinition" {
+			sortedObjects = append(sortedObjects, obj)
+		}
+	}
+	for _, obj := range objects {
+		if obj.GetKind() != "Namespace" && obj.GetKind() != "CustomResourceDefinition" {
+			sortedObjects = append(sortedObjects, obj)
+		}
+	}
+
+	for _, obj := range sortedObjects {
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return errors.Wrapf(err, "constructing REST client mapping for %s", gvk.String())
+		}
+		var resource dynamic.ResourceInterface = dynamicClient.Resource(mapping.Resource)
+		if obj.GetNamespace() != "" {
+			resource = dynamicClient.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+		}
+		id := fmt.Sprintf("%s %q", strings.ToLower(gvk.Kind), obj.GetName())
+
+		existing, err := resource.Get(obj.GetName(), metav1.GetOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return errors.Wrapf(err, "cannot get %s", id)
+			}
+			if _, err := resource.Create(obj, metav1.CreateOptions{}); err != nil {
+				return errors.Wrapf(err, "cannot create %s", id)
+			}
+			logger.Info("created %s", id)
+			continue
+		}
+		obj.SetResourceVersion(existing.GetResourceVersion())
+		if _, err := resource.Update(obj, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "cannot update %s", id)
+		}
+		logger.Info("replaced %s", id)
+	}
+	return nil
+}
+
+func getToolkitManifests(opts *InstallOpts, cs kubeclient.Interface) (map[string][]byte, error) {
+	manifests := map[string][]byte{}
+	nsExists, err := kubernetes.CheckNamespaceExists(cs, opts.Namespace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot check if namespace %s exists", opts.Namespace)
+	}
+	if !nsExists {
+		manifests[fluxNamespaceFileName] = kubernetes.NewNamespaceYAML(opts.Namespace)
+	}
+
+	components := toolkitComponents
+	if opts.WithHelm {
+		components = append(components, toolkitHelmComponent)
+	}
+	client := &http.Client{Timeout: opts.Timeout}
+	var componentManifests [][]byte
+	for _, component := range components {
+		for _, kind := range []string{"crds", "deployment"} {
+			manifest, err := downloadToolkitManifest(client, component, kind)
+			if err != nil {
+				return nil, err
+			}
+			namespaced, err := setNamespace(manifest, opts.Namespace)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot process %s manifests for %s", kind, component.name)
+			}
+			componentManifests = append(componentManifests, namespaced)
+		}
+	}
+	manifests[toolkitComponentsFileName] = kubernetes.ConcatManifests(componentManifests...)
+	manifests[toolkitRBACFileName] = []byte(fmt.Sprintf(toolkitRBACTemplate, opts.Namespace))
+
+	syncManifest, err := getToolkitSyncManifest(opts)
+	if err != nil {
+		return nil, err
+	}
+	manifests[toolkitSyncFileName] = syncManifest
+	return manifests, nil
+}
+
+func downloadToolkitManifest(client *http.Client, component toolkitComponent, kind string) ([]byte, error) {
+	url := fmt.Sprintf(toolkitManifestURLTemplate, component.name, component.version, kind)
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot download %s", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot download %s: unexpected status %q", url, resp.Status)
+	}
+	manifest, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read %s", url)
+	}
+	return manifest, nil
+}
+
+// getToolkitSyncManifest generates a GitRepository pointing at the repository
+// and a Kustomization for the Flux directory and for each of the Git paths
+func getToolkitSyncManifest(opts *InstallOpts) ([]byte, error) {
+	url, err := toSSHURL(opts.GitURL)
+	if err != nil {
+		return nil, err
+	}
+	syncManifests := [][]byte{
+		[]byte(fmt.Sprintf(toolkitGitRepositoryTemplate, toolkitSyncName, opts.Namespace, opts.GitBranch, url)),
+		[]byte(fmt.Sprintf(toolkitKustomizationTemplate, toolkitSyncName, opts.Namespace, kustomizationPath(opts.GitFluxPath), toolkitSyncName)),
+	}
+	for i, gitPath := range opts.GitPaths {
+		name := fmt.Sprintf("%s-%d", toolkitSyncName, i)
+		syncManifests = append(syncManifests,
+			[]byte(fmt.Sprintf(toolkitKustomizationTemplate, name, opts.Namespace, kustomizationPath(gitPath), toolkitSyncName)))
+	}
+	return kubernetes.ConcatManifests(syncManifests...), nil
+}
+
+// kustomizationPath turns a path within the repository into the "./"-prefixed
+// form expected by the kustomize controller
+func kustomizationPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if cleaned == "/" {
+		return "./"
+	}
+	return "." + cleaned
+}
+
+// toSSHURL converts scp-like Git URLs (e.g. git@github.com:org/repo.git),
+// which the source controller does not support, into ssh:// URLs
+func toSSHURL(gitURL string) (string, error) {
+	u, err := giturls.Parse(gitURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to parse git URL '%s'", gitURL)
+	}
+	if u.Scheme != "ssh" {
+		return "", fmt.Errorf("only SSH Git URLs are supported by the GitOps Toolkit installer, got '%s'", gitURL)
+	}
+	return u.String(), nil
+}
+
+func setNamespace(manifest []byte, namespace string) ([]byte, error) {
+	objects, err := decodeUnstructured(manifest)
+	if err != nil {
+		return nil, err
+	}
+	var namespaced [][]byte
+	for _, obj := range objects {
+		if !clusterScopedKinds[obj.GetKind()] {
+			obj.SetNamespace(namespace)
+		}
+		objBytes, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+		namespaced = append(namespaced, objBytes)
+	}
+	return kubernetes.ConcatManifests(namespaced...), nil
+}
+
+func decodeUnstructured(manifest []byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+	for {
+		obj := map[string]interface{}{}
+		if err := decoder.Decode(&obj); err != nil {
+			if err == io.EOF {
+				return objects, nil
+			}
+			return nil, errors.Wrap(err, "decoding object")
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		objects = append(objects, &unstructured.Unstructured{Object: obj})
+	}
+}
+
+func newDeployKey(gitURL string, timeout time.Duration) (*deployKey, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot generate SSH key")
+	}
+	privateKeyDER, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot serialize SSH private key")
+	}
+	publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot generate SSH public key")
+	}
+
+	u, err := giturls.Parse(gitURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse git URL '%s'", gitURL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "22"
+	}
+	knownHosts, err := scanKnownHosts(net.JoinHostPort(u.Hostname(), port), timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deployKey{
+		privateKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privateKeyDER}),
+		publicKey:  ssh.MarshalAuthorizedKey(publicKey),
+		knownHosts: knownHosts,
+	}, nil
+}
+
+func newDeployKeySecret(namespace string, key *deployKey) *corev1.Secret {
+	secret := &corev1.Secret{
+		Data: map[string][]byte{
+			"identity":     key.privateKey,
+			"identity.pub": key.publicKey,
+			"known_hosts":  key.knownHosts,
+		},
+	}
+	secret.Kind = "Secret"
+	secret.APIVersion = "v1"
+	secret.Name = toolkitSyncName
+	secret.Namespace = namespace
+	return secret
+}
+
+// sshKeyScan returns the known_hosts line for the host key presented by addr
+func sshKeyScan(addr string, timeout time.Duration) ([]byte, error) {
+	var hostKey ssh.PublicKey
+	config := &ssh.ClientConfig{
+		User: "git",
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			hostKey = key
+			return nil
+		},
+		Timeout: timeout,
+	}
+	// Authentication is expected to fail, the host key is all we're after
+	conn, err := ssh.Dial("tcp", addr, config)
+	if err == nil {
+		_ = conn.Close()
+	}
+	if hostKey == nil {
+		return nil, errors.Wrapf(err, "cannot obtain SSH host key of %s", addr)
+	}
+	return []byte(knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostKey) + "\n"), nil
+}
+
+func waitForToolkitComponentToStart(ctx context.Context, namespace, component string, timeout time.Duration,
+	restConfig *rest.Config, cs kubeclient.Interface) error {
+	try := func(rootURL string) error {
+		req, err := http.NewRequest("GET", rootURL+"healthz", nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %s", err)
+		}
+		healthzCtx, healthzCtxCancel := context.WithTimeout(ctx, timeout)
+		defer healthzCtxCancel()
+		resp, err := http.DefaultClient.Do(req.WithContext(healthzCtx))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status %q", resp.Status)
+		}
+		return nil
+	}
+	return waitForPodToStart(namespace, "app", component, toolkitHealthPort, component, restConfig, cs, try)
+}
@@ -0,0 +1,182 @@
+package flux
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	fakeCRDsManifest = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: %[1]ss.toolkit.fluxcd.io
+`
+	fakeDeploymentManifest = `apiVersion: v1
+kind: Service
+metadata:
+  name: %[1]s
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %[1]s
+`
+)
+
+var _ = Describe("GitOps Toolkit installer", func() {
+	var (
+		server           *httptest.Server
+		requested        []string
+		originalTemplate string
+		opts             *InstallOpts
+	)
+
+	BeforeEach(func() {
+		requested = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested = append(requested, r.URL.Path)
+			parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+			switch {
+			case len(parts) == 3 && strings.HasSuffix(parts[2], ".crds.yaml"):
+				fmt.Fprintf(w, fakeCRDsManifest, parts[0])
+			case len(parts) == 3 && strings.HasSuffix(parts[2], ".deployment.yaml"):
+				fmt.Fprintf(w, fakeDeploymentManifest, parts[0])
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		originalTemplate = toolkitManifestURLTemplate
+		toolkitManifestURLTemplate = server.URL + "/%[1]s/%[2]s/%[1]s.%[3]s.yaml"
+
+		opts = &InstallOpts{
+			GitURL:      "git@github.com:foo/bar.git",
+			GitBranch:   "gitbranch",
+			GitPaths:    []string{"gitpath/"},
+			GitFluxPath: "fluxpath/",
+			Namespace:   "flux-system",
+			FluxVersion: FluxVersionV2,
+		}
+	})
+
+	AfterEach(func() {
+		toolkitManifestURLTemplate = originalTemplate
+		server.Close()
+	})
+
+	It("should download the pinned source and kustomize controllers", func() {
+		manifests, err := getToolkitManifests(opts, fake.NewSimpleClientset())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifests).To(HaveLen(4))
+		Expect(manifests).To(HaveKey(fluxNamespaceFileName))
+		Expect(manifests).To(HaveKey(toolkitRBACFileName))
+		Expect(manifests).To(HaveKey(toolkitSyncFileName))
+
+		Expect(requested).To(ConsistOf(
+			"/source-controller/v0.1.0/source-controller.crds.yaml",
+			"/source-controller/v0.1.0/source-controller.deployment.yaml",
+			"/kustomize-controller/v0.1.0/kustomize-controller.crds.yaml",
+			"/kustomize-controller/v0.1.0/kustomize-controller.deployment.yaml",
+		))
+	})
+
+	It("should add the helm controller when requested", func() {
+		opts.WithHelm = true
+		manifests, err := getToolkitManifests(opts, fake.NewSimpleClientset())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requested).To(ContainElement("/helm-controller/v0.1.0/helm-controller.deployment.yaml"))
+		Expect(string(manifests[toolkitComponentsFileName])).To(ContainSubstring("helm-controller"))
+	})
+
+	It("should only set the namespace of namespaced objects", func() {
+		manifests, err := getToolkitManifests(opts, fake.NewSimpleClientset())
+		Expect(err).NotTo(HaveOccurred())
+
+		objects, err := decodeUnstructured(manifests[toolkitComponentsFileName])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objects).To(HaveLen(6))
+		for _, obj := range objects {
+			if obj.GetKind() == "CustomResourceDefinition" {
+				Expect(obj.GetNamespace()).To(BeEmpty())
+			} else {
+				Expect(obj.GetNamespace()).To(Equal("flux-system"))
+			}
+		}
+	})
+
+	It("should generate the sync objects", func() {
+		manifests, err := getToolkitManifests(opts, fake.NewSimpleClientset())
+		Expect(err).NotTo(HaveOccurred())
+
+		objects, err := decodeUnstructured(manifests[toolkitSyncFileName])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objects).To(HaveLen(3))
+
+		Expect(objects[0].GetKind()).To(Equal("GitRepository"))
+		Expect(objects[0].Object["spec"]).To(HaveKeyWithValue("url", "ssh://git@github.com/foo/bar.git"))
+		Expect(objects[0].Object["spec"]).To(HaveKeyWithValue("ref", HaveKeyWithValue("branch", "gitbranch")))
+		Expect(objects[0].Object["spec"]).To(HaveKeyWithValue("secretRef", HaveKeyWithValue("name", "flux-system")))
+
+		Expect(objects[1].GetKind()).To(Equal("Kustomization"))
+		Expect(objects[1].GetName()).To(Equal("flux-system"))
+		Expect(objects[1].Object["spec"]).To(HaveKeyWithValue("path", "./fluxpath"))
+
+		Expect(objects[2].GetKind()).To(Equal("Kustomization"))
+		Expect(objects[2].GetName()).To(Equal("flux-system-0"))
+		Expect(objects[2].Object["spec"]).To(HaveKeyWithValue("path", "./gitpath"))
+	})
+
+	It("should fail when a manifest cannot be downloaded", func() {
+		toolkitManifestURLTemplate = server.URL + "/missing/%[1]s/%[2]s/%[3]s"
+		_, err := getToolkitManifests(opts, fake.NewSimpleClientset())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("404"))
+	})
+
+	DescribeTable("converting Git URLs to ssh:// URLs",
+		func(gitURL, expected string, expectErr bool) {
+			url, err := toSSHURL(gitURL)
+			if expectErr {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(url).To(Equal(expected))
+		},
+		Entry("scp-like", "git@github.com:foo/bar.git", "ssh://git@github.com/foo/bar.git", false),
+		Entry("ssh", "ssh://git@example.com:2222/foo/bar.git", "ssh://git@example.com:2222/foo/bar.git", false),
+		Entry("https", "https://github.com/foo/bar.git", "", true),
+	)
+
+	It("should generate a usable deploy key", func() {
+		originalScan := scanKnownHosts
+		defer func() { scanKnownHosts = originalScan }()
+		var scannedAddr string
+		scanKnownHosts = func(addr string, _ time.Duration) ([]byte, error) {
+			scannedAddr = addr
+			return []byte("github.com ssh-rsa AAAA\n"), nil
+		}
+
+		key, err := newDeployKey("git@github.com:foo/bar.git", time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scannedAddr).To(Equal("github.com:22"))
+
+		signer, err := ssh.ParsePrivateKey(key.privateKey)
+		Expect(err).NotTo(HaveOccurred())
+		publicKey, _, _, _, err := ssh.ParseAuthorizedKey(key.publicKey)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(publicKey.Marshal()).To(Equal(signer.PublicKey().Marshal()))
+
+		secret := newDeployKeySecret("flux-system", key)
+		Expect(secret.Name).To(Equal("flux-system"))
+		Expect(secret.Data).To(HaveKeyWithValue("known_hosts", []byte("github.com ssh-rsa AAAA\n")))
+	})
+})
@@ -9,6 +9,7 @@ import (
 
 	portforward "github.com/justinbarrick/go-k8s-portforward"
 	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
 	fluxapi "github.com/weaveworks/flux/api/v6"
 	transport "github.com/weaveworks/flux/http"
 	"github.com/weaveworks/flux/http/client"
@@ -30,10 +31,32 @@ func waitForFluxToStart(ctx context.Context, namespace string, timeout time.Dura
 		fluxGitConfig, err = fluxClient.GitRepoConfig(repoCtx, false)
 		return err
 	}
-	err := waitForPodToStart(namespace, "flux", 3030, "Flux", restConfig, cs, try)
+	err := waitForPodToStart(namespace, "name", "flux", 3030, "Flux", restConfig, cs, try)
 	return fluxGitConfig.PublicSSHKey, err
 }
 
+// waitForFluxToSync waits until Flux has applied the commit, which requires Flux to have access to the repository,
+// for up to timeout or until ctx is done
+func waitForFluxToSync(ctx context.Context, namespace, commit string, timeout time.Duration, restConfig *rest.Config,
+	cs kubeclient.Interface) error {
+	try := func(rootURL string) error {
+		fluxURL := rootURL + "api/flux"
+		fluxClient := client.New(http.DefaultClient, transport.NewAPIRouter(), fluxURL, client.Token(""))
+		syncCtx, syncCtxCancel := context.WithTimeout(ctx, timeout)
+		defer syncCtxCancel()
+		// The commits which haven't been applied yet, all of them until the first sync
+		pending, err := fluxClient.SyncStatus(syncCtx, commit)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d commit(s) still to be synced", len(pending))
+		}
+		return nil
+	}
+	return waitForPodToStartWithRetryTimeout(ctx, namespace, "name", "flux", 3030, "Flux", restConfig, cs, try, timeout)
+}
+
 func waitForHelmOpToStart(ctx context.Context, namespace string, timeout time.Duration, restConfig *rest.Config,
 	cs kubeclient.Interface) error {
 	try := func(rootURL string) error {
@@ -48,19 +71,26 @@ func waitForHelmOpToStart(ctx context.Context, namespace string, timeout time.Du
 		_, err = http.DefaultClient.Do(req)
 		return err
 	}
-	return waitForPodToStart(namespace, "flux-helm-operator", 3030, "Helm Operator", restConfig, cs, try)
+	return waitForPodToStart(namespace, "name", "flux-helm-operator", 3030, "Helm Operator", restConfig, cs, try)
 }
 
 type tryFunc func(rootURL string) error
 
-func waitForPodToStart(namespace string, nameLabelValue string, port int, name string,
+func waitForPodToStart(namespace string, labelKey string, labelValue string, port int, name string,
 	restConfig *rest.Config, cs kubeclient.Interface, try tryFunc) error {
+	return waitForPodToStartWithRetryTimeout(context.Background(), namespace, labelKey, labelValue, port, name, restConfig, cs, try, 30*time.Second)
+}
+
+// waitForPodToStartWithRetryTimeout port-forwards to the pod and retries try until it succeeds, for up to retryTimeout
+// or until ctx is done
+func waitForPodToStartWithRetryTimeout(ctx context.Context, namespace string, labelKey string, labelValue string, port int, name string,
+	restConfig *rest.Config, cs kubeclient.Interface, try tryFunc, retryTimeout time.Duration) error {
 	fluxSelector := metav1.LabelSelector{
 		MatchExpressions: []metav1.LabelSelectorRequirement{
 			{
-				Key:      "name",
+				Key:      labelKey,
 				Operator: metav1.LabelSelectorOpIn,
-				Values:   []string{nameLabelValue},
+				Values:   []string{labelValue},
 			},
 		},
 	}
@@ -87,14 +117,17 @@ func waitForPodToStart(namespace string, nameLabelValue string, port int, name s
 	}
 	baseURL := fmt.Sprintf("http://127.0.0.1:%d/", portforwarder.ListenPort)
 	// Make sure it's alive
-	retryDeadline := time.Now().Add(30 * time.Second)
-	for ; time.Now().Before(retryDeadline); time.Sleep(2 * time.Second) {
+	retryDeadline := time.Now().Add(retryTimeout)
+	for ; time.Now().Before(retryDeadline) && ctx.Err() == nil; time.Sleep(2 * time.Second) {
 		err := try(baseURL)
 		if err == nil {
 			break
 		}
 		logger.Warning("%s is not ready yet (%s), retrying ...", name, err)
 	}
+	if err := ctx.Err(); err != nil {
+		return errors.Wrapf(err, "waiting for %s to be operative", name)
+	}
 	if time.Now().After(retryDeadline) {
 		return fmt.Errorf("timed out waiting for %s to be operative", name)
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/weaveworks/eksctl/pkg/git"
+	"github.com/weaveworks/eksctl/pkg/gitops/catalog"
 	"github.com/weaveworks/eksctl/pkg/gitops/fileprocessor"
 )
 
@@ -22,6 +23,16 @@ func (m *mockCloner) CloneRepo(cloneDirPrefix string, branch string, gitURL stri
 	return args.String(0), args.Error(1)
 }
 
+func (m *mockCloner) HeadCommit() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockCloner) Checkout(revision string) error {
+	args := m.Called(revision)
+	return args.Error(0)
+}
+
 var _ = Describe("gitops profile", func() {
 
 	var (
@@ -48,6 +59,7 @@ var _ = Describe("gitops profile", func() {
 			// mock git clone
 			gitCloner = new(mockCloner)
 			gitCloner.On("CloneRepo", mock.Anything, mock.Anything, mock.Anything).Return(testDir, nil)
+			gitCloner.On("HeadCommit").Return("abc123", nil)
 
 			// output path
 			outputDir, _ = io.TempDir("", "test-output-dir-")
@@ -86,6 +98,44 @@ var _ = Describe("gitops profile", func() {
 			Expect(template2).To(MatchYAML([]byte("name: test-cluster")))
 		})
 
+		It("records the source of the manifests", func() {
+			err := profile.Generate(context.Background())
+
+			Expect(err).ToNot(HaveOccurred())
+			metadata, err := profile.readMetadata()
+			Expect(err).ToNot(HaveOccurred())
+			_, clusterConfigHash, err := profile.templateInputs()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*metadata).To(Equal(Metadata{
+				Source:            "git@github.com:someorg/test-gitops-repo.git",
+				URL:               "git@github.com:someorg/test-gitops-repo.git",
+				Revision:          "master",
+				Commit:            "abc123",
+				Values:            map[string]interface{}{},
+				ClusterConfigHash: clusterConfigHash,
+			}))
+		})
+
+		It("resolves name@version references with the catalog", func() {
+			profile.Catalog = &catalog.Default
+			profile.GitOpts = git.Options{URL: "app-dev@v0.1.0"}
+
+			err := profile.Generate(context.Background())
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitCloner.AssertCalled(GinkgoT(), "CloneRepo", cloneDirPrefix, "v0.1.0", catalog.Default.Find("app-dev").URL)).To(BeTrue())
+		})
+
+		It("fails on references to unknown profiles", func() {
+			profile.Catalog = &catalog.Default
+			profile.GitOpts = git.Options{URL: "unknown-profile"}
+
+			err := profile.Generate(context.Background())
+
+			Expect(err).To(HaveOccurred())
+			Expect(gitCloner.AssertNotCalled(GinkgoT(), "CloneRepo", mock.Anything, mock.Anything, mock.Anything)).To(BeTrue())
+		})
+
 		It("can load files and ignore .git/ files", func() {
 			files, err := profile.loadFiles(testDir)
 
@@ -147,7 +197,7 @@ metadata:
 					},
 				}
 
-				files, err := profile.processFiles(inputFiles, "dir0")
+				files, err := profile.processFiles(processor, inputFiles, "dir0")
 
 				Expect(err).ToNot(HaveOccurred())
 				Expect(files).To(HaveLen(4))
@@ -172,6 +222,147 @@ metadata:
 			})
 		})
 	})
+
+	Context("updating a profile", func() {
+		const profileURL = "git@github.com:someorg/test-profile.git"
+
+		var (
+			baseDir  string
+			otherDir string
+		)
+
+		BeforeEach(func() {
+			memFs = afero.NewMemMapFs()
+			io = afero.Afero{Fs: memFs}
+
+			// The profile at the recorded commit, and at the new version
+			baseDir, _ = io.TempDir("", "test-base-")
+			createFile(memFs, filepath.Join(baseDir, "unchanged.yaml"), "a: 1\n")
+			createFile(memFs, filepath.Join(baseDir, "updated.yaml"), "a: 1\n")
+			createFile(memFs, filepath.Join(baseDir, "merged.yaml"), "a: 1\nb: 2\nc: 3\nd: 4\ne: 5\n")
+			createFile(memFs, filepath.Join(baseDir, "conflict.yaml.tmpl"), "cluster: {{ .ClusterName }}\n")
+			createFile(memFs, filepath.Join(baseDir, "removed.yaml"), "a: 1\n")
+			createFile(memFs, filepath.Join(baseDir, "removed-but-edited.yaml"), "a: 1\n")
+
+			otherDir, _ = io.TempDir("", "test-other-")
+			createFile(memFs, filepath.Join(otherDir, "unchanged.yaml"), "a: 1\n")
+			createFile(memFs, filepath.Join(otherDir, "updated.yaml"), "a: 2\n")
+			createFile(memFs, filepath.Join(otherDir, "merged.yaml"), "a: 1\nb: 2\nc: 3\nd: 4\ne: 6\n")
+			createFile(memFs, filepath.Join(otherDir, "conflict.yaml.tmpl"), "cluster: {{ .ClusterName | upper }}\n")
+			createFile(memFs, filepath.Join(otherDir, "added.yaml"), "a: 1\n")
+
+			gitCloner = new(mockCloner)
+			gitCloner.On("CloneRepo", mock.Anything, "v1", profileURL).Return(baseDir, nil)
+			gitCloner.On("CloneRepo", mock.Anything, "v2", profileURL).Return(otherDir, nil)
+			gitCloner.On("Checkout", "commit1").Return(nil)
+			gitCloner.On("HeadCommit").Return("commit2", nil)
+
+			// The user's files, as generated from the base and then edited
+			outputDir, _ = io.TempDir("", "test-output-dir-")
+			createFile(memFs, filepath.Join(outputDir, MetadataFileName),
+				"source: "+profileURL+"@v1\nurl: "+profileURL+"\nrevision: v1\ncommit: commit1\n")
+			createFile(memFs, filepath.Join(outputDir, "unchanged.yaml"), "a: 1\nlocal: true\n")
+			createFile(memFs, filepath.Join(outputDir, "updated.yaml"), "a: 1\n")
+			createFile(memFs, filepath.Join(outputDir, "merged.yaml"), "a: 0\nb: 2\nc: 3\nd: 4\ne: 5\n")
+			createFile(memFs, filepath.Join(outputDir, "conflict.yaml"), "cluster: edited\n")
+			createFile(memFs, filepath.Join(outputDir, "removed.yaml"), "a: 1\n")
+			createFile(memFs, filepath.Join(outputDir, "removed-but-edited.yaml"), "a: 2\n")
+
+			profile = &Profile{
+				Path:      outputDir,
+				GitOpts:   git.Options{URL: profileURL + "@v2"},
+				IO:        io,
+				FS:        memFs,
+				GitCloner: gitCloner,
+				Processor: &fileprocessor.GoTemplateProcessor{
+					Params: fileprocessor.TemplateParameters{ClusterName: "test-cluster"},
+				},
+				Catalog: &catalog.Default,
+			}
+		})
+
+		AfterEach(func() {
+			io.RemoveAll(outputDir)
+		})
+
+		readOutput := func(path string) string {
+			data, err := io.ReadFile(filepath.Join(outputDir, path))
+			Expect(err).ToNot(HaveOccurred())
+			return string(data)
+		}
+
+		It("merges the new version with the local edits and reports conflicts", func() {
+			conflicts, err := profile.Update(context.Background())
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conflicts).To(Equal([]string{"conflict.yaml", "removed-but-edited.yaml"}))
+
+			Expect(readOutput("unchanged.yaml")).To(Equal("a: 1\nlocal: true\n"))
+			Expect(readOutput("updated.yaml")).To(Equal("a: 2\n"))
+			Expect(readOutput("merged.yaml")).To(Equal("a: 0\nb: 2\nc: 3\nd: 4\ne: 6\n"))
+			Expect(readOutput("added.yaml")).To(Equal("a: 1\n"))
+			Expect(readOutput("removed-but-edited.yaml")).To(Equal("a: 2\n"))
+			Expect(readOutput("conflict.yaml")).To(And(
+				ContainSubstring("<<<<<<< local/conflict.yaml"),
+				ContainSubstring("cluster: edited"),
+				ContainSubstring("cluster: TEST-CLUSTER"),
+				ContainSubstring(">>>>>>> commit2/conflict.yaml"),
+			))
+			exists, err := io.Exists(filepath.Join(outputDir, "removed.yaml"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exists).To(BeFalse())
+
+			Expect(gitCloner.AssertCalled(GinkgoT(), "Checkout", "commit1")).To(BeTrue())
+		})
+
+		It("records the new version", func() {
+			_, err := profile.Update(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+
+			metadata, err := profile.readMetadata()
+			Expect(err).ToNot(HaveOccurred())
+			_, clusterConfigHash, err := profile.templateInputs()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*metadata).To(Equal(Metadata{
+				Source:            profileURL + "@v2",
+				URL:               profileURL,
+				Revision:          "v2",
+				Commit:            "commit2",
+				Values:            map[string]interface{}{},
+				ClusterConfigHash: clusterConfigHash,
+			}))
+		})
+
+		It("generates the base manifests with the recorded values", func() {
+			createFile(memFs, filepath.Join(baseDir, "values.yaml.tmpl"), "name: {{ .Values.name }}\n")
+			createFile(memFs, filepath.Join(otherDir, "values.yaml.tmpl"), "name: {{ .Values.name }}\nreplicas: 2\n")
+			createFile(memFs, filepath.Join(outputDir, "values.yaml"), "name: old\n")
+			createFile(memFs, filepath.Join(outputDir, MetadataFileName),
+				"source: "+profileURL+"@v1\nurl: "+profileURL+"\nrevision: v1\ncommit: commit1\nvalues:\n  name: old\n")
+			profile.Processor = &fileprocessor.GoTemplateProcessor{
+				Params: fileprocessor.TemplateParameters{ClusterName: "test-cluster", Values: map[string]interface{}{"name": "new"}},
+			}
+
+			conflicts, err := profile.Update(context.Background())
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conflicts).ToNot(ContainElement("values.yaml"))
+			Expect(readOutput("values.yaml")).To(Equal("name: new\nreplicas: 2\n"))
+
+			metadata, err := profile.readMetadata()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metadata.Values).To(Equal(map[string]interface{}{"name": "new"}))
+		})
+
+		It("fails without metadata", func() {
+			Expect(io.Remove(filepath.Join(outputDir, MetadataFileName))).To(Succeed())
+
+			_, err := profile.Update(context.Background())
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("only profiles generated by eksctl can be updated"))
+		})
+	})
 })
 
 func createTestFiles(testDir string, memFs afero.Fs) {
@@ -0,0 +1,100 @@
+package gitops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/weaveworks/eksctl/pkg/gitops/fileprocessor"
+)
+
+// MetadataFileName is the file recording the source of a profile in its output directory,
+// it has no .yaml extension so that it isn't applied by Flux
+const MetadataFileName = ".eksctl-profile"
+
+// Metadata records the source the manifests of a profile were generated from
+type Metadata struct {
+	// Source is the profile reference, e.g. app-dev@v0.1.0 or a URL
+	Source   string `json:"source"`
+	URL      string `json:"url"`
+	Revision string `json:"revision"`
+	// Commit is the commit of the profile's repository which was generated
+	Commit string `json:"commit"`
+	// Values are the values the templates were executed with, an update generates
+	// the manifests of Commit with them again
+	Values map[string]interface{} `json:"values"`
+	// ClusterConfigHash is a hash of the cluster parameters the templates were executed with,
+	// only used to warn that they have changed
+	ClusterConfigHash string `json:"clusterConfigHash,omitempty"`
+}
+
+// templateInputs returns the values and a hash of the cluster parameters the templates
+// are executed with, or nothing when the processor doesn't execute templates
+func (p *Profile) templateInputs() (map[string]interface{}, string, error) {
+	templates, ok := p.Processor.(*fileprocessor.GoTemplateProcessor)
+	if !ok {
+		return nil, "", nil
+	}
+	values := templates.Params.Values
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	clusterParams := templates.Params
+	clusterParams.Values = nil
+	data, err := json.Marshal(clusterParams)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "cannot encode the cluster parameters of the templates")
+	}
+	hash := sha256.Sum256(data)
+	return values, hex.EncodeToString(hash[:]), nil
+}
+
+// baseProcessor returns the processor generating the manifests of the recorded commit,
+// which executes the templates with the recorded values when there are some
+func (p *Profile) baseProcessor(metadata *Metadata) fileprocessor.FileProcessor {
+	templates, ok := p.Processor.(*fileprocessor.GoTemplateProcessor)
+	if !ok || metadata.Values == nil {
+		return p.Processor
+	}
+	params := templates.Params
+	params.Values = metadata.Values
+	return &fileprocessor.GoTemplateProcessor{Params: params}
+}
+
+func (p *Profile) metadataPath() string {
+	return filepath.Join(p.Path, MetadataFileName)
+}
+
+func (p *Profile) readMetadata() (*Metadata, error) {
+	data, err := p.IO.ReadFile(p.metadataPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("no %s file found in %q, only profiles generated by eksctl can be updated", MetadataFileName, p.Path)
+		}
+		return nil, errors.Wrapf(err, "cannot read profile metadata %q", p.metadataPath())
+	}
+	metadata := &Metadata{}
+	if err := yaml.Unmarshal(data, metadata); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse profile metadata %q", p.metadataPath())
+	}
+	return metadata, nil
+}
+
+func (p *Profile) writeMetadata(metadata Metadata) error {
+	data, err := yaml.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	if err := p.FS.MkdirAll(p.Path, 0755); err != nil {
+		return errors.Wrapf(err, "error creating output manifests dir: %q", p.Path)
+	}
+	if err := p.IO.WriteFile(p.metadataPath(), data, 0644); err != nil {
+		return errors.Wrapf(err, "error writing profile metadata %q", p.metadataPath())
+	}
+	return nil
+}
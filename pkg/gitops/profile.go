@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/afero"
 
 	"github.com/weaveworks/eksctl/pkg/git"
+	"github.com/weaveworks/eksctl/pkg/gitops/catalog"
 	"github.com/weaveworks/eksctl/pkg/gitops/fileprocessor"
 )
 
@@ -20,18 +21,31 @@ const (
 
 // Profile represents a GitOps profile
 type Profile struct {
+	Name      string
 	Processor fileprocessor.FileProcessor
 	Path      string
 	GitOpts   git.Options
 	GitCloner git.Cloner
 	FS        afero.Fs
 	IO        afero.Afero
+	// Catalog, when set, resolves GitOpts.URL if it is the name of a profile
+	// and accepts name@version references
+	Catalog   *catalog.Catalog
 	clonedDir string
 }
 
 // Generate clones the specified Git repo in a base directory and generates overlays if the Git repo
 // points to a profile repo
 func (p *Profile) Generate(ctx context.Context) error {
+	source := p.GitOpts.URL
+	if p.Catalog != nil {
+		ref, err := p.Catalog.Resolve(p.GitOpts.URL, p.GitOpts.Branch)
+		if err != nil {
+			return err
+		}
+		p.GitOpts.URL, p.GitOpts.Branch = ref.URL, ref.Revision
+	}
+
 	logger.Info("cloning repository %q:%s", p.GitOpts.URL, p.GitOpts.Branch)
 	clonedDir, err := p.GitCloner.CloneRepo(cloneDirPrefix, p.GitOpts.Branch, p.GitOpts.URL)
 	if err != nil {
@@ -39,13 +53,18 @@ func (p *Profile) Generate(ctx context.Context) error {
 	}
 	p.clonedDir = clonedDir
 
+	commit, err := p.GitCloner.HeadCommit()
+	if err != nil {
+		return errors.Wrapf(err, "error reading the commit of repository %s", p.GitOpts.URL)
+	}
+
 	allManifests, err := p.loadFiles(clonedDir)
 	if err != nil {
 		return errors.Wrapf(err, "error loading files from repository %s", p.GitOpts.URL)
 	}
 
 	logger.Info("processing template files in repository")
-	outputFiles, err := p.processFiles(allManifests, clonedDir)
+	outputFiles, err := p.processFiles(p.Processor, allManifests, clonedDir)
 	if err != nil {
 		return errors.Wrapf(err, "error processing manifests from repository %s", p.GitOpts.URL)
 	}
@@ -62,7 +81,19 @@ func (p *Profile) Generate(ctx context.Context) error {
 		return errors.Wrapf(err, "error writing manifests to dir: %q", p.Path)
 	}
 
-	return nil
+	// Record the source and the inputs of the manifests so that the profile can be updated
+	values, clusterConfigHash, err := p.templateInputs()
+	if err != nil {
+		return err
+	}
+	return p.writeMetadata(Metadata{
+		Source:            source,
+		URL:               p.GitOpts.URL,
+		Revision:          p.GitOpts.Branch,
+		Commit:            commit,
+		Values:            values,
+		ClusterConfigHash: clusterConfigHash,
+	})
 }
 
 // DeleteClonedDirectory deletes the directory where the repository was cloned
@@ -104,10 +135,10 @@ func (p *Profile) loadFiles(directory string) ([]fileprocessor.File, error) {
 	return files, nil
 }
 
-func (p *Profile) processFiles(files []fileprocessor.File, baseDir string) ([]fileprocessor.File, error) {
+func (p *Profile) processFiles(processor fileprocessor.FileProcessor, files []fileprocessor.File, baseDir string) ([]fileprocessor.File, error) {
 	outputFiles := make([]fileprocessor.File, 0, len(files))
 	for _, file := range files {
-		outputFile, err := p.Processor.ProcessFile(file)
+		outputFile, err := processor.ProcessFile(file)
 		if err != nil {
 			return nil, errors.Wrapf(err, "error processing file %q ", file.Path)
 		}
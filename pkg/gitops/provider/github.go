@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+)
+
+// GitHubAPIURL is the base URL of GitHub's API
+const GitHubAPIURL = "https://api.github.com"
+
+// GitHub manages repositories with GitHub's REST API (v3)
+type GitHub struct {
+	client
+}
+
+// NewGitHub creates a GitHub provider authenticated with a personal access token,
+// baseURL defaults to GitHubAPIURL
+func NewGitHub(baseURL, token string) *GitHub {
+	if baseURL == "" {
+		baseURL = GitHubAPIURL
+	}
+	return &GitHub{client{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: requestTimeout},
+		authorize: func(req *http.Request) {
+			req.Header.Set("Authorization", "token "+token)
+			req.Header.Set("Accept", "application/vnd.github.v3+json")
+		},
+	}}
+}
+
+type gitHubRepository struct {
+	Name          string `json:"name"`
+	DefaultBranch string `json:"default_branch,omitempty"`
+	Private       bool   `json:"private"`
+	AutoInit      bool   `json:"auto_init,omitempty"`
+}
+
+type gitHubRef struct {
+	Ref    string `json:"ref"`
+	Object struct {
+		SHA string `json:"sha"`
+	} `json:"object"`
+}
+
+type gitHubNewRef struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+type gitHubKey struct {
+	Title    string `json:"title,omitempty"`
+	Key      string `json:"key"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// CreateRepository creates the repository in the organization, or for the authenticated user
+// when they own it; it is initialized with a README, as an empty repository cannot be cloned
+func (g *GitHub) CreateRepository(ctx context.Context, repo Repository, branch string) (bool, error) {
+	status, err := g.do(ctx, http.MethodGet, "/repos/"+repo.String(), nil, nil, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return false, err
+	}
+	if status == http.StatusOK {
+		return false, nil
+	}
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if _, err := g.do(ctx, http.MethodGet, "/user", nil, &user, http.StatusOK); err != nil {
+		return false, errors.Wrap(err, "cannot get the authenticated user")
+	}
+	path := "/orgs/" + repo.Owner + "/repos"
+	if strings.EqualFold(user.Login, repo.Owner) {
+		path = "/user/repos"
+	}
+	var created gitHubRepository
+	in := gitHubRepository{Name: repo.Name, Private: true, AutoInit: true}
+	if _, err := g.do(ctx, http.MethodPost, path, in, &created, http.StatusCreated); err != nil {
+		return false, errors.Wrapf(err, "cannot create repository %s", repo)
+	}
+	logger.Info("created repository %s", repo)
+
+	// The repository can't be created with a given default branch, so the branch is created from it
+	if branch == "" || branch == created.DefaultBranch {
+		return true, nil
+	}
+	var defaultRef gitHubRef
+	refPath := "/repos/" + repo.String() + "/git/ref/heads/" + created.DefaultBranch
+	if _, err := g.do(ctx, http.MethodGet, refPath, nil, &defaultRef, http.StatusOK); err != nil {
+		return true, errors.Wrapf(err, "cannot get branch %s of repository %s", created.DefaultBranch, repo)
+	}
+	newRef := gitHubNewRef{Ref: "refs/heads/" + branch, SHA: defaultRef.Object.SHA}
+	if _, err := g.do(ctx, http.MethodPost, "/repos/"+repo.String()+"/git/refs", newRef, nil, http.StatusCreated); err != nil {
+		return true, errors.Wrapf(err, "cannot create branch %s of repository %s", branch, repo)
+	}
+	return true, nil
+}
+
+// AddDeployKey adds the key to the repository's deploy keys
+func (g *GitHub) AddDeployKey(ctx context.Context, repo Repository, key DeployKey) error {
+	keysPath := "/repos/" + repo.String() + "/keys"
+	for page := 1; ; page++ {
+		var keys []gitHubKey
+		if _, err := g.do(ctx, http.MethodGet, pagePath(keysPath, page), nil, &keys, http.StatusOK); err != nil {
+			return errors.Wrapf(err, "cannot list the deploy keys of repository %s", repo)
+		}
+		for _, existing := range keys {
+			if sameKey(existing.Key, key.Key) {
+				logger.Info("the key is already a deploy key of repository %s", repo)
+				return nil
+			}
+		}
+		if len(keys) < perPage {
+			break
+		}
+	}
+
+	in := gitHubKey{Title: key.Title, Key: strings.TrimSpace(key.Key), ReadOnly: key.ReadOnly}
+	if _, err := g.do(ctx, http.MethodPost, keysPath, in, nil, http.StatusCreated); err != nil {
+		return errors.Wrapf(err, "cannot add deploy key to repository %s", repo)
+	}
+	logger.Info("added deploy key %q to repository %s", key.Title, repo)
+	return nil
+}
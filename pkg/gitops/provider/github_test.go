@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeGitHub is an in-memory stand-in for the parts of GitHub's API used by the provider
+type fakeGitHub struct {
+	user          string
+	defaultBranch string
+	repos         map[string]bool
+	branches      map[string][]string
+	keys          map[string][]gitHubKey
+	requests      []string
+}
+
+func newFakeGitHub() *fakeGitHub {
+	return &fakeGitHub{
+		user:          "user",
+		defaultBranch: "main",
+		repos:         map[string]bool{},
+		branches:      map[string][]string{},
+		keys:          map[string][]gitHubKey{},
+	}
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer GinkgoRecover()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	if r.Header.Get("Authorization") != "token secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/user":
+		writeJSON(w, http.StatusOK, map[string]string{"login": f.user})
+	case r.Method == http.MethodPost && (r.URL.Path == "/user/repos" || len(parts) == 3 && parts[0] == "orgs"):
+		owner := f.user
+		if parts[0] == "orgs" {
+			owner = parts[1]
+		}
+		var in gitHubRepository
+		Expect(json.NewDecoder(r.Body).Decode(&in)).To(Succeed())
+		Expect(in.Private).To(BeTrue())
+		Expect(in.AutoInit).To(BeTrue())
+		name := owner + "/" + in.Name
+		f.repos[name] = true
+		f.branches[name] = []string{f.defaultBranch}
+		writeJSON(w, http.StatusCreated, gitHubRepository{Name: in.Name, DefaultBranch: f.defaultBranch})
+	case len(parts) < 3 || parts[0] != "repos" || !f.repos[parts[1]+"/"+parts[2]]:
+		http.NotFound(w, r)
+	default:
+		name := parts[1] + "/" + parts[2]
+		rest := strings.Join(parts[3:], "/")
+		switch {
+		case r.Method == http.MethodGet && rest == "":
+			writeJSON(w, http.StatusOK, gitHubRepository{Name: parts[2], DefaultBranch: f.defaultBranch})
+		case r.Method == http.MethodGet && rest == "git/ref/heads/"+f.defaultBranch:
+			ref := gitHubRef{Ref: "refs/heads/" + f.defaultBranch}
+			ref.Object.SHA = "abc123"
+			writeJSON(w, http.StatusOK, ref)
+		case r.Method == http.MethodPost && rest == "git/refs":
+			var in gitHubNewRef
+			Expect(json.NewDecoder(r.Body).Decode(&in)).To(Succeed())
+			Expect(in.SHA).To(Equal("abc123"))
+			f.branches[name] = append(f.branches[name], strings.TrimPrefix(in.Ref, "refs/heads/"))
+			writeJSON(w, http.StatusCreated, in)
+		case r.Method == http.MethodGet && rest == "keys":
+			writeJSON(w, http.StatusOK, page(r, f.keys[name]))
+		case r.Method == http.MethodPost && rest == "keys":
+			var in gitHubKey
+			Expect(json.NewDecoder(r.Body).Decode(&in)).To(Succeed())
+			f.keys[name] = append(f.keys[name], gitHubKey{Title: in.Title, Key: in.Key, ReadOnly: in.ReadOnly})
+			writeJSON(w, http.StatusCreated, in)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// page returns the page of keys requested with the per_page and page parameters
+func page(r *http.Request, keys []gitHubKey) []gitHubKey {
+	size, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	Expect(err).ToNot(HaveOccurred())
+	number, err := strconv.Atoi(r.URL.Query().Get("page"))
+	Expect(err).ToNot(HaveOccurred())
+	start := (number - 1) * size
+	if start >= len(keys) {
+		return []gitHubKey{}
+	}
+	end := start + size
+	if end > len(keys) {
+		end = len(keys)
+	}
+	return keys[start:end]
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+var _ = Describe("GitHub", func() {
+	var (
+		fake     *fakeGitHub
+		server   *httptest.Server
+		provider *GitHub
+		ctx      = context.Background()
+	)
+
+	BeforeEach(func() {
+		fake = newFakeGitHub()
+		server = httptest.NewServer(fake)
+		provider = NewGitHub(server.URL, "secret")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("does not create existing repositories", func() {
+		fake.repos["org/repo"] = true
+
+		created, err := provider.CreateRepository(ctx, Repository{Owner: "org", Name: "repo"}, "master")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created).To(BeFalse())
+		Expect(fake.requests).To(Equal([]string{"GET /repos/org/repo"}))
+	})
+
+	It("creates repositories in organizations, with the branch", func() {
+		created, err := provider.CreateRepository(ctx, Repository{Owner: "org", Name: "repo"}, "master")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created).To(BeTrue())
+		Expect(fake.repos).To(HaveKey("org/repo"))
+		Expect(fake.branches["org/repo"]).To(ConsistOf("main", "master"))
+	})
+
+	It("creates repositories of the authenticated user", func() {
+		created, err := provider.CreateRepository(ctx, Repository{Owner: "user", Name: "repo"}, "main")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created).To(BeTrue())
+		Expect(fake.requests).To(ContainElement("POST /user/repos"))
+		Expect(fake.branches["user/repo"]).To(ConsistOf("main"))
+	})
+
+	It("adds deploy keys once", func() {
+		fake.repos["org/repo"] = true
+		key := DeployKey{Title: "flux", Key: "ssh-rsa AAAA flux@cluster\n"}
+
+		Expect(provider.AddDeployKey(ctx, Repository{Owner: "org", Name: "repo"}, key)).To(Succeed())
+		Expect(provider.AddDeployKey(ctx, Repository{Owner: "org", Name: "repo"}, key)).To(Succeed())
+		Expect(fake.keys["org/repo"]).To(Equal([]gitHubKey{{Title: "flux", Key: "ssh-rsa AAAA flux@cluster", ReadOnly: false}}))
+	})
+
+	It("finds existing deploy keys past the first page", func() {
+		fake.repos["org/repo"] = true
+		for i := 0; i < perPage; i++ {
+			fake.keys["org/repo"] = append(fake.keys["org/repo"], gitHubKey{Key: "ssh-rsa OTHER" + strconv.Itoa(i)})
+		}
+		fake.keys["org/repo"] = append(fake.keys["org/repo"], gitHubKey{Key: "ssh-rsa AAAA"})
+
+		key := DeployKey{Title: "flux", Key: "ssh-rsa AAAA flux@cluster\n"}
+		Expect(provider.AddDeployKey(ctx, Repository{Owner: "org", Name: "repo"}, key)).To(Succeed())
+		Expect(fake.keys["org/repo"]).To(HaveLen(perPage + 1))
+		Expect(fake.requests).To(Equal([]string{"GET /repos/org/repo/keys", "GET /repos/org/repo/keys"}))
+	})
+
+	It("reports API errors", func() {
+		provider = NewGitHub(server.URL, "wrong")
+		_, err := provider.CreateRepository(ctx, Repository{Owner: "org", Name: "repo"}, "master")
+		Expect(err).To(MatchError(ContainSubstring("401")))
+
+		provider = NewGitHub(server.URL, "secret")
+		err = provider.AddDeployKey(ctx, Repository{Owner: "org", Name: "missing"}, DeployKey{Key: "ssh-rsa AAAA"})
+		Expect(err).To(MatchError(ContainSubstring("404")))
+	})
+})
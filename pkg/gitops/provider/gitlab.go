@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+)
+
+// GitLabAPIURL is the base URL of GitLab's API
+const GitLabAPIURL = "https://gitlab.com/api/v4"
+
+// GitLab manages projects with GitLab's REST API (v4)
+type GitLab struct {
+	client
+}
+
+// NewGitLab creates a GitLab provider authenticated with a personal access token,
+// baseURL defaults to GitLabAPIURL
+func NewGitLab(baseURL, token string) *GitLab {
+	if baseURL == "" {
+		baseURL = GitLabAPIURL
+	}
+	return &GitLab{client{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: requestTimeout},
+		authorize: func(req *http.Request) {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		},
+	}}
+}
+
+type gitLabProject struct {
+	Name                 string `json:"name"`
+	Path                 string `json:"path"`
+	NamespaceID          int    `json:"namespace_id"`
+	Visibility           string `json:"visibility"`
+	InitializeWithReadme bool   `json:"initialize_with_readme"`
+	DefaultBranch        string `json:"default_branch,omitempty"`
+}
+
+type gitLabKey struct {
+	Title   string `json:"title,omitempty"`
+	Key     string `json:"key"`
+	CanPush bool   `json:"can_push"`
+}
+
+// projectPath is the path of a project in the API, identified by its URL-encoded full path
+func projectPath(repo Repository) string {
+	return "/projects/" + url.PathEscape(repo.String())
+}
+
+// CreateRepository creates the project in the user's or group's namespace; it is
+// initialized with a README, as an empty repository cannot be cloned
+func (g *GitLab) CreateRepository(ctx context.Context, repo Repository, branch string) (bool, error) {
+	status, err := g.do(ctx, http.MethodGet, projectPath(repo), nil, nil, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return false, err
+	}
+	if status == http.StatusOK {
+		return false, nil
+	}
+
+	var namespace struct {
+		ID int `json:"id"`
+	}
+	if _, err := g.do(ctx, http.MethodGet, "/namespaces/"+url.PathEscape(repo.Owner), nil, &namespace, http.StatusOK); err != nil {
+		return false, errors.Wrapf(err, "cannot get namespace %s", repo.Owner)
+	}
+	in := gitLabProject{
+		Name:                 repo.Name,
+		Path:                 repo.Name,
+		NamespaceID:          namespace.ID,
+		Visibility:           "private",
+		InitializeWithReadme: true,
+		DefaultBranch:        branch,
+	}
+	if _, err := g.do(ctx, http.MethodPost, "/projects", in, nil, http.StatusCreated); err != nil {
+		return false, errors.Wrapf(err, "cannot create repository %s", repo)
+	}
+	logger.Info("created repository %s", repo)
+	return true, nil
+}
+
+// AddDeployKey adds the key to the project's deploy keys
+func (g *GitLab) AddDeployKey(ctx context.Context, repo Repository, key DeployKey) error {
+	keysPath := projectPath(repo) + "/deploy_keys"
+	for page := 1; ; page++ {
+		var keys []gitLabKey
+		if _, err := g.do(ctx, http.MethodGet, pagePath(keysPath, page), nil, &keys, http.StatusOK); err != nil {
+			return errors.Wrapf(err, "cannot list the deploy keys of repository %s", repo)
+		}
+		for _, existing := range keys {
+			if sameKey(existing.Key, key.Key) {
+				logger.Info("the key is already a deploy key of repository %s", repo)
+				return nil
+			}
+		}
+		if len(keys) < perPage {
+			break
+		}
+	}
+
+	in := gitLabKey{Title: key.Title, Key: strings.TrimSpace(key.Key), CanPush: !key.ReadOnly}
+	if _, err := g.do(ctx, http.MethodPost, keysPath, in, nil, http.StatusCreated); err != nil {
+		return errors.Wrapf(err, "cannot add deploy key to repository %s", repo)
+	}
+	logger.Info("added deploy key %q to repository %s", key.Title, repo)
+	return nil
+}
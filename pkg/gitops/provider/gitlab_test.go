@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeGitLab is an in-memory stand-in for the parts of GitLab's API used by the provider
+type fakeGitLab struct {
+	namespaces map[string]int
+	projects   map[string]gitLabProject
+	keys       map[string][]gitLabKey
+}
+
+func newFakeGitLab() *fakeGitLab {
+	return &fakeGitLab{
+		namespaces: map[string]int{"group": 1, "group/subgroup": 2},
+		projects:   map[string]gitLabProject{},
+		keys:       map[string][]gitLabKey{},
+	}
+}
+
+func (f *fakeGitLab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer GinkgoRecover()
+	if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	// Projects and namespaces are identified by their URL-encoded full path
+	parts := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	unescape := func(s string) string {
+		unescaped, err := url.PathUnescape(s)
+		Expect(err).ToNot(HaveOccurred())
+		return unescaped
+	}
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "namespaces":
+		id, ok := f.namespaces[unescape(parts[1])]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]int{"id": id})
+	case r.Method == http.MethodPost && r.URL.Path == "/projects":
+		var in gitLabProject
+		Expect(json.NewDecoder(r.Body).Decode(&in)).To(Succeed())
+		for namespace, id := range f.namespaces {
+			if id == in.NamespaceID {
+				f.projects[namespace+"/"+in.Path] = in
+			}
+		}
+		writeJSON(w, http.StatusCreated, in)
+	case len(parts) >= 2 && parts[0] == "projects":
+		name := unescape(parts[1])
+		if _, ok := f.projects[name]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		rest := strings.Join(parts[2:], "/")
+		switch {
+		case r.Method == http.MethodGet && rest == "":
+			writeJSON(w, http.StatusOK, f.projects[name])
+		case r.Method == http.MethodGet && rest == "deploy_keys":
+			writeJSON(w, http.StatusOK, f.keys[name])
+		case r.Method == http.MethodPost && rest == "deploy_keys":
+			var in gitLabKey
+			Expect(json.NewDecoder(r.Body).Decode(&in)).To(Succeed())
+			f.keys[name] = append(f.keys[name], in)
+			writeJSON(w, http.StatusCreated, in)
+		default:
+			http.NotFound(w, r)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+var _ = Describe("GitLab", func() {
+	var (
+		fake     *fakeGitLab
+		server   *httptest.Server
+		provider *GitLab
+		ctx      = context.Background()
+	)
+
+	BeforeEach(func() {
+		fake = newFakeGitLab()
+		server = httptest.NewServer(fake)
+		provider = NewGitLab(server.URL, "secret")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("does not create existing projects", func() {
+		fake.projects["group/subgroup/repo"] = gitLabProject{Name: "repo"}
+
+		created, err := provider.CreateRepository(ctx, Repository{Owner: "group/subgroup", Name: "repo"}, "master")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created).To(BeFalse())
+	})
+
+	It("creates private projects in the namespace, with the branch", func() {
+		created, err := provider.CreateRepository(ctx, Repository{Owner: "group/subgroup", Name: "repo"}, "master")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created).To(BeTrue())
+		Expect(fake.projects).To(HaveKeyWithValue("group/subgroup/repo", gitLabProject{
+			Name:                 "repo",
+			Path:                 "repo",
+			NamespaceID:          2,
+			Visibility:           "private",
+			InitializeWithReadme: true,
+			DefaultBranch:        "master",
+		}))
+	})
+
+	It("fails to create projects in unknown namespaces", func() {
+		_, err := provider.CreateRepository(ctx, Repository{Owner: "other", Name: "repo"}, "master")
+		Expect(err).To(MatchError(ContainSubstring("cannot get namespace other")))
+	})
+
+	It("adds deploy keys with write access once", func() {
+		fake.projects["group/repo"] = gitLabProject{Name: "repo"}
+		key := DeployKey{Title: "flux", Key: "ssh-rsa AAAA flux@cluster"}
+
+		Expect(provider.AddDeployKey(ctx, Repository{Owner: "group", Name: "repo"}, key)).To(Succeed())
+		Expect(provider.AddDeployKey(ctx, Repository{Owner: "group", Name: "repo"}, key)).To(Succeed())
+		Expect(fake.keys["group/repo"]).To(Equal([]gitLabKey{{Title: "flux", Key: "ssh-rsa AAAA flux@cluster", CanPush: true}}))
+	})
+})
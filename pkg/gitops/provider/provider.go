@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	giturls "github.com/whilp/git-urls"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+)
+
+// Environment variables holding the tokens used to authenticate with the providers
+const (
+	GitHubTokenEnvVar = "GITHUB_TOKEN"
+	GitLabTokenEnvVar = "GITLAB_TOKEN"
+)
+
+// Provider is a Git hosting service which manages repositories and their deploy keys
+type Provider interface {
+	// CreateRepository creates the repository, as a private one with the given branch,
+	// unless it already exists; it returns whether the repository was created
+	CreateRepository(ctx context.Context, repo Repository, branch string) (bool, error)
+	// AddDeployKey adds the key to the repository, unless the repository already has it
+	AddDeployKey(ctx context.Context, repo Repository, key DeployKey) error
+}
+
+// Repository identifies a repository of a provider
+type Repository struct {
+	// Owner is the user or organization (or GitLab group, including its subgroups) owning the repository
+	Owner string
+	Name  string
+}
+
+// DeployKey is an SSH public key granting access to a single repository
+type DeployKey struct {
+	Title string
+	// Key is in the authorized_keys format
+	Key      string
+	ReadOnly bool
+}
+
+// New creates the provider of the given kind, either api.GitProviderGitHub or
+// api.GitProviderGitLab, using its public API unless baseURL is set
+func New(kind, baseURL, token string) (Provider, error) {
+	if kind != api.GitProviderGitHub && kind != api.GitProviderGitLab {
+		return nil, fmt.Errorf("unsupported Git provider %q, must be either %q or %q",
+			kind, api.GitProviderGitHub, api.GitProviderGitLab)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("a token is required to use the %s API", kind)
+	}
+	if kind == api.GitProviderGitLab {
+		return NewGitLab(baseURL, token), nil
+	}
+	return NewGitHub(baseURL, token), nil
+}
+
+// NewFromEnv creates the provider of the given kind, with the token read from
+// the provider's environment variable
+func NewFromEnv(kind, baseURL string) (Provider, error) {
+	envVar := GitHubTokenEnvVar
+	if kind == api.GitProviderGitLab {
+		envVar = GitLabTokenEnvVar
+	}
+	token := os.Getenv(envVar)
+	if token == "" {
+		return nil, fmt.Errorf("$%s must be set to use the %s API", envVar, kind)
+	}
+	return New(kind, baseURL, token)
+}
+
+// RepositoryFromURL returns the repository of a Git URL, e.g. git@github.com:org/repo.git
+func RepositoryFromURL(gitURL string) (Repository, error) {
+	u, err := giturls.Parse(gitURL)
+	if err != nil {
+		return Repository{}, errors.Wrapf(err, "unable to parse git URL '%s'", gitURL)
+	}
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	i := strings.LastIndex(path, "/")
+	if i <= 0 || i == len(path)-1 {
+		return Repository{}, fmt.Errorf("could not find the owner and name of repository %s", gitURL)
+	}
+	return Repository{Owner: path[:i], Name: path[i+1:]}, nil
+}
+
+func (r Repository) String() string {
+	return r.Owner + "/" + r.Name
+}
+
+// sameKey compares the type and the data of authorized_keys lines, as providers drop the comments
+func sameKey(a, b string) bool {
+	fieldsA, fieldsB := strings.Fields(a), strings.Fields(b)
+	if len(fieldsA) < 2 || len(fieldsB) < 2 {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+	return fieldsA[0] == fieldsB[0] && fieldsA[1] == fieldsB[1]
+}
+
+// perPage is the number of items requested per page when listing, the maximum of both providers
+const perPage = 100
+
+// pagePath returns the path of a page of a list, pages start at 1
+func pagePath(path string, page int) string {
+	return fmt.Sprintf("%s?per_page=%d&page=%d", path, perPage, page)
+}
+
+// requestTimeout bounds each request to the API of a provider
+var requestTimeout = 30 * time.Second
+
+// client calls the JSON API of a provider
+type client struct {
+	baseURL    string
+	httpClient *http.Client
+	authorize  func(req *http.Request)
+}
+
+// do sends a request to the API, encoding in as JSON when it isn't nil, and decodes the response
+// into out when it isn't nil; the status of the response must be one of the expected ones
+func (c *client) do(ctx context.Context, method, path string, in, out interface{}, expected ...int) (int, error) {
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return 0, errors.Wrap(err, "cannot encode request")
+		}
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.baseURL, "/")+path, &body)
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot create request %s %s", method, path)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authorize(req)
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s %s", method, path)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, errors.Wrapf(err, "reading response of %s %s", method, path)
+	}
+
+	for _, status := range expected {
+		if resp.StatusCode != status {
+			continue
+		}
+		if out != nil && resp.StatusCode < http.StatusMultipleChoices {
+			if err := json.Unmarshal(data, out); err != nil {
+				return resp.StatusCode, errors.Wrapf(err, "cannot decode response of %s %s", method, path)
+			}
+		}
+		return resp.StatusCode, nil
+	}
+	err = fmt.Errorf("%s %s: unexpected status %q", method, path, resp.Status)
+	if message := strings.TrimSpace(string(data)); message != "" {
+		err = errors.Wrap(err, message)
+	}
+	return resp.StatusCode, err
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Git providers", func() {
+	DescribeTable("finds the repository of a Git URL",
+		func(gitURL string, expected Repository) {
+			repo, err := RepositoryFromURL(gitURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repo).To(Equal(expected))
+		},
+		Entry("scp-like URL", "git@github.com:org/repo.git", Repository{Owner: "org", Name: "repo"}),
+		Entry("SSH URL", "ssh://git@github.com/org/repo.git", Repository{Owner: "org", Name: "repo"}),
+		Entry("URL without .git", "git@github.com:org/repo", Repository{Owner: "org", Name: "repo"}),
+		Entry("GitLab subgroup", "git@gitlab.com:group/subgroup/repo.git", Repository{Owner: "group/subgroup", Name: "repo"}),
+	)
+
+	It("rejects URLs without an owner", func() {
+		_, err := RepositoryFromURL("git@github.com:repo.git")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("creates the providers", func() {
+		p, err := New("github", "", "token")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p).To(BeAssignableToTypeOf(&GitHub{}))
+		Expect(p.(*GitHub).baseURL).To(Equal(GitHubAPIURL))
+
+		p, err = New("gitlab", "https://gitlab.example.com/api/v4", "token")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p).To(BeAssignableToTypeOf(&GitLab{}))
+		Expect(p.(*GitLab).baseURL).To(Equal("https://gitlab.example.com/api/v4"))
+
+		_, err = New("bitbucket", "", "token")
+		Expect(err).To(MatchError(`unsupported Git provider "bitbucket", must be either "github" or "gitlab"`))
+
+		_, err = New("github", "", "")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("reads the token from the environment", func() {
+		defer os.Setenv(GitLabTokenEnvVar, os.Getenv(GitLabTokenEnvVar))
+
+		os.Setenv(GitLabTokenEnvVar, "")
+		_, err := NewFromEnv("gitlab", "")
+		Expect(err).To(MatchError("$GITLAB_TOKEN must be set to use the gitlab API"))
+
+		os.Setenv(GitLabTokenEnvVar, "token")
+		_, err = NewFromEnv("gitlab", "")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("compares keys without their comments", func() {
+		Expect(sameKey("ssh-rsa AAAA flux@cluster\n", "ssh-rsa AAAA")).To(BeTrue())
+		Expect(sameKey("ssh-rsa AAAA", "ssh-rsa BBBB")).To(BeFalse())
+		Expect(sameKey("ssh-rsa AAAA", "ecdsa-sha2-nistp256 AAAA")).To(BeFalse())
+	})
+})
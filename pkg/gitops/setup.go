@@ -0,0 +1,128 @@
+package gitops
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	kubeclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/git"
+	"github.com/weaveworks/eksctl/pkg/gitops/catalog"
+	"github.com/weaveworks/eksctl/pkg/gitops/fileprocessor"
+	"github.com/weaveworks/eksctl/pkg/gitops/flux"
+)
+
+// NewApplier creates an Applier which sets up GitOps as declared in the git
+// section of clusterConfig (which must have been defaulted and validated, and
+// refreshed as the profiles are templated with it); the user's repository is
+// cloned into outputPath to add the profiles, which are resolved with profilesCatalog
+func NewApplier(ctx context.Context, k8sRestConfig *rest.Config, k8sClientSet kubeclient.Interface,
+	clusterConfig *api.ClusterConfig, profilesCatalog *catalog.Catalog, outputPath string) (*Applier, error) {
+	repo := clusterConfig.Git.Repo
+
+	fluxOpts := flux.NewInstallOpts(clusterConfig.Git, git.DefaultGitTimeout)
+	fluxInstaller := flux.NewInstaller(ctx, k8sRestConfig, k8sClientSet, fluxOpts)
+
+	usersRepoName, err := git.RepoName(repo.URL)
+	if err != nil {
+		return nil, err
+	}
+	usersRepoDir := filepath.Join(outputPath, usersRepoName)
+
+	profiles, err := newProfiles(ctx, clusterConfig, profilesCatalog, usersRepoDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Applier{
+		UserRepoPath:  usersRepoDir,
+		UsersRepoOpts: usersRepoOpts(repo),
+		GitClient:     newUsersRepoGitClient(ctx, repo, usersRepoDir),
+		Profiles:      profiles,
+		FluxInstaller: fluxInstaller,
+		ClusterConfig: clusterConfig,
+	}, nil
+}
+
+// NewProfileUpdater creates a ProfileUpdater which updates the profiles declared in the git
+// section of clusterConfig to the declared versions; the user's repository is cloned into outputPath
+func NewProfileUpdater(ctx context.Context, clusterConfig *api.ClusterConfig, profilesCatalog *catalog.Catalog,
+	outputPath string) (*ProfileUpdater, error) {
+	repo := clusterConfig.Git.Repo
+
+	usersRepoName, err := git.RepoName(repo.URL)
+	if err != nil {
+		return nil, err
+	}
+	usersRepoDir := filepath.Join(outputPath, usersRepoName)
+
+	profiles, err := newProfiles(ctx, clusterConfig, profilesCatalog, usersRepoDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProfileUpdater{
+		UserRepoPath:  usersRepoDir,
+		UsersRepoOpts: usersRepoOpts(repo),
+		GitClient:     newUsersRepoGitClient(ctx, repo, usersRepoDir),
+		Profiles:      profiles,
+	}, nil
+}
+
+// newProfiles creates the profiles, which output the processed templates into their own directory in the user's repo
+func newProfiles(ctx context.Context, clusterConfig *api.ClusterConfig, profilesCatalog *catalog.Catalog,
+	usersRepoDir string) ([]*Profile, error) {
+	var profiles []*Profile
+	for _, p := range clusterConfig.Git.Profiles {
+		ref, err := profilesCatalog.Resolve(p.Source, p.Revision)
+		if err != nil {
+			return nil, err
+		}
+		values, err := fileprocessor.ReadValuesFile(p.ValuesFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := ref.CheckParameters(values); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, &Profile{
+			Name: p.Source,
+			Processor: &fileprocessor.GoTemplateProcessor{
+				Params: fileprocessor.NewTemplateParameters(clusterConfig, values),
+			},
+			Path: filepath.Join(usersRepoDir, p.OutputPath),
+			GitOpts: git.Options{
+				URL:    p.Source,
+				Branch: p.Revision,
+			},
+			Catalog: profilesCatalog,
+			GitCloner: git.NewGitClient(ctx, git.ClientParams{
+				Timeout: git.DefaultGitTimeout,
+			}),
+			FS: afero.NewOsFs(),
+			IO: afero.Afero{Fs: afero.NewOsFs()},
+		})
+	}
+	return profiles, nil
+}
+
+func usersRepoOpts(repo *api.Repo) git.Options {
+	return git.Options{
+		URL:    repo.URL,
+		Branch: repo.Branch,
+		User:   repo.User,
+		Email:  repo.Email,
+	}
+}
+
+// newUsersRepoGitClient creates a git client that operates in the user's repo
+func newUsersRepoGitClient(ctx context.Context, repo *api.Repo, usersRepoDir string) *git.Client {
+	return git.NewGitClient(ctx, git.ClientParams{
+		PrivateSSHKeyPath: repo.PrivateSSHKeyPath,
+		Timeout:           git.DefaultGitTimeout,
+		Dir:               usersRepoDir,
+	})
+}
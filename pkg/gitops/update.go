@@ -0,0 +1,203 @@
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kris-nova/logger"
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/eksctl/pkg/git"
+	"github.com/weaveworks/eksctl/pkg/gitops/catalog"
+	"github.com/weaveworks/eksctl/pkg/gitops/fileprocessor"
+)
+
+// Update generates the profile again, from GitOpts or, when GitOpts.URL is empty, from the source recorded
+// in its metadata file, and three-way merges the new manifests into the files in Path, using the manifests
+// generated from the recorded commit with the recorded values as the base. Local edits are kept, and the
+// paths of the files which couldn't be merged are returned; these files are left with conflict markers, or
+// unchanged when one side deleted them. Only the values are recorded, so changes to the cluster since the
+// profile was generated show up as upstream changes of the manifests
+func (p *Profile) Update(ctx context.Context) ([]string, error) {
+	metadata, err := p.readMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	source := p.GitOpts.URL
+	if source == "" {
+		source = metadata.Source
+		if _, version := catalog.SplitReference(source); version == "" {
+			p.GitOpts.Branch = metadata.Revision
+		}
+		p.GitOpts.URL = source
+	}
+	if p.Catalog != nil {
+		ref, err := p.Catalog.Resolve(p.GitOpts.URL, p.GitOpts.Branch)
+		if err != nil {
+			return nil, err
+		}
+		p.GitOpts.URL, p.GitOpts.Branch = ref.URL, ref.Revision
+	}
+
+	values, clusterConfigHash, err := p.templateInputs()
+	if err != nil {
+		return nil, err
+	}
+	if metadata.ClusterConfigHash != "" && metadata.ClusterConfigHash != clusterConfigHash {
+		logger.Warning("the cluster has changed since the profile was generated, manifests depending on it will be merged as upstream changes")
+	}
+
+	logger.Info("generating the base manifests from %q at commit %s", metadata.URL, metadata.Commit)
+	base, _, err := p.render(p.baseProcessor(metadata), metadata.URL, metadata.Revision, metadata.Commit)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("generating the new manifests from %q:%s", p.GitOpts.URL, p.GitOpts.Branch)
+	other, commit, err := p.render(p.Processor, p.GitOpts.URL, p.GitOpts.Branch, "")
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts, err := p.mergeFiles(base, other, metadata.Commit, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.writeMetadata(Metadata{
+		Source:            source,
+		URL:               p.GitOpts.URL,
+		Revision:          p.GitOpts.Branch,
+		Commit:            commit,
+		Values:            values,
+		ClusterConfigHash: clusterConfigHash,
+	}); err != nil {
+		return nil, err
+	}
+	return conflicts, nil
+}
+
+// render clones the profile's repository at the given revision, or commit when set, and returns
+// the files processed with processor, keyed by their path relative to the root of the repository
+func (p *Profile) render(processor fileprocessor.FileProcessor, url, revision, commit string) (map[string][]byte, string, error) {
+	clonedDir, err := p.GitCloner.CloneRepo(cloneDirPrefix, revision, url)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "error cloning repository %s", url)
+	}
+	defer func() {
+		if err := p.IO.RemoveAll(clonedDir); err != nil {
+			logger.Warning("unable to delete cloned directory %q", clonedDir)
+		}
+	}()
+
+	if commit != "" {
+		if err := p.GitCloner.Checkout(commit); err != nil {
+			return nil, "", errors.Wrapf(err, "error checking out commit %s of repository %s", commit, url)
+		}
+	} else if commit, err = p.GitCloner.HeadCommit(); err != nil {
+		return nil, "", errors.Wrapf(err, "error reading the commit of repository %s", url)
+	}
+
+	allManifests, err := p.loadFiles(clonedDir)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "error loading files from repository %s", url)
+	}
+	outputFiles, err := p.processFiles(processor, allManifests, clonedDir)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "error processing manifests from repository %s", url)
+	}
+
+	files := make(map[string][]byte, len(outputFiles))
+	for _, f := range outputFiles {
+		files[f.Path] = f.Data
+	}
+	return files, commit, nil
+}
+
+func (p *Profile) mergeFiles(base, other map[string][]byte, baseCommit, otherCommit string) ([]string, error) {
+	paths := map[string]bool{}
+	for path := range base {
+		paths[path] = true
+	}
+	for path := range other {
+		paths[path] = true
+	}
+	sortedPaths := make([]string, 0, len(paths))
+	for path := range paths {
+		sortedPaths = append(sortedPaths, path)
+	}
+	sort.Strings(sortedPaths)
+
+	var conflicts []string
+	for _, path := range sortedPaths {
+		filePath := filepath.Join(p.Path, path)
+		baseData, inBase := base[path]
+		otherData, inOther := other[path]
+		current, err := p.IO.ReadFile(filePath)
+		inCurrent := err == nil
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "cannot read file %q", filePath)
+		}
+
+		switch {
+		case inOther && inCurrent && bytes.Equal(current, otherData):
+			// Already up to date
+		case inBase && inOther && bytes.Equal(baseData, otherData):
+			// Unchanged upstream, local edits or deletions are kept
+		case inBase && inCurrent && bytes.Equal(current, baseData):
+			// Unchanged locally, take the new version
+			if err := p.writeOrRemove(filePath, otherData, inOther); err != nil {
+				return nil, err
+			}
+		case !inBase && !inCurrent:
+			// Added upstream
+			if err := p.writeOrRemove(filePath, otherData, true); err != nil {
+				return nil, err
+			}
+		case inBase && !inOther && !inCurrent:
+			// Deleted on both sides
+		case !inOther || !inCurrent:
+			// Deleted on one side and modified on the other, the local file is left as it is
+			logger.Warning("%q was deleted on one side and modified on the other", filePath)
+			conflicts = append(conflicts, path)
+		default:
+			merged, conflict, err := git.MergeFile(current, baseData, otherData, git.MergeLabels{
+				Current: filepath.Join("local", path),
+				Base:    filepath.Join(baseCommit, path),
+				Other:   filepath.Join(otherCommit, path),
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot merge file %q", filePath)
+			}
+			if err := p.writeOrRemove(filePath, merged, true); err != nil {
+				return nil, err
+			}
+			if conflict {
+				conflicts = append(conflicts, path)
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+func (p *Profile) writeOrRemove(filePath string, data []byte, write bool) error {
+	if !write {
+		logger.Debug("removing file %q", filePath)
+		if err := p.FS.Remove(filePath); err != nil {
+			return errors.Wrapf(err, "error removing file %q", filePath)
+		}
+		return nil
+	}
+	if err := p.FS.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return errors.Wrapf(err, "error creating directory for %q", filePath)
+	}
+	logger.Debug("writing file %q", filePath)
+	if err := p.IO.WriteFile(filePath, data, 0644); err != nil {
+		return errors.Wrapf(err, "error writing manifest: %q", filePath)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package journal
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kris-nova/logger"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+)
+
+// OperationKind identifies the command that started an operation
+type OperationKind string
+
+// Kinds of operations that are recorded
+const (
+	CreateCluster    OperationKind = "create-cluster"
+	CreateNodeGroups OperationKind = "create-nodegroup"
+	DeleteCluster    OperationKind = "delete-cluster"
+	DeleteNodeGroups OperationKind = "delete-nodegroup"
+)
+
+// IsResumable returns true for kinds of operations that can be resumed
+func (k OperationKind) IsResumable() bool {
+	return k == CreateCluster || k == CreateNodeGroups
+}
+
+// TaskRecord holds the recorded state of a single task
+type TaskRecord struct {
+	Description string            `json:"description"`
+	State       manager.TaskState `json:"state"`
+	StackName   string            `json:"stackName,omitempty"`
+	StartTime   *time.Time        `json:"startTime,omitempty"`
+	EndTime     *time.Time        `json:"endTime,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// Operation holds the recorded progress of a command, along with the
+// configuration it used, so that it can be resumed
+type Operation struct {
+	ID        string            `json:"id"`
+	Kind      OperationKind     `json:"kind"`
+	Cluster   string            `json:"cluster"`
+	Region    string            `json:"region"`
+	Profile   string            `json:"profile,omitempty"`
+	State     manager.TaskState `json:"state"`
+	StartTime time.Time         `json:"startTime"`
+	EndTime   *time.Time        `json:"endTime,omitempty"`
+
+	// NodeGroups holds names of the nodegroups the operation applies to
+	NodeGroups []string `json:"nodeGroups,omitempty"`
+	// SkipAuthConfigMap is set when nodes shouldn't be added to aws-auth configmap
+	SkipAuthConfigMap bool `json:"skipAuthConfigMap,omitempty"`
+
+	ClusterConfig *api.ClusterConfig `json:"clusterConfig,omitempty"`
+
+	// AdvancedParameter is set once an SSM store keeps the operation in an advanced parameter,
+	// as SSM doesn't allow changing it back to a standard parameter
+	AdvancedParameter bool `json:"advancedParameter,omitempty"`
+
+	Tasks []*TaskRecord `json:"tasks"`
+}
+
+// NewOperation creates a new operation for the given cluster; it records a copy of
+// the config, as the running tasks update the config while the operation is saved
+func NewOperation(kind OperationKind, provider *api.ProviderConfig, cfg *api.ClusterConfig, nodeGroups sets.String) *Operation {
+	now := time.Now().UTC()
+	return &Operation{
+		ID:            fmt.Sprintf("%s-%s-%s", now.Format("20060102T150405Z"), kind, cfg.Metadata.Name),
+		Kind:          kind,
+		Cluster:       cfg.Metadata.Name,
+		Region:        cfg.Metadata.Region,
+		Profile:       provider.Profile,
+		State:         manager.TaskRunning,
+		StartTime:     now,
+		NodeGroups:    nodeGroups.List(),
+		ClusterConfig: cfg.DeepCopy(),
+	}
+}
+
+// IsComplete returns true if all the tasks have completed successfully
+func (op *Operation) IsComplete() bool {
+	return op.State == manager.TaskDone
+}
+
+// CompletedTasks returns descriptions of all tasks that have completed successfully
+func (op *Operation) CompletedTasks() sets.String {
+	completed := sets.NewString()
+	for _, task := range op.Tasks {
+		if task.State == manager.TaskDone {
+			completed.Insert(task.Description)
+		}
+	}
+	return completed
+}
+
+// IncompleteTasks returns all tasks that haven't completed successfully
+func (op *Operation) IncompleteTasks() []*TaskRecord {
+	incomplete := []*TaskRecord{}
+	for _, task := range op.Tasks {
+		if task.State != manager.TaskDone {
+			incomplete = append(incomplete, task)
+		}
+	}
+	return incomplete
+}
+
+func (op *Operation) findTask(description string) *TaskRecord {
+	for _, task := range op.Tasks {
+		if task.Description == description {
+			return task
+		}
+	}
+	return nil
+}
+
+// Journal records progress of an operation to a store, it implements manager.TaskRecorder
+type Journal struct {
+	store Store
+	op    *Operation
+	mutex sync.Mutex
+}
+
+// New creates a journal for the given operation
+func New(store Store, op *Operation) *Journal {
+	return &Journal{
+		store: store,
+		op:    op,
+	}
+}
+
+// Operation returns the recorded operation
+func (j *Journal) Operation() *Operation { return j.op }
+
+// Start marks the operation as running and saves it
+func (j *Journal) Start() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.op.State = manager.TaskRunning
+	j.op.EndTime = nil
+	return j.store.Save(j.op)
+}
+
+// RecordTask updates the state of a task and saves the operation;
+// as a failure to save shouldn't stop any of the running tasks,
+// it's only logged
+func (j *Journal) RecordTask(result *manager.TaskResult) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	task := j.op.findTask(result.Description)
+	if task == nil {
+		task = &TaskRecord{Description: result.Description}
+		j.op.Tasks = append(j.op.Tasks, task)
+	}
+	if result.State == manager.TaskPending && task.State == manager.TaskDone {
+		// task was completed by a previous run
+		return
+	}
+
+	task.State = result.State
+	if result.StackName != "" {
+		task.StackName = result.StackName
+	}
+	if !result.StartTime.IsZero() {
+		startTime := result.StartTime.UTC()
+		task.StartTime = &startTime
+	}
+	if !result.EndTime.IsZero() {
+		endTime := result.EndTime.UTC()
+		task.EndTime = &endTime
+	}
+	task.Error = ""
+	if result.Err != nil {
+		task.Error = result.Err.Error()
+	}
+
+	if err := j.store.Save(j.op); err != nil {
+		logger.Warning("failed to record state of task %q in operation %q: %s", result.Description, j.op.ID, err.Error())
+	}
+}
+
+// Finish sets the final state of the operation based on the state of its tasks and saves it
+func (j *Journal) Finish() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.op.State = manager.TaskDone
+	for _, task := range j.op.Tasks {
+		if task.State == manager.TaskCancelled {
+			j.op.State = manager.TaskCancelled
+		}
+		if task.State != manager.TaskDone && j.op.State == manager.TaskDone {
+			j.op.State = manager.TaskFailed
+		}
+	}
+	endTime := time.Now().UTC()
+	j.op.EndTime = &endTime
+	return j.store.Save(j.op)
+}
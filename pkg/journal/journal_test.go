@@ -0,0 +1,193 @@
+package journal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ssm"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+	"github.com/weaveworks/eksctl/pkg/eks/mocks"
+)
+
+var _ = Describe("operations journal", func() {
+	var (
+		dir   string
+		store Store
+		cfg   *api.ClusterConfig
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "eksctl-journal")
+		Expect(err).NotTo(HaveOccurred())
+
+		store, err = NewStore(dir, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg = api.NewClusterConfig()
+		cfg.Metadata.Name = "test-cluster"
+		cfg.Metadata.Region = "us-west-2"
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should select store based on location", func() {
+		s, err := NewStore("s3://bucket/some/prefix/", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.(*S3Store).bucket).To(Equal("bucket"))
+		Expect(s.(*S3Store).key("op")).To(Equal("some/prefix/op.json"))
+
+		s, err = NewStore("ssm:/eksctl/operations/", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.(*SSMStore).name("op")).To(Equal("/eksctl/operations/op"))
+
+		s, err = NewStore("", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.(*FileStore).dir).To(Equal(DefaultDir()))
+
+		_, err = NewStore("s3://", nil)
+		Expect(err).To(HaveOccurred())
+		_, err = NewStore("ssm:", nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should record a copy of the config", func() {
+		op := NewOperation(CreateCluster, &api.ProviderConfig{}, cfg, sets.NewString())
+		Expect(op.ClusterConfig).To(Equal(cfg))
+
+		cfg.Metadata.Region = "eu-west-1"
+		Expect(op.ClusterConfig.Metadata.Region).To(Equal("us-west-2"))
+	})
+
+	It("should record progress of tasks", func() {
+		op := NewOperation(CreateCluster, &api.ProviderConfig{}, cfg, sets.NewString("ng-1"))
+		j := New(store, op)
+		Expect(j.Start()).To(Succeed())
+
+		startTime := time.Now()
+		j.RecordTask(&manager.TaskResult{Description: "t1", State: manager.TaskPending, StackName: "eksctl-test-cluster-cluster"})
+		j.RecordTask(&manager.TaskResult{Description: "t2", State: manager.TaskPending})
+		j.RecordTask(&manager.TaskResult{Description: "t1", State: manager.TaskRunning, StackName: "eksctl-test-cluster-cluster", StartTime: startTime})
+		j.RecordTask(&manager.TaskResult{Description: "t1", State: manager.TaskDone, StackName: "eksctl-test-cluster-cluster", StartTime: startTime, EndTime: time.Now()})
+		j.RecordTask(&manager.TaskResult{Description: "t2", State: manager.TaskFailed, Err: fmt.Errorf("t2 failed")})
+		Expect(j.Finish()).To(Succeed())
+
+		loaded, err := store.Load(op.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Kind).To(Equal(CreateCluster))
+		Expect(loaded.Cluster).To(Equal("test-cluster"))
+		Expect(loaded.NodeGroups).To(Equal([]string{"ng-1"}))
+		Expect(loaded.ClusterConfig.Metadata.Region).To(Equal("us-west-2"))
+		Expect(loaded.State).To(Equal(manager.TaskFailed))
+		Expect(loaded.EndTime).NotTo(BeNil())
+		Expect(loaded.Tasks).To(HaveLen(2))
+		Expect(loaded.Tasks[0].State).To(Equal(manager.TaskDone))
+		Expect(loaded.Tasks[0].StackName).To(Equal("eksctl-test-cluster-cluster"))
+		Expect(loaded.Tasks[0].StartTime).NotTo(BeNil())
+		Expect(loaded.Tasks[1].Error).To(Equal("t2 failed"))
+		Expect(loaded.CompletedTasks().List()).To(Equal([]string{"t1"}))
+		Expect(loaded.IncompleteTasks()).To(HaveLen(1))
+
+		// resuming keeps the state of completed tasks
+		j = New(store, loaded)
+		Expect(j.Start()).To(Succeed())
+		j.RecordTask(&manager.TaskResult{Description: "t1", State: manager.TaskPending})
+		j.RecordTask(&manager.TaskResult{Description: "t2", State: manager.TaskDone})
+		Expect(j.Finish()).To(Succeed())
+
+		loaded, err = store.Load(op.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.IsComplete()).To(BeTrue())
+		Expect(loaded.Tasks[0].State).To(Equal(manager.TaskDone))
+		Expect(loaded.Tasks[1].Error).To(BeEmpty())
+	})
+
+	It("should list operations, most recent first", func() {
+		older := NewOperation(DeleteNodeGroups, &api.ProviderConfig{}, cfg, sets.NewString())
+		older.ID = "older"
+		older.StartTime = time.Now().Add(-time.Hour)
+		newer := NewOperation(CreateNodeGroups, &api.ProviderConfig{}, cfg, sets.NewString())
+		newer.ID = "newer"
+		Expect(store.Save(older)).To(Succeed())
+		Expect(store.Save(newer)).To(Succeed())
+
+		ops, err := store.List()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ops).To(HaveLen(2))
+		Expect(ops[0].ID).To(Equal("newer"))
+		Expect(ops[1].ID).To(Equal("older"))
+
+		_, err = store.Load("missing")
+		Expect(err).To(MatchError(`operation "missing" not found`))
+	})
+
+	It("should compress SSM parameter values", func() {
+		value, err := compress([]byte(`{"id":"op"}`))
+		Expect(err).NotTo(HaveOccurred())
+		data, err := decompress(value)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"id":"op"}`))
+	})
+
+	It("should use advanced SSM parameters for operations that exceed the standard limit", func() {
+		client := &mocks.SSMAPI{}
+		var (
+			tiers []string
+			saved *string
+		)
+		client.On("PutParameter", mock.Anything).Return(&ssm.PutParameterOutput{}, nil).Run(func(args mock.Arguments) {
+			input := args.Get(0).(*ssm.PutParameterInput)
+			tiers = append(tiers, *input.Tier)
+			saved = input.Value
+		})
+		client.On("GetParameter", mock.Anything).Return(func(input *ssm.GetParameterInput) *ssm.GetParameterOutput {
+			return &ssm.GetParameterOutput{Parameter: &ssm.Parameter{Name: input.Name, Value: saved}}
+		}, nil)
+		ssmStore := NewSSMStore(client, "/eksctl/operations")
+
+		op := NewOperation(CreateCluster, &api.ProviderConfig{}, cfg, sets.NewString())
+		Expect(ssmStore.Save(op)).To(Succeed())
+
+		// random task descriptions don't compress well
+		addRandomTasks(op, 120)
+		Expect(ssmStore.Save(op)).To(Succeed())
+
+		op.Tasks = op.Tasks[:1]
+		Expect(ssmStore.Save(op)).To(Succeed())
+
+		// a store used to resume the operation keeps the advanced parameter
+		resumed, err := NewSSMStore(client, "/eksctl/operations").Load(op.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resumed.AdvancedParameter).To(BeTrue())
+		Expect(NewSSMStore(client, "/eksctl/operations").Save(resumed)).To(Succeed())
+
+		Expect(tiers).To(Equal([]string{ssm.ParameterTierStandard, ssm.ParameterTierAdvanced, ssm.ParameterTierAdvanced, ssm.ParameterTierAdvanced}))
+
+		addRandomTasks(op, 400)
+		err = ssmStore.Save(op)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("use an S3 journal location instead"))
+		Expect(client.AssertNumberOfCalls(GinkgoT(), "PutParameter", 4)).To(BeTrue())
+	})
+})
+
+func addRandomTasks(op *Operation, n int) {
+	for i := 0; i < n; i++ {
+		description := make([]byte, 32)
+		_, err := rand.Read(description)
+		Expect(err).NotTo(HaveOccurred())
+		op.Tasks = append(op.Tasks, &TaskRecord{Description: hex.EncodeToString(description), State: manager.TaskDone})
+	}
+}
@@ -0,0 +1,115 @@
+package journal
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+)
+
+// S3Store keeps each operation in a JSON object in an S3 bucket, so that it can be shared
+// between machines, e.g. CI jobs
+type S3Store struct {
+	client s3iface.S3API
+	bucket string
+	prefix string
+}
+
+// NewS3Store creates a store that uses objects under the given prefix
+func NewS3Store(client s3iface.S3API, bucket, prefix string) *S3Store {
+	return &S3Store{
+		client: client,
+		bucket: bucket,
+		prefix: strings.Trim(prefix, "/"),
+	}
+}
+
+func newS3Client(configProvider client.ConfigProvider) s3iface.S3API {
+	return s3.New(configProvider)
+}
+
+func (s *S3Store) key(id string) string {
+	return path.Join(s.prefix, id+".json")
+}
+
+// Save puts the operation into the bucket
+func (s *S3Store) Save(op *Operation) error {
+	data, err := encode(op)
+	if err != nil {
+		return err
+	}
+	input := &s3.PutObjectInput{
+		Bucket:      &s.bucket,
+		Key:         aws.String(s.key(op.ID)),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	}
+	if _, err := s.client.PutObject(input); err != nil {
+		return errors.Wrapf(err, "saving operation %q to bucket %q", op.ID, s.bucket)
+	}
+	return nil
+}
+
+// Load gets the operation from the bucket
+func (s *S3Store) Load(id string) (*Operation, error) {
+	input := &s3.GetObjectInput{
+		Bucket: &s.bucket,
+		Key:    aws.String(s.key(id)),
+	}
+	output, err := s.client.GetObject(input)
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, errOperationNotFound(id)
+		}
+		return nil, errors.Wrapf(err, "loading operation %q from bucket %q", id, s.bucket)
+	}
+	defer output.Body.Close()
+	data, err := ioutil.ReadAll(output.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading operation %q from bucket %q", id, s.bucket)
+	}
+	return decode(id, data)
+}
+
+// List gets all operations under the prefix
+func (s *S3Store) List() ([]*Operation, error) {
+	prefix := s.prefix
+	if prefix != "" {
+		prefix += "/"
+	}
+	input := &s3.ListObjectsV2Input{
+		Bucket: &s.bucket,
+		Prefix: &prefix,
+	}
+	ids := []string{}
+	err := s.client.ListObjectsV2Pages(input, func(output *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range output.Contents {
+			key := aws.StringValue(object.Key)
+			if strings.HasSuffix(key, ".json") {
+				ids = append(ids, strings.TrimSuffix(path.Base(key), ".json"))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing operations in bucket %q", s.bucket)
+	}
+
+	ops := []*Operation{}
+	for _, id := range ids {
+		op, err := s.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	sortOperations(ops)
+	return ops, nil
+}
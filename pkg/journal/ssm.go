@@ -0,0 +1,168 @@
+package journal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/pkg/errors"
+)
+
+const (
+	standardParameterLimit = 4 * 1024
+	advancedParameterLimit = 8 * 1024
+)
+
+// SSMStore keeps each operation in an SSM parameter under the given path; as the value of
+// a standard parameter is limited to 4KB, operations are stored compressed, and operations
+// that are still too big are stored in advanced parameters, which are limited to 8KB
+type SSMStore struct {
+	client ssmiface.SSMAPI
+	path   string
+}
+
+// NewSSMStore creates a store that uses parameters under the given path
+func NewSSMStore(client ssmiface.SSMAPI, path string) *SSMStore {
+	return &SSMStore{
+		client: client,
+		path:   path,
+	}
+}
+
+func newSSMClient(configProvider client.ConfigProvider) ssmiface.SSMAPI {
+	return ssm.New(configProvider)
+}
+
+func (s *SSMStore) name(id string) string {
+	return path.Join(s.path, id)
+}
+
+// Save puts the operation into a parameter
+func (s *SSMStore) Save(op *Operation) error {
+	value, err := encodeParameter(op)
+	if err != nil {
+		return err
+	}
+	if len(value) > standardParameterLimit && !op.AdvancedParameter {
+		// the tier is recorded in the operation, so that it's still known when the operation is resumed
+		op.AdvancedParameter = true
+		if value, err = encodeParameter(op); err != nil {
+			return err
+		}
+	}
+	if len(value) > advancedParameterLimit {
+		return fmt.Errorf("saving operation %q: compressed size of %d bytes exceeds the limit of SSM parameters (%d bytes), use an S3 journal location instead", op.ID, len(value), advancedParameterLimit)
+	}
+	tier := ssm.ParameterTierStandard
+	if op.AdvancedParameter {
+		tier = ssm.ParameterTierAdvanced
+	}
+	input := &ssm.PutParameterInput{
+		Name:      aws.String(s.name(op.ID)),
+		Type:      aws.String(ssm.ParameterTypeString),
+		Tier:      aws.String(tier),
+		Value:     &value,
+		Overwrite: aws.Bool(true),
+	}
+	if _, err := s.client.PutParameter(input); err != nil {
+		return errors.Wrapf(err, "saving operation %q to SSM parameter %q", op.ID, *input.Name)
+	}
+	return nil
+}
+
+// Load gets the operation from its parameter
+func (s *SSMStore) Load(id string) (*Operation, error) {
+	input := &ssm.GetParameterInput{
+		Name: aws.String(s.name(id)),
+	}
+	output, err := s.client.GetParameter(input)
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ssm.ErrCodeParameterNotFound {
+			return nil, errOperationNotFound(id)
+		}
+		return nil, errors.Wrapf(err, "loading operation %q from SSM parameter %q", id, *input.Name)
+	}
+	return decodeParameter(output.Parameter)
+}
+
+// List gets all operations under the path
+func (s *SSMStore) List() ([]*Operation, error) {
+	input := &ssm.GetParametersByPathInput{
+		Path: &s.path,
+	}
+	ops := []*Operation{}
+	var decodeErr error
+	err := s.client.GetParametersByPathPages(input, func(output *ssm.GetParametersByPathOutput, _ bool) bool {
+		for _, parameter := range output.Parameters {
+			op, err := decodeParameter(parameter)
+			if err != nil {
+				decodeErr = err
+				return false
+			}
+			ops = append(ops, op)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing operations in SSM parameter path %q", s.path)
+	}
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	sortOperations(ops)
+	return ops, nil
+}
+
+func encodeParameter(op *Operation) (string, error) {
+	data, err := encode(op)
+	if err != nil {
+		return "", err
+	}
+	value, err := compress(data)
+	if err != nil {
+		return "", errors.Wrapf(err, "saving operation %q", op.ID)
+	}
+	return value, nil
+}
+
+func decodeParameter(parameter *ssm.Parameter) (*Operation, error) {
+	id := path.Base(aws.StringValue(parameter.Name))
+	data, err := decompress(aws.StringValue(parameter.Value))
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding operation %q", id)
+	}
+	return decode(id, data)
+}
+
+func compress(data []byte) (string, error) {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(data); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func decompress(value string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
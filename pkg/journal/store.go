@@ -0,0 +1,158 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Store persists operations
+type Store interface {
+	// Save creates or replaces the operation
+	Save(*Operation) error
+	// Load returns the operation with the given ID
+	Load(id string) (*Operation, error)
+	// List returns all operations, most recent first
+	List() ([]*Operation, error)
+}
+
+const (
+	s3Scheme   = "s3://"
+	ssmScheme  = "ssm:"
+	fileScheme = "file://"
+)
+
+// DefaultDir is where operations are stored when no location is given
+func DefaultDir() string {
+	return filepath.Join(clientcmd.RecommendedConfigDir, "eksctl", "operations")
+}
+
+// NewStore returns a store for the given location, which is either "s3://<bucket>/<prefix>",
+// "ssm:<parameter path>" or a local directory; the default directory is used when location
+// is empty, configProvider is only required for S3 and SSM
+func NewStore(location string, configProvider client.ConfigProvider) (Store, error) {
+	switch {
+	case strings.HasPrefix(location, s3Scheme):
+		parts := strings.SplitN(strings.TrimPrefix(location, s3Scheme), "/", 2)
+		if parts[0] == "" {
+			return nil, fmt.Errorf("journal location %q doesn't specify an S3 bucket", location)
+		}
+		prefix := ""
+		if len(parts) == 2 {
+			prefix = parts[1]
+		}
+		return NewS3Store(newS3Client(configProvider), parts[0], prefix), nil
+	case strings.HasPrefix(location, ssmScheme):
+		path := "/" + strings.Trim(strings.TrimPrefix(location, ssmScheme), "/")
+		if path == "/" {
+			return nil, fmt.Errorf("journal location %q doesn't specify an SSM parameter path", location)
+		}
+		return NewSSMStore(newSSMClient(configProvider), path), nil
+	case location == "":
+		return NewFileStore(DefaultDir()), nil
+	default:
+		return NewFileStore(strings.TrimPrefix(location, fileScheme)), nil
+	}
+}
+
+// FileStore keeps each operation in a JSON file in a local directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store in the given directory, it will be created on first use
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Save writes the operation to a file, which is replaced atomically
+func (s *FileStore) Save(op *Operation) error {
+	data, err := encode(op)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return errors.Wrapf(err, "creating directory %q", s.dir)
+	}
+	tmpFile, err := ioutil.TempFile(s.dir, op.ID+".tmp")
+	if err != nil {
+		return errors.Wrapf(err, "saving operation %q", op.ID)
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return errors.Wrapf(err, "saving operation %q", op.ID)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return errors.Wrapf(err, "saving operation %q", op.ID)
+	}
+	return errors.Wrapf(os.Rename(tmpFile.Name(), s.path(op.ID)), "saving operation %q", op.ID)
+}
+
+// Load reads the operation from its file
+func (s *FileStore) Load(id string) (*Operation, error) {
+	data, err := ioutil.ReadFile(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errOperationNotFound(id)
+		}
+		return nil, errors.Wrapf(err, "loading operation %q", id)
+	}
+	return decode(id, data)
+}
+
+// List reads all operations in the directory
+func (s *FileStore) List() ([]*Operation, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing operations in %q", s.dir)
+	}
+	ops := []*Operation{}
+	for _, file := range files {
+		op, err := s.Load(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	sortOperations(ops)
+	return ops, nil
+}
+
+func encode(op *Operation) ([]byte, error) {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return nil, errors.Wrapf(err, "encoding operation %q", op.ID)
+	}
+	return data, nil
+}
+
+func decode(id string, data []byte) (*Operation, error) {
+	op := &Operation{}
+	if err := json.Unmarshal(data, op); err != nil {
+		return nil, errors.Wrapf(err, "decoding operation %q", id)
+	}
+	return op, nil
+}
+
+func sortOperations(ops []*Operation) {
+	sort.SliceStable(ops, func(i, j int) bool {
+		return ops[i].StartTime.After(ops[j].StartTime)
+	})
+}
+
+func errOperationNotFound(id string) error {
+	return fmt.Errorf("operation %q not found", id)
+}
@@ -76,7 +76,7 @@ func addFilesAndScripts(config *cloudconfig.CloudConfig, files configFiles, scri
 func makeClientConfigData(spec *api.ClusterConfig, ng *api.NodeGroup) ([]byte, error) {
 	clientConfig, _, _ := kubeconfig.New(spec, "kubelet", configDir+"ca.crt")
 	authenticator := kubeconfig.AWSIAMAuthenticator
-	if ng.AMIFamily == ami.ImageFamilyUbuntu1804 {
+	if bootstrapFamily(spec, ng) == ami.ImageFamilyUbuntu1804 {
 		authenticator = kubeconfig.HeptioAuthenticatorAWS
 	}
 	kubeconfig.AppendAuthenticator(clientConfig, spec, authenticator, "", "")
@@ -165,6 +165,30 @@ func makeMetadata(spec *api.ClusterConfig) []string {
 	}
 }
 
+// makeSSMAgentCommand returns a command that installs and starts SSM agent,
+// it returns an empty string if SSM is not enabled for the nodegroup
+func makeSSMAgentCommand(spec *api.ClusterConfig, ng *api.NodeGroup) string {
+	if ng.SSH == nil || !api.IsEnabled(ng.SSH.EnableSSM) {
+		return ""
+	}
+	switch bootstrapFamily(spec, ng) {
+	case ami.ImageFamilyAmazonLinux2:
+		region := spec.Metadata.Region
+		return fmt.Sprintf("yum install -y https://s3.%s.amazonaws.com/amazon-ssm-%s/latest/linux_amd64/amazon-ssm-agent.rpm && systemctl enable --now amazon-ssm-agent", region, region)
+	case ami.ImageFamilyUbuntu1804:
+		return "snap install amazon-ssm-agent --classic && systemctl enable --now snap.amazon-ssm-agent.amazon-ssm-agent.service"
+	default:
+		return ""
+	}
+}
+
+// MaxPodsPerNode returns the maximum number of pods that nodes of the given instance
+// type can run with the default CNI configuration
+func MaxPodsPerNode(instanceType string) (int, bool) {
+	maxPods, ok := maxPodsPerNodeType[instanceType]
+	return maxPods, ok
+}
+
 func makeMaxPodsMapping() string {
 	var text strings.Builder
 	for k, v := range maxPodsPerNodeType {
@@ -173,9 +197,18 @@ func makeMaxPodsMapping() string {
 	return text.String()
 }
 
+// bootstrapFamily returns the built-in image family whose bootstrapping is used
+// for the nodegroup, which differs from its image family for custom image families
+func bootstrapFamily(spec *api.ClusterConfig, ng *api.NodeGroup) string {
+	if family := spec.FindImageFamily(ng.AMIFamily); family != nil {
+		return family.Bootstrap
+	}
+	return ng.AMIFamily
+}
+
 // NewUserData creates new user data for a given node image family
 func NewUserData(spec *api.ClusterConfig, ng *api.NodeGroup) (string, error) {
-	switch ng.AMIFamily {
+	switch bootstrapFamily(spec, ng) {
 	case ami.ImageFamilyAmazonLinux2:
 		return NewUserDataForAmazonLinux2(spec, ng)
 	case ami.ImageFamilyUbuntu1804:
@@ -53,6 +53,10 @@ func NewUserDataForAmazonLinux2(spec *api.ClusterConfig, ng *api.NodeGroup) (str
 
 	scripts := []string{}
 
+	if command := makeSSMAgentCommand(spec, ng); command != "" {
+		config.AddShellCommand(command)
+	}
+
 	for _, command := range ng.PreBootstrapCommands {
 		config.AddShellCommand(command)
 	}
@@ -7,6 +7,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/weaveworks/eksctl/pkg/ami"
 	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
 	kubeletapi "k8s.io/kubelet/config/v1beta1"
 	"sigs.k8s.io/yaml"
@@ -76,4 +77,42 @@ var _ = Describe("User data", func() {
 			Expect(kubelet.FeatureGates["RotateKubeletServerCertificate"]).To(Equal(false))
 		})
 	})
+
+	Describe("installing SSM agent", func() {
+		var (
+			clusterConfig *api.ClusterConfig
+			ng            *api.NodeGroup
+		)
+		BeforeEach(func() {
+			clusterConfig = api.NewClusterConfig()
+			clusterConfig.Metadata.Region = "eu-north-1"
+			ng = clusterConfig.NewNodeGroup()
+		})
+
+		It("doesn't install the agent when SSM is not enabled", func() {
+			ng.AMIFamily = ami.ImageFamilyAmazonLinux2
+			Expect(makeSSMAgentCommand(clusterConfig, ng)).To(BeEmpty())
+		})
+
+		It("installs the agent from the regional bucket on Amazon Linux 2", func() {
+			ng.AMIFamily = ami.ImageFamilyAmazonLinux2
+			ng.SSH.EnableSSM = api.Enabled()
+			command := makeSSMAgentCommand(clusterConfig, ng)
+			Expect(command).To(ContainSubstring("https://s3.eu-north-1.amazonaws.com/amazon-ssm-eu-north-1/"))
+			Expect(command).To(ContainSubstring("systemctl enable --now amazon-ssm-agent"))
+		})
+
+		It("installs the agent snap on Ubuntu", func() {
+			ng.AMIFamily = ami.ImageFamilyUbuntu1804
+			ng.SSH.EnableSSM = api.Enabled()
+			Expect(makeSSMAgentCommand(clusterConfig, ng)).To(HavePrefix("snap install amazon-ssm-agent"))
+		})
+
+		It("uses the bootstrap family of custom image families", func() {
+			clusterConfig.ImageFamilies = []api.ImageFamily{{Name: "Golden", Bootstrap: ami.ImageFamilyUbuntu1804}}
+			ng.AMIFamily = "Golden"
+			ng.SSH.EnableSSM = api.Enabled()
+			Expect(makeSSMAgentCommand(clusterConfig, ng)).To(HavePrefix("snap install amazon-ssm-agent"))
+		})
+	})
 })
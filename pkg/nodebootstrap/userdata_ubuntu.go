@@ -55,6 +55,10 @@ func NewUserDataForUbuntu1804(spec *api.ClusterConfig, ng *api.NodeGroup) (strin
 
 	scripts := []string{}
 
+	if command := makeSSMAgentCommand(spec, ng); command != "" {
+		config.AddShellCommand(command)
+	}
+
 	for _, command := range ng.PreBootstrapCommands {
 		config.AddShellCommand(command)
 	}
@@ -0,0 +1,103 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/pkg/errors"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+)
+
+// iamPermissions checks that the caller is allowed to perform the given actions, which
+// are needed by CloudFormation unless a service role is used for it
+type iamPermissions struct {
+	actions []string
+}
+
+func (*iamPermissions) Name() string {
+	return "IAM permissions"
+}
+
+func (c *iamPermissions) Run(provider api.ClusterProvider, spec *api.ClusterConfig) (Status, string) {
+	if provider.CloudFormationRoleARN() != "" {
+		return StatusPass, "using CloudFormation service role"
+	}
+
+	output, err := provider.STS().GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return warnUnableToCheck(errors.Wrap(err, "getting caller identity"))
+	}
+	principal := policySourceARN(aws.StringValue(output.Arn))
+
+	denied := []string{}
+	input := &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principal),
+		ActionNames:     aws.StringSlice(c.actions),
+	}
+	err = provider.IAM().SimulatePrincipalPolicyPages(input, func(output *iam.SimulatePolicyResponse, _ bool) bool {
+		for _, result := range output.EvaluationResults {
+			if aws.StringValue(result.EvalDecision) != iam.PolicyEvaluationDecisionTypeAllowed {
+				denied = append(denied, aws.StringValue(result.EvalActionName))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return warnUnableToCheck(errors.Wrapf(err, "simulating policies of %q", principal))
+	}
+	if len(denied) > 0 {
+		return StatusFail, fmt.Sprintf("%q is not allowed to %s", principal, strings.Join(denied, ", "))
+	}
+	return StatusPass, ""
+}
+
+// policySourceARN turns the ARN of an assumed role session into the ARN of the role,
+// as policies can't be simulated for sessions; roles with a path can't be resolved
+// this way, in which case the simulation fails and the check results in a warning
+func policySourceARN(callerARN string) string {
+	parts := strings.Split(callerARN, ":")
+	if len(parts) != 6 || parts[2] != "sts" || !strings.HasPrefix(parts[5], "assumed-role/") {
+		return callerARN
+	}
+	resource := strings.Split(strings.TrimPrefix(parts[5], "assumed-role/"), "/")
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", parts[1], parts[4], resource[0])
+}
+
+func createActions(spec *api.ClusterConfig, nodeGroups []*api.NodeGroup, withCluster bool) []string {
+	actions := []string{"cloudformation:CreateStack"}
+
+	createsRoles := withCluster && spec.IAM.ServiceRoleARN == ""
+	createsInstanceProfiles := false
+	for _, ng := range nodeGroups {
+		if ng.IAM == nil || ng.IAM.InstanceProfileARN == "" {
+			createsInstanceProfiles = true
+			if ng.IAM == nil || ng.IAM.InstanceRoleARN == "" {
+				createsRoles = true
+			}
+		}
+	}
+	// creating roles requires CAPABILITY_NAMED_IAM, which only succeeds when the caller
+	// can perform the IAM actions itself
+	if createsRoles {
+		actions = append(actions, "iam:CreateRole", "iam:AttachRolePolicy", "iam:PutRolePolicy", "iam:GetRole", "iam:PassRole")
+	}
+	if createsInstanceProfiles {
+		actions = append(actions, "iam:CreateInstanceProfile", "iam:AddRoleToInstanceProfile")
+	}
+	return actions
+}
+
+func deleteActions() []string {
+	return []string{
+		"cloudformation:DeleteStack",
+		"iam:DeleteRole",
+		"iam:DetachRolePolicy",
+		"iam:DeleteRolePolicy",
+		"iam:RemoveRoleFromInstanceProfile",
+		"iam:DeleteInstanceProfile",
+	}
+}
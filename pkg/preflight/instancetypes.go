@@ -0,0 +1,88 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+)
+
+// instanceTypesAvailable checks that instance types of the nodegroups are offered in
+// the availability zones the nodegroups will use
+type instanceTypesAvailable struct {
+	nodeGroups []*api.NodeGroup
+}
+
+func (*instanceTypesAvailable) Name() string {
+	return "instance types available in zones"
+}
+
+func (c *instanceTypesAvailable) Run(provider api.ClusterProvider, spec *api.ClusterConfig) (Status, string) {
+	// there is no API to list instance types offered in a zone, but reserved
+	// instance offerings only exist for instance types that can be launched
+	zonesByInstanceType := make(map[string]sets.String)
+	for _, ng := range c.nodeGroups {
+		zones := ng.AvailabilityZones
+		if len(zones) == 0 {
+			zones = spec.AvailabilityZones
+		}
+		instanceTypes := []string{ng.InstanceType}
+		if ng.InstancesDistribution != nil && len(ng.InstancesDistribution.InstanceTypes) > 0 {
+			instanceTypes = ng.InstancesDistribution.InstanceTypes
+		}
+		for _, instanceType := range instanceTypes {
+			if _, ok := zonesByInstanceType[instanceType]; !ok {
+				zonesByInstanceType[instanceType] = sets.NewString()
+			}
+			zonesByInstanceType[instanceType].Insert(zones...)
+		}
+	}
+
+	problems := []string{}
+	for instanceType, zones := range zonesByInstanceType {
+		if zones.Len() == 0 {
+			continue
+		}
+		offered, err := zonesOfferingInstanceType(provider.EC2(), instanceType, zones.List())
+		if err != nil {
+			return warnUnableToCheck(err)
+		}
+		if missing := zones.Difference(offered); missing.Len() > 0 {
+			problems = append(problems, fmt.Sprintf("%s may not be offered in %s", instanceType, strings.Join(missing.List(), ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		// the lack of reserved instance offerings is only a hint, so it's not a failure
+		return StatusWarn, fmt.Sprintf("%s, as there are no reserved instance offerings", strings.Join(problems, "; "))
+	}
+	return StatusPass, ""
+}
+
+func zonesOfferingInstanceType(ec2API ec2iface.EC2API, instanceType string, zones []string) (sets.String, error) {
+	input := &ec2.DescribeReservedInstancesOfferingsInput{
+		InstanceType:       aws.String(instanceType),
+		IncludeMarketplace: aws.Bool(false),
+		ProductDescription: aws.String("Linux/UNIX"),
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("availability-zone"),
+			Values: aws.StringSlice(zones),
+		}},
+	}
+	offered := sets.NewString()
+	err := ec2API.DescribeReservedInstancesOfferingsPages(input, func(output *ec2.DescribeReservedInstancesOfferingsOutput, _ bool) bool {
+		for _, offering := range output.ReservedInstancesOfferings {
+			offered.Insert(aws.StringValue(offering.AvailabilityZone))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "describing offerings of %s", instanceType)
+	}
+	return offered, nil
+}
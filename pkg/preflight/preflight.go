@@ -0,0 +1,166 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kris-nova/logger"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/printers"
+)
+
+// Status is the outcome of a check
+type Status string
+
+// Check outcomes, only failures abort the command
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Check is a pre-flight check, it's meant to find problems that would otherwise
+// only surface once CloudFormation has started creating or deleting resources
+type Check interface {
+	// Name describes what is checked
+	Name() string
+	// Run performs the check, any problem with performing the check itself
+	// should result in a warning rather than a failure
+	Run(provider api.ClusterProvider, spec *api.ClusterConfig) (Status, string)
+}
+
+// Result is the outcome of a single check
+type Result struct {
+	Check   string
+	Status  Status
+	Message string
+}
+
+// Results is the outcome of all checks
+type Results []Result
+
+// Failed returns the results of checks that failed
+func (r Results) Failed() Results {
+	failed := Results{}
+	for _, result := range r {
+		if result.Status == StatusFail {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Runner runs a set of checks against a cluster
+type Runner struct {
+	provider api.ClusterProvider
+	spec     *api.ClusterConfig
+	checks   []Check
+}
+
+// NewRunner creates a runner for the given checks
+func NewRunner(provider api.ClusterProvider, spec *api.ClusterConfig, checks ...Check) *Runner {
+	return &Runner{
+		provider: provider,
+		spec:     spec,
+		checks:   checks,
+	}
+}
+
+// Add adds more checks to the runner
+func (r *Runner) Add(checks ...Check) {
+	r.checks = append(r.checks, checks...)
+}
+
+// Run runs all checks, one after another
+func (r *Runner) Run() Results {
+	results := Results{}
+	for _, check := range r.checks {
+		logger.Debug("running pre-flight check %q", check.Name())
+		status, message := check.Run(r.provider, r.spec)
+		results = append(results, Result{
+			Check:   check.Name(),
+			Status:  status,
+			Message: message,
+		})
+	}
+	return results
+}
+
+// RunAndReport runs all checks, logs a table of results and returns an
+// error if any of the checks has failed
+func (r *Runner) RunAndReport() error {
+	results := r.Run()
+	if len(results) == 0 {
+		return nil
+	}
+
+	printer := printers.NewTablePrinter().(*printers.TablePrinter)
+	printer.AddColumn("CHECK", func(result Result) string {
+		return result.Check
+	})
+	printer.AddColumn("STATUS", func(result Result) string {
+		return strings.ToUpper(string(result.Status))
+	})
+	printer.AddColumn("DETAILS", func(result Result) string {
+		return result.Message
+	})
+	if err := printer.LogObj(logger.Info, "pre-flight checks:\n%s", results); err != nil {
+		return err
+	}
+
+	if failed := results.Failed(); len(failed) > 0 {
+		checks := []string{}
+		for _, result := range failed {
+			checks = append(checks, result.Check)
+		}
+		return fmt.Errorf("%d pre-flight check(s) failed (%s), fix the problems or use --skip-preflight to ignore them", len(failed), strings.Join(checks, ", "))
+	}
+	return nil
+}
+
+// CreateClusterChecks returns the checks to run before creating a cluster with the given nodegroups
+func CreateClusterChecks(spec *api.ClusterConfig, nodeGroupNames sets.String) []Check {
+	nodeGroups := selectNodeGroups(spec, nodeGroupNames)
+	return []Check{
+		&noClusterStacks{},
+		&elasticIPQuota{},
+		&instanceQuota{nodeGroups: nodeGroups},
+		&instanceTypesAvailable{nodeGroups: nodeGroups},
+		&iamPermissions{actions: createActions(spec, nodeGroups, true)},
+	}
+}
+
+// CreateNodeGroupsChecks returns the checks to run before creating the given nodegroups
+func CreateNodeGroupsChecks(spec *api.ClusterConfig, nodeGroupNames sets.String) []Check {
+	nodeGroups := selectNodeGroups(spec, nodeGroupNames)
+	return []Check{
+		&noNodeGroupStacks{nodeGroups: nodeGroups},
+		&instanceQuota{nodeGroups: nodeGroups},
+		&instanceTypesAvailable{nodeGroups: nodeGroups},
+		&iamPermissions{actions: createActions(spec, nodeGroups, false)},
+	}
+}
+
+// DeleteClusterChecks returns the checks to run before deleting a cluster
+func DeleteClusterChecks() []Check {
+	return []Check{
+		&stacksNotInProgress{},
+		&iamPermissions{actions: deleteActions()},
+	}
+}
+
+func selectNodeGroups(spec *api.ClusterConfig, names sets.String) []*api.NodeGroup {
+	nodeGroups := []*api.NodeGroup{}
+	for _, ng := range spec.NodeGroups {
+		if names.Has(ng.Name) {
+			nodeGroups = append(nodeGroups, ng)
+		}
+	}
+	return nodeGroups
+}
+
+func warnUnableToCheck(err error) (Status, string) {
+	return StatusWarn, fmt.Sprintf("unable to check: %s", err.Error())
+}
@@ -0,0 +1,179 @@
+package preflight
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
+)
+
+type fixedCheck struct {
+	name   string
+	status Status
+	ran    bool
+}
+
+func (c *fixedCheck) Name() string {
+	return c.name
+}
+
+func (c *fixedCheck) Run(_ api.ClusterProvider, _ *api.ClusterConfig) (Status, string) {
+	c.ran = true
+	return c.status, ""
+}
+
+var _ = Describe("Pre-flight checks", func() {
+	var (
+		p   *mockprovider.MockProvider
+		cfg *api.ClusterConfig
+	)
+
+	BeforeEach(func() {
+		p = mockprovider.NewMockProvider()
+		cfg = api.NewClusterConfig()
+		cfg.Metadata.Name = "test"
+		cfg.AvailabilityZones = []string{"us-west-2a", "us-west-2b", "us-west-2c"}
+	})
+
+	Context("Runner", func() {
+		It("should run all checks and fail only if a check has failed", func() {
+			checks := []Check{
+				&fixedCheck{name: "a", status: StatusPass},
+				&fixedCheck{name: "b", status: StatusWarn},
+			}
+			Expect(NewRunner(p, cfg, checks...).RunAndReport()).To(Succeed())
+
+			failing := &fixedCheck{name: "c", status: StatusFail}
+			last := &fixedCheck{name: "d", status: StatusPass}
+			runner := NewRunner(p, cfg, checks...)
+			runner.Add(failing, last)
+
+			results := runner.Run()
+			Expect(results).To(HaveLen(4))
+			Expect(results.Failed()).To(Equal(Results{{Check: "c", Status: StatusFail}}))
+			Expect(last.ran).To(BeTrue())
+
+			err := runner.RunAndReport()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("1 pre-flight check(s) failed (c)"))
+		})
+	})
+
+	Context("Elastic IP quota", func() {
+		BeforeEach(func() {
+			p.MockEC2().On("DescribeAccountAttributes", mock.Anything).Return(&ec2.DescribeAccountAttributesOutput{
+				AccountAttributes: []*ec2.AccountAttribute{{
+					AttributeName: aws.String("vpc-max-elastic-ips"),
+					AttributeValues: []*ec2.AccountAttributeValue{
+						{AttributeValue: aws.String("5")},
+					},
+				}},
+			}, nil)
+			p.MockEC2().On("DescribeAddresses", mock.Anything).Return(&ec2.DescribeAddressesOutput{
+				Addresses: []*ec2.Address{{}, {}, {}},
+			}, nil)
+		})
+
+		It("should pass when there are enough Elastic IPs for a single NAT gateway", func() {
+			status, _ := (&elasticIPQuota{}).Run(p, cfg)
+			Expect(status).To(Equal(StatusPass))
+		})
+
+		It("should fail when there are too few Elastic IPs for highly available NAT gateways", func() {
+			cfg.VPC.NAT.Gateway = aws.String(api.ClusterHighlyAvailableNAT)
+			status, message := (&elasticIPQuota{}).Run(p, cfg)
+			Expect(status).To(Equal(StatusFail))
+			Expect(message).To(ContainSubstring("3 Elastic IP(s) are required for NAT gateways, but only 2 of 5 are available"))
+		})
+
+		It("should not require Elastic IPs when the VPC is imported", func() {
+			cfg.VPC.ID = "vpc-123"
+			Expect(requiredElasticIPs(cfg)).To(Equal(0))
+		})
+	})
+
+	Context("instance types", func() {
+		It("should warn when an instance type may not be offered in a zone", func() {
+			ng := cfg.NewNodeGroup()
+			ng.InstanceType = "p3.2xlarge"
+
+			p.MockEC2().On("DescribeReservedInstancesOfferingsPages", mock.Anything, mock.Anything).Return(func(_ *ec2.DescribeReservedInstancesOfferingsInput, fn func(*ec2.DescribeReservedInstancesOfferingsOutput, bool) bool) error {
+				fn(&ec2.DescribeReservedInstancesOfferingsOutput{
+					ReservedInstancesOfferings: []*ec2.ReservedInstancesOffering{
+						{AvailabilityZone: aws.String("us-west-2a")},
+						{AvailabilityZone: aws.String("us-west-2b")},
+					},
+				}, true)
+				return nil
+			})
+
+			status, message := (&instanceTypesAvailable{nodeGroups: cfg.NodeGroups}).Run(p, cfg)
+			Expect(status).To(Equal(StatusWarn))
+			Expect(message).To(Equal("p3.2xlarge may not be offered in us-west-2c, as there are no reserved instance offerings"))
+		})
+	})
+
+	Context("stacks in progress", func() {
+		mockStacks := func(statuses map[string]string) {
+			summaries := []*cloudformation.StackSummary{}
+			for name := range statuses {
+				summaries = append(summaries, &cloudformation.StackSummary{StackName: aws.String(name)})
+			}
+			p.MockCloudFormation().On("ListStacksPages", mock.Anything, mock.Anything).Return(func(_ *cloudformation.ListStacksInput, fn func(*cloudformation.ListStacksOutput, bool) bool) error {
+				fn(&cloudformation.ListStacksOutput{StackSummaries: summaries}, true)
+				return nil
+			})
+			p.MockCloudFormation().On("DescribeStacks", mock.Anything).Return(func(input *cloudformation.DescribeStacksInput) *cloudformation.DescribeStacksOutput {
+				return &cloudformation.DescribeStacksOutput{
+					Stacks: []*cloudformation.Stack{{
+						StackName:   input.StackName,
+						StackStatus: aws.String(statuses[*input.StackName]),
+					}},
+				}
+			}, nil)
+		}
+
+		It("should allow stacks that are being deleted", func() {
+			mockStacks(map[string]string{
+				"eksctl-test-cluster":        cloudformation.StackStatusCreateComplete,
+				"eksctl-test-nodegroup-ng-1": cloudformation.StackStatusDeleteInProgress,
+			})
+
+			status, _ := (&stacksNotInProgress{}).Run(p, cfg)
+			Expect(status).To(Equal(StatusPass))
+		})
+
+		It("should fail when a stack is being changed", func() {
+			mockStacks(map[string]string{
+				"eksctl-test-cluster":        cloudformation.StackStatusCreateComplete,
+				"eksctl-test-nodegroup-ng-1": cloudformation.StackStatusUpdateInProgress,
+			})
+
+			status, message := (&stacksNotInProgress{}).Run(p, cfg)
+			Expect(status).To(Equal(StatusFail))
+			Expect(message).To(ContainSubstring(`"eksctl-test-nodegroup-ng-1" (UPDATE_IN_PROGRESS)`))
+		})
+	})
+
+	Context("IAM permissions", func() {
+		It("should use the role of an assumed role session", func() {
+			Expect(policySourceARN("arn:aws:sts::123456789012:assumed-role/admin/session")).To(Equal("arn:aws:iam::123456789012:role/admin"))
+			Expect(policySourceARN("arn:aws:iam::123456789012:user/alice")).To(Equal("arn:aws:iam::123456789012:user/alice"))
+		})
+
+		It("should only require IAM actions for roles that will be created", func() {
+			cfg.IAM.ServiceRoleARN = "arn:aws:iam::123456789012:role/eks"
+			ng := cfg.NewNodeGroup()
+			ng.IAM.InstanceProfileARN = "arn:aws:iam::123456789012:instance-profile/nodes"
+			Expect(createActions(cfg, cfg.NodeGroups, true)).To(Equal([]string{"cloudformation:CreateStack"}))
+
+			ng.IAM.InstanceProfileARN = ""
+			Expect(createActions(cfg, cfg.NodeGroups, true)).To(ContainElement("iam:CreateRole"))
+		})
+	})
+})
@@ -0,0 +1,133 @@
+package preflight
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/pkg/errors"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+)
+
+// elasticIPQuota checks that there are enough Elastic IPs for the NAT gateways of a new VPC
+type elasticIPQuota struct{}
+
+func (*elasticIPQuota) Name() string {
+	return "Elastic IP quota"
+}
+
+func (*elasticIPQuota) Run(provider api.ClusterProvider, spec *api.ClusterConfig) (Status, string) {
+	required := requiredElasticIPs(spec)
+	if required == 0 {
+		return StatusPass, "no NAT gateways will be created"
+	}
+
+	limit, err := accountAttribute(provider.EC2(), "vpc-max-elastic-ips")
+	if err != nil {
+		return warnUnableToCheck(err)
+	}
+	output, err := provider.EC2().DescribeAddresses(&ec2.DescribeAddressesInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("domain"),
+			Values: aws.StringSlice([]string{"vpc"}),
+		}},
+	})
+	if err != nil {
+		return warnUnableToCheck(errors.Wrap(err, "describing addresses"))
+	}
+
+	if available := limit - len(output.Addresses); available < required {
+		return StatusFail, fmt.Sprintf("%d Elastic IP(s) are required for NAT gateways, but only %d of %d are available; request a quota increase or use a different --vpc-nat-mode", required, available, limit)
+	}
+	return StatusPass, fmt.Sprintf("%d Elastic IP(s) required", required)
+}
+
+// requiredElasticIPs returns the number of NAT gateways that will be created
+func requiredElasticIPs(spec *api.ClusterConfig) int {
+	if spec.VPC == nil || spec.VPC.ID != "" || spec.VPC.NAT == nil || spec.VPC.NAT.Gateway == nil {
+		return 0
+	}
+	switch *spec.VPC.NAT.Gateway {
+	case api.ClusterHighlyAvailableNAT:
+		return len(spec.AvailabilityZones)
+	case api.ClusterSingleNAT:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// instanceQuota checks that the account can run the desired number of instances, it
+// only warns as the limit is being replaced by vCPU-based limits
+type instanceQuota struct {
+	nodeGroups []*api.NodeGroup
+}
+
+func (*instanceQuota) Name() string {
+	return "EC2 instance quota"
+}
+
+func (c *instanceQuota) Run(provider api.ClusterProvider, spec *api.ClusterConfig) (Status, string) {
+	required := 0
+	for _, ng := range c.nodeGroups {
+		switch {
+		case ng.DesiredCapacity != nil:
+			required += *ng.DesiredCapacity
+		case ng.MinSize != nil:
+			required += *ng.MinSize
+		}
+	}
+	if required == 0 {
+		return StatusPass, "no instances will be launched"
+	}
+
+	limit, err := accountAttribute(provider.EC2(), "max-instances")
+	if err != nil {
+		return warnUnableToCheck(err)
+	}
+
+	running := 0
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning}),
+		}},
+	}
+	err = provider.EC2().DescribeInstancesPages(input, func(output *ec2.DescribeInstancesOutput, _ bool) bool {
+		for _, reservation := range output.Reservations {
+			running += len(reservation.Instances)
+		}
+		return true
+	})
+	if err != nil {
+		return warnUnableToCheck(errors.Wrap(err, "describing instances"))
+	}
+
+	if available := limit - running; available < required {
+		return StatusWarn, fmt.Sprintf("%d instance(s) will be launched, but only %d of %d are available, nodes may fail to launch", required, available, limit)
+	}
+	return StatusPass, fmt.Sprintf("%d instance(s) will be launched", required)
+}
+
+func accountAttribute(ec2API ec2iface.EC2API, name string) (int, error) {
+	output, err := ec2API.DescribeAccountAttributes(&ec2.DescribeAccountAttributesInput{
+		AttributeNames: aws.StringSlice([]string{name}),
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "describing account attribute %q", name)
+	}
+	for _, attribute := range output.AccountAttributes {
+		if aws.StringValue(attribute.AttributeName) != name || len(attribute.AttributeValues) == 0 {
+			continue
+		}
+		value, err := strconv.Atoi(aws.StringValue(attribute.AttributeValues[0].AttributeValue))
+		if err != nil {
+			return 0, errors.Wrapf(err, "parsing account attribute %q", name)
+		}
+		return value, nil
+	}
+	return 0, fmt.Errorf("account attribute %q not found", name)
+}
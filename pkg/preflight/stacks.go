@@ -0,0 +1,103 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/cfn/manager"
+)
+
+// noClusterStacks checks that there are no stacks left from a cluster with the same name
+type noClusterStacks struct{}
+
+func (*noClusterStacks) Name() string {
+	return "no existing cluster stacks"
+}
+
+func (*noClusterStacks) Run(provider api.ClusterProvider, spec *api.ClusterConfig) (Status, string) {
+	stacks, err := manager.NewStackCollection(provider, spec).ListStacksForCluster()
+	if err != nil {
+		return warnUnableToCheck(err)
+	}
+	if len(stacks) > 0 {
+		return StatusFail, fmt.Sprintf("found existing stack(s) %s, delete them or use a different cluster name", stackNamesWithStatus(stacks))
+	}
+	return StatusPass, ""
+}
+
+// noNodeGroupStacks checks that none of the nodegroups exist already
+type noNodeGroupStacks struct {
+	nodeGroups []*api.NodeGroup
+}
+
+func (*noNodeGroupStacks) Name() string {
+	return "no existing nodegroup stacks"
+}
+
+func (c *noNodeGroupStacks) Run(provider api.ClusterProvider, spec *api.ClusterConfig) (Status, string) {
+	stackManager := manager.NewStackCollection(provider, spec)
+	stacks, err := stackManager.ListStacksForCluster()
+	if err != nil {
+		return warnUnableToCheck(err)
+	}
+
+	names := sets.NewString()
+	for _, ng := range c.nodeGroups {
+		names.Insert(ng.Name)
+	}
+	existing := []*manager.Stack{}
+	for _, s := range stacks {
+		if names.Has(stackManager.GetNodeGroupName(s)) {
+			existing = append(existing, s)
+		}
+	}
+	if len(existing) > 0 {
+		return StatusFail, fmt.Sprintf("found existing stack(s) %s, delete them or use different nodegroup names", stackNamesWithStatus(existing))
+	}
+	return StatusPass, ""
+}
+
+// stacksNotInProgress checks that none of the stacks of the cluster is being changed
+type stacksNotInProgress struct{}
+
+func (*stacksNotInProgress) Name() string {
+	return "no stacks in progress"
+}
+
+func (*stacksNotInProgress) Run(provider api.ClusterProvider, spec *api.ClusterConfig) (Status, string) {
+	stacks, err := manager.NewStackCollection(provider, spec).ListStacksForCluster()
+	if err != nil {
+		return warnUnableToCheck(err)
+	}
+	if len(stacks) == 0 {
+		return StatusWarn, "no stacks found, only resources that weren't created by CloudFormation will be deleted"
+	}
+	inProgress := []*manager.Stack{}
+	for _, s := range stacks {
+		status := aws.StringValue(s.StackStatus)
+		// a stack that is already being deleted doesn't prevent deleting the cluster
+		if status == cloudformation.StackStatusDeleteInProgress {
+			continue
+		}
+		if strings.HasSuffix(status, "_IN_PROGRESS") {
+			inProgress = append(inProgress, s)
+		}
+	}
+	if len(inProgress) > 0 {
+		return StatusFail, fmt.Sprintf("stack(s) %s are still being changed, wait until they're done", stackNamesWithStatus(inProgress))
+	}
+	return StatusPass, ""
+}
+
+func stackNamesWithStatus(stacks []*manager.Stack) string {
+	names := []string{}
+	for _, s := range stacks {
+		names = append(names, fmt.Sprintf("%q (%s)", aws.StringValue(s.StackName), aws.StringValue(s.StackStatus)))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,68 @@
+package ssh
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/pkg/errors"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+)
+
+// ListNodeGroupInstances returns running instances of a nodegroup
+func ListNodeGroupInstances(clusterName, ngName string, provider api.ClusterProvider) ([]*ec2.Instance, error) {
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("tag:" + api.ClusterNameTag),
+				Values: aws.StringSlice([]string{clusterName}),
+			},
+			{
+				Name:   aws.String("tag:" + api.NodeGroupNameTag),
+				Values: aws.StringSlice([]string{ngName}),
+			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{ec2.InstanceStateNameRunning}),
+			},
+		},
+	}
+
+	instances := []*ec2.Instance{}
+	for {
+		output, err := provider.EC2().DescribeInstances(input)
+		if err != nil {
+			return nil, errors.Wrapf(err, "describing instances of nodegroup %q", ngName)
+		}
+		for _, reservation := range output.Reservations {
+			instances = append(instances, reservation.Instances...)
+		}
+		if output.NextToken == nil {
+			return instances, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// SessionCommand returns the command that starts an SSM session to the given instance
+func SessionCommand(region, instanceID string) []string {
+	return []string{"aws", "ssm", "start-session", "--target", instanceID, "--region", region}
+}
+
+// FindInstance returns the instance with the given ID, or the only instance
+// if ID is empty, it is an error if there is more than one instance to choose from
+func FindInstance(instances []*ec2.Instance, instanceID string) (*ec2.Instance, error) {
+	if instanceID == "" {
+		if len(instances) != 1 {
+			return nil, fmt.Errorf("found %d instances, an instance ID must be specified", len(instances))
+		}
+		return instances[0], nil
+	}
+	for _, instance := range instances {
+		if aws.StringValue(instance.InstanceId) == instanceID {
+			return instance, nil
+		}
+	}
+	return nil, fmt.Errorf("instance %q not found", instanceID)
+}
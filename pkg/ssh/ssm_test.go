@@ -0,0 +1,87 @@
+package ssh
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/weaveworks/eksctl/pkg/testutils/mockprovider"
+)
+
+var _ = Describe("ssm sessions", func() {
+	var (
+		mockProvider *mockprovider.MockProvider
+	)
+
+	BeforeEach(func() {
+		mockProvider = mockprovider.NewMockProvider()
+	})
+
+	It("should list instances of a nodegroup across all pages", func() {
+		mockProvider.MockEC2().
+			On("DescribeInstances", mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+				return input.NextToken == nil && len(input.Filters) == 3 &&
+					*input.Filters[0].Values[0] == "cluster-1" &&
+					*input.Filters[1].Values[0] == "ng-1"
+			})).
+			Return(&ec2.DescribeInstancesOutput{
+				Reservations: []*ec2.Reservation{
+					{Instances: []*ec2.Instance{{InstanceId: aws.String("i-1")}}},
+				},
+				NextToken: aws.String("next"),
+			}, nil)
+		mockProvider.MockEC2().
+			On("DescribeInstances", mock.MatchedBy(func(input *ec2.DescribeInstancesInput) bool {
+				return input.NextToken != nil && *input.NextToken == "next"
+			})).
+			Return(&ec2.DescribeInstancesOutput{
+				Reservations: []*ec2.Reservation{
+					{Instances: []*ec2.Instance{{InstanceId: aws.String("i-2")}}},
+				},
+			}, nil)
+
+		instances, err := ListNodeGroupInstances("cluster-1", "ng-1", *mockProvider)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instances).To(HaveLen(2))
+		Expect(*instances[0].InstanceId).To(Equal("i-1"))
+		Expect(*instances[1].InstanceId).To(Equal("i-2"))
+	})
+
+	It("should build the session command", func() {
+		Expect(SessionCommand("eu-north-1", "i-1")).To(Equal([]string{
+			"aws", "ssm", "start-session", "--target", "i-1", "--region", "eu-north-1",
+		}))
+	})
+
+	Describe("finding an instance", func() {
+		instances := []*ec2.Instance{
+			{InstanceId: aws.String("i-1")},
+			{InstanceId: aws.String("i-2")},
+		}
+
+		It("should find an instance by ID", func() {
+			instance, err := FindInstance(instances, "i-2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*instance.InstanceId).To(Equal("i-2"))
+		})
+
+		It("should require an ID when there is more than one instance", func() {
+			_, err := FindInstance(instances, "")
+			Expect(err).To(MatchError("found 2 instances, an instance ID must be specified"))
+		})
+
+		It("should return the only instance when no ID is given", func() {
+			instance, err := FindInstance(instances[:1], "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*instance.InstanceId).To(Equal("i-1"))
+		})
+
+		It("should fail for unknown instances", func() {
+			_, err := FindInstance(instances, "i-3")
+			Expect(err).To(MatchError(`instance "i-3" not found`))
+		})
+	})
+})
@@ -4,13 +4,16 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
 	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
 	"github.com/aws/aws-sdk-go/service/cloudtrail/cloudtrailiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/eks/eksiface"
 	"github.com/aws/aws-sdk-go/service/elb/elbiface"
 	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 
 	//"github.com/aws/aws-sdk-go/aws/awserr"
@@ -29,6 +32,8 @@ var ProviderConfig = &api.ProviderConfig{
 	Region:      api.DefaultRegion,
 	Profile:     "default",
 	WaitTimeout: 1200000000000,
+
+	StackEventsOutput: api.StackEventsOutputNone,
 }
 
 type MockAWSClient struct {
@@ -56,6 +61,10 @@ type MockProvider struct {
 	sts        *mocks.STSAPI
 	iam        *mocks.IAMAPI
 	cloudtrail *mocks.CloudTrailAPI
+
+	cloudwatchlogs *mocks.CloudWatchLogsAPI
+	asg            *mocks.AutoScalingAPI
+	ssm            *mocks.SSMAPI
 }
 
 // NewMockProvider returns a new MockProvider
@@ -71,6 +80,10 @@ func NewMockProvider() *MockProvider {
 		sts:        &mocks.STSAPI{},
 		iam:        &mocks.IAMAPI{},
 		cloudtrail: &mocks.CloudTrailAPI{},
+
+		cloudwatchlogs: &mocks.CloudWatchLogsAPI{},
+		asg:            &mocks.AutoScalingAPI{},
+		ssm:            &mocks.SSMAPI{},
 	}
 }
 
@@ -123,6 +136,28 @@ func (m MockProvider) MockCloudTrail() *mocks.CloudTrailAPI {
 	return m.CloudTrail().(*mocks.CloudTrailAPI)
 }
 
+// CloudWatchLogs returns a representation of the CloudWatchLogs API
+func (m MockProvider) CloudWatchLogs() cloudwatchlogsiface.CloudWatchLogsAPI {
+	return m.cloudwatchlogs
+}
+
+// MockCloudWatchLogs returns a mocked CloudWatchLogs API
+func (m MockProvider) MockCloudWatchLogs() *mocks.CloudWatchLogsAPI {
+	return m.CloudWatchLogs().(*mocks.CloudWatchLogsAPI)
+}
+
+// ASG returns a representation of the AutoScaling API
+func (m MockProvider) ASG() autoscalingiface.AutoScalingAPI { return m.asg }
+
+// MockASG returns a mocked AutoScaling API
+func (m MockProvider) MockASG() *mocks.AutoScalingAPI { return m.ASG().(*mocks.AutoScalingAPI) }
+
+// SSM returns a representation of the SSM API
+func (m MockProvider) SSM() ssmiface.SSMAPI { return m.ssm }
+
+// MockSSM returns a mocked SSM API
+func (m MockProvider) MockSSM() *mocks.SSMAPI { return m.SSM().(*mocks.SSMAPI) }
+
 // Profile returns current profile setting
 func (m MockProvider) Profile() string { return ProviderConfig.Profile }
 
@@ -132,6 +167,9 @@ func (m MockProvider) Region() string { return ProviderConfig.Region }
 // WaitTimeout returns current timeout setting
 func (m MockProvider) WaitTimeout() time.Duration { return ProviderConfig.WaitTimeout }
 
+// StackEventsOutput returns current stack events output setting
+func (m MockProvider) StackEventsOutput() string { return ProviderConfig.StackEventsOutput }
+
 func NewMockAWSClient() *MockAWSClient {
 	m := &MockAWSClient{
 		Client: awstesting.NewClient(&aws.Config{
@@ -0,0 +1,87 @@
+package kubeconfig
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthv1alpha1 "k8s.io/client-go/pkg/apis/clientauthentication/v1alpha1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	execCredentialAPIVersion = "client.authentication.k8s.io/v1alpha1"
+
+	// TokenExpiration is how long a token is considered valid for, tokens are accepted by EKS
+	// for 15 minutes, this leaves a margin for clock skew
+	TokenExpiration = 14 * time.Minute
+
+	// tokenRefreshWindow is how long before expiration cached token gets refreshed
+	tokenRefreshWindow = time.Minute
+)
+
+// NewExecCredential serialises token as ExecCredential object for use by kubectl
+func NewExecCredential(token string, expiration time.Time) ([]byte, error) {
+	expirationTimestamp := metav1.NewTime(expiration)
+	credential := clientauthv1alpha1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: execCredentialAPIVersion,
+			Kind:       "ExecCredential",
+		},
+		Status: &clientauthv1alpha1.ExecCredentialStatus{
+			Token:               token,
+			ExpirationTimestamp: &expirationTimestamp,
+		},
+	}
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return nil, errors.Wrap(err, "serialising ExecCredential")
+	}
+	return data, nil
+}
+
+// TokenCachePath returns the path to a file used for caching tokens of the given cluster,
+// role and profile are included in the file name, as they determine the identity
+func TokenCachePath(clusterName, region, roleARN, profile string) string {
+	identity := sha256.Sum256([]byte(fmt.Sprintf("%s/%s", roleARN, profile)))
+	fileName := fmt.Sprintf("token-%s-%s-%x.json", clusterName, region, identity[:8])
+	return path.Join(clientcmd.RecommendedConfigDir, "eksctl", "cache", fileName)
+}
+
+// ReadCachedExecCredential returns ExecCredential stored in the cache file, as long as
+// it is not about to expire; a missing or invalid cache file is treated as a cache miss
+func ReadCachedExecCredential(cachePath string, now time.Time) ([]byte, bool) {
+	data, err := ioutil.ReadFile(cachePath)
+	if err != nil {
+		return nil, false
+	}
+	credential := clientauthv1alpha1.ExecCredential{}
+	if err := json.Unmarshal(data, &credential); err != nil {
+		return nil, false
+	}
+	status := credential.Status
+	if status == nil || status.Token == "" || status.ExpirationTimestamp == nil {
+		return nil, false
+	}
+	if !now.Add(tokenRefreshWindow).Before(status.ExpirationTimestamp.Time) {
+		return nil, false
+	}
+	return data, true
+}
+
+// WriteCachedExecCredential stores ExecCredential in the cache file, which is only readable by the user
+func WriteCachedExecCredential(cachePath string, data []byte) error {
+	if err := os.MkdirAll(path.Dir(cachePath), 0700); err != nil {
+		return errors.Wrapf(err, "creating token cache directory")
+	}
+	if err := ioutil.WriteFile(cachePath, data, 0600); err != nil {
+		return errors.Wrapf(err, "writing token cache file %q", cachePath)
+	}
+	return nil
+}
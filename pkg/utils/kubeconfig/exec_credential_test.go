@@ -0,0 +1,95 @@
+package kubeconfig_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	eksctlapi "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha5"
+	"github.com/weaveworks/eksctl/pkg/utils/kubeconfig"
+)
+
+var _ = Describe("ExecCredential", func() {
+	var (
+		cacheDir  string
+		cachePath string
+		now       = time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		var err error
+		cacheDir, err = ioutil.TempDir("", "")
+		Expect(err).ToNot(HaveOccurred())
+		cachePath = path.Join(cacheDir, "cache", "token.json")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(cacheDir)
+	})
+
+	It("serialises ExecCredential", func() {
+		data, err := kubeconfig.NewExecCredential("k8s-aws-v1.abc", now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(MatchJSON(`{
+			"kind": "ExecCredential",
+			"apiVersion": "client.authentication.k8s.io/v1alpha1",
+			"spec": {},
+			"status": {
+				"expirationTimestamp": "2019-06-01T12:00:00Z",
+				"token": "k8s-aws-v1.abc"
+			}
+		}`))
+	})
+
+	It("uses cached token until it is about to expire", func() {
+		data, err := kubeconfig.NewExecCredential("k8s-aws-v1.abc", now.Add(kubeconfig.TokenExpiration))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(kubeconfig.WriteCachedExecCredential(cachePath, data)).To(Succeed())
+
+		cached, ok := kubeconfig.ReadCachedExecCredential(cachePath, now)
+		Expect(ok).To(BeTrue())
+		Expect(cached).To(Equal(data))
+
+		_, ok = kubeconfig.ReadCachedExecCredential(cachePath, now.Add(kubeconfig.TokenExpiration-30*time.Second))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("writes cache file only readable by the user", func() {
+		Expect(kubeconfig.WriteCachedExecCredential(cachePath, []byte("{}"))).To(Succeed())
+		info, err := os.Stat(cachePath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("treats missing or invalid cache as a miss", func() {
+		_, ok := kubeconfig.ReadCachedExecCredential(cachePath, now)
+		Expect(ok).To(BeFalse())
+
+		Expect(kubeconfig.WriteCachedExecCredential(cachePath, []byte("not json"))).To(Succeed())
+		_, ok = kubeconfig.ReadCachedExecCredential(cachePath, now)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("uses different cache files for different identities", func() {
+		Expect(kubeconfig.TokenCachePath("cluster-1", "us-west-2", "", "default")).
+			ToNot(Equal(kubeconfig.TokenCachePath("cluster-1", "us-west-2", "arn:aws:iam::123:role/admin", "default")))
+	})
+
+	It("points exec plugin at eksctl", func() {
+		cfg := eksctlapi.NewClusterConfig()
+		cfg.Metadata.Name = "cluster-1"
+		cfg.Metadata.Region = "us-west-2"
+		cfg.Status = &eksctlapi.ClusterStatus{Endpoint: "https://127.0.0.1:8443"}
+
+		config := kubeconfig.NewForKubectl(cfg, "user", kubeconfig.EksctlAuthenticator, "arn:aws:iam::123:role/admin", "")
+		exec := config.AuthInfos[config.CurrentContext].Exec
+		Expect(exec.Command).To(Equal("eksctl"))
+		Expect(exec.Args).To(Equal([]string{
+			"get-token", "--cluster", "cluster-1", "--region", "us-west-2", "--role-arn", "arn:aws:iam::123:role/admin",
+		}))
+	})
+})
@@ -27,6 +27,9 @@ const (
 	HeptioAuthenticatorAWS = "heptio-authenticator-aws"
 	// AWSEKSAuthenticator defines the recently added `aws eks get-token` command
 	AWSEKSAuthenticator = "aws"
+	// EksctlAuthenticator defines the `eksctl get-token` command, it is used when
+	// none of the other authenticators are available
+	EksctlAuthenticator = "eksctl"
 )
 
 // AuthenticatorCommands returns all of authenticator commands
@@ -38,6 +41,16 @@ func AuthenticatorCommands() []string {
 	}
 }
 
+// IsSupportedAuthenticator checks if the given authenticator command can be used in kubeconfig
+func IsSupportedAuthenticator(authenticatorCMD string) bool {
+	for _, cmd := range append(AuthenticatorCommands(), EksctlAuthenticator) {
+		if cmd == authenticatorCMD {
+			return true
+		}
+	}
+	return false
+}
+
 // New creates Kubernetes client configuration for a given username
 // if certificateAuthorityPath is not empty, it is used instead of
 // embedded certificate-authority-data
@@ -72,13 +85,20 @@ func New(spec *api.ClusterConfig, username, certificateAuthorityPath string) (*c
 	return c, clusterName, contextName
 }
 
-// NewForKubectl creates configuration for kubectl using a suitable authenticator
-func NewForKubectl(spec *api.ClusterConfig, username, roleARN, profile string) *clientcmdapi.Config {
+// NewForKubectl creates configuration for kubectl using the given authenticator,
+// or a suitable one if authenticator is empty
+func NewForKubectl(spec *api.ClusterConfig, username, authenticator, roleARN, profile string) *clientcmdapi.Config {
 	config, _, _ := New(spec, username, "")
-	authenticator, found := LookupAuthenticator()
-	if !found {
-		// fall back to aws-iam-authenticator
-		authenticator = AWSIAMAuthenticator
+	if authenticator == "" {
+		var found bool
+		authenticator, found = LookupAuthenticator()
+		if !found {
+			// fall back to eksctl itself, kubectl resolves it via PATH like the other authenticators
+			authenticator = EksctlAuthenticator
+			if _, err := exec.LookPath(EksctlAuthenticator); err != nil {
+				logger.Warning("%s is not on PATH, kubectl won't be able to get a token until it is", EksctlAuthenticator)
+			}
+		}
 	}
 	AppendAuthenticator(config, spec, authenticator, roleARN, profile)
 	return config
@@ -103,13 +123,19 @@ func AppendAuthenticator(config *clientcmdapi.Config, spec *api.ClusterConfig, a
 		if spec.Metadata.Region != "" {
 			args = append(args, "--region", spec.Metadata.Region)
 		}
+	case EksctlAuthenticator:
+		args = []string{"get-token", "--cluster", spec.Metadata.Name}
+		roleARNFlag = "--role-arn"
+		if spec.Metadata.Region != "" {
+			args = append(args, "--region", spec.Metadata.Region)
+		}
 	}
 	if roleARN != "" {
 		args = append(args, roleARNFlag, roleARN)
 	}
 
 	execConfig := &clientcmdapi.ExecConfig{
-		APIVersion: "client.authentication.k8s.io/v1alpha1",
+		APIVersion: execCredentialAPIVersion,
 		Command:    authenticatorCMD,
 		Args:       args,
 	}
@@ -240,6 +266,47 @@ func MaybeDeleteConfig(cl *api.ClusterMeta) {
 	}
 }
 
+// ParseClusterName parses cluster name as written by eksctl, i.e. "<name>.<region>.eksctl.io",
+// it returns false for clusters that were not added by eksctl
+func ParseClusterName(clusterName string) (*api.ClusterMeta, bool) {
+	parts := strings.Split(clusterName, ".")
+	if len(parts) != 4 || parts[2] != "eksctl" || parts[3] != "io" || parts[0] == "" || parts[1] == "" {
+		return nil, false
+	}
+	return &api.ClusterMeta{Name: parts[0], Region: parts[1]}, true
+}
+
+// Prune removes information of eksctl clusters for which shouldRemove returns true
+// from the kubeconfig, it returns names of the removed clusters
+func Prune(path string, shouldRemove func(*api.ClusterMeta) bool) ([]string, error) {
+	configAccess := getConfigAccess(path)
+
+	config, err := configAccess.GetStartingConfig()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read existing kubeconfig file %q", path)
+	}
+
+	removed := []string{}
+	for clusterName := range config.Clusters {
+		cl, ok := ParseClusterName(clusterName)
+		if !ok || !shouldRemove(cl) {
+			continue
+		}
+		if deleteClusterInfo(config, cl) {
+			removed = append(removed, clusterName)
+		}
+	}
+
+	if len(removed) == 0 {
+		return removed, nil
+	}
+
+	if err := clientcmd.ModifyConfig(configAccess, *config, true); err != nil {
+		return nil, errors.Wrapf(err, "unable to update kubeconfig file %q", path)
+	}
+	return removed, nil
+}
+
 // deleteClusterInfo removes a cluster's information from the kubeconfig if the cluster name
 // provided by ctl matches a eksctl-created cluster in the kubeconfig
 // returns 'true' if the existing config has changes and 'false' otherwise
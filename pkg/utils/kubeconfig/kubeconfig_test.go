@@ -46,6 +46,18 @@ var _ = Describe("Kubeconfig", func() {
 		return ioutil.WriteFile(filename, []byte(minikubeSample), os.FileMode(0755))
 	}
 
+	It("parses cluster names written by eksctl", func() {
+		cl, ok := kubeconfig.ParseClusterName("cluster-one.us-west-2.eksctl.io")
+		Expect(ok).To(BeTrue())
+		Expect(cl.Name).To(Equal("cluster-one"))
+		Expect(cl.Region).To(Equal("us-west-2"))
+
+		_, ok = kubeconfig.ParseClusterName("minikube")
+		Expect(ok).To(BeFalse())
+		_, ok = kubeconfig.ParseClusterName("cluster-one.us-west-2.example.com")
+		Expect(ok).To(BeFalse())
+	})
+
 	It("creating new Kubeconfig", func() {
 		filename, err := kubeconfig.Write(configFile.Name(), testConfig, false)
 		Expect(err).To(BeNil())
@@ -282,6 +294,30 @@ var _ = Describe("Kubeconfig", func() {
 			Expect(configFileAsBytes).To(MatchYAML(oneClusterAsBytes), "Failed to delete cluster from config")
 		})
 
+		It("prunes clusters that no longer exist", func() {
+			removed, err := kubeconfig.Prune(configFile.Name(), func(cl *eksctlapi.ClusterMeta) bool {
+				return cl.Name == "cluster-two" && cl.Region == "us-west-2"
+			})
+			Expect(err).To(BeNil())
+			Expect(removed).To(Equal([]string{"cluster-two.us-west-2.eksctl.io"}))
+
+			configFileAsBytes, err := ioutil.ReadFile(configFile.Name())
+			Expect(err).To(BeNil())
+			Expect(configFileAsBytes).To(MatchYAML(oneClusterAsBytes), "Failed to prune cluster from config")
+		})
+
+		It("not change the kubeconfig if there is nothing to prune", func() {
+			removed, err := kubeconfig.Prune(configFile.Name(), func(_ *eksctlapi.ClusterMeta) bool {
+				return false
+			})
+			Expect(err).To(BeNil())
+			Expect(removed).To(BeEmpty())
+
+			configFileAsBytes, err := ioutil.ReadFile(configFile.Name())
+			Expect(err).To(BeNil())
+			Expect(configFileAsBytes).To(MatchYAML(twoClustersAsBytes), "Should not change")
+		})
+
 		It("not change the kubeconfig if the kubeconfig does not include the cluster", func() {
 			nonExistentClusterConfig := GetClusterConfig("not-a-cluster")
 			kubeconfig.MaybeDeleteConfig(nonExistentClusterConfig.Metadata)
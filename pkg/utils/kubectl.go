@@ -61,9 +61,9 @@ func CheckAllCommands(kubeconfigPath string, isContextSet bool, contextName stri
 	{
 		authenticator, found := kubeconfig.LookupAuthenticator()
 		if !found {
-			return fmt.Errorf("could not find any of the authenticator commands: %s", strings.Join(kubeconfig.AuthenticatorCommands(), ", "))
+			authenticator = kubeconfig.EksctlAuthenticator
 		}
-		logger.Debug("found authenticator: %s", authenticator)
+		logger.Debug("using authenticator: %s", authenticator)
 	}
 
 	if kubeconfigPath != "" {
@@ -13,19 +13,23 @@ import (
 )
 
 // Wait for something with a name to reach status that is expressed by acceptors using newRequest
-// until we hit waitTimeout, on unexpected status troubleshoot will be called with the desired
-// status as an argument, so that it can find what migth have gone wrong
-func Wait(name, msg string, acceptors []request.WaiterAcceptor, newRequest func() *request.Request, waitTimeout time.Duration, troubleshoot func(string)) error {
+// until we hit waitTimeout or parentCtx is cancelled, on unexpected status troubleshoot will be
+// called with the desired status as an argument, so that it can find what migth have gone wrong
+func Wait(parentCtx context.Context, name, msg string, acceptors []request.WaiterAcceptor, newRequest func() *request.Request, waitTimeout time.Duration, troubleshoot func(string)) error {
 	desiredStatus := fmt.Sprintf("%v", acceptors[0].Expected)
 	msg = fmt.Sprintf("%s to reach %q status", msg, desiredStatus)
 	name = strings.Join([]string{"wait", name, desiredStatus}, "_")
 
-	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	ctx, cancel := context.WithTimeout(parentCtx, waitTimeout)
 	defer cancel()
 	startTime := time.Now()
 	w := makeWaiter(ctx, name, msg, acceptors, newRequest)
 	logger.Debug("start %s", msg)
 	if waitErr := w.WaitWithContext(ctx); waitErr != nil {
+		if err := parentCtx.Err(); err != nil {
+			// there is nothing to troubleshoot, as waiting was cancelled
+			return errors.Wrap(err, msg)
+		}
 		if troubleshoot != nil {
 			troubleshoot(desiredStatus)
 		}
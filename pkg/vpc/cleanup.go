@@ -18,7 +18,9 @@ func fmtSecurityGroupNameRegexForCluster(name string) string {
 	return fmt.Sprintf(ourSecurityGroupNameRegexFmt, name)
 }
 
-func findDanglingENIs(ec2API ec2iface.EC2API, spec *api.ClusterConfig) ([]string, error) {
+// FindDanglingENIs returns IDs of network interfaces in the cluster VPC that are no longer
+// attached, but still belong to security groups of the cluster
+func FindDanglingENIs(ec2API ec2iface.EC2API, spec *api.ClusterConfig) ([]string, error) {
 	input := &ec2.DescribeNetworkInterfacesInput{
 		Filters: []*ec2.Filter{
 			{
@@ -63,7 +65,7 @@ func findDanglingENIs(ec2API ec2iface.EC2API, spec *api.ClusterConfig) ([]string
 
 // CleanupNetworkInterfaces finds and deletes any dangling ENIs
 func CleanupNetworkInterfaces(ec2API ec2iface.EC2API, spec *api.ClusterConfig) error {
-	eniIDs, err := findDanglingENIs(ec2API, spec)
+	eniIDs, err := FindDanglingENIs(ec2API, spec)
 	if err != nil {
 		return err
 	}